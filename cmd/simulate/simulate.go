@@ -0,0 +1,158 @@
+package simulate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hanzoai/ingress-parser/cli"
+	"github.com/hanzoai/ingress/pkg/api"
+)
+
+// Configuration is the configuration of the simulate command.
+type Configuration struct {
+	API        string            `description:"URL of the Hanzo Ingress API, including its base path." export:"true"`
+	EntryPoint string            `description:"Entry point receiving the request." export:"true"`
+	Method     string            `description:"Method of the request." export:"true"`
+	Host       string            `description:"Host of the request." export:"true"`
+	Path       string            `description:"Path of the request, with its query." export:"true"`
+	Headers    map[string]string `description:"Headers of the request." export:"true"`
+	ClientIP   string            `description:"IP address of the client." export:"true"`
+	SNI        string            `description:"Server name sent in the TLS ClientHello, it implies a TLS connection." export:"true"`
+	TLS        bool              `description:"Simulates a request received over TLS." export:"true"`
+	JSON       bool              `description:"Prints the result as JSON." export:"true"`
+}
+
+// NewCmd builds a new Simulate command.
+func NewCmd() *cli.Command {
+	config := &Configuration{
+		API:        "http://localhost:8080",
+		EntryPoint: "web",
+		Method:     http.MethodGet,
+		Path:       "/",
+	}
+
+	return &cli.Command{
+		Name: "simulate",
+		Description: `Asks the Hanzo Ingress API (which must be enabled) which routers, middlewares and service would handle a request, without sending any traffic.
+The command exits with a non-zero status when no router matches the request.`,
+		Configuration: config,
+		Resources:     []cli.ResourceLoader{&cli.FlagLoader{}},
+		Run: func(_ []string) error {
+			client := &http.Client{
+				Timeout: 5 * time.Second,
+				Transport: &http.Transport{
+					Proxy: nil,
+				},
+			}
+
+			result, err := Do(client, *config)
+			if err != nil {
+				return err
+			}
+
+			if config.JSON {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(result); err != nil {
+					return err
+				}
+			} else {
+				Print(os.Stdout, result)
+			}
+
+			if len(result.Routers) == 0 {
+				return errors.New("no router matches the request")
+			}
+
+			return nil
+		},
+	}
+}
+
+// Do calls the route simulation endpoint of the API.
+func Do(client *http.Client, config Configuration) (*api.SimulationResult, error) {
+	body, err := json.Marshal(api.SimulationRequest{
+		EntryPoint: config.EntryPoint,
+		Method:     config.Method,
+		Host:       config.Host,
+		Path:       config.Path,
+		Headers:    config.Headers,
+		ClientIP:   config.ClientIP,
+		SNI:        config.SNI,
+		TLS:        config.TLS,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Post(strings.TrimSuffix(config.API, "/")+"/v1/ingress/simulate", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("calling the API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected API response status %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var result api.SimulationResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decoding the API response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// Print writes a human-readable form of the simulation result.
+func Print(w io.Writer, result *api.SimulationResult) {
+	_, _ = fmt.Fprintf(w, "Entry point: %s\n", result.EntryPoint)
+
+	if len(result.Routers) == 0 {
+		_, _ = fmt.Fprintln(w, "Router:      none")
+	}
+
+	for i, router := range result.Routers {
+		label := "Router:"
+		if i > 0 {
+			label = "Child:"
+		}
+		_, _ = fmt.Fprintf(w, "%-12s %s\n", label, formatRouter(router))
+	}
+
+	for i, middleware := range result.Middlewares {
+		label := ""
+		if i == 0 {
+			label = "Middlewares:"
+		}
+
+		name := middleware.Name
+		if middleware.Type != "" {
+			name += " (" + middleware.Type + ")"
+		}
+		_, _ = fmt.Fprintf(w, "%-12s %s, from %s\n", label, name, middleware.Router)
+	}
+
+	if result.Service != "" {
+		_, _ = fmt.Fprintf(w, "%-12s %s\n", "Service:", result.Service)
+	}
+
+	for i, router := range result.RunnerUps {
+		label := ""
+		if i == 0 {
+			label = "Runner-ups:"
+		}
+		_, _ = fmt.Fprintf(w, "%-12s %s\n", label, formatRouter(router))
+	}
+}
+
+func formatRouter(router api.SimulatedRouter) string {
+	return fmt.Sprintf("%s [%s, priority %d] %s", router.Name, router.Protocol, router.Priority, router.Rule)
+}
@@ -0,0 +1,90 @@
+package simulate
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/hanzoai/ingress/pkg/api"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/config/runtime"
+	"github.com/hanzoai/ingress/pkg/config/static"
+)
+
+func TestDo(t *testing.T) {
+	rtConf := &runtime.Configuration{
+		Routers: map[string]*runtime.RouterInfo{
+			"api@file": {
+				Router: &dynamic.Router{
+					EntryPoints: []string{"web"},
+					Rule:        "Host(`example.com`) && PathPrefix(`/api`)",
+					Middlewares: []string{"auth"},
+					Service:     "api",
+				},
+				Status: runtime.StatusEnabled,
+				Using:  []string{"web"},
+			},
+			"site@file": {
+				Router: &dynamic.Router{
+					EntryPoints: []string{"web"},
+					Rule:        "Host(`example.com`)",
+					Service:     "site",
+				},
+				Status: runtime.StatusEnabled,
+				Using:  []string{"web"},
+			},
+		},
+		Middlewares: map[string]*runtime.MiddlewareInfo{
+			"auth@file": {
+				Middleware: &dynamic.Middleware{BasicAuth: &dynamic.BasicAuth{}},
+			},
+		},
+	}
+
+	staticConfig := static.Configuration{
+		API:         &static.API{},
+		EntryPoints: static.EntryPoints{"web": {}},
+	}
+
+	explainer := routeExplainerFunc(func(string, *http.Request) ([]api.SimulatedRouter, []api.SimulatedRouter, error) {
+		return []api.SimulatedRouter{
+			{Name: "api@file", Protocol: api.ProtocolHTTP, Rule: "Host(`example.com`) && PathPrefix(`/api`)", Priority: 41},
+		}, []api.SimulatedRouter{
+			{Name: "site@file", Protocol: api.ProtocolHTTP, Rule: "Host(`example.com`)", Priority: 19},
+		}, nil
+	})
+
+	server := httptest.NewServer(api.NewBuilder(staticConfig, api.Options{RouteExplainer: explainer})(rtConf))
+	t.Cleanup(server.Close)
+
+	result, err := Do(http.DefaultClient, Configuration{
+		API:        server.URL + "/",
+		EntryPoint: "web",
+		Host:       "example.com",
+		Path:       "/api/users",
+	})
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	Print(&out, result)
+
+	expected := "Entry point: web\n" +
+		"Router:      api@file [http, priority 41] Host(`example.com`) && PathPrefix(`/api`)\n" +
+		"Middlewares: auth@file (basicauth), from api@file\n" +
+		"Service:     api@file\n" +
+		"Runner-ups:  site@file [http, priority 19] Host(`example.com`)\n"
+	assert.Equal(t, expected, out.String())
+
+	_, err = Do(http.DefaultClient, Configuration{API: server.URL, EntryPoint: "unknown"})
+	require.ErrorContains(t, err, "entry point not found: unknown")
+}
+
+// routeExplainerFunc is an api.RouteExplainer answering with the result of the function.
+type routeExplainerFunc func(entryPointName string, req *http.Request) ([]api.SimulatedRouter, []api.SimulatedRouter, error)
+
+func (f routeExplainerFunc) ExplainRoute(entryPointName string, req *http.Request) ([]api.SimulatedRouter, []api.SimulatedRouter, error) {
+	return f(entryPointName, req)
+}
@@ -23,21 +23,21 @@ func init() {
 	zerolog.SetGlobalLevel(zerolog.ErrorLevel)
 }
 
-func setupLogger(ctx context.Context, staticConfiguration *static.Configuration) error {
+func setupLogger(ctx context.Context, staticConfiguration *static.Configuration) (*logs.LevelController, error) {
 	// Validate that the experimental flag is set up at this point,
 	// rather than validating the static configuration before the setupLogger call.
 	// This ensures that validation messages are not logged using an un-configured logger.
 	if staticConfiguration.Log != nil && staticConfiguration.Log.OTLP != nil &&
 		(staticConfiguration.Experimental == nil || !staticConfiguration.Experimental.OTLPLogs) {
-		return errors.New("the experimental OTLPLogs feature must be enabled to use OTLP logging")
+		return nil, errors.New("the experimental OTLPLogs feature must be enabled to use OTLP logging")
 	}
 
 	// configure log format
 	w := getLogWriter(staticConfiguration)
 
-	// configure log level
+	// configure log level, which can be changed at runtime.
 	logLevel := getLogLevel(staticConfiguration)
-	zerolog.SetGlobalLevel(logLevel)
+	logLevels := logs.NewLevelController(logLevel)
 
 	// create logger
 	logger := zerolog.New(w).With().Timestamp()
@@ -45,13 +45,13 @@ func setupLogger(ctx context.Context, staticConfiguration *static.Configuration)
 		logger = logger.Caller()
 	}
 
-	log.Logger = logger.Logger().Level(logLevel)
+	log.Logger = logger.Logger().Hook(logLevels)
 
 	if staticConfiguration.Log != nil && staticConfiguration.Log.OTLP != nil {
 		var err error
 		log.Logger, err = logs.SetupOTelLogger(ctx, log.Logger, staticConfiguration.Log.OTLP)
 		if err != nil {
-			return fmt.Errorf("setting up OpenTelemetry logger: %w", err)
+			return nil, fmt.Errorf("setting up OpenTelemetry logger: %w", err)
 		}
 	}
 
@@ -64,7 +64,7 @@ func setupLogger(ctx context.Context, staticConfiguration *static.Configuration)
 	stdlog.SetFlags(stdlog.Lshortfile | stdlog.LstdFlags)
 	stdlog.SetOutput(logs.NoLevel(log.Logger, zerolog.DebugLevel))
 
-	return nil
+	return logLevels, nil
 }
 
 func getLogWriter(staticConfiguration *static.Configuration) io.Writer {
@@ -24,11 +24,15 @@ import (
 	"github.com/hanzoai/ingress-parser/cli"
 	"github.com/hanzoai/ingress/cmd"
 	"github.com/hanzoai/ingress/cmd/healthcheck"
+	"github.com/hanzoai/ingress/cmd/simulate"
 	cmdVersion "github.com/hanzoai/ingress/cmd/version"
 	tcli "github.com/hanzoai/ingress/pkg/cli"
 	"github.com/hanzoai/ingress/pkg/collector"
 	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/config/history"
 	"github.com/hanzoai/ingress/pkg/config/runtime"
+	"github.com/hanzoai/ingress/pkg/config/secret"
+	"github.com/hanzoai/ingress/pkg/config/snapshot"
 	"github.com/hanzoai/ingress/pkg/config/static"
 	"github.com/hanzoai/ingress/pkg/middlewares/accesslog"
 	"github.com/hanzoai/ingress/pkg/observability/logs"
@@ -39,6 +43,7 @@ import (
 	"github.com/hanzoai/ingress/pkg/provider/aggregator"
 	"github.com/hanzoai/ingress/pkg/provider/tailscale"
 	"github.com/hanzoai/ingress/pkg/provider/builtins"
+	"github.com/hanzoai/ingress/pkg/provider/status"
 	"github.com/hanzoai/ingress/pkg/proxy"
 	"github.com/hanzoai/ingress/pkg/proxy/httputil"
 	"github.com/hanzoai/ingress/pkg/redactor"
@@ -80,6 +85,18 @@ Based on Ingress. Documentation: https://hanzo.ai/docs/ingress/`,
 		os.Exit(1)
 	}
 
+	err = cmdIngress.AddCommand(newValidateCmd(loaders))
+	if err != nil {
+		stdlog.Println(err)
+		os.Exit(1)
+	}
+
+	err = cmdIngress.AddCommand(simulate.NewCmd())
+	if err != nil {
+		stdlog.Println(err)
+		os.Exit(1)
+	}
+
 	err = cli.Execute(cmdIngress)
 	if err != nil {
 		log.Error().Err(err).Msg("Command error")
@@ -93,7 +110,8 @@ func runCmd(staticConfiguration *static.Configuration) error {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
-	if err := setupLogger(ctx, staticConfiguration); err != nil {
+	logLevels, err := setupLogger(ctx, staticConfiguration)
+	if err != nil {
 		return fmt.Errorf("setting up logger: %w", err)
 	}
 
@@ -123,7 +141,7 @@ func runCmd(staticConfiguration *static.Configuration) error {
 
 	stats(staticConfiguration)
 
-	svr, err := setupServer(staticConfiguration)
+	svr, err := setupServer(staticConfiguration, logLevels)
 	if err != nil {
 		return err
 	}
@@ -171,7 +189,7 @@ func runCmd(staticConfiguration *static.Configuration) error {
 	return nil
 }
 
-func setupServer(staticConfiguration *static.Configuration) (*server.Server, error) {
+func setupServer(staticConfiguration *static.Configuration, logLevels *logs.LevelController) (*server.Server, error) {
 	providerAggregator := aggregator.NewProviderAggregator(*staticConfiguration.Providers)
 
 	ctx := context.Background()
@@ -215,7 +233,7 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 	metricsRegistry := metrics.NewMultiRegistry(metricRegistries)
 	accessLog := setupAccessLog(ctx, staticConfiguration.AccessLog)
 	tracer, tracerCloser := setupTracing(ctx, staticConfiguration.Tracing)
-	observabilityMgr := middleware.NewObservabilityMgr(*staticConfiguration, metricsRegistry, semConvMetricRegistry, accessLog, tracer, tracerCloser)
+	observabilityMgr := middleware.NewObservabilityMgr(*staticConfiguration, metricsRegistry, semConvMetricRegistry, accessLog, tracer, tracerCloser, logLevels)
 
 	// Entrypoints
 
@@ -303,7 +321,28 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 
 	dialerManager := tcp.NewDialerManager(spiffeX509Source)
 	acmeHTTPHandler := getHTTPChallengeHandler(acmeProviders, httpChallengeProvider)
+
+	var configSnapshot *snapshot.Snapshot
+	if staticConfiguration.Providers.Snapshot != nil {
+		configSnapshot, err = snapshot.New(staticConfiguration.Providers.Snapshot.FilePath, staticConfiguration.Providers.Snapshot.EncryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("creating configuration snapshot: %w", err)
+		}
+		configSnapshot.SetProvisionalTimeout(time.Duration(staticConfiguration.Providers.Snapshot.ProvisionalTimeout))
+	}
+
+	configHistory := history.New(history.DefaultSize)
+
+	providerStatus := status.NewTracker(metricsRegistry)
+	providerAggregator.SetFailureHandler(providerStatus.Failed)
+	if staticConfiguration.Ping != nil {
+		staticConfiguration.Ping.UseProviderStatus(providerStatus)
+	}
+
 	managerFactory := service.NewManagerFactory(*staticConfiguration, routinesPool, observabilityMgr, transportManager, proxyBuilder, acmeHTTPHandler)
+	managerFactory.SetConfigSnapshot(configSnapshot)
+	managerFactory.SetConfigHistory(configHistory)
+	managerFactory.SetProviderStatus(providerStatus)
 
 	// Router factory
 
@@ -312,6 +351,8 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 		return nil, fmt.Errorf("creating router factory: %w", err)
 	}
 
+	managerFactory.SetRouteExplainer(routerFactory)
+
 	// Watcher
 
 	watcher := server.NewConfigurationWatcher(
@@ -320,6 +361,23 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 		getDefaultsEntrypoints(staticConfiguration),
 		"internal",
 	)
+	watcher.UseSnapshot(configSnapshot)
+	watcher.UseHistory(configHistory)
+	watcher.UseProviderStatus(providerStatus)
+
+	// Secrets
+	var secretResolver *secret.Resolver
+	if staticConfiguration.Secrets != nil {
+		secretResolver, err = secret.NewResolver(*staticConfiguration.Secrets)
+		if err != nil {
+			return nil, fmt.Errorf("creating secret resolver: %w", err)
+		}
+
+		watcher.AddTransformer(secretResolver.Resolve)
+		routinesPool.GoCtx(func(ctx context.Context) {
+			secretResolver.Run(ctx, watcher.Refresh)
+		})
+	}
 
 	// TLS
 	watcher.AddListener(func(conf dynamic.Configuration) {
@@ -346,7 +404,7 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 	})
 
 	// Switch router
-	watcher.AddListener(switchRouter(routerFactory, serverEntryPointsTCP, serverEntryPointsUDP))
+	watcher.AddListener(switchRouter(routerFactory, secretResolver, serverEntryPointsTCP, serverEntryPointsUDP))
 
 	// Metrics
 	if metricsRegistry.IsEpEnabled() || metricsRegistry.IsRouterEnabled() || metricsRegistry.IsSvcEnabled() {
@@ -440,12 +498,20 @@ func getDefaultsEntrypoints(staticConfiguration *static.Configuration) []string
 	return defaultEntryPoints
 }
 
-func switchRouter(routerFactory *server.RouterFactory, serverEntryPointsTCP server.TCPEntryPoints, serverEntryPointsUDP server.UDPEntryPoints) func(conf dynamic.Configuration) {
+func switchRouter(routerFactory *server.RouterFactory, secretResolver *secret.Resolver, serverEntryPointsTCP server.TCPEntryPoints, serverEntryPointsUDP server.UDPEntryPoints) func(conf dynamic.Configuration) {
 	return func(conf dynamic.Configuration) {
+		// The changes limited to the servers of load-balancers are applied without rebuilding the routers.
+		if routerFactory.UpdateServers(conf) {
+			return
+		}
+
 		rtConf := runtime.NewConfig(conf)
 
 		routers, udpRouters := routerFactory.CreateRouters(rtConf)
 
+		// The resolved secrets are not exposed by the API.
+		secretResolver.Conceal(rtConf)
+
 		serverEntryPointsTCP.Switch(routers)
 		serverEntryPointsUDP.Switch(udpRouters)
 	}
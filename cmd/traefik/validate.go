@@ -0,0 +1,574 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/hanzoai/ingress-parser/cli"
+	"github.com/hanzoai/ingress-parser/flag"
+	"github.com/hanzoai/ingress/cmd"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/config/runtime"
+	"github.com/hanzoai/ingress/pkg/provider/builtins"
+	"github.com/hanzoai/ingress/pkg/provider/kubernetes/crd"
+	"github.com/hanzoai/ingress/pkg/server"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/meta"
+	kruntime "k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// validateConfiguration is the configuration of the validate command.
+type validateConfiguration struct {
+	cmd.IngressCmdConfiguration `export:"true"`
+
+	// Manifests are the Kubernetes manifests validated with the Kubernetes CRD provider.
+	Manifests []string `description:"Kubernetes manifests (files or directories) to validate with the Kubernetes CRD provider." export:"true"`
+}
+
+// newValidateCmd builds the validate command.
+func newValidateCmd(loaders []cli.ResourceLoader) *cli.Command {
+	validateConfig := &validateConfiguration{IngressCmdConfiguration: *cmd.NewIngressConfiguration()}
+
+	return &cli.Command{
+		Name: "validate",
+		Description: `Validates the static configuration, the dynamic configuration of the file provider, and Kubernetes manifests for the Kubernetes CRD provider, without starting Hanzo Ingress.
+Every error and warning is reported with its source location, and the command exits with a non-zero status when an error is found.`,
+		Configuration: validateConfig,
+		Resources:     loaders,
+		Run: func(args []string) error {
+			// The manifests are read from the flags even when the static configuration is loaded from a file,
+			// in which case the other flags are ignored.
+			ref, err := flag.Parse(args, validateConfig)
+			if err != nil {
+				return err
+			}
+
+			for key, value := range ref {
+				if strings.EqualFold(key, "ingress.manifests") {
+					validateConfig.Manifests = strings.Split(value, ",")
+				}
+			}
+
+			return runValidate(context.Background(), os.Stdout, validateConfig)
+		},
+	}
+}
+
+// validationIssue is an error or a warning found during the validation.
+type validationIssue struct {
+	location string
+	severity string
+	element  string
+	message  string
+}
+
+func (i validationIssue) String() string {
+	var b strings.Builder
+	if i.location != "" {
+		b.WriteString(i.location + ": ")
+	}
+	b.WriteString(i.severity + ": ")
+	if i.element != "" {
+		b.WriteString(i.element + ": ")
+	}
+	b.WriteString(i.message)
+
+	return b.String()
+}
+
+func runValidate(ctx context.Context, w io.Writer, validateConfig *validateConfiguration) error {
+	staticConfiguration := &validateConfig.Configuration
+	staticConfiguration.SetEffectiveConfiguration()
+	if err := staticConfiguration.ValidateConfiguration(); err != nil {
+		_, _ = fmt.Fprintln(w, validationIssue{severity: severityError, element: "static configuration", message: err.Error()})
+		return errors.New("invalid static configuration")
+	}
+
+	sources := newSourceLocations()
+
+	// The warnings and errors logged by the providers while building their configuration are reported as issues.
+	providerLogs := &providerLogs{sources: sources}
+	globalLogger, globalLevel := log.Logger, zerolog.GlobalLevel()
+	defer func() {
+		log.Logger = globalLogger
+		zerolog.SetGlobalLevel(globalLevel)
+	}()
+
+	log.Logger = zerolog.New(providerLogs).Level(zerolog.WarnLevel)
+	zerolog.SetGlobalLevel(zerolog.WarnLevel)
+	providerCtx := log.Logger.WithContext(ctx)
+
+	configurations := dynamic.Configurations{}
+
+	internalConfigurations := make(chan dynamic.Message, 1)
+	if err := builtins.New(*staticConfiguration).Provide(internalConfigurations, nil); err != nil {
+		return fmt.Errorf("building internal configuration: %w", err)
+	}
+	internal := <-internalConfigurations
+	configurations[internal.ProviderName] = internal.Configuration
+
+	var issues []validationIssue
+
+	if staticConfiguration.Providers.File != nil {
+		issues = append(issues, sources.indexFiles(staticConfiguration.Providers.File.Filename, staticConfiguration.Providers.File.Directory)...)
+
+		fileConfiguration, err := staticConfiguration.Providers.File.BuildConfiguration()
+		if err != nil {
+			issues = append(issues, validationIssue{severity: severityError, element: "file provider", message: err.Error()})
+		} else {
+			configurations["file"] = fileConfiguration
+		}
+	}
+
+	if len(validateConfig.Manifests) > 0 {
+		// The endpoints are managed by the cluster, and are usually not part of the manifests.
+		crdProvider := &crd.Provider{AllowEmptyServices: true}
+		if configured := staticConfiguration.Providers.KubernetesCRD; configured != nil {
+			crdProvider.Namespaces = configured.Namespaces
+			crdProvider.AllowCrossNamespace = configured.AllowCrossNamespace
+			crdProvider.AllowExternalNameServices = configured.AllowExternalNameServices
+			crdProvider.LabelSelector = configured.LabelSelector
+			crdProvider.IngressClass = configured.IngressClass
+			crdProvider.NativeLBByDefault = configured.NativeLBByDefault
+			crdProvider.DisableClusterScopeResources = configured.DisableClusterScopeResources
+		}
+
+		objects, manifestIssues := sources.indexManifests(validateConfig.Manifests)
+		issues = append(issues, manifestIssues...)
+
+		crdConfiguration, err := crdProvider.LoadManifests(providerCtx, objects)
+		if err != nil {
+			issues = append(issues, validationIssue{severity: severityError, element: "kubernetes CRD provider", message: err.Error()})
+		} else {
+			configurations["kubernetescrd"] = crdConfiguration
+		}
+	}
+
+	issues = append(issues, providerLogs.issues...)
+
+	// The issues of the routers, services and middlewares are reported from the runtime configuration,
+	// the logs would only duplicate them.
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+
+	pluginBuilder, err := createPluginBuilder(staticConfiguration)
+	if err != nil {
+		issues = append(issues, validationIssue{severity: severityWarning, element: "plugins", message: err.Error()})
+	}
+
+	validation, err := server.ValidateConfigurations(ctx, *staticConfiguration, configurations, getDefaultsEntrypoints(staticConfiguration), pluginBuilder)
+	if err != nil {
+		return fmt.Errorf("validating dynamic configuration: %w", err)
+	}
+
+	issues = append(issues, runtimeIssues(validation.Runtime, sources)...)
+
+	for name, err := range validation.TLSOptions {
+		issues = append(issues, validationIssue{
+			location: sources.lookup("tls.options", name),
+			severity: severityError,
+			element:  fmt.Sprintf("TLS options %q", name),
+			message:  err.Error(),
+		})
+	}
+
+	slices.SortFunc(issues, func(a, b validationIssue) int {
+		return cmp.Or(
+			cmp.Compare(a.location, b.location),
+			cmp.Compare(a.element, b.element),
+			cmp.Compare(a.message, b.message),
+		)
+	})
+
+	var errorCount, warningCount int
+	for _, issue := range issues {
+		_, _ = fmt.Fprintln(w, issue)
+
+		if issue.severity == severityError {
+			errorCount++
+		} else {
+			warningCount++
+		}
+	}
+
+	if errorCount > 0 {
+		_, _ = fmt.Fprintf(w, "Configuration is invalid: %d error(s), %d warning(s)\n", errorCount, warningCount)
+		return fmt.Errorf("configuration is invalid: %d error(s)", errorCount)
+	}
+
+	_, _ = fmt.Fprintf(w, "Configuration is valid: %d warning(s)\n", warningCount)
+
+	return nil
+}
+
+// runtimeIssues returns the issues reported by the elements of the runtime configuration.
+func runtimeIssues(rtConf *runtime.Configuration, sources *sourceLocations) []validationIssue {
+	var issues []validationIssue
+
+	add := func(section, kind, name, status string, errs []string) {
+		severity := severityWarning
+		if status == runtime.StatusDisabled {
+			severity = severityError
+		}
+
+		for _, msg := range errs {
+			issues = append(issues, validationIssue{
+				location: sources.lookup(section, name),
+				severity: severity,
+				element:  fmt.Sprintf("%s %q", kind, name),
+				message:  msg,
+			})
+		}
+	}
+
+	for name, info := range rtConf.Routers {
+		add("http.routers", "HTTP router", name, info.Status, info.Err)
+	}
+	for name, info := range rtConf.Services {
+		add("http.services", "HTTP service", name, info.Status, info.Err)
+	}
+	for name, info := range rtConf.Middlewares {
+		add("http.middlewares", "HTTP middleware", name, info.Status, info.Err)
+	}
+	for name, info := range rtConf.TCPRouters {
+		add("tcp.routers", "TCP router", name, info.Status, info.Err)
+	}
+	for name, info := range rtConf.TCPServices {
+		add("tcp.services", "TCP service", name, info.Status, info.Err)
+	}
+	for name, info := range rtConf.TCPMiddlewares {
+		add("tcp.middlewares", "TCP middleware", name, info.Status, info.Err)
+	}
+	for name, info := range rtConf.UDPRouters {
+		add("udp.routers", "UDP router", name, info.Status, info.Err)
+	}
+	for name, info := range rtConf.UDPServices {
+		add("udp.services", "UDP service", name, info.Status, info.Err)
+	}
+
+	return issues
+}
+
+// providerLogs collects the warnings and errors logged by the providers as validation issues.
+type providerLogs struct {
+	sources *sourceLocations
+	issues  []validationIssue
+}
+
+func (l *providerLogs) Write(p []byte) (int, error) {
+	var event struct {
+		Level        string `json:"level"`
+		Message      string `json:"message"`
+		Error        string `json:"error"`
+		ProviderName string `json:"providerName"`
+		Namespace    string `json:"namespace"`
+		Ingress      string `json:"ingress"`
+		TLSOption    string `json:"tlsOption"`
+		Middleware   string `json:"middlewareName"`
+	}
+	if err := json.Unmarshal(p, &event); err != nil {
+		return 0, err
+	}
+
+	issue := validationIssue{
+		severity: severityError,
+		element:  cmp.Or(event.ProviderName, "kubernetescrd") + " provider",
+		message:  event.Message,
+	}
+
+	if event.Level == zerolog.WarnLevel.String() {
+		issue.severity = severityWarning
+	}
+
+	if event.Error != "" {
+		issue.message = strings.TrimPrefix(issue.message+": "+event.Error, ": ")
+	}
+
+	switch {
+	case event.Ingress != "":
+		issue.location = l.sources.crdObjects[cmp.Or(event.Namespace, "default")+"-"+event.Ingress]
+	case event.TLSOption != "":
+		issue.location = l.sources.crdObjects[cmp.Or(event.Namespace, "default")+"-"+event.TLSOption]
+	case event.Middleware != "":
+		issue.location = l.sources.crdObjects[event.Middleware]
+	}
+
+	l.issues = append(l.issues, issue)
+
+	return len(p), nil
+}
+
+// sourceLocations holds the source locations of the dynamic configuration elements.
+type sourceLocations struct {
+	// elements holds the locations by section and qualified name, e.g. "http.routers/foo@file".
+	elements map[string]string
+	// crdObjects holds the locations of the Kubernetes objects by "<namespace>-<name>",
+	// which prefixes the names of the elements built from them.
+	crdObjects map[string]string
+}
+
+func newSourceLocations() *sourceLocations {
+	return &sourceLocations{
+		elements:   make(map[string]string),
+		crdObjects: make(map[string]string),
+	}
+}
+
+// lookup returns the source location of the given element, or an empty string when it is unknown.
+func (s *sourceLocations) lookup(section, name string) string {
+	if !strings.Contains(name, "@") {
+		name += "@file"
+	}
+
+	if location, ok := s.elements[section+"/"+name]; ok {
+		return location
+	}
+
+	objectName, ok := strings.CutSuffix(name, "@kubernetescrd")
+	if !ok {
+		return ""
+	}
+
+	var location string
+	var longest int
+	for prefix, loc := range s.crdObjects {
+		if len(prefix) > longest && (objectName == prefix || strings.HasPrefix(objectName, prefix+"-")) {
+			location = loc
+			longest = len(prefix)
+		}
+	}
+
+	return location
+}
+
+// indexFiles indexes the elements defined in the dynamic configuration files of the file provider.
+func (s *sourceLocations) indexFiles(filename, directory string) []validationIssue {
+	var files []string
+	if filename != "" {
+		files = append(files, filename)
+	}
+
+	if directory != "" {
+		err := filepath.WalkDir(directory, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			switch strings.ToLower(filepath.Ext(path)) {
+			case ".toml", ".yaml", ".yml":
+				if !d.IsDir() {
+					files = append(files, path)
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return []validationIssue{{location: directory, severity: severityError, element: "file provider", message: err.Error()}}
+		}
+	}
+
+	for _, path := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			// Already reported by the file provider.
+			continue
+		}
+
+		if strings.ToLower(filepath.Ext(path)) == ".toml" {
+			s.indexTOML(path, content)
+			continue
+		}
+
+		s.indexYAML(path, content)
+	}
+
+	return nil
+}
+
+// tomlTableHeader matches the TOML table headers defining a dynamic configuration element,
+// e.g. [http.routers.foo] or [http.services."foo".loadBalancer].
+var tomlTableHeader = regexp.MustCompile(`^\s*\[\[?\s*(http|tcp|udp|tls)\.(\w+)\.(?:"([^"]+)"|([\w-]+))`)
+
+func (s *sourceLocations) indexTOML(path string, content []byte) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+
+	var line int
+	for scanner.Scan() {
+		line++
+
+		match := tomlTableHeader.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+
+		name := cmp.Or(match[3], match[4])
+		key := match[1] + "." + match[2] + "/" + name + "@file"
+		if _, ok := s.elements[key]; !ok {
+			s.elements[key] = fmt.Sprintf("%s:%d", path, line)
+		}
+	}
+}
+
+func (s *sourceLocations) indexYAML(path string, content []byte) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil || len(root.Content) == 0 {
+		// The templates cannot be parsed before being rendered, the elements are located by file only.
+		return
+	}
+
+	for protocol, sections := range mappingEntries(root.Content[0]) {
+		for section, elements := range mappingEntries(sections) {
+			for name, element := range mappingKeys(elements) {
+				s.elements[protocol+"."+section+"/"+name+"@file"] = fmt.Sprintf("%s:%d", path, element.Line)
+			}
+		}
+	}
+}
+
+// indexManifests decodes the Kubernetes objects of the given manifests, and indexes their locations.
+func (s *sourceLocations) indexManifests(paths []string) ([]kruntime.Object, []validationIssue) {
+	var files []string
+	var issues []validationIssue
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			switch strings.ToLower(filepath.Ext(path)) {
+			case ".yaml", ".yml", ".json":
+				if !d.IsDir() {
+					files = append(files, path)
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			issues = append(issues, validationIssue{location: path, severity: severityError, element: "manifests", message: err.Error()})
+		}
+	}
+
+	var objects []kruntime.Object
+	for _, path := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			issues = append(issues, validationIssue{location: path, severity: severityError, element: "manifests", message: err.Error()})
+			continue
+		}
+
+		for _, document := range splitManifest(content) {
+			location := fmt.Sprintf("%s:%d", path, document.line)
+
+			obj, err := crd.DecodeManifest(document.content)
+			if kruntime.IsMissingKind(err) {
+				issues = append(issues, validationIssue{location: location, severity: severityWarning, element: "manifests", message: "skipping object without kind"})
+				continue
+			}
+			if kruntime.IsNotRegisteredError(err) {
+				issues = append(issues, validationIssue{location: location, severity: severityWarning, element: "manifests", message: "skipping unsupported object: " + err.Error()})
+				continue
+			}
+			if err != nil {
+				issues = append(issues, validationIssue{location: location, severity: severityError, element: "manifests", message: err.Error()})
+				continue
+			}
+
+			if accessor, err := meta.Accessor(obj); err == nil {
+				namespace := cmp.Or(accessor.GetNamespace(), "default")
+				s.crdObjects[namespace+"-"+accessor.GetName()] = location
+			}
+
+			objects = append(objects, obj)
+		}
+	}
+
+	return objects, issues
+}
+
+type manifestDocument struct {
+	line    int
+	content []byte
+}
+
+// splitManifest splits a multi-document YAML manifest, and returns the documents with their first line.
+func splitManifest(content []byte) []manifestDocument {
+	var documents []manifestDocument
+
+	current := manifestDocument{line: 1}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(nil, max(len(content)+1, bufio.MaxScanTokenSize))
+
+	var line int
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+
+		if strings.HasPrefix(text, "---") {
+			documents = append(documents, current)
+			current = manifestDocument{line: line + 1}
+			continue
+		}
+
+		trimmed := strings.TrimSpace(text)
+		if len(current.content) == 0 && (trimmed == "" || strings.HasPrefix(trimmed, "#")) {
+			current.line = line + 1
+			continue
+		}
+
+		current.content = append(current.content, text...)
+		current.content = append(current.content, '\n')
+	}
+	documents = append(documents, current)
+
+	return slices.DeleteFunc(documents, func(document manifestDocument) bool {
+		return len(document.content) == 0
+	})
+}
+
+// mappingEntries returns the values of a YAML mapping node by key.
+func mappingEntries(node *yaml.Node) map[string]*yaml.Node {
+	entries := make(map[string]*yaml.Node)
+	if node == nil || node.Kind != yaml.MappingNode {
+		return entries
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		entries[node.Content[i].Value] = node.Content[i+1]
+	}
+
+	return entries
+}
+
+// mappingKeys returns the key nodes of a YAML mapping node by key.
+func mappingKeys(node *yaml.Node) map[string]*yaml.Node {
+	keys := make(map[string]*yaml.Node)
+	if node == nil || node.Kind != yaml.MappingNode {
+		return keys
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keys[node.Content[i].Value] = node.Content[i]
+	}
+
+	return keys
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/hanzoai/ingress/cmd"
+	"github.com/hanzoai/ingress/pkg/config/static"
+	"github.com/hanzoai/ingress/pkg/provider/file"
+)
+
+func TestRunValidate(t *testing.T) {
+	dir := t.TempDir()
+
+	dynamicConfig := filepath.Join(dir, "dynamic.yml")
+	err := os.WriteFile(dynamicConfig, []byte(`http:
+  routers:
+    valid:
+      rule: Host(`+"`foo.com`"+`)
+      service: whoami
+    invalid:
+      rule: Host(`+"`foo.com`"+`
+      service: whoami
+  services:
+    whoami:
+      loadBalancer:
+        servers:
+          - url: http://127.0.0.1:8080
+`), 0o600)
+	require.NoError(t, err)
+
+	manifest := filepath.Join(dir, "manifest.yml")
+	err = os.WriteFile(manifest, []byte(`# IngressRoute
+apiVersion: hanzo.ai/v1alpha1
+kind: IngressRoute
+metadata:
+  name: test
+  namespace: default
+spec:
+  entryPoints:
+    - unknown
+  routes:
+    - match: Host(`+"`bar.com`"+`)
+      kind: Rule
+      services:
+        - name: whoami
+          port: 80
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: whoami
+  namespace: default
+spec:
+  ports:
+    - port: 80
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: foo
+`), 0o600)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc           string
+		manifests      []string
+		expectedOutput []string
+	}{
+		{
+			desc: "file provider",
+			expectedOutput: []string{
+				dynamicConfig + `:6: error: HTTP router "invalid@file": error while parsing rule Host(` + "`foo.com`" + `: parsing rule Host(` + "`foo.com`" + `: 1:15: missing ',' before newline in argument list`,
+				"Configuration is invalid: 1 error(s), 0 warning(s)",
+			},
+		},
+		{
+			desc:      "file provider and manifests",
+			manifests: []string{manifest},
+			expectedOutput: []string{
+				dynamicConfig + `:6: error: HTTP router "invalid@file": error while parsing rule Host(` + "`foo.com`" + `: parsing rule Host(` + "`foo.com`" + `: 1:15: missing ',' before newline in argument list`,
+				manifest + `:2: error: HTTP router "default-test-1f773b7f0ac1aad6d729@kubernetescrd": entryPoint "unknown" doesn't exist`,
+				manifest + `:2: error: HTTP router "default-test-1f773b7f0ac1aad6d729@kubernetescrd": no valid entryPoint for this router`,
+				manifest + `:26: warning: manifests: skipping unsupported object: no kind "Widget" is registered for version "example.com/v1" in scheme "pkg/runtime/scheme.go:110"`,
+				"Configuration is invalid: 3 error(s), 1 warning(s)",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			validateConfig := &validateConfiguration{
+				IngressCmdConfiguration: *cmd.NewIngressConfiguration(),
+				Manifests:               test.manifests,
+			}
+			validateConfig.EntryPoints = static.EntryPoints{"web": {Address: ":8080"}}
+			validateConfig.Providers.File = &file.Provider{Filename: dynamicConfig}
+
+			var output bytes.Buffer
+			err := runValidate(t.Context(), &output, validateConfig)
+			require.Error(t, err)
+
+			assert.Equal(t, test.expectedOutput, splitLines(output.String()))
+		})
+	}
+}
+
+func TestRunValidate_valid(t *testing.T) {
+	dynamicConfig := filepath.Join(t.TempDir(), "dynamic.toml")
+	err := os.WriteFile(dynamicConfig, []byte(`[http.routers.foo]
+  rule = "Host(`+"`foo.com`"+`)"
+  service = "foo"
+
+[http.services.foo.loadBalancer]
+  [[http.services.foo.loadBalancer.servers]]
+    url = "http://127.0.0.1:8080"
+`), 0o600)
+	require.NoError(t, err)
+
+	validateConfig := &validateConfiguration{IngressCmdConfiguration: *cmd.NewIngressConfiguration()}
+	validateConfig.EntryPoints = static.EntryPoints{"web": {Address: ":8080"}}
+	validateConfig.Providers.File = &file.Provider{Filename: dynamicConfig}
+
+	var output bytes.Buffer
+	err = runValidate(t.Context(), &output, validateConfig)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Configuration is valid: 0 warning(s)\n", output.String())
+}
+
+func TestSourceLocations_indexTOML(t *testing.T) {
+	sources := newSourceLocations()
+	sources.indexTOML("dynamic.toml", []byte(`[http.routers]
+  [http.routers.foo]
+    rule = "Host(`+"`foo.com`"+`)"
+
+[http.routers.foo.tls]
+
+[tcp.services."bar".loadBalancer]
+`))
+
+	assert.Equal(t, "dynamic.toml:2", sources.lookup("http.routers", "foo@file"))
+	assert.Equal(t, "dynamic.toml:7", sources.lookup("tcp.services", "bar@file"))
+	assert.Empty(t, sources.lookup("http.routers", "bar@file"))
+}
+
+func splitLines(s string) []string {
+	var lines []string
+	for line := range bytes.Lines([]byte(s)) {
+		lines = append(lines, string(bytes.TrimSuffix(line, []byte("\n"))))
+	}
+
+	return lines
+}
@@ -9,8 +9,13 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/rs/zerolog/log"
 	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/config/history"
 	"github.com/hanzoai/ingress/pkg/config/runtime"
+	"github.com/hanzoai/ingress/pkg/config/snapshot"
 	"github.com/hanzoai/ingress/pkg/config/static"
+	"github.com/hanzoai/ingress/pkg/middlewares/tap"
+	"github.com/hanzoai/ingress/pkg/observability/logs"
+	"github.com/hanzoai/ingress/pkg/provider/status"
 	"github.com/hanzoai/ingress/pkg/version"
 )
 
@@ -58,12 +63,49 @@ type Handler struct {
 
 	// runtimeConfiguration is the data set used to create all the data representations exposed by the API.
 	runtimeConfiguration *runtime.Configuration
+
+	// tapHub is used to stream the requests handled by HTTP routers, the tap endpoint is disabled when nil.
+	tapHub *tap.Hub
+
+	// logLevels is used to change the log levels at runtime, the log level endpoints are disabled when nil.
+	logLevels *logs.LevelController
+
+	// configSnapshot is the last known good configuration snapshot, the snapshot endpoint is disabled when nil.
+	configSnapshot *snapshot.Snapshot
+
+	// configHistory is the history of the applied configurations, the history endpoint is disabled when nil.
+	configHistory *history.History
+
+	// providerStatus tracks the health status of the providers, the providers endpoint is disabled when nil.
+	providerStatus *status.Tracker
+
+	// routeExplainer evaluates the simulated requests against the running routers, the simulate endpoint is disabled when nil.
+	routeExplainer RouteExplainer
+}
+
+// Options holds the optional components used by the API endpoints,
+// an endpoint being disabled when the component it uses is nil.
+type Options struct {
+	TapHub         *tap.Hub
+	LogLevels      *logs.LevelController
+	ConfigSnapshot *snapshot.Snapshot
+	ConfigHistory  *history.History
+	ProviderStatus *status.Tracker
+	RouteExplainer RouteExplainer
 }
 
 // NewBuilder returns a http.Handler builder based on runtime.Configuration.
-func NewBuilder(staticConfig static.Configuration) func(*runtime.Configuration) http.Handler {
+func NewBuilder(staticConfig static.Configuration, opts Options) func(*runtime.Configuration) http.Handler {
 	return func(configuration *runtime.Configuration) http.Handler {
-		return New(staticConfig, configuration).createRouter()
+		handler := New(staticConfig, configuration)
+		handler.tapHub = opts.TapHub
+		handler.logLevels = opts.LogLevels
+		handler.configSnapshot = opts.ConfigSnapshot
+		handler.configHistory = opts.ConfigHistory
+		handler.providerStatus = opts.ProviderStatus
+		handler.routeExplainer = opts.RouteExplainer
+
+		return handler.createRouter()
 	}
 }
 
@@ -98,11 +140,23 @@ func (h Handler) createRouter() *mux.Router {
 
 	apiRouter.Methods(http.MethodGet).Path("/v1/ingress/support-dump").HandlerFunc(h.getSupportDump)
 
+	apiRouter.Methods(http.MethodGet).Path("/v1/ingress/config/snapshot").HandlerFunc(h.getConfigSnapshot)
+	apiRouter.Methods(http.MethodGet).Path("/v1/ingress/config/history").HandlerFunc(h.getConfigHistory)
+
+	apiRouter.Methods(http.MethodPost).Path("/v1/ingress/simulate").HandlerFunc(h.postSimulation)
+
+	apiRouter.Methods(http.MethodGet).Path("/v1/ingress/log/levels").HandlerFunc(h.getLogLevels)
+	apiRouter.Methods(http.MethodPut).Path("/v1/ingress/log/levels").HandlerFunc(h.putLogLevels)
+	apiRouter.Methods(http.MethodDelete).Path("/v1/ingress/log/levels").HandlerFunc(h.deleteLogLevels)
+
+	apiRouter.Methods(http.MethodGet).Path("/v1/ingress/providers").HandlerFunc(h.getProviders)
+
 	apiRouter.Methods(http.MethodGet).Path("/v1/ingress/entrypoints").HandlerFunc(h.getEntryPoints)
 	apiRouter.Methods(http.MethodGet).Path("/v1/ingress/entrypoints/{entryPointID}").HandlerFunc(h.getEntryPoint)
 
 	apiRouter.Methods(http.MethodGet).Path("/v1/ingress/http/routers").HandlerFunc(h.getRouters)
 	apiRouter.Methods(http.MethodGet).Path("/v1/ingress/http/routers/{routerID}").HandlerFunc(h.getRouter)
+	apiRouter.Methods(http.MethodGet).Path("/v1/ingress/http/routers/{routerID}/tap").HandlerFunc(h.getRouterTap)
 	apiRouter.Methods(http.MethodGet).Path("/v1/ingress/http/services").HandlerFunc(h.getServices)
 	apiRouter.Methods(http.MethodGet).Path("/v1/ingress/http/services/{serviceID}").HandlerFunc(h.getService)
 	apiRouter.Methods(http.MethodGet).Path("/v1/ingress/http/middlewares").HandlerFunc(h.getMiddlewares)
@@ -126,6 +180,9 @@ func (h Handler) createRouter() *mux.Router {
 }
 
 func (h Handler) getRuntimeConfiguration(rw http.ResponseWriter, request *http.Request) {
+	h.runtimeConfiguration.RLockServices()
+	defer h.runtimeConfiguration.RUnlockServices()
+
 	siRepr := make(map[string]*serviceInfoRepresentation, len(h.runtimeConfiguration.Services))
 	for k, v := range h.runtimeConfiguration.Services {
 		siRepr[k] = &serviceInfoRepresentation{
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	"github.com/hanzoai/ingress/pkg/config/history"
+)
+
+func (h Handler) getConfigHistory(rw http.ResponseWriter, request *http.Request) {
+	if h.configHistory == nil {
+		writeError(rw, "configuration history is not enabled", http.StatusNotFound)
+		return
+	}
+
+	provider := request.URL.Query().Get("provider")
+
+	results := make([]history.Entry, 0)
+	for _, entry := range h.configHistory.Entries() {
+		if provider == "" {
+			results = append(results, entry)
+			continue
+		}
+
+		// Only the changes of the requested provider are kept.
+		for _, diff := range entry.Providers {
+			if diff.Provider == provider {
+				entry.Providers = []history.ProviderDiff{diff}
+				results = append(results, entry)
+				break
+			}
+		}
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	pageInfo, err := pagination(request, len(results))
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set(nextPageHeader, strconv.Itoa(pageInfo.nextPage))
+
+	err = json.NewEncoder(rw).Encode(results[pageInfo.startIndex:pageInfo.endIndex])
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/config/history"
+	"github.com/hanzoai/ingress/pkg/config/runtime"
+	"github.com/hanzoai/ingress/pkg/config/static"
+)
+
+func TestHandler_ConfigHistory(t *testing.T) {
+	conf := static.Configuration{API: &static.API{}, Global: &static.Global{}}
+
+	handler := NewBuilder(conf, Options{})(&runtime.Configuration{})
+
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/ingress/config/history", nil))
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+
+	configHistory := history.New(history.DefaultSize)
+
+	first := dynamic.Configurations{
+		"file": {HTTP: &dynamic.HTTPConfiguration{Routers: map[string]*dynamic.Router{"foo": {}}}},
+	}
+	configHistory.Record(nil, first)
+
+	second := dynamic.Configurations{
+		"file":   first["file"],
+		"docker": {HTTP: &dynamic.HTTPConfiguration{Services: map[string]*dynamic.Service{"bar": {}}}},
+	}
+	configHistory.Record(first, second)
+
+	handler = NewBuilder(conf, Options{ConfigHistory: configHistory})(&runtime.Configuration{})
+
+	testCases := []struct {
+		desc        string
+		path        string
+		expectedIDs []uint64
+		nextPage    string
+	}{
+		{
+			desc:        "all entries",
+			path:        "/v1/ingress/config/history",
+			expectedIDs: []uint64{2, 1},
+			nextPage:    "1",
+		},
+		{
+			desc:        "provider entries",
+			path:        "/v1/ingress/config/history?provider=file",
+			expectedIDs: []uint64{1},
+			nextPage:    "1",
+		},
+		{
+			desc:        "paginated entries",
+			path:        "/v1/ingress/config/history?per_page=1",
+			expectedIDs: []uint64{2},
+			nextPage:    "2",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, test.path, nil))
+			require.Equal(t, http.StatusOK, rw.Code)
+
+			assert.Equal(t, test.nextPage, rw.Header().Get(nextPageHeader))
+
+			var entries []history.Entry
+			require.NoError(t, json.NewDecoder(rw.Body).Decode(&entries))
+
+			var ids []uint64
+			for _, entry := range entries {
+				ids = append(ids, entry.ID)
+				assert.Len(t, entry.Providers, 1)
+			}
+			assert.Equal(t, test.expectedIDs, ids)
+		})
+	}
+}
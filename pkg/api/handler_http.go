@@ -127,6 +127,9 @@ func (h Handler) getRouter(rw http.ResponseWriter, request *http.Request) {
 }
 
 func (h Handler) getServices(rw http.ResponseWriter, request *http.Request) {
+	h.runtimeConfiguration.RLockServices()
+	defer h.runtimeConfiguration.RUnlockServices()
+
 	results := make([]serviceRepresentation, 0, len(h.runtimeConfiguration.Services))
 
 	query := request.URL.Query()
@@ -158,6 +161,9 @@ func (h Handler) getServices(rw http.ResponseWriter, request *http.Request) {
 }
 
 func (h Handler) getService(rw http.ResponseWriter, request *http.Request) {
+	h.runtimeConfiguration.RLockServices()
+	defer h.runtimeConfiguration.RUnlockServices()
+
 	scapedServiceID := mux.Vars(request)["serviceID"]
 
 	serviceID, err := url.PathUnescape(scapedServiceID)
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	ptypes "github.com/hanzoai/ingress-parser/types"
+	"github.com/rs/zerolog/log"
+	"github.com/hanzoai/ingress/pkg/observability/logs"
+)
+
+type logLevelsRepresentation struct {
+	logs.Levels
+
+	RevertAt *time.Time `json:"revertAt,omitempty"`
+}
+
+type logLevelsRequest struct {
+	logs.Levels
+
+	// RevertAfter is the duration after which the previous log levels are restored.
+	RevertAfter ptypes.Duration `json:"revertAfter,omitempty"`
+}
+
+func (h Handler) getLogLevels(rw http.ResponseWriter, request *http.Request) {
+	if h.logLevels == nil {
+		writeError(rw, "log level control is not available", http.StatusNotImplemented)
+		return
+	}
+
+	h.writeLogLevels(rw, request)
+}
+
+func (h Handler) putLogLevels(rw http.ResponseWriter, request *http.Request) {
+	if !h.canChangeLogLevels(rw) {
+		return
+	}
+
+	var levelsRequest logLevelsRequest
+	if err := json.NewDecoder(request.Body).Decode(&levelsRequest); err != nil {
+		writeError(rw, fmt.Sprintf("unable to decode log levels: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.logLevels.SetLevels(levelsRequest.Levels, time.Duration(levelsRequest.RevertAfter)); err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	log.Ctx(request.Context()).Warn().Interface("logLevels", levelsRequest).Msg("Log levels changed through the API")
+
+	h.writeLogLevels(rw, request)
+}
+
+func (h Handler) deleteLogLevels(rw http.ResponseWriter, request *http.Request) {
+	if !h.canChangeLogLevels(rw) {
+		return
+	}
+
+	h.logLevels.Reset()
+
+	log.Ctx(request.Context()).Warn().Msg("Log levels restored through the API")
+
+	h.writeLogLevels(rw, request)
+}
+
+// canChangeLogLevels writes an error and returns false when the log levels cannot be changed through the API.
+// As the insecure API cannot be protected by authentication middlewares, it is read-only.
+func (h Handler) canChangeLogLevels(rw http.ResponseWriter) bool {
+	if h.logLevels == nil {
+		writeError(rw, "log level control is not available", http.StatusNotImplemented)
+		return false
+	}
+
+	if h.staticConfig.API.Insecure {
+		writeError(rw, "log levels cannot be changed through the insecure API", http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
+
+func (h Handler) writeLogLevels(rw http.ResponseWriter, request *http.Request) {
+	levels, revertAt := h.logLevels.Levels()
+
+	result := logLevelsRepresentation{Levels: levels}
+	if !revertAt.IsZero() {
+		result.RevertAt = &revertAt
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	err := json.NewEncoder(rw).Encode(result)
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
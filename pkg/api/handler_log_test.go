@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/hanzoai/ingress/pkg/config/runtime"
+	"github.com/hanzoai/ingress/pkg/config/static"
+	"github.com/hanzoai/ingress/pkg/observability/logs"
+)
+
+func TestHandler_LogLevels(t *testing.T) {
+	globalLevel := zerolog.GlobalLevel()
+	t.Cleanup(func() { zerolog.SetGlobalLevel(globalLevel) })
+
+	testCases := []struct {
+		desc           string
+		insecure       bool
+		disabled       bool
+		method         string
+		body           string
+		expectedStatus int
+		expectedLevels logs.Levels
+		expectedRevert bool
+	}{
+		{
+			desc:           "get the static levels",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusOK,
+			expectedLevels: logs.Levels{Level: "error"},
+		},
+		{
+			desc:           "log level control disabled",
+			disabled:       true,
+			method:         http.MethodGet,
+			expectedStatus: http.StatusNotImplemented,
+		},
+		{
+			desc:           "set the levels",
+			method:         http.MethodPut,
+			body:           `{"level":"info","providers":{"docker":"debug"},"acmeResolvers":{"le":"trace"},"revertAfter":"10m"}`,
+			expectedStatus: http.StatusOK,
+			expectedLevels: logs.Levels{Level: "info", Providers: map[string]string{"docker": "debug"}, ACMEResolvers: map[string]string{"le": "trace"}},
+			expectedRevert: true,
+		},
+		{
+			desc:           "invalid level",
+			method:         http.MethodPut,
+			body:           `{"routers":{"foo@file":"verbose"}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "insecure API",
+			insecure:       true,
+			method:         http.MethodPut,
+			body:           `{"level":"debug"}`,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "reset the levels",
+			method:         http.MethodDelete,
+			expectedStatus: http.StatusOK,
+			expectedLevels: logs.Levels{Level: "error"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			var logLevels *logs.LevelController
+			if !test.disabled {
+				logLevels = logs.NewLevelController(zerolog.ErrorLevel)
+			}
+
+			conf := static.Configuration{API: &static.API{Insecure: test.insecure}, Global: &static.Global{}}
+			handler := NewBuilder(conf, Options{LogLevels: logLevels})(&runtime.Configuration{})
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, httptest.NewRequest(test.method, "/v1/ingress/log/levels", strings.NewReader(test.body)))
+
+			require.Equal(t, test.expectedStatus, rw.Code)
+			if test.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var result logLevelsRepresentation
+			require.NoError(t, json.NewDecoder(rw.Body).Decode(&result))
+
+			assert.Equal(t, test.expectedLevels, result.Levels)
+			assert.Equal(t, test.expectedRevert, result.RevertAt != nil)
+		})
+	}
+}
@@ -38,6 +38,9 @@ type overview struct {
 }
 
 func (h Handler) getOverview(rw http.ResponseWriter, request *http.Request) {
+	h.runtimeConfiguration.RLockServices()
+	defer h.runtimeConfiguration.RUnlockServices()
+
 	result := overview{
 		HTTP: schemeOverview{
 			Routers:     getHTTPRouterSection(h.runtimeConfiguration.Routers),
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/hanzoai/ingress/pkg/provider/status"
+)
+
+type providerRepresentation struct {
+	status.Status
+
+	// LastUpdateAge is the time elapsed since the provider last delivered a configuration.
+	LastUpdateAge string `json:"lastUpdateAge,omitempty"`
+}
+
+func (h Handler) getProviders(rw http.ResponseWriter, request *http.Request) {
+	if h.providerStatus == nil {
+		writeError(rw, "provider status is not enabled", http.StatusNotFound)
+		return
+	}
+
+	results := make([]providerRepresentation, 0)
+	for _, providerStatus := range h.providerStatus.Statuses() {
+		result := providerRepresentation{Status: providerStatus}
+		if providerStatus.LastUpdate != nil {
+			result.LastUpdateAge = time.Since(*providerStatus.LastUpdate).Truncate(time.Second).String()
+		}
+
+		results = append(results, result)
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	pageInfo, err := pagination(request, len(results))
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set(nextPageHeader, strconv.Itoa(pageInfo.nextPage))
+
+	err = json.NewEncoder(rw).Encode(results[pageInfo.startIndex:pageInfo.endIndex])
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
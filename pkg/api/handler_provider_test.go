@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/config/runtime"
+	"github.com/hanzoai/ingress/pkg/config/static"
+	"github.com/hanzoai/ingress/pkg/provider/status"
+)
+
+func TestHandler_Providers(t *testing.T) {
+	conf := static.Configuration{API: &static.API{}, Global: &static.Global{}}
+
+	handler := NewBuilder(conf, Options{})(&runtime.Configuration{})
+
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/ingress/providers", nil))
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+
+	providerStatus := status.NewTracker(nil)
+	providerStatus.Updated("file", &dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{Routers: map[string]*dynamic.Router{"foo": {}}},
+	})
+	providerStatus.Failed("docker", errors.New("connection refused"))
+
+	handler = NewBuilder(conf, Options{ProviderStatus: providerStatus})(&runtime.Configuration{})
+
+	rw = httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/ingress/providers", nil))
+	require.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "1", rw.Header().Get(nextPageHeader))
+
+	var providers []providerRepresentation
+	require.NoError(t, json.NewDecoder(rw.Body).Decode(&providers))
+	require.Len(t, providers, 2)
+
+	assert.Equal(t, "docker", providers[0].Name)
+	assert.Equal(t, status.StateError, providers[0].State)
+	assert.Equal(t, "connection refused", providers[0].LastError)
+	assert.Equal(t, uint64(1), providers[0].ErrorCount)
+	assert.Empty(t, providers[0].LastUpdateAge)
+
+	assert.Equal(t, "file", providers[1].Name)
+	assert.Equal(t, status.StateReady, providers[1].State)
+	assert.Equal(t, 1, providers[1].Objects.HTTPRouters)
+	assert.Equal(t, "0s", providers[1].LastUpdateAge)
+}
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"cmp"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/hanzoai/ingress/pkg/middlewares/requestdecorator"
+	"github.com/hanzoai/ingress/pkg/server/provider"
+)
+
+// Protocols of the simulated routers.
+const (
+	ProtocolHTTP = "http"
+	ProtocolTCP  = "tcp"
+)
+
+// RouteExplainer explains how the running routers route a request.
+type RouteExplainer interface {
+	// ExplainRoute returns the routers handling the request received on the entry point,
+	// from the root router to the router forwarding it to its service,
+	// and the other routers matching the request.
+	ExplainRoute(entryPointName string, req *http.Request) (routers, runnerUps []SimulatedRouter, err error)
+}
+
+// SimulationRequest describes the synthetic request evaluated by the route simulation.
+type SimulationRequest struct {
+	EntryPoint string            `json:"entryPoint"`
+	Method     string            `json:"method,omitempty"`
+	Host       string            `json:"host,omitempty"`
+	Path       string            `json:"path,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	ClientIP   string            `json:"clientIP,omitempty"`
+	// SNI is the server name sent in the TLS ClientHello, setting it implies a TLS connection.
+	SNI string `json:"sni,omitempty"`
+	TLS bool   `json:"tls,omitempty"`
+}
+
+// SimulatedRouter is a router matching the simulated request.
+type SimulatedRouter struct {
+	Name     string `json:"name"`
+	Protocol string `json:"protocol"`
+	Rule     string `json:"rule,omitempty"`
+	Priority int    `json:"priority"`
+}
+
+// SimulatedMiddleware is a middleware of the chain which would handle the simulated request.
+type SimulatedMiddleware struct {
+	Name string `json:"name"`
+	Type string `json:"type,omitempty"`
+	// Router is the router declaring the middleware.
+	Router string `json:"router"`
+}
+
+// SimulationResult is the result of the route simulation.
+type SimulationResult struct {
+	EntryPoint string `json:"entryPoint"`
+	// Routers are the routers which would handle the request,
+	// from the root router to the child router forwarding it to the service.
+	Routers []SimulatedRouter `json:"routers,omitempty"`
+	// RunnerUps are the other routers matching the request, by decreasing priority.
+	RunnerUps   []SimulatedRouter     `json:"runnerUps,omitempty"`
+	Middlewares []SimulatedMiddleware `json:"middlewares,omitempty"`
+	Service     string                `json:"service,omitempty"`
+}
+
+func (h Handler) postSimulation(rw http.ResponseWriter, request *http.Request) {
+	if h.routeExplainer == nil {
+		writeError(rw, "route simulation is not enabled", http.StatusNotFound)
+		return
+	}
+
+	var simRequest SimulationRequest
+	if err := json.NewDecoder(request.Body).Decode(&simRequest); err != nil {
+		writeError(rw, fmt.Sprintf("unable to decode simulation request: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.simulate(simRequest)
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	err = json.NewEncoder(rw).Encode(result)
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// simulate evaluates the given request against the muxers of the running routers, without sending any traffic,
+// and completes their answer with the middlewares and the service of the routers handling the request.
+func (h Handler) simulate(simRequest SimulationRequest) (*SimulationResult, error) {
+	if simRequest.EntryPoint == "" {
+		return nil, errors.New("the entry point is required")
+	}
+
+	if _, ok := h.staticConfig.EntryPoints[simRequest.EntryPoint]; !ok {
+		return nil, fmt.Errorf("entry point not found: %s", simRequest.EntryPoint)
+	}
+
+	if simRequest.ClientIP != "" && net.ParseIP(simRequest.ClientIP) == nil {
+		return nil, fmt.Errorf("invalid client IP: %s", simRequest.ClientIP)
+	}
+
+	req, err := newSimulatedHTTPRequest(simRequest, simRequest.TLS || simRequest.SNI != "")
+	if err != nil {
+		return nil, err
+	}
+
+	routers, runnerUps, err := h.routeExplainer.ExplainRoute(simRequest.EntryPoint, req)
+	if err != nil {
+		return nil, fmt.Errorf("explaining route: %w", err)
+	}
+
+	result := &SimulationResult{
+		EntryPoint: simRequest.EntryPoint,
+		Routers:    routers,
+		RunnerUps:  runnerUps,
+	}
+
+	slices.SortFunc(result.RunnerUps, func(a, b SimulatedRouter) int {
+		return cmp.Or(cmp.Compare(b.Priority, a.Priority), cmp.Compare(a.Name, b.Name))
+	})
+
+	for _, router := range routers {
+		ctx := provider.AddInContext(context.Background(), router.Name)
+
+		if router.Protocol == ProtocolTCP {
+			rt, ok := h.runtimeConfiguration.TCPRouters[router.Name]
+			if !ok {
+				continue
+			}
+
+			for _, name := range rt.Middlewares {
+				qualifiedName := provider.GetQualifiedName(ctx, name)
+
+				middleware := SimulatedMiddleware{Name: qualifiedName, Router: router.Name}
+				if mi, ok := h.runtimeConfiguration.TCPMiddlewares[qualifiedName]; ok {
+					middleware.Type = strings.ToLower(extractType(mi.TCPMiddleware))
+				}
+				result.Middlewares = append(result.Middlewares, middleware)
+			}
+			result.Service = provider.GetQualifiedName(ctx, rt.Service)
+			continue
+		}
+
+		rt, ok := h.runtimeConfiguration.Routers[router.Name]
+		if !ok {
+			continue
+		}
+
+		for _, name := range rt.Middlewares {
+			result.Middlewares = h.appendHTTPMiddlewares(ctx, result.Middlewares, router.Name, provider.GetQualifiedName(ctx, name), nil)
+		}
+
+		if len(rt.ChildRefs) == 0 {
+			result.Service = provider.GetQualifiedName(ctx, rt.Service)
+		}
+	}
+
+	return result, nil
+}
+
+// appendHTTPMiddlewares appends the given middleware to the chain, replacing the chain middlewares by their content.
+func (h Handler) appendHTTPMiddlewares(ctx context.Context, middlewares []SimulatedMiddleware, routerName, name string, chains []string) []SimulatedMiddleware {
+	mi, ok := h.runtimeConfiguration.Middlewares[name]
+	if !ok {
+		return append(middlewares, SimulatedMiddleware{Name: name, Router: routerName})
+	}
+
+	if mi.Chain == nil || slices.Contains(chains, name) {
+		return append(middlewares, SimulatedMiddleware{
+			Name:   name,
+			Type:   strings.ToLower(extractType(mi.Middleware)),
+			Router: routerName,
+		})
+	}
+
+	chainCtx := provider.AddInContext(ctx, name)
+	for _, chainedName := range mi.Chain.Middlewares {
+		middlewares = h.appendHTTPMiddlewares(chainCtx, middlewares, routerName, provider.GetQualifiedName(chainCtx, chainedName), append(chains, name))
+	}
+
+	return middlewares
+}
+
+func newSimulatedHTTPRequest(simRequest SimulationRequest, isTLS bool) (*http.Request, error) {
+	path := cmp.Or(simRequest.Path, "/")
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("invalid path %q: must start with /", path)
+	}
+
+	req, err := http.NewRequest(cmp.Or(simRequest.Method, http.MethodGet), path, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	req.Host = cmp.Or(simRequest.Host, simRequest.SNI)
+	for name, value := range simRequest.Headers {
+		req.Header.Set(name, value)
+	}
+
+	if simRequest.ClientIP != "" {
+		req.RemoteAddr = net.JoinHostPort(simRequest.ClientIP, "0")
+	}
+
+	if isTLS {
+		req.TLS = &tls.ConnectionState{ServerName: simRequest.SNI}
+	}
+
+	// The Host matchers rely on the canonical host stored in the request context.
+	requestdecorator.New(nil).ServeHTTP(nil, req, func(_ http.ResponseWriter, decorated *http.Request) {
+		req = decorated
+	})
+
+	return req, nil
+}
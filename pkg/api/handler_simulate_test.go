@@ -0,0 +1,344 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/config/runtime"
+	"github.com/hanzoai/ingress/pkg/config/static"
+)
+
+func TestHandler_Simulate(t *testing.T) {
+	rtConf := &runtime.Configuration{
+		Routers: map[string]*runtime.RouterInfo{
+			"api@file": {
+				Router: &dynamic.Router{
+					EntryPoints: []string{"web"},
+					Rule:        "Host(`example.com`) && PathPrefix(`/api`)",
+					Middlewares: []string{"auth", "secured@docker"},
+					Service:     "api-svc",
+				},
+				Status: runtime.StatusEnabled,
+				Using:  []string{"web"},
+			},
+			"site@file": {
+				Router: &dynamic.Router{
+					EntryPoints: []string{"web"},
+					Rule:        "Host(`example.com`)",
+					Service:     "site-svc",
+				},
+				Status: runtime.StatusEnabled,
+				Using:  []string{"web"},
+			},
+			"catchall@file": {
+				Router: &dynamic.Router{
+					EntryPoints: []string{"web"},
+					Rule:        "PathPrefix(`/`)",
+					Priority:    1,
+					Service:     "fallback",
+				},
+				Status: runtime.StatusEnabled,
+				Using:  []string{"web"},
+			},
+			"broken@file": {
+				Router: &dynamic.Router{
+					EntryPoints: []string{"web"},
+					Rule:        "PathPrefix(`/`)",
+					Priority:    1000,
+					Service:     "missing",
+				},
+				Status: runtime.StatusDisabled,
+				Using:  []string{"web"},
+			},
+			"admin@file": {
+				Router: &dynamic.Router{
+					EntryPoints: []string{"web"},
+					Rule:        "Host(`admin.example.com`) && ClientIP(`10.0.0.0/8`)",
+					Service:     "admin-svc",
+				},
+				Status: runtime.StatusEnabled,
+				Using:  []string{"web"},
+			},
+			"parent@file": {
+				Router: &dynamic.Router{
+					EntryPoints: []string{"web"},
+					Rule:        "Host(`multi.example.com`)",
+					Middlewares: []string{"auth"},
+				},
+				Status:    runtime.StatusEnabled,
+				Using:     []string{"web"},
+				ChildRefs: []string{"child-a@file", "child-b@file"},
+			},
+			"child-a@file": {
+				Router: &dynamic.Router{
+					ParentRefs: []string{"parent@file"},
+					Rule:       "Header(`X-Version`, `2`)",
+					Service:    "v2",
+				},
+				Status: runtime.StatusEnabled,
+			},
+			"child-b@file": {
+				Router: &dynamic.Router{
+					ParentRefs: []string{"parent@file"},
+					Rule:       "PathPrefix(`/`)",
+					Service:    "v1",
+				},
+				Status: runtime.StatusEnabled,
+			},
+			"secure@file": {
+				Router: &dynamic.Router{
+					EntryPoints: []string{"websecure"},
+					Rule:        "Host(`example.com`)",
+					Service:     "site-svc",
+					TLS:         &dynamic.RouterTLSConfig{},
+				},
+				Status: runtime.StatusEnabled,
+				Using:  []string{"websecure"},
+			},
+		},
+		Middlewares: map[string]*runtime.MiddlewareInfo{
+			"auth@file": {
+				Middleware: &dynamic.Middleware{
+					Chain: &dynamic.Chain{Middlewares: []string{"basic", "headers@docker"}},
+				},
+			},
+			"basic@file": {
+				Middleware: &dynamic.Middleware{BasicAuth: &dynamic.BasicAuth{}},
+			},
+			"headers@docker": {
+				Middleware: &dynamic.Middleware{Headers: &dynamic.Headers{}},
+			},
+		},
+		TCPRouters: map[string]*runtime.TCPRouterInfo{
+			"db@file": {
+				TCPRouter: &dynamic.TCPRouter{
+					EntryPoints: []string{"websecure"},
+					Rule:        "HostSNI(`db.example.com`)",
+					Middlewares: []string{"allowlist"},
+					Service:     "db-svc",
+					TLS:         &dynamic.RouterTCPTLSConfig{Passthrough: true},
+				},
+				Status: runtime.StatusEnabled,
+				Using:  []string{"websecure"},
+			},
+			"tcp-catchall@file": {
+				TCPRouter: &dynamic.TCPRouter{
+					EntryPoints: []string{"websecure"},
+					Rule:        "HostSNI(`*`)",
+					Service:     "tcp-svc",
+					TLS:         &dynamic.RouterTCPTLSConfig{},
+				},
+				Status: runtime.StatusEnabled,
+				Using:  []string{"websecure"},
+			},
+		},
+		TCPMiddlewares: map[string]*runtime.TCPMiddlewareInfo{
+			"allowlist@file": {
+				TCPMiddleware: &dynamic.TCPMiddleware{IPAllowList: &dynamic.TCPIPAllowList{}},
+			},
+		},
+	}
+
+	testCases := []struct {
+		desc           string
+		body           string
+		disabled       bool
+		explainer      routeExplainerFunc
+		expectedStatus int
+		expected       SimulationResult
+	}{
+		{
+			desc:           "disabled",
+			body:           `{"entryPoint":"web"}`,
+			disabled:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "invalid body",
+			body:           `{`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "missing entry point",
+			body:           `{"host":"example.com"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "unknown entry point",
+			body:           `{"entryPoint":"unknown"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "invalid client IP",
+			body:           `{"entryPoint":"web","clientIP":"foo"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc: "explanation error",
+			body: `{"entryPoint":"web"}`,
+			explainer: func(string, *http.Request) ([]SimulatedRouter, []SimulatedRouter, error) {
+				return nil, nil, errors.New("boom")
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc: "HTTP router with runner-ups",
+			body: `{"entryPoint":"web","host":"example.com","path":"/api/users"}`,
+			explainer: func(string, *http.Request) ([]SimulatedRouter, []SimulatedRouter, error) {
+				return []SimulatedRouter{
+					{Name: "api@file", Protocol: ProtocolHTTP, Rule: "Host(`example.com`) && PathPrefix(`/api`)", Priority: 41},
+				}, []SimulatedRouter{
+					{Name: "catchall@file", Protocol: ProtocolHTTP, Rule: "PathPrefix(`/`)", Priority: 1},
+					{Name: "site@file", Protocol: ProtocolHTTP, Rule: "Host(`example.com`)", Priority: 19},
+				}, nil
+			},
+			expectedStatus: http.StatusOK,
+			expected: SimulationResult{
+				EntryPoint: "web",
+				Routers: []SimulatedRouter{
+					{Name: "api@file", Protocol: ProtocolHTTP, Rule: "Host(`example.com`) && PathPrefix(`/api`)", Priority: 41},
+				},
+				RunnerUps: []SimulatedRouter{
+					{Name: "site@file", Protocol: ProtocolHTTP, Rule: "Host(`example.com`)", Priority: 19},
+					{Name: "catchall@file", Protocol: ProtocolHTTP, Rule: "PathPrefix(`/`)", Priority: 1},
+				},
+				Middlewares: []SimulatedMiddleware{
+					{Name: "basic@file", Type: "basicauth", Router: "api@file"},
+					{Name: "headers@docker", Type: "headers", Router: "api@file"},
+					{Name: "secured@docker", Router: "api@file"},
+				},
+				Service: "api-svc@file",
+			},
+		},
+		{
+			desc: "child routers",
+			body: `{"entryPoint":"web","host":"multi.example.com","headers":{"X-Version":"2"}}`,
+			explainer: func(string, *http.Request) ([]SimulatedRouter, []SimulatedRouter, error) {
+				return []SimulatedRouter{
+					{Name: "parent@file", Protocol: ProtocolHTTP, Rule: "Host(`multi.example.com`)", Priority: 25},
+					{Name: "child-a@file", Protocol: ProtocolHTTP, Rule: "Header(`X-Version`, `2`)", Priority: 24},
+				}, nil, nil
+			},
+			expectedStatus: http.StatusOK,
+			expected: SimulationResult{
+				EntryPoint: "web",
+				Routers: []SimulatedRouter{
+					{Name: "parent@file", Protocol: ProtocolHTTP, Rule: "Host(`multi.example.com`)", Priority: 25},
+					{Name: "child-a@file", Protocol: ProtocolHTTP, Rule: "Header(`X-Version`, `2`)", Priority: 24},
+				},
+				Middlewares: []SimulatedMiddleware{
+					{Name: "basic@file", Type: "basicauth", Router: "parent@file"},
+					{Name: "headers@docker", Type: "headers", Router: "parent@file"},
+				},
+				Service: "v2@file",
+			},
+		},
+		{
+			desc: "parent router without matching child router",
+			body: `{"entryPoint":"web","host":"multi.example.com"}`,
+			explainer: func(string, *http.Request) ([]SimulatedRouter, []SimulatedRouter, error) {
+				return []SimulatedRouter{
+					{Name: "parent@file", Protocol: ProtocolHTTP, Rule: "Host(`multi.example.com`)", Priority: 25},
+				}, nil, nil
+			},
+			expectedStatus: http.StatusOK,
+			expected: SimulationResult{
+				EntryPoint: "web",
+				Routers: []SimulatedRouter{
+					{Name: "parent@file", Protocol: ProtocolHTTP, Rule: "Host(`multi.example.com`)", Priority: 25},
+				},
+				Middlewares: []SimulatedMiddleware{
+					{Name: "basic@file", Type: "basicauth", Router: "parent@file"},
+					{Name: "headers@docker", Type: "headers", Router: "parent@file"},
+				},
+			},
+		},
+		{
+			desc: "TCP router",
+			body: `{"entryPoint":"websecure","sni":"db.example.com","clientIP":"10.1.2.3"}`,
+			explainer: func(entryPointName string, req *http.Request) ([]SimulatedRouter, []SimulatedRouter, error) {
+				if entryPointName != "websecure" || req.TLS == nil || req.TLS.ServerName != "db.example.com" || req.RemoteAddr != "10.1.2.3:0" {
+					return nil, nil, errors.New("unexpected request")
+				}
+
+				return []SimulatedRouter{
+					{Name: "db@file", Protocol: ProtocolTCP, Rule: "HostSNI(`db.example.com`)", Priority: 25},
+				}, []SimulatedRouter{
+					{Name: "tcp-catchall@file", Protocol: ProtocolTCP, Rule: "HostSNI(`*`)", Priority: -1},
+				}, nil
+			},
+			expectedStatus: http.StatusOK,
+			expected: SimulationResult{
+				EntryPoint: "websecure",
+				Routers: []SimulatedRouter{
+					{Name: "db@file", Protocol: ProtocolTCP, Rule: "HostSNI(`db.example.com`)", Priority: 25},
+				},
+				RunnerUps: []SimulatedRouter{
+					{Name: "tcp-catchall@file", Protocol: ProtocolTCP, Rule: "HostSNI(`*`)", Priority: -1},
+				},
+				Middlewares: []SimulatedMiddleware{
+					{Name: "allowlist@file", Type: "ipallowlist", Router: "db@file"},
+				},
+				Service: "db-svc@file",
+			},
+		},
+		{
+			desc: "no matching router",
+			body: `{"entryPoint":"traefik"}`,
+			explainer: func(string, *http.Request) ([]SimulatedRouter, []SimulatedRouter, error) {
+				return nil, nil, nil
+			},
+			expectedStatus: http.StatusOK,
+			expected:       SimulationResult{EntryPoint: "traefik"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			conf := static.Configuration{
+				API: &static.API{},
+				EntryPoints: static.EntryPoints{
+					"web":       {},
+					"websecure": {},
+					"traefik":   {},
+				},
+			}
+
+			var opts Options
+			if !test.disabled {
+				opts.RouteExplainer = test.explainer
+			}
+
+			handler := NewBuilder(conf, opts)(rtConf)
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/v1/ingress/simulate", strings.NewReader(test.body)))
+
+			require.Equal(t, test.expectedStatus, rw.Code)
+			if test.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var result SimulationResult
+			require.NoError(t, json.NewDecoder(rw.Body).Decode(&result))
+
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+// routeExplainerFunc is a RouteExplainer answering with the result of the function.
+type routeExplainerFunc func(entryPointName string, req *http.Request) ([]SimulatedRouter, []SimulatedRouter, error)
+
+func (f routeExplainerFunc) ExplainRoute(entryPointName string, req *http.Request) ([]SimulatedRouter, []SimulatedRouter, error) {
+	return f(entryPointName, req)
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/hanzoai/ingress/pkg/config/snapshot"
+)
+
+type snapshotRepresentation struct {
+	snapshot.Status
+
+	// Age is the time elapsed since the snapshot was saved.
+	Age string `json:"age,omitempty"`
+}
+
+func (h Handler) getConfigSnapshot(rw http.ResponseWriter, request *http.Request) {
+	if h.configSnapshot == nil {
+		writeError(rw, "configuration snapshot is not enabled", http.StatusNotFound)
+		return
+	}
+
+	result := snapshotRepresentation{Status: h.configSnapshot.Status()}
+	if !result.SavedAt.IsZero() {
+		result.Age = time.Since(result.SavedAt).Truncate(time.Second).String()
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	err := json.NewEncoder(rw).Encode(result)
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
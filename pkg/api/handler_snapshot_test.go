@@ -0,0 +1,52 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/config/runtime"
+	"github.com/hanzoai/ingress/pkg/config/snapshot"
+	"github.com/hanzoai/ingress/pkg/config/static"
+)
+
+func TestHandler_ConfigSnapshot(t *testing.T) {
+	conf := static.Configuration{API: &static.API{}, Global: &static.Global{}}
+
+	handler := NewBuilder(conf, Options{})(&runtime.Configuration{})
+
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/ingress/config/snapshot", nil))
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+
+	filePath := filepath.Join(t.TempDir(), "snapshot.json")
+
+	configSnapshot, err := snapshot.New(filePath, "")
+	require.NoError(t, err)
+	require.NoError(t, configSnapshot.Save(dynamic.Configurations{"file": &dynamic.Configuration{}}))
+
+	configSnapshot, err = snapshot.New(filePath, "")
+	require.NoError(t, err)
+	_, err = configSnapshot.Load()
+	require.NoError(t, err)
+
+	handler = NewBuilder(conf, Options{ConfigSnapshot: configSnapshot})(&runtime.Configuration{})
+
+	rw = httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/ingress/config/snapshot", nil))
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	var result snapshotRepresentation
+	require.NoError(t, json.NewDecoder(rw.Body).Decode(&result))
+
+	assert.Equal(t, filePath, result.FilePath)
+	assert.False(t, result.Encrypted)
+	assert.False(t, result.SavedAt.IsZero())
+	assert.NotEmpty(t, result.Age)
+	assert.Equal(t, []string{"file"}, result.ProvisionalProviders)
+}
@@ -23,7 +23,9 @@ func (h Handler) getSupportDump(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	h.runtimeConfiguration.RLockServices()
 	runtimeConfig, err := json.Marshal(h.runtimeConfiguration)
+	h.runtimeConfiguration.RUnlockServices()
 	if err != nil {
 		logger.Error().Err(err).Msg("Unable to marshal runtime configuration")
 		writeError(rw, err.Error(), http.StatusInternalServerError)
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"github.com/hanzoai/ingress/pkg/middlewares/tap"
+)
+
+// getRouterTap streams, as server-sent events, the requests handled by an HTTP router.
+func (h Handler) getRouterTap(rw http.ResponseWriter, request *http.Request) {
+	scapedRouterID := mux.Vars(request)["routerID"]
+
+	routerID, err := url.PathUnescape(scapedRouterID)
+	if err != nil {
+		writeError(rw, fmt.Sprintf("unable to decode routerID %q: %s", scapedRouterID, err), http.StatusBadRequest)
+		return
+	}
+
+	if h.tapHub == nil {
+		writeError(rw, "request tap is not available", http.StatusNotImplemented)
+		return
+	}
+
+	if _, ok := h.runtimeConfiguration.Routers[routerID]; !ok {
+		writeError(rw, fmt.Sprintf("router not found: %s", routerID), http.StatusNotFound)
+		return
+	}
+
+	opts, err := newTapOptions(request.URL.Query())
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sub, err := h.tapHub.Subscribe(routerID, opts)
+	if errors.Is(err, tap.ErrTooManySubscriptions) {
+		writeError(rw, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer sub.Close()
+
+	rc := http.NewResponseController(rw)
+	// The stream lasts longer than the entry point write timeout.
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Msg("Unable to flush the tap stream")
+		return
+	}
+
+	for {
+		select {
+		case <-request.Context().Done():
+			return
+		case <-sub.Done():
+			return
+		case event := <-sub.Events():
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Ctx(request.Context()).Error().Err(err).Send()
+				continue
+			}
+
+			if _, err := fmt.Fprintf(rw, "event: request\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func newTapOptions(query url.Values) (tap.SubscriptionOptions, error) {
+	opts := tap.SubscriptionOptions{
+		Filter: query.Get("filter"),
+	}
+
+	var err error
+	if value := query.Get("rate"); value != "" {
+		opts.Rate, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return opts, fmt.Errorf("invalid rate %q: %w", value, err)
+		}
+	}
+
+	if value := query.Get("sampleRate"); value != "" {
+		opts.SampleRate, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return opts, fmt.Errorf("invalid sampleRate %q: %w", value, err)
+		}
+	}
+
+	if value := query.Get("ttl"); value != "" {
+		opts.TTL, err = time.ParseDuration(value)
+		if err != nil {
+			return opts, fmt.Errorf("invalid ttl %q: %w", value, err)
+		}
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/config/runtime"
+	"github.com/hanzoai/ingress/pkg/config/static"
+	"github.com/hanzoai/ingress/pkg/middlewares/tap"
+)
+
+func TestHandler_RouterTap(t *testing.T) {
+	hub, err := tap.NewHub(nil)
+	require.NoError(t, err)
+
+	rtConf := &runtime.Configuration{
+		Routers: map[string]*runtime.RouterInfo{
+			"foo@file": {Router: &dynamic.Router{Rule: "Host(`foo.bar`)", Service: "svc"}},
+		},
+	}
+
+	testCases := []struct {
+		desc       string
+		hub        *tap.Hub
+		path       string
+		statusCode int
+	}{
+		{
+			desc:       "tap disabled",
+			path:       "/api/v1/ingress/http/routers/foo@file/tap",
+			statusCode: http.StatusNotImplemented,
+		},
+		{
+			desc:       "unknown router",
+			hub:        hub,
+			path:       "/api/v1/ingress/http/routers/bar@file/tap",
+			statusCode: http.StatusNotFound,
+		},
+		{
+			desc:       "invalid filter",
+			hub:        hub,
+			path:       "/api/v1/ingress/http/routers/foo@file/tap?filter=Invalid(%60foo%60)",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			desc:       "invalid ttl",
+			hub:        hub,
+			path:       "/api/v1/ingress/http/routers/foo@file/tap?ttl=foo",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler := NewBuilder(static.Configuration{API: &static.API{BasePath: "/api"}, Global: &static.Global{}}, Options{TapHub: test.hub})(rtConf)
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, test.path, nil))
+
+			assert.Equal(t, test.statusCode, rw.Code)
+		})
+	}
+}
+
+func TestHandler_RouterTap_stream(t *testing.T) {
+	hub, err := tap.NewHub(nil)
+	require.NoError(t, err)
+
+	rtConf := &runtime.Configuration{
+		Routers: map[string]*runtime.RouterInfo{
+			"foo@file": {Router: &dynamic.Router{Rule: "Host(`foo.bar`)", Service: "svc"}},
+		},
+	}
+
+	server := httptest.NewServer(NewBuilder(static.Configuration{API: &static.API{}, Global: &static.Global{}}, Options{TapHub: hub})(rtConf))
+	t.Cleanup(server.Close)
+
+	resp, err := http.Get(server.URL + "/v1/ingress/http/routers/foo@file/tap")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	tapped, err := tap.WrapHandler(t.Context(), hub, "foo@file")(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	require.NoError(t, err)
+
+	tapped.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://foo.bar/baz", nil))
+
+	reader := bufio.NewReader(resp.Body)
+
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "event: request\n", line)
+
+	line, err = reader.ReadString('\n')
+	require.NoError(t, err)
+
+	var event tap.Event
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+	assert.Equal(t, "foo@file", event.Router)
+	assert.Equal(t, "/baz", event.Path)
+	assert.Equal(t, http.StatusNoContent, event.StatusCode)
+}
@@ -122,6 +122,9 @@ func (h Handler) getTCPRouter(rw http.ResponseWriter, request *http.Request) {
 }
 
 func (h Handler) getTCPServices(rw http.ResponseWriter, request *http.Request) {
+	h.runtimeConfiguration.RLockServices()
+	defer h.runtimeConfiguration.RUnlockServices()
+
 	results := make([]tcpServiceRepresentation, 0, len(h.runtimeConfiguration.TCPServices))
 
 	query := request.URL.Query()
@@ -153,6 +156,9 @@ func (h Handler) getTCPServices(rw http.ResponseWriter, request *http.Request) {
 }
 
 func (h Handler) getTCPService(rw http.ResponseWriter, request *http.Request) {
+	h.runtimeConfiguration.RLockServices()
+	defer h.runtimeConfiguration.RUnlockServices()
+
 	scapedServiceID := mux.Vars(request)["serviceID"]
 
 	serviceID, err := url.PathUnescape(scapedServiceID)
@@ -103,6 +103,9 @@ func (h Handler) getUDPRouter(rw http.ResponseWriter, request *http.Request) {
 }
 
 func (h Handler) getUDPServices(rw http.ResponseWriter, request *http.Request) {
+	h.runtimeConfiguration.RLockServices()
+	defer h.runtimeConfiguration.RUnlockServices()
+
 	results := make([]udpServiceRepresentation, 0, len(h.runtimeConfiguration.UDPServices))
 
 	query := request.URL.Query()
@@ -134,6 +137,9 @@ func (h Handler) getUDPServices(rw http.ResponseWriter, request *http.Request) {
 }
 
 func (h Handler) getUDPService(rw http.ResponseWriter, request *http.Request) {
+	h.runtimeConfiguration.RLockServices()
+	defer h.runtimeConfiguration.RUnlockServices()
+
 	scapedServiceID := mux.Vars(request)["serviceID"]
 
 	serviceID, err := url.PathUnescape(scapedServiceID)
@@ -25,6 +25,9 @@ const (
 	// DefaultFlushInterval is the default value for the ResponseForwarding flush interval.
 	DefaultFlushInterval = ptypes.Duration(100 * time.Millisecond)
 
+	// DefaultDNSRefreshInterval is the default value for the DNSDiscovery refresh interval.
+	DefaultDNSRefreshInterval = ptypes.Duration(5 * time.Second)
+
 	// MirroringDefaultMirrorBody is the Mirroring.MirrorBody option default value.
 	MirroringDefaultMirrorBody = true
 	// MirroringDefaultMaxBodySize is the Mirroring.MaxBodySize option default value.
@@ -363,6 +366,8 @@ type ServersLoadBalancer struct {
 	Sticky   *Sticky          `json:"sticky,omitempty" toml:"sticky,omitempty" yaml:"sticky,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
 	Servers  []Server         `json:"servers,omitempty" toml:"servers,omitempty" yaml:"servers,omitempty" label-slice-as-struct:"server" export:"true"`
 	Strategy BalancerStrategy `json:"strategy,omitempty" toml:"strategy,omitempty" yaml:"strategy,omitempty" export:"true"`
+	// DNS enables the discovery of servers through DNS, in addition to the defined servers.
+	DNS *DNSDiscovery `json:"dns,omitempty" toml:"dns,omitempty" yaml:"dns,omitempty" export:"true"`
 	// HealthCheck enables regular active checks of the responsiveness of the
 	// children servers of this load-balancer. To propagate status changes (e.g. all
 	// servers of this service are down) upwards, HealthCheck must also be enabled on
@@ -370,9 +375,25 @@ type ServersLoadBalancer struct {
 	HealthCheck *ServerHealthCheck `json:"healthCheck,omitempty" toml:"healthCheck,omitempty" yaml:"healthCheck,omitempty" export:"true"`
 	// PassiveHealthCheck enables passive health checks for children servers of this load-balancer.
 	PassiveHealthCheck *PassiveServerHealthCheck `json:"passiveHealthCheck,omitempty" toml:"passiveHealthCheck,omitempty" yaml:"passiveHealthCheck,omitempty" export:"true"`
-	PassHostHeader     *bool                     `json:"passHostHeader" toml:"passHostHeader" yaml:"passHostHeader" export:"true"`
-	ResponseForwarding *ResponseForwarding       `json:"responseForwarding,omitempty" toml:"responseForwarding,omitempty" yaml:"responseForwarding,omitempty" export:"true"`
-	ServersTransport   string                    `json:"serversTransport,omitempty" toml:"serversTransport,omitempty" yaml:"serversTransport,omitempty" export:"true"`
+	// Hedging enables sending a duplicate of the idempotent requests to another server,
+	// when the response of the first server is delayed.
+	Hedging *Hedging `json:"hedging,omitempty" toml:"hedging,omitempty" yaml:"hedging,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	// RetryBudget limits the concurrent retries of the requests sent to the servers of this load-balancer
+	// by the retry middleware, to a percentage of the active requests.
+	RetryBudget *RetryBudget `json:"retryBudget,omitempty" toml:"retryBudget,omitempty" yaml:"retryBudget,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	// SlowStart enables ramping up the weight of the servers which have just been added or have recovered.
+	SlowStart *SlowStart `json:"slowStart,omitempty" toml:"slowStart,omitempty" yaml:"slowStart,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	// Locality enables preferring the servers of the zone of the instance,
+	// and spilling over to the servers of the other zones according to their health and load.
+	Locality *Locality `json:"locality,omitempty" toml:"locality,omitempty" yaml:"locality,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	// OutlierDetection enables ejecting, for an increasing duration, the servers which success rate or latency deviates from the other servers.
+	OutlierDetection   *OutlierDetection   `json:"outlierDetection,omitempty" toml:"outlierDetection,omitempty" yaml:"outlierDetection,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	PassHostHeader     *bool               `json:"passHostHeader" toml:"passHostHeader" yaml:"passHostHeader" export:"true"`
+	ResponseForwarding *ResponseForwarding `json:"responseForwarding,omitempty" toml:"responseForwarding,omitempty" yaml:"responseForwarding,omitempty" export:"true"`
+	ServersTransport   string              `json:"serversTransport,omitempty" toml:"serversTransport,omitempty" yaml:"serversTransport,omitempty" export:"true"`
+	// DrainTimeout is the duration during which the in-flight requests to a server removed from this load-balancer
+	// are allowed to finish, before being canceled. Zero disables the draining of the removed servers.
+	DrainTimeout *ptypes.Duration `json:"drainTimeout,omitempty" toml:"drainTimeout,omitempty" yaml:"drainTimeout,omitempty" export:"true"`
 }
 
 // Merge merges the other load balancer into this one.
@@ -447,6 +468,8 @@ type Server struct {
 	Weight       *int   `json:"weight,omitempty" toml:"weight,omitempty" yaml:"weight,omitempty" export:"true"`
 	PreservePath bool   `json:"preservePath,omitempty" toml:"preservePath,omitempty" yaml:"preservePath,omitempty" export:"true"`
 	Fenced       bool   `json:"fenced,omitempty" toml:"-" yaml:"-" label:"-" file:"-" kv:"-"`
+	// Zone is the zone, or region, of the server, for the load-balancer to prefer the servers of the zone of the instance.
+	Zone string `json:"zone,omitempty" toml:"zone,omitempty" yaml:"zone,omitempty" export:"true"`
 	// Scheme can only be defined with label Providers.
 	Scheme string `json:"-" toml:"-" yaml:"-" file:"-" kv:"-"`
 	Port   string `json:"-" toml:"-" yaml:"-" file:"-" kv:"-"`
@@ -454,6 +477,34 @@ type Server struct {
 
 // +k8s:deepcopy-gen=true
 
+// DNSDiscovery holds the configuration of the discovery of the servers of a load-balancer through DNS.
+// The records are resolved periodically, and the servers of the load-balancer are updated without reloading the configuration.
+type DNSDiscovery struct {
+	// Name is the DNS name to resolve.
+	Name string `json:"name,omitempty" toml:"name,omitempty" yaml:"name,omitempty"`
+	// RecordType is the type of the DNS records to resolve: SRV, A or AAAA.
+	// The weights of SRV records are used as the weights of the servers.
+	RecordType string `json:"recordType,omitempty" toml:"recordType,omitempty" yaml:"recordType,omitempty" export:"true"`
+	// Port is the port of the servers, required for A and AAAA records.
+	Port int `json:"port,omitempty" toml:"port,omitempty,omitzero" yaml:"port,omitempty" export:"true"`
+	// Scheme is the scheme of the URLs of the discovered HTTP servers.
+	Scheme string `json:"scheme,omitempty" toml:"scheme,omitempty" yaml:"scheme,omitempty" export:"true"`
+	// TLS enables TLS for the discovered TCP servers.
+	TLS bool `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" export:"true"`
+	// RefreshInterval is the minimum interval between two resolutions.
+	// The records are resolved again when their TTL expires.
+	RefreshInterval ptypes.Duration `json:"refreshInterval,omitempty" toml:"refreshInterval,omitempty" yaml:"refreshInterval,omitempty" export:"true"`
+}
+
+// SetDefaults Default values for a DNSDiscovery.
+func (d *DNSDiscovery) SetDefaults() {
+	d.RecordType = "SRV"
+	d.Scheme = "http"
+	d.RefreshInterval = DefaultDNSRefreshInterval
+}
+
+// +k8s:deepcopy-gen=true
+
 // ServerHealthCheck holds the HealthCheck configuration.
 type ServerHealthCheck struct {
 	Scheme            string            `json:"scheme,omitempty" toml:"scheme,omitempty" yaml:"scheme,omitempty" export:"true"`
@@ -494,6 +545,121 @@ func (p *PassiveServerHealthCheck) SetDefaults() {
 
 // +k8s:deepcopy-gen=true
 
+// Hedging holds the hedging configuration of a load-balancer.
+type Hedging struct {
+	// Delay is the time to wait for the response of a server, before sending a hedged request to another server.
+	Delay ptypes.Duration `json:"delay,omitempty" toml:"delay,omitempty" yaml:"delay,omitempty" export:"true"`
+	// Percentile, when set, uses the given percentile of the response times observed by the leasttime strategy as delay.
+	// The Delay is used until response times are observed.
+	Percentile float64 `json:"percentile,omitempty" toml:"percentile,omitempty" yaml:"percentile,omitempty" export:"true"`
+	// MaxRatio is the maximum ratio of hedged requests to the hedgeable requests of the service.
+	MaxRatio float64 `json:"maxRatio,omitempty" toml:"maxRatio,omitempty" yaml:"maxRatio,omitempty" export:"true"`
+}
+
+// SetDefaults Default values for a Hedging.
+func (h *Hedging) SetDefaults() {
+	h.Delay = ptypes.Duration(100 * time.Millisecond)
+	h.MaxRatio = 0.1
+}
+
+// +k8s:deepcopy-gen=true
+
+// RetryBudget holds the retry budget configuration of a load-balancer.
+type RetryBudget struct {
+	// Percent is the maximum percentage of the active requests of the load-balancer which can be concurrent retries.
+	Percent float64 `json:"percent,omitempty" toml:"percent,omitempty" yaml:"percent,omitempty" export:"true"`
+	// MinRetryConcurrency is the number of concurrent retries which are always allowed, regardless of the active requests.
+	MinRetryConcurrency int `json:"minRetryConcurrency,omitempty" toml:"minRetryConcurrency,omitempty" yaml:"minRetryConcurrency,omitempty" export:"true"`
+}
+
+// SetDefaults Default values for a RetryBudget.
+func (r *RetryBudget) SetDefaults() {
+	r.Percent = 20
+	r.MinRetryConcurrency = 3
+}
+
+// +k8s:deepcopy-gen=true
+
+// OutlierDetection holds the outlier detection configuration of a load-balancer.
+type OutlierDetection struct {
+	// Interval is the interval between two analyses of the servers.
+	Interval ptypes.Duration `json:"interval,omitempty" toml:"interval,omitempty" yaml:"interval,omitempty" export:"true"`
+	// BaseEjectionTime is the duration of the first ejection of a server, doubled for each consecutive ejection.
+	BaseEjectionTime ptypes.Duration `json:"baseEjectionTime,omitempty" toml:"baseEjectionTime,omitempty" yaml:"baseEjectionTime,omitempty" export:"true"`
+	// MaxEjectionTime is the maximum duration of an ejection.
+	MaxEjectionTime ptypes.Duration `json:"maxEjectionTime,omitempty" toml:"maxEjectionTime,omitempty" yaml:"maxEjectionTime,omitempty" export:"true"`
+	// MaxEjectionPercent is the maximum percentage of the servers which can be ejected at the same time.
+	// At least one server can be ejected.
+	MaxEjectionPercent int `json:"maxEjectionPercent,omitempty" toml:"maxEjectionPercent,omitempty" yaml:"maxEjectionPercent,omitempty" export:"true"`
+	// MinimumHosts is the minimum number of servers having received RequestVolume requests during an interval,
+	// for the servers to be analyzed.
+	MinimumHosts int `json:"minimumHosts,omitempty" toml:"minimumHosts,omitempty" yaml:"minimumHosts,omitempty" export:"true"`
+	// RequestVolume is the minimum number of requests received by a server during an interval, for the server to be analyzed.
+	RequestVolume int `json:"requestVolume,omitempty" toml:"requestVolume,omitempty" yaml:"requestVolume,omitempty" export:"true"`
+	// SuccessRateStdevFactor ejects the servers which success rate is lower than the mean success rate of the servers,
+	// by more than this factor multiplied by the standard deviation. Zero disables the success rate detection.
+	SuccessRateStdevFactor float64 `json:"successRateStdevFactor,omitempty" toml:"successRateStdevFactor,omitempty" yaml:"successRateStdevFactor,omitempty" export:"true"`
+	// LatencyStdevFactor ejects the servers which average latency is higher than the mean average latency of the servers,
+	// by more than this factor multiplied by the standard deviation. Zero disables the latency detection.
+	LatencyStdevFactor float64 `json:"latencyStdevFactor,omitempty" toml:"latencyStdevFactor,omitempty" yaml:"latencyStdevFactor,omitempty" export:"true"`
+}
+
+// SetDefaults Default values for an OutlierDetection.
+func (o *OutlierDetection) SetDefaults() {
+	o.Interval = ptypes.Duration(10 * time.Second)
+	o.BaseEjectionTime = ptypes.Duration(30 * time.Second)
+	o.MaxEjectionTime = ptypes.Duration(300 * time.Second)
+	o.MaxEjectionPercent = 10
+	o.MinimumHosts = 5
+	o.RequestVolume = 100
+	o.SuccessRateStdevFactor = 1.9
+}
+
+// +k8s:deepcopy-gen=true
+
+// SlowStart holds the slow start configuration of a load-balancer.
+type SlowStart struct {
+	// Window is the duration during which the weight of a new or recovered server is ramped up.
+	Window ptypes.Duration `json:"window,omitempty" toml:"window,omitempty" yaml:"window,omitempty" export:"true"`
+	// Ramp is the curve of the ramp up of the weight: linear or exponential.
+	Ramp string `json:"ramp,omitempty" toml:"ramp,omitempty" yaml:"ramp,omitempty" export:"true"`
+	// MinWeightPercent is the percentage of its weight given to a server at the start of the window.
+	MinWeightPercent int `json:"minWeightPercent,omitempty" toml:"minWeightPercent,omitempty" yaml:"minWeightPercent,omitempty" export:"true"`
+}
+
+// SetDefaults Default values for a SlowStart.
+func (s *SlowStart) SetDefaults() {
+	s.Window = ptypes.Duration(30 * time.Second)
+	s.Ramp = SlowStartRampLinear
+	s.MinWeightPercent = 10
+}
+
+const (
+	// SlowStartRampLinear ramps up the weight linearly.
+	SlowStartRampLinear = "linear"
+	// SlowStartRampExponential ramps up the weight exponentially.
+	SlowStartRampExponential = "exponential"
+)
+
+// +k8s:deepcopy-gen=true
+
+// Locality holds the zone-aware load-balancing configuration of a load-balancer.
+type Locality struct {
+	// MinHealthyPercent is the percentage of the servers of the zone of the instance which must be healthy
+	// for them to receive all the requests. Below it, the requests spill over to the other zones in proportion.
+	MinHealthyPercent int `json:"minHealthyPercent,omitempty" toml:"minHealthyPercent,omitempty" yaml:"minHealthyPercent,omitempty" export:"true"`
+	// MaxInFlightPerServer is the number of in-flight requests per healthy server of the zone of the instance
+	// above which the requests spill over to the other zones. Zero means no limit.
+	MaxInFlightPerServer int `json:"maxInFlightPerServer,omitempty" toml:"maxInFlightPerServer,omitempty" yaml:"maxInFlightPerServer,omitempty" export:"true"`
+}
+
+// SetDefaults Default values for a Locality.
+func (l *Locality) SetDefaults() {
+	l.MinHealthyPercent = 70
+}
+
+// +k8s:deepcopy-gen=true
+
 // HealthCheck controls healthcheck awareness and propagation at the services level.
 type HealthCheck struct{}
 
@@ -513,6 +679,35 @@ type ServersTransport struct {
 	DisableHTTP2        bool                    `description:"Disables HTTP/2 for connections with backend servers." json:"disableHTTP2,omitempty" toml:"disableHTTP2,omitempty" yaml:"disableHTTP2,omitempty" export:"true"`
 	PeerCertURI         string                  `description:"Defines the URI used to match against SAN URI during the peer certificate verification." json:"peerCertURI,omitempty" toml:"peerCertURI,omitempty" yaml:"peerCertURI,omitempty" export:"true"`
 	Spiffe              *Spiffe                 `description:"Defines the SPIFFE configuration." json:"spiffe,omitempty" toml:"spiffe,omitempty" yaml:"spiffe,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	HTTP3               *HTTP3ClientConfig      `description:"Defines the HTTP/3 configuration used with the h3 servers." json:"http3,omitempty" toml:"http3,omitempty" yaml:"http3,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	Proxy               *ForwardProxy           `description:"Defines the forward proxy used to reach the backend servers." json:"proxy,omitempty" toml:"proxy,omitempty" yaml:"proxy,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// HTTP3ClientConfig holds the HTTP/3 configuration used to contact the backend servers.
+type HTTP3ClientConfig struct {
+	// Enable0RTT enables 0-RTT for the GET and HEAD requests sent on resumed connections.
+	// The 0-RTT data is not protected against replay attacks.
+	Enable0RTT bool `description:"Enables 0-RTT for the GET and HEAD requests sent on resumed connections (0-RTT data is not protected against replay attacks)." json:"enable0RTT,omitempty" toml:"enable0RTT,omitempty" yaml:"enable0RTT,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// ForwardProxy holds the configuration of the forward proxy used to reach the backend servers.
+type ForwardProxy struct {
+	// URL is the URL of the forward proxy, with the http or https scheme for HTTP CONNECT, or the socks5 scheme.
+	URL string `description:"Defines the URL of the forward proxy, with the http or https scheme for HTTP CONNECT, or the socks5 scheme." json:"url,omitempty" toml:"url,omitempty" yaml:"url,omitempty"`
+	// Username and Password are the credentials used to authenticate with the forward proxy,
+	// with the basic authentication scheme for HTTP CONNECT.
+	Username string `description:"Defines the username used to authenticate with the forward proxy." json:"username,omitempty" toml:"username,omitempty" yaml:"username,omitempty" loggable:"false"`
+	Password string `description:"Defines the password used to authenticate with the forward proxy." json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty" loggable:"false"`
+	// NoProxy lists the servers reached without the forward proxy, with the NO_PROXY environment variable syntax.
+	NoProxy []string `description:"Defines the servers reached without the forward proxy, as host names, domain names (.example.com), IP addresses or CIDRs, optionally with a port." json:"noProxy,omitempty" toml:"noProxy,omitempty" yaml:"noProxy,omitempty" export:"true"`
+	// InsecureSkipVerify and RootCAs configure the verification of the certificate of a forward proxy with the https scheme,
+	// independently of the TLS configuration used with the backend servers.
+	InsecureSkipVerify bool                  `description:"Disables the verification of the certificate of the forward proxy." json:"insecureSkipVerify,omitempty" toml:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty" export:"true"`
+	RootCAs            []types.FileOrContent `description:"Defines a list of CA certificates used to verify the certificate of the forward proxy, instead of the system ones." json:"rootCAs,omitempty" toml:"rootCAs,omitempty" yaml:"rootCAs,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -49,6 +49,9 @@ type Middleware struct {
 	GrpcWeb           *GrpcWeb           `json:"grpcWeb,omitempty" toml:"grpcWeb,omitempty" yaml:"grpcWeb,omitempty" export:"true"`
 	StaticFiles       *StaticFiles       `json:"staticFiles,omitempty" toml:"staticFiles,omitempty" yaml:"staticFiles,omitempty" export:"true"`
 
+	AdaptiveConcurrency *AdaptiveConcurrency `json:"adaptiveConcurrency,omitempty" toml:"adaptiveConcurrency,omitempty" yaml:"adaptiveConcurrency,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	Queue               *Queue               `json:"queue,omitempty" toml:"queue,omitempty" yaml:"queue,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+
 	Plugin map[string]PluginConf `json:"plugin,omitempty" toml:"plugin,omitempty" yaml:"plugin,omitempty" export:"true"`
 
 	// Gateway API filter middlewares.
@@ -106,6 +109,42 @@ type ContentType struct {
 
 // +k8s:deepcopy-gen=true
 
+// AdaptiveConcurrency holds the adaptive concurrency middleware configuration.
+// This middleware limits the number of requests being processed concurrently to a limit adjusted from the observed latencies,
+// and sheds the requests above the limit, starting with the lowest priority ones.
+type AdaptiveConcurrency struct {
+	// Algorithm defines the algorithm adjusting the limit from the observed latencies: gradient, aimd or vegas.
+	Algorithm string `json:"algorithm,omitempty" toml:"algorithm,omitempty" yaml:"algorithm,omitempty" export:"true"`
+	// InitialLimit defines the limit before any latency has been observed.
+	InitialLimit int `json:"initialLimit,omitempty" toml:"initialLimit,omitempty" yaml:"initialLimit,omitempty" export:"true"`
+	// MinLimit defines the lowest value of the limit.
+	MinLimit int `json:"minLimit,omitempty" toml:"minLimit,omitempty" yaml:"minLimit,omitempty" export:"true"`
+	// MaxLimit defines the highest value of the limit.
+	MaxLimit int `json:"maxLimit,omitempty" toml:"maxLimit,omitempty" yaml:"maxLimit,omitempty" export:"true"`
+	// Group defines the name of the limit shared by the middlewares of the same group,
+	// for instance by the middlewares of all the routers of a service.
+	// Defaults to the middleware name.
+	Group string `json:"group,omitempty" toml:"group,omitempty" yaml:"group,omitempty" export:"true"`
+	// Priority defines the priority class of the requests: critical, standard or sheddable.
+	Priority string `json:"priority,omitempty" toml:"priority,omitempty" yaml:"priority,omitempty" export:"true"`
+	// PriorityHeader defines the name of the request header overriding the priority class of the request.
+	PriorityHeader string `json:"priorityHeader,omitempty" toml:"priorityHeader,omitempty" yaml:"priorityHeader,omitempty" export:"true"`
+	// RetryAfter defines the delay advertised in the Retry-After header of the shed requests.
+	RetryAfter ptypes.Duration `json:"retryAfter,omitempty" toml:"retryAfter,omitempty" yaml:"retryAfter,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on an AdaptiveConcurrency.
+func (a *AdaptiveConcurrency) SetDefaults() {
+	a.Algorithm = "gradient"
+	a.InitialLimit = 20
+	a.MinLimit = 1
+	a.MaxLimit = 1000
+	a.Priority = "standard"
+	a.RetryAfter = ptypes.Duration(time.Second)
+}
+
+// +k8s:deepcopy-gen=true
+
 // AddPrefix holds the add prefix middleware configuration.
 // This middleware updates the path of a request before forwarding it.
 // More info: https://hanzo.ai/docs/ingress/v3.6/middlewares/http/addprefix/
@@ -652,6 +691,44 @@ func (r *RateLimit) SetDefaults() {
 
 // +k8s:deepcopy-gen=true
 
+// Queue holds the queue middleware configuration.
+// This middleware limits the number of requests being processed concurrently,
+// and queues the requests above the limit instead of rejecting them.
+type Queue struct {
+	// MaxInFlight defines the maximum number of requests being processed concurrently, per source.
+	MaxInFlight int64 `json:"maxInFlight,omitempty" toml:"maxInFlight,omitempty" yaml:"maxInFlight,omitempty" export:"true"`
+	// MaxSize defines the maximum number of requests waiting in the queue, per source.
+	// The middleware responds with HTTP 429 Too Many Requests to the requests which do not fit in the queue.
+	MaxSize int `json:"maxSize,omitempty" toml:"maxSize,omitempty" yaml:"maxSize,omitempty" export:"true"`
+	// MaxWait defines the maximum duration a request waits in the queue.
+	// The middleware responds with HTTP 503 Service Unavailable to the requests waiting longer.
+	MaxWait ptypes.Duration `json:"maxWait,omitempty" toml:"maxWait,omitempty" yaml:"maxWait,omitempty" export:"true"`
+	// Order defines the order in which the queued requests are served: fifo, or priority to serve the highest priority requests first.
+	Order string `json:"order,omitempty" toml:"order,omitempty" yaml:"order,omitempty" export:"true"`
+	// Priority defines the priority class of the requests: critical, standard or sheddable.
+	Priority string `json:"priority,omitempty" toml:"priority,omitempty" yaml:"priority,omitempty" export:"true"`
+	// PriorityHeader defines the name of the request header overriding the priority class of the request.
+	PriorityHeader string `json:"priorityHeader,omitempty" toml:"priorityHeader,omitempty" yaml:"priorityHeader,omitempty" export:"true"`
+	// PriorityClaim defines the name of the claim of the JWT bearer token overriding the priority class of the request.
+	// The token is not verified by this middleware.
+	PriorityClaim string `json:"priorityClaim,omitempty" toml:"priorityClaim,omitempty" yaml:"priorityClaim,omitempty" export:"true"`
+	// SourceCriterion defines what criterion is used to group requests as originating from a common source.
+	// If several strategies are defined at the same time, an error will be raised.
+	// If none are set, the default is to use the requestHost.
+	SourceCriterion *SourceCriterion `json:"sourceCriterion,omitempty" toml:"sourceCriterion,omitempty" yaml:"sourceCriterion,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a Queue.
+func (q *Queue) SetDefaults() {
+	q.MaxInFlight = 10
+	q.MaxSize = 100
+	q.MaxWait = ptypes.Duration(10 * time.Second)
+	q.Order = "fifo"
+	q.Priority = "standard"
+}
+
+// +k8s:deepcopy-gen=true
+
 // Redis holds the Redis configuration.
 type Redis struct {
 	// Endpoints contains either a single address or a seed list of host:port addresses.
@@ -97,6 +97,8 @@ type RouterTCPTLSConfig struct {
 type TCPServersLoadBalancer struct {
 	Servers          []TCPServer `json:"servers,omitempty" toml:"servers,omitempty" yaml:"servers,omitempty" label-slice-as-struct:"server" export:"true"`
 	ServersTransport string      `json:"serversTransport,omitempty" toml:"serversTransport,omitempty" yaml:"serversTransport,omitempty" export:"true"`
+	// DNS enables the discovery of servers through DNS, in addition to the defined servers.
+	DNS *DNSDiscovery `json:"dns,omitempty" toml:"dns,omitempty" yaml:"dns,omitempty" export:"true"`
 	// ProxyProtocol holds the PROXY Protocol configuration.
 	//
 	// Deprecated: use ServersTransport to configure ProxyProtocol instead.
@@ -110,6 +112,9 @@ type TCPServersLoadBalancer struct {
 	// Deprecated: use ServersTransport to configure the TerminationDelay instead.
 	TerminationDelay *int                  `json:"terminationDelay,omitempty" toml:"terminationDelay,omitempty" yaml:"terminationDelay,omitempty" export:"true"`
 	HealthCheck      *TCPServerHealthCheck `json:"healthCheck,omitempty" toml:"healthCheck,omitempty" yaml:"healthCheck,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	// DrainTimeout is the duration during which the connections to a server removed from this load-balancer
+	// are allowed to finish, before being closed. Zero disables the draining of the removed servers.
+	DrainTimeout *ptypes.Duration `json:"drainTimeout,omitempty" toml:"drainTimeout,omitempty" yaml:"drainTimeout,omitempty" export:"true"`
 }
 
 // Merge merges the other load balancer into this one.
@@ -190,6 +195,7 @@ type TCPServersTransport struct {
 	// means an infinite deadline (i.e. the reading capability is never closed).
 	TerminationDelay ptypes.Duration  `description:"Defines the delay to wait before fully terminating the connection, after one connected peer has closed its writing capability." json:"terminationDelay,omitempty" toml:"terminationDelay,omitempty" yaml:"terminationDelay,omitempty" export:"true"`
 	TLS              *TLSClientConfig `description:"Defines the TLS configuration." json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	Proxy            *ForwardProxy    `description:"Defines the forward proxy used to reach the backend servers." json:"proxy,omitempty" toml:"proxy,omitempty" yaml:"proxy,omitempty" export:"true"`
 }
 
 // SetDefaults sets the default values for a TCPServersTransport.
@@ -64,6 +64,8 @@ type UDPRouter struct {
 // UDPServersLoadBalancer defines the configuration for a load-balancer of UDP servers.
 type UDPServersLoadBalancer struct {
 	Servers []UDPServer `json:"servers,omitempty" toml:"servers,omitempty" yaml:"servers,omitempty" label-slice-as-struct:"server" export:"true"`
+	// DNS enables the discovery of servers through DNS, in addition to the defined servers.
+	DNS *DNSDiscovery `json:"dns,omitempty" toml:"dns,omitempty" yaml:"dns,omitempty" export:"true"`
 }
 
 // Merge merges the other load balancer into this one.
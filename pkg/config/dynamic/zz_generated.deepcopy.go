@@ -35,6 +35,22 @@ import (
 	types "github.com/hanzoai/ingress/pkg/types"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdaptiveConcurrency) DeepCopyInto(out *AdaptiveConcurrency) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdaptiveConcurrency.
+func (in *AdaptiveConcurrency) DeepCopy() *AdaptiveConcurrency {
+	if in == nil {
+		return nil
+	}
+	out := new(AdaptiveConcurrency)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AddPrefix) DeepCopyInto(out *AddPrefix) {
 	*out = *in
@@ -306,6 +322,22 @@ func (in *Cookie) DeepCopy() *Cookie {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSDiscovery) DeepCopyInto(out *DNSDiscovery) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSDiscovery.
+func (in *DNSDiscovery) DeepCopy() *DNSDiscovery {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSDiscovery)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DigestAuth) DeepCopyInto(out *DigestAuth) {
 	*out = *in
@@ -469,6 +501,32 @@ func (in *ForwardAuth) DeepCopy() *ForwardAuth {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ForwardProxy) DeepCopyInto(out *ForwardProxy) {
+	*out = *in
+	if in.NoProxy != nil {
+		in, out := &in.NoProxy, &out.NoProxy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RootCAs != nil {
+		in, out := &in.RootCAs, &out.RootCAs
+		*out = make([]types.FileOrContent, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ForwardProxy.
+func (in *ForwardProxy) DeepCopy() *ForwardProxy {
+	if in == nil {
+		return nil
+	}
+	out := new(ForwardProxy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ForwardingTimeouts) DeepCopyInto(out *ForwardingTimeouts) {
 	*out = *in
@@ -543,6 +601,38 @@ func (in *HRWService) DeepCopy() *HRWService {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Hedging) DeepCopyInto(out *Hedging) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Hedging.
+func (in *Hedging) DeepCopy() *Hedging {
+	if in == nil {
+		return nil
+	}
+	out := new(Hedging)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTP3ClientConfig) DeepCopyInto(out *HTTP3ClientConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTP3ClientConfig.
+func (in *HTTP3ClientConfig) DeepCopy() *HTTP3ClientConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTP3ClientConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTPConfiguration) DeepCopyInto(out *HTTPConfiguration) {
 	*out = *in
@@ -910,6 +1000,22 @@ func (in *InFlightReq) DeepCopy() *InFlightReq {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Locality) DeepCopyInto(out *Locality) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Locality.
+func (in *Locality) DeepCopy() *Locality {
+	if in == nil {
+		return nil
+	}
+	out := new(Locality)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Message) DeepCopyInto(out *Message) {
 	*out = *in
@@ -1064,6 +1170,16 @@ func (in *Middleware) DeepCopyInto(out *Middleware) {
 		*out = new(StaticFiles)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AdaptiveConcurrency != nil {
+		in, out := &in.AdaptiveConcurrency, &out.AdaptiveConcurrency
+		*out = new(AdaptiveConcurrency)
+		**out = **in
+	}
+	if in.Queue != nil {
+		in, out := &in.Queue, &out.Queue
+		*out = new(Queue)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Plugin != nil {
 		in, out := &in.Plugin, &out.Plugin
 		*out = make(map[string]PluginConf, len(*in))
@@ -1193,6 +1309,22 @@ func (in *Model) DeepCopy() *Model {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OutlierDetection) DeepCopyInto(out *OutlierDetection) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutlierDetection.
+func (in *OutlierDetection) DeepCopy() *OutlierDetection {
+	if in == nil {
+		return nil
+	}
+	out := new(OutlierDetection)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PassTLSClientCert) DeepCopyInto(out *PassTLSClientCert) {
 	*out = *in
@@ -1246,6 +1378,27 @@ func (in *ProxyProtocol) DeepCopy() *ProxyProtocol {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Queue) DeepCopyInto(out *Queue) {
+	*out = *in
+	if in.SourceCriterion != nil {
+		in, out := &in.SourceCriterion, &out.SourceCriterion
+		*out = new(SourceCriterion)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Queue.
+func (in *Queue) DeepCopy() *Queue {
+	if in == nil {
+		return nil
+	}
+	out := new(Queue)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RateLimit) DeepCopyInto(out *RateLimit) {
 	*out = *in
@@ -1465,6 +1618,22 @@ func (in *Retry) DeepCopy() *Retry {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryBudget) DeepCopyInto(out *RetryBudget) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryBudget.
+func (in *RetryBudget) DeepCopy() *RetryBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Router) DeepCopyInto(out *Router) {
 	*out = *in
@@ -1674,6 +1843,11 @@ func (in *ServersLoadBalancer) DeepCopyInto(out *ServersLoadBalancer) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = new(DNSDiscovery)
+		**out = **in
+	}
 	if in.HealthCheck != nil {
 		in, out := &in.HealthCheck, &out.HealthCheck
 		*out = new(ServerHealthCheck)
@@ -1684,6 +1858,31 @@ func (in *ServersLoadBalancer) DeepCopyInto(out *ServersLoadBalancer) {
 		*out = new(PassiveServerHealthCheck)
 		**out = **in
 	}
+	if in.Hedging != nil {
+		in, out := &in.Hedging, &out.Hedging
+		*out = new(Hedging)
+		**out = **in
+	}
+	if in.RetryBudget != nil {
+		in, out := &in.RetryBudget, &out.RetryBudget
+		*out = new(RetryBudget)
+		**out = **in
+	}
+	if in.SlowStart != nil {
+		in, out := &in.SlowStart, &out.SlowStart
+		*out = new(SlowStart)
+		**out = **in
+	}
+	if in.Locality != nil {
+		in, out := &in.Locality, &out.Locality
+		*out = new(Locality)
+		**out = **in
+	}
+	if in.OutlierDetection != nil {
+		in, out := &in.OutlierDetection, &out.OutlierDetection
+		*out = new(OutlierDetection)
+		**out = **in
+	}
 	if in.PassHostHeader != nil {
 		in, out := &in.PassHostHeader, &out.PassHostHeader
 		*out = new(bool)
@@ -1694,6 +1893,11 @@ func (in *ServersLoadBalancer) DeepCopyInto(out *ServersLoadBalancer) {
 		*out = new(ResponseForwarding)
 		**out = **in
 	}
+	if in.DrainTimeout != nil {
+		in, out := &in.DrainTimeout, &out.DrainTimeout
+		*out = new(paersertypes.Duration)
+		**out = **in
+	}
 	return
 }
 
@@ -1735,6 +1939,16 @@ func (in *ServersTransport) DeepCopyInto(out *ServersTransport) {
 		*out = new(Spiffe)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.HTTP3 != nil {
+		in, out := &in.HTTP3, &out.HTTP3
+		*out = new(HTTP3ClientConfig)
+		**out = **in
+	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(ForwardProxy)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1794,6 +2008,22 @@ func (in *Service) DeepCopy() *Service {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlowStart) DeepCopyInto(out *SlowStart) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlowStart.
+func (in *SlowStart) DeepCopy() *SlowStart {
+	if in == nil {
+		return nil
+	}
+	out := new(SlowStart)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SourceCriterion) DeepCopyInto(out *SourceCriterion) {
 	*out = *in
@@ -2204,6 +2434,11 @@ func (in *TCPServersLoadBalancer) DeepCopyInto(out *TCPServersLoadBalancer) {
 		*out = make([]TCPServer, len(*in))
 		copy(*out, *in)
 	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = new(DNSDiscovery)
+		**out = **in
+	}
 	if in.ProxyProtocol != nil {
 		in, out := &in.ProxyProtocol, &out.ProxyProtocol
 		*out = new(ProxyProtocol)
@@ -2219,6 +2454,11 @@ func (in *TCPServersLoadBalancer) DeepCopyInto(out *TCPServersLoadBalancer) {
 		*out = new(TCPServerHealthCheck)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DrainTimeout != nil {
+		in, out := &in.DrainTimeout, &out.DrainTimeout
+		*out = new(paersertypes.Duration)
+		**out = **in
+	}
 	return
 }
 
@@ -2245,6 +2485,11 @@ func (in *TCPServersTransport) DeepCopyInto(out *TCPServersTransport) {
 		*out = new(TLSClientConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(ForwardProxy)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -2554,6 +2799,11 @@ func (in *UDPServersLoadBalancer) DeepCopyInto(out *UDPServersLoadBalancer) {
 		*out = make([]UDPServer, len(*in))
 		copy(*out, *in)
 	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = new(DNSDiscovery)
+		**out = **in
+	}
 	return
 }
 
@@ -0,0 +1,235 @@
+package history
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+)
+
+// DefaultSize is the default number of applied configurations kept in the history.
+const DefaultSize = 100
+
+// Actions of a Change.
+const (
+	ActionAdded    = "added"
+	ActionRemoved  = "removed"
+	ActionModified = "modified"
+)
+
+// Change is an element of the dynamic configuration of a provider which was added, removed or modified.
+type Change struct {
+	// Kind is the kind of the element, as its path in the dynamic configuration, e.g. "http.routers".
+	Kind string `json:"kind"`
+	// Name is the name of the element, it is empty for the TLS certificates which have no name.
+	Name   string `json:"name,omitempty"`
+	Action string `json:"action"`
+	// Fields are the modified fields of the element.
+	Fields []string `json:"fields,omitempty"`
+}
+
+// ProviderDiff holds the changes of the dynamic configuration of a provider.
+type ProviderDiff struct {
+	Provider string   `json:"provider"`
+	Changes  []Change `json:"changes"`
+}
+
+// Entry is an applied set of configurations, with the changes since the previous one.
+type Entry struct {
+	ID        uint64         `json:"id"`
+	AppliedAt time.Time      `json:"appliedAt"`
+	Providers []ProviderDiff `json:"providers,omitempty"`
+}
+
+// History keeps a bounded history of the applied dynamic configurations, as the changes made by each provider.
+type History struct {
+	size int
+
+	mu      sync.RWMutex
+	lastID  uint64
+	entries []Entry
+}
+
+// New creates a new History keeping at most size entries.
+func New(size int) *History {
+	if size <= 0 {
+		size = DefaultSize
+	}
+
+	return &History{size: size}
+}
+
+// Record adds an entry holding the changes between the previous and the current configurations.
+// When the history is full, the oldest entry is dropped.
+func (h *History) Record(previous, current dynamic.Configurations) {
+	if h == nil {
+		return
+	}
+
+	entry := Entry{
+		AppliedAt: time.Now().UTC(),
+		Providers: Diff(previous, current),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+	entry.ID = h.lastID
+
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > h.size {
+		h.entries = slices.Delete(h.entries, 0, len(h.entries)-h.size)
+	}
+}
+
+// Entries returns the entries of the history, from the most recent to the oldest.
+func (h *History) Entries() []Entry {
+	if h == nil {
+		return nil
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	entries := slices.Clone(h.entries)
+	slices.Reverse(entries)
+
+	return entries
+}
+
+// Diff returns the changes between the previous and the current configurations, by provider.
+func Diff(previous, current dynamic.Configurations) []ProviderDiff {
+	var providers []string
+	for name := range previous {
+		providers = append(providers, name)
+	}
+	for name := range current {
+		if _, ok := previous[name]; !ok {
+			providers = append(providers, name)
+		}
+	}
+	slices.Sort(providers)
+
+	var diffs []ProviderDiff
+	for _, name := range providers {
+		changes := diffConfiguration(previous[name], current[name])
+		if len(changes) > 0 {
+			diffs = append(diffs, ProviderDiff{Provider: name, Changes: changes})
+		}
+	}
+
+	return diffs
+}
+
+func diffConfiguration(previous, current *dynamic.Configuration) []Change {
+	prev, cur := normalize(previous), normalize(current)
+
+	var changes []Change
+	changes = append(changes, diffElements("http.routers", prev.HTTP.Routers, cur.HTTP.Routers)...)
+	changes = append(changes, diffElements("http.services", prev.HTTP.Services, cur.HTTP.Services)...)
+	changes = append(changes, diffElements("http.middlewares", prev.HTTP.Middlewares, cur.HTTP.Middlewares)...)
+	changes = append(changes, diffElements("http.serversTransports", prev.HTTP.ServersTransports, cur.HTTP.ServersTransports)...)
+	changes = append(changes, diffElements("tcp.routers", prev.TCP.Routers, cur.TCP.Routers)...)
+	changes = append(changes, diffElements("tcp.services", prev.TCP.Services, cur.TCP.Services)...)
+	changes = append(changes, diffElements("tcp.middlewares", prev.TCP.Middlewares, cur.TCP.Middlewares)...)
+	changes = append(changes, diffElements("tcp.serversTransports", prev.TCP.ServersTransports, cur.TCP.ServersTransports)...)
+	changes = append(changes, diffElements("udp.routers", prev.UDP.Routers, cur.UDP.Routers)...)
+	changes = append(changes, diffElements("udp.services", prev.UDP.Services, cur.UDP.Services)...)
+	changes = append(changes, diffElements("tls.options", prev.TLS.Options, cur.TLS.Options)...)
+	changes = append(changes, diffElements("tls.stores", prev.TLS.Stores, cur.TLS.Stores)...)
+
+	// The certificates have no name, and their content must not be exposed.
+	switch {
+	case len(prev.TLS.Certificates) == 0 && len(cur.TLS.Certificates) > 0:
+		changes = append(changes, Change{Kind: "tls.certificates", Action: ActionAdded})
+	case len(prev.TLS.Certificates) > 0 && len(cur.TLS.Certificates) == 0:
+		changes = append(changes, Change{Kind: "tls.certificates", Action: ActionRemoved})
+	case !reflect.DeepEqual(prev.TLS.Certificates, cur.TLS.Certificates):
+		changes = append(changes, Change{Kind: "tls.certificates", Action: ActionModified})
+	}
+
+	return changes
+}
+
+// normalize returns a configuration in which all the sections are set.
+func normalize(conf *dynamic.Configuration) dynamic.Configuration {
+	var normalized dynamic.Configuration
+	if conf != nil {
+		normalized = *conf
+	}
+
+	if normalized.HTTP == nil {
+		normalized.HTTP = &dynamic.HTTPConfiguration{}
+	}
+	if normalized.TCP == nil {
+		normalized.TCP = &dynamic.TCPConfiguration{}
+	}
+	if normalized.UDP == nil {
+		normalized.UDP = &dynamic.UDPConfiguration{}
+	}
+	if normalized.TLS == nil {
+		normalized.TLS = &dynamic.TLSConfiguration{}
+	}
+
+	return normalized
+}
+
+func diffElements[V any](kind string, previous, current map[string]V) []Change {
+	var changes []Change
+	for name, cur := range current {
+		prev, ok := previous[name]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Kind: kind, Name: name, Action: ActionAdded})
+		case !reflect.DeepEqual(prev, cur):
+			changes = append(changes, Change{Kind: kind, Name: name, Action: ActionModified, Fields: modifiedFields(prev, cur)})
+		}
+	}
+
+	for name := range previous {
+		if _, ok := current[name]; !ok {
+			changes = append(changes, Change{Kind: kind, Name: name, Action: ActionRemoved})
+		}
+	}
+
+	slices.SortFunc(changes, func(a, b Change) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return changes
+}
+
+// modifiedFields returns the JSON names of the top-level fields which differ between the two given structs.
+func modifiedFields(previous, current any) []string {
+	prev, cur := reflect.Indirect(reflect.ValueOf(previous)), reflect.Indirect(reflect.ValueOf(current))
+	if !prev.IsValid() || !cur.IsValid() || prev.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []string
+	for i := range prev.NumField() {
+		field := prev.Type().Field(i)
+		if !field.IsExported() || reflect.DeepEqual(prev.Field(i).Interface(), cur.Field(i).Interface()) {
+			continue
+		}
+
+		// The fields of the embedded structs are inlined.
+		if field.Anonymous {
+			fields = append(fields, modifiedFields(prev.Field(i).Interface(), cur.Field(i).Interface())...)
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		fields = append(fields, cmp.Or(name, field.Name))
+	}
+
+	return fields
+}
@@ -0,0 +1,133 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/tls"
+)
+
+func TestDiff(t *testing.T) {
+	previous := dynamic.Configurations{
+		"file": {
+			HTTP: &dynamic.HTTPConfiguration{
+				Routers: map[string]*dynamic.Router{
+					"api":  {Rule: "Host(`api.example.com`)", Service: "api"},
+					"site": {Rule: "Host(`example.com`)", Service: "site"},
+				},
+				Services: map[string]*dynamic.Service{
+					"api": {LoadBalancer: &dynamic.ServersLoadBalancer{Servers: []dynamic.Server{{URL: "http://10.0.0.1"}}}},
+				},
+			},
+			TLS: &dynamic.TLSConfiguration{
+				Certificates: []*tls.CertAndStores{{Certificate: tls.Certificate{CertFile: "cert.pem", KeyFile: "key.pem"}}},
+			},
+		},
+		"docker": {
+			HTTP: &dynamic.HTTPConfiguration{
+				Routers: map[string]*dynamic.Router{
+					"whoami": {Rule: "Host(`whoami.example.com`)", Service: "whoami"},
+				},
+			},
+		},
+		"unchanged": {
+			TCP: &dynamic.TCPConfiguration{
+				Routers: map[string]*dynamic.TCPRouter{
+					"db": {Rule: "HostSNI(`*`)", Service: "db"},
+				},
+			},
+		},
+	}
+
+	current := dynamic.Configurations{
+		"file": {
+			HTTP: &dynamic.HTTPConfiguration{
+				Routers: map[string]*dynamic.Router{
+					"api": {Rule: "Host(`api.example.com`) && PathPrefix(`/v2`)", Service: "api", Priority: 10},
+				},
+				Services: map[string]*dynamic.Service{
+					"api": {LoadBalancer: &dynamic.ServersLoadBalancer{Servers: []dynamic.Server{{URL: "http://10.0.0.2"}}}},
+				},
+				Middlewares: map[string]*dynamic.Middleware{
+					"auth": {BasicAuth: &dynamic.BasicAuth{}},
+				},
+			},
+		},
+		"unchanged": {
+			TCP: &dynamic.TCPConfiguration{
+				Routers: map[string]*dynamic.TCPRouter{
+					"db": {Rule: "HostSNI(`*`)", Service: "db"},
+				},
+			},
+		},
+		"kubernetescrd": {
+			TLS: &dynamic.TLSConfiguration{
+				Options: map[string]tls.Options{"modern": {MinVersion: "VersionTLS13"}},
+			},
+		},
+	}
+
+	expected := []ProviderDiff{
+		{
+			Provider: "docker",
+			Changes: []Change{
+				{Kind: "http.routers", Name: "whoami", Action: ActionRemoved},
+			},
+		},
+		{
+			Provider: "file",
+			Changes: []Change{
+				{Kind: "http.routers", Name: "api", Action: ActionModified, Fields: []string{"rule", "priority"}},
+				{Kind: "http.routers", Name: "site", Action: ActionRemoved},
+				{Kind: "http.services", Name: "api", Action: ActionModified, Fields: []string{"loadBalancer"}},
+				{Kind: "http.middlewares", Name: "auth", Action: ActionAdded},
+				{Kind: "tls.certificates", Action: ActionRemoved},
+			},
+		},
+		{
+			Provider: "kubernetescrd",
+			Changes: []Change{
+				{Kind: "tls.options", Name: "modern", Action: ActionAdded},
+			},
+		},
+	}
+
+	assert.Equal(t, expected, Diff(previous, current))
+}
+
+func TestHistory(t *testing.T) {
+	h := New(2)
+
+	configurations := []dynamic.Configurations{
+		{"file": {HTTP: &dynamic.HTTPConfiguration{Routers: map[string]*dynamic.Router{"a": {}}}}},
+		{"file": {HTTP: &dynamic.HTTPConfiguration{Routers: map[string]*dynamic.Router{"b": {}}}}},
+		{"file": {HTTP: &dynamic.HTTPConfiguration{Routers: map[string]*dynamic.Router{"c": {}}}}},
+	}
+
+	var previous dynamic.Configurations
+	for _, current := range configurations {
+		h.Record(previous, current)
+		previous = current
+	}
+
+	entries := h.Entries()
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, uint64(3), entries[0].ID)
+	assert.Equal(t, []ProviderDiff{{
+		Provider: "file",
+		Changes: []Change{
+			{Kind: "http.routers", Name: "b", Action: ActionRemoved},
+			{Kind: "http.routers", Name: "c", Action: ActionAdded},
+		},
+	}}, entries[0].Providers)
+
+	assert.Equal(t, uint64(2), entries[1].ID)
+	assert.False(t, entries[1].AppliedAt.After(entries[0].AppliedAt))
+
+	var nilHistory *History
+	nilHistory.Record(nil, configurations[0])
+	assert.Empty(t, nilHistory.Entries())
+}
@@ -2,6 +2,7 @@ package runtime
 
 import (
 	"sort"
+	"sync"
 	"strings"
 
 	"github.com/rs/zerolog/log"
@@ -20,6 +21,9 @@ const (
 const (
 	StatusUp   = "UP"
 	StatusDown = "DOWN"
+	// StatusDraining is the status of the servers removed from their load-balancer,
+	// until their in-flight requests are done.
+	StatusDraining = "DRAINING"
 )
 
 // Configuration holds the information about the currently running ingress instance.
@@ -33,15 +37,19 @@ type Configuration struct {
 	TCPServices    map[string]*TCPServiceInfo    `json:"tcpServices,omitempty"`
 	UDPRouters     map[string]*UDPRouterInfo     `json:"udpRouters,omitempty"`
 	UDPServices    map[string]*UDPServiceInfo    `json:"udpServices,omitempty"`
+
+	// servicesMu protects the configurations of the services, which are replaced when their servers are updated in place.
+	// It is nil for the configurations which are not created by NewConfig, as they are not updated.
+	servicesMu *sync.RWMutex
 }
 
 // NewConfig returns a Configuration initialized with the given conf. It never returns nil.
 func NewConfig(conf dynamic.Configuration) *Configuration {
 	if conf.HTTP == nil && conf.TCP == nil && conf.UDP == nil {
-		return &Configuration{}
+		return &Configuration{servicesMu: &sync.RWMutex{}}
 	}
 
-	runtimeConfig := &Configuration{}
+	runtimeConfig := &Configuration{servicesMu: &sync.RWMutex{}}
 
 	if conf.HTTP != nil {
 		routers := conf.HTTP.Routers
@@ -113,6 +121,34 @@ func NewConfig(conf dynamic.Configuration) *Configuration {
 	return runtimeConfig
 }
 
+// RLockServices locks the configurations of the services for reading,
+// as they are replaced when their servers are updated in place.
+func (c *Configuration) RLockServices() {
+	if c.servicesMu != nil {
+		c.servicesMu.RLock()
+	}
+}
+
+// RUnlockServices undoes a single RLockServices call.
+func (c *Configuration) RUnlockServices() {
+	if c.servicesMu != nil {
+		c.servicesMu.RUnlock()
+	}
+}
+
+// publishService replaces a configuration of a service with the given function, while the services are locked.
+func (c *Configuration) publishService(replace func()) {
+	if c.servicesMu == nil {
+		replace()
+		return
+	}
+
+	c.servicesMu.Lock()
+	defer c.servicesMu.Unlock()
+
+	replace()
+}
+
 // PopulateUsedBy populates all the UsedBy lists of the underlying fields of r,
 // based on the relations between the included services, routers, and middlewares.
 func (c *Configuration) PopulateUsedBy() {
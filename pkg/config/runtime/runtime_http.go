@@ -138,6 +138,20 @@ func (m *MiddlewareInfo) AddError(err error, critical bool) {
 	}
 }
 
+// UpdateServers replaces the servers of the given load-balancer service,
+// by publishing a copy of its configuration while the services are locked.
+func (c *Configuration) UpdateServers(serviceName string, servers []dynamic.Server) {
+	info, ok := c.Services[serviceName]
+	if !ok || info.LoadBalancer == nil {
+		return
+	}
+
+	svc := info.Service.DeepCopy()
+	svc.LoadBalancer.Servers = servers
+
+	c.publishService(func() { info.Service = svc })
+}
+
 // ServiceInfo holds information about a currently running service.
 type ServiceInfo struct {
 	*dynamic.Service // dynamic configuration
@@ -185,6 +199,29 @@ func (s *ServiceInfo) UpdateServerStatus(server, status string) {
 	s.serverStatus[server] = status
 }
 
+// RemoveServerStatus removes the status of the server from the ServiceInfo.
+// It is the responsibility of the caller to check that s is not nil.
+func (s *ServiceInfo) RemoveServerStatus(server string) {
+	s.serverStatusMu.Lock()
+	defer s.serverStatusMu.Unlock()
+
+	delete(s.serverStatus, server)
+}
+
+// RemoveServerStatusIf removes the status of the server from the ServiceInfo, if it is the given one.
+// It reports whether the status has been removed.
+func (s *ServiceInfo) RemoveServerStatusIf(server, status string) bool {
+	s.serverStatusMu.Lock()
+	defer s.serverStatusMu.Unlock()
+
+	if s.serverStatus[server] != status {
+		return false
+	}
+
+	delete(s.serverStatus, server)
+	return true
+}
+
 // GetAllStatus returns all the statuses of all the servers in ServiceInfo.
 // It is the responsibility of the caller to check that s is not nil.
 func (s *ServiceInfo) GetAllStatus() map[string]string {
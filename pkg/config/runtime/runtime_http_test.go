@@ -215,3 +215,14 @@ func TestGetRoutersByEntryPoints(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceInfo_RemoveServerStatusIf(t *testing.T) {
+	info := &ServiceInfo{}
+	info.UpdateServerStatus("http://127.0.0.1", StatusDraining)
+
+	assert.False(t, info.RemoveServerStatusIf("http://127.0.0.1", StatusUp))
+	assert.Equal(t, map[string]string{"http://127.0.0.1": StatusDraining}, info.GetAllStatus())
+
+	assert.True(t, info.RemoveServerStatusIf("http://127.0.0.1", StatusDraining))
+	assert.Empty(t, info.GetAllStatus())
+}
@@ -79,6 +79,20 @@ func (r *TCPRouterInfo) AddError(err error, critical bool) {
 	}
 }
 
+// UpdateTCPServers replaces the servers of the given TCP load-balancer service,
+// by publishing a copy of its configuration while the services are locked.
+func (c *Configuration) UpdateTCPServers(serviceName string, servers []dynamic.TCPServer) {
+	info, ok := c.TCPServices[serviceName]
+	if !ok || info.LoadBalancer == nil {
+		return
+	}
+
+	svc := info.TCPService.DeepCopy()
+	svc.LoadBalancer.Servers = servers
+
+	c.publishService(func() { info.TCPService = svc })
+}
+
 // TCPServiceInfo holds information about a currently running TCP service.
 type TCPServiceInfo struct {
 	*dynamic.TCPService // dynamic configuration
@@ -124,6 +138,28 @@ func (s *TCPServiceInfo) UpdateServerStatus(server, status string) {
 	s.serverStatus[server] = status
 }
 
+// RemoveServerStatus removes the status of the server from the TCPServiceInfo.
+func (s *TCPServiceInfo) RemoveServerStatus(server string) {
+	s.serverStatusMu.Lock()
+	defer s.serverStatusMu.Unlock()
+
+	delete(s.serverStatus, server)
+}
+
+// RemoveServerStatusIf removes the status of the server from the TCPServiceInfo, if it is the given one.
+// It reports whether the status has been removed.
+func (s *TCPServiceInfo) RemoveServerStatusIf(server, status string) bool {
+	s.serverStatusMu.Lock()
+	defer s.serverStatusMu.Unlock()
+
+	if s.serverStatus[server] != status {
+		return false
+	}
+
+	delete(s.serverStatus, server)
+	return true
+}
+
 // GetAllStatus returns all the statuses of all the servers in TCPServiceInfo.
 func (s *TCPServiceInfo) GetAllStatus() map[string]string {
 	s.serverStatusMu.RLock()
@@ -83,6 +83,20 @@ func (r *UDPRouterInfo) AddError(err error, critical bool) {
 	}
 }
 
+// UpdateUDPServers replaces the servers of the given UDP load-balancer service,
+// by publishing a copy of its configuration while the services are locked.
+func (c *Configuration) UpdateUDPServers(serviceName string, servers []dynamic.UDPServer) {
+	info, ok := c.UDPServices[serviceName]
+	if !ok || info.LoadBalancer == nil {
+		return
+	}
+
+	svc := info.UDPService.DeepCopy()
+	svc.LoadBalancer.Servers = servers
+
+	c.publishService(func() { info.UDPService = svc })
+}
+
 // UDPServiceInfo holds information about a currently running UDP service.
 type UDPServiceInfo struct {
 	*dynamic.UDPService // dynamic configuration
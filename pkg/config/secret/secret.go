@@ -0,0 +1,393 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/copystructure"
+	"github.com/rs/zerolog/log"
+	ptypes "github.com/hanzoai/ingress-parser/types"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/config/runtime"
+	"github.com/hanzoai/ingress/pkg/observability/logs"
+	"github.com/hanzoai/ingress/pkg/server/provider"
+)
+
+// Schemes of the secret references.
+const (
+	SchemeEnv    = "env://"
+	SchemeFile   = "file://"
+	SchemeSecret = "secret://"
+)
+
+// Backend is a store from which the secrets are read.
+type Backend interface {
+	// Get returns the value of the given key of the secret at the given path.
+	Get(ctx context.Context, path, key string) (string, error)
+}
+
+// Configuration configures the resolution of the secret references of the dynamic configuration.
+type Configuration struct {
+	Providers       []string                         `description:"Providers whose dynamic configuration can reference secrets." json:"providers,omitempty" toml:"providers,omitempty" yaml:"providers,omitempty" export:"true"`
+	RefreshInterval ptypes.Duration                  `description:"Interval at which the resolved secrets are refreshed. The dynamic configuration is reloaded when one of them changed." json:"refreshInterval,omitempty" toml:"refreshInterval,omitempty" yaml:"refreshInterval,omitempty" export:"true"`
+	Backends        map[string]*BackendConfiguration `description:"Backends referenced as secret://<backend>/<path>#<key>." json:"backends,omitempty" toml:"backends,omitempty" yaml:"backends,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (c *Configuration) SetDefaults() {
+	// The configuration of the other providers may come from untrusted sources, such as labels or annotations.
+	c.Providers = []string{"file"}
+	c.RefreshInterval = ptypes.Duration(time.Minute)
+}
+
+// BackendConfiguration configures a secret backend.
+type BackendConfiguration struct {
+	Vault *Vault `description:"HashiCorp Vault compatible backend, reading the KV secrets engine." json:"vault,omitempty" toml:"vault,omitempty" yaml:"vault,omitempty" export:"true"`
+}
+
+// Resolver resolves the secret references of the dynamic configuration,
+// and keeps them in the elements exposed by the runtime configuration.
+// Only the references of the secret fields, tagged loggable:"false", of the allowed providers are resolved.
+type Resolver struct {
+	providers       map[string]struct{}
+	backends        map[string]Backend
+	refreshInterval time.Duration
+
+	// resolveMu serializes the resolutions and the refreshes, for a refreshed secret not to be overwritten by a resolution.
+	resolveMu sync.Mutex
+
+	mu    sync.RWMutex
+	cache map[string]string
+	// originals are the elements holding secret references, by kind and qualified name, as defined by the providers.
+	originals map[string]map[string]any
+}
+
+// NewResolver creates a new Resolver.
+func NewResolver(config Configuration) (*Resolver, error) {
+	r := &Resolver{
+		providers:       make(map[string]struct{}, len(config.Providers)),
+		backends:        make(map[string]Backend),
+		refreshInterval: time.Duration(config.RefreshInterval),
+		cache:           make(map[string]string),
+	}
+
+	for _, providerName := range config.Providers {
+		r.providers[providerName] = struct{}{}
+	}
+
+	for name, backend := range config.Backends {
+		if backend == nil || backend.Vault == nil {
+			return nil, fmt.Errorf("backend %s: no backend type defined", name)
+		}
+
+		if err := backend.Vault.Init(); err != nil {
+			return nil, fmt.Errorf("backend %s: %w", name, err)
+		}
+
+		r.backends[name] = backend.Vault
+	}
+
+	return r, nil
+}
+
+// Resolve replaces the secret references of the given configurations by their values.
+// A reference which cannot be resolved is kept as is, and the error is logged.
+func (r *Resolver) Resolve(ctx context.Context, configurations dynamic.Configurations) dynamic.Configurations {
+	if r == nil {
+		return configurations
+	}
+
+	r.resolveMu.Lock()
+	defer r.resolveMu.Unlock()
+
+	previous := r.cache
+
+	// Only the secrets still referenced are kept in the cache.
+	cache := make(map[string]string)
+	originals := make(map[string]map[string]any)
+
+	for providerName, conf := range configurations {
+		if conf == nil {
+			continue
+		}
+
+		if _, ok := r.providers[providerName]; !ok {
+			continue
+		}
+
+		logger := log.Ctx(ctx).With().Str(logs.ProviderName, providerName).Logger()
+
+		if conf.HTTP != nil {
+			collectOriginals(originals, "http.routers", providerName, conf.HTTP.Routers)
+			collectOriginals(originals, "http.middlewares", providerName, conf.HTTP.Middlewares)
+			collectOriginals(originals, "http.services", providerName, conf.HTTP.Services)
+		}
+		if conf.TCP != nil {
+			collectOriginals(originals, "tcp.routers", providerName, conf.TCP.Routers)
+			collectOriginals(originals, "tcp.middlewares", providerName, conf.TCP.Middlewares)
+			collectOriginals(originals, "tcp.services", providerName, conf.TCP.Services)
+		}
+		if conf.UDP != nil {
+			collectOriginals(originals, "udp.routers", providerName, conf.UDP.Routers)
+			collectOriginals(originals, "udp.services", providerName, conf.UDP.Services)
+		}
+
+		walk(reflect.ValueOf(conf), false, func(value string) string {
+			if !IsReference(value) {
+				return value
+			}
+
+			if secret, ok := cache[value]; ok {
+				return secret
+			}
+
+			if secret, ok := previous[value]; ok {
+				cache[value] = secret
+				return secret
+			}
+
+			secret, err := r.lookup(logger.WithContext(ctx), value)
+			if err != nil {
+				logger.Error().Err(err).Msgf("Unable to resolve secret %s", value)
+				return value
+			}
+
+			cache[value] = secret
+			return secret
+		})
+	}
+
+	r.mu.Lock()
+	r.cache = cache
+	r.originals = originals
+	r.mu.Unlock()
+
+	return configurations
+}
+
+// Conceal replaces, in the given runtime configuration, the elements holding resolved secrets
+// by their definition holding the secret references, so that the secrets are never exposed.
+// It must be called once the handlers are built from the runtime configuration.
+func (r *Resolver) Conceal(conf *runtime.Configuration) {
+	if r == nil || conf == nil {
+		return
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	conceal(r.originals["http.routers"], conf.Routers, func(info *runtime.RouterInfo, original *dynamic.Router) { info.Router = original })
+	conceal(r.originals["http.middlewares"], conf.Middlewares, func(info *runtime.MiddlewareInfo, original *dynamic.Middleware) { info.Middleware = original })
+	conceal(r.originals["http.services"], conf.Services, func(info *runtime.ServiceInfo, original *dynamic.Service) { info.Service = original })
+	conceal(r.originals["tcp.routers"], conf.TCPRouters, func(info *runtime.TCPRouterInfo, original *dynamic.TCPRouter) { info.TCPRouter = original })
+	conceal(r.originals["tcp.middlewares"], conf.TCPMiddlewares, func(info *runtime.TCPMiddlewareInfo, original *dynamic.TCPMiddleware) { info.TCPMiddleware = original })
+	conceal(r.originals["tcp.services"], conf.TCPServices, func(info *runtime.TCPServiceInfo, original *dynamic.TCPService) { info.TCPService = original })
+	conceal(r.originals["udp.routers"], conf.UDPRouters, func(info *runtime.UDPRouterInfo, original *dynamic.UDPRouter) { info.UDPRouter = original })
+	conceal(r.originals["udp.services"], conf.UDPServices, func(info *runtime.UDPServiceInfo, original *dynamic.UDPService) { info.UDPService = original })
+}
+
+// Run refreshes the resolved secrets at the refresh interval, and calls onChange when one of them changed,
+// so that the dynamic configuration is resolved and applied again.
+func (r *Resolver) Run(ctx context.Context, onChange func()) {
+	if r == nil || r.refreshInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if r.refresh(ctx) {
+				log.Ctx(ctx).Info().Msg("Secrets changed, reloading the configuration")
+				onChange()
+			}
+		}
+	}
+}
+
+// refresh looks up the resolved secrets again, and reports whether one of them changed.
+func (r *Resolver) refresh(ctx context.Context) bool {
+	r.resolveMu.Lock()
+	defer r.resolveMu.Unlock()
+
+	var changed bool
+	cache := make(map[string]string, len(r.cache))
+	for reference, previous := range r.cache {
+		cache[reference] = previous
+
+		secret, err := r.lookup(ctx, reference)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("Unable to refresh secret %s", reference)
+			continue
+		}
+
+		if secret != previous {
+			cache[reference] = secret
+			changed = true
+		}
+	}
+
+	if !changed {
+		return false
+	}
+
+	r.mu.Lock()
+	r.cache = cache
+	r.mu.Unlock()
+
+	return true
+}
+
+// lookup reads the value of the given secret reference.
+func (r *Resolver) lookup(ctx context.Context, reference string) (string, error) {
+	switch {
+	case strings.HasPrefix(reference, SchemeEnv):
+		name := strings.TrimPrefix(reference, SchemeEnv)
+
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return value, nil
+
+	case strings.HasPrefix(reference, SchemeFile):
+		content, err := os.ReadFile(strings.TrimPrefix(reference, SchemeFile))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+
+	case strings.HasPrefix(reference, SchemeSecret):
+		backendName, path, ok := strings.Cut(strings.TrimPrefix(reference, SchemeSecret), "/")
+		if !ok {
+			return "", errors.New("missing secret path")
+		}
+
+		path, key, ok := strings.Cut(path, "#")
+		if !ok || key == "" {
+			return "", errors.New("missing secret key")
+		}
+
+		backend, ok := r.backends[backendName]
+		if !ok {
+			return "", fmt.Errorf("unknown backend %s", backendName)
+		}
+
+		return backend.Get(ctx, path, key)
+
+	default:
+		return "", errors.New("unknown secret scheme")
+	}
+}
+
+// IsReference reports whether the given value is a secret reference.
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, SchemeEnv) || strings.HasPrefix(value, SchemeFile) || strings.HasPrefix(value, SchemeSecret)
+}
+
+// collectOriginals keeps a copy of the given elements holding secret references, by their qualified name.
+func collectOriginals[V any](originals map[string]map[string]any, kind, providerName string, elements map[string]V) {
+	for name, element := range elements {
+		var hasReference bool
+		walk(reflect.ValueOf(element), false, func(value string) string {
+			hasReference = hasReference || IsReference(value)
+			return value
+		})
+
+		if !hasReference {
+			continue
+		}
+
+		original, err := copystructure.Copy(element)
+		if err != nil {
+			continue
+		}
+
+		if originals[kind] == nil {
+			originals[kind] = make(map[string]any)
+		}
+		originals[kind][provider.MakeQualifiedName(providerName, name)] = original
+	}
+}
+
+func conceal[I, E any](originals map[string]any, infos map[string]*I, set func(*I, E)) {
+	for name, original := range originals {
+		info, ok := infos[name]
+		if !ok {
+			continue
+		}
+
+		if element, ok := original.(E); ok {
+			set(info, element)
+		}
+	}
+}
+
+// walk calls fn on every string of the given value held by a secret field, tagged loggable:"false",
+// and replaces the string by the returned one.
+// The secret flag reports whether the given value is held by a secret field.
+func walk(v reflect.Value, secret bool, fn func(string) string) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			walk(v.Elem(), secret, fn)
+		}
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+
+		// The value held by an interface is not addressable, it is replaced by an updated copy.
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		walk(elem, secret, fn)
+
+		if v.CanSet() {
+			v.Set(elem)
+		}
+
+	case reflect.Struct:
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if field.IsExported() {
+				walk(v.Field(i), secret || field.Tag.Get("loggable") == "false", fn)
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			walk(v.Index(i), secret, fn)
+		}
+
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			// The values of a map are not addressable, they are replaced by an updated copy.
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			walk(elem, secret, fn)
+			v.SetMapIndex(key, elem)
+		}
+
+	case reflect.String:
+		if !secret {
+			return
+		}
+
+		if value := fn(v.String()); value != v.String() && v.CanSet() {
+			v.SetString(value)
+		}
+
+	default:
+	}
+}
@@ -0,0 +1,142 @@
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	ptypes "github.com/hanzoai/ingress-parser/types"
+	"github.com/hanzoai/ingress/pkg/types"
+)
+
+var _ Backend = (*Vault)(nil)
+
+// Vault is a Backend reading the secrets of a HashiCorp Vault compatible KV secrets engine, through its HTTP API.
+// The first segment of the secret path is the mount path of the secrets engine.
+type Vault struct {
+	Address   string           `description:"Address of the Vault server. Defaults to the VAULT_ADDR environment variable." json:"address,omitempty" toml:"address,omitempty" yaml:"address,omitempty"`
+	Token     string           `description:"Token used to authenticate. Defaults to the VAULT_TOKEN environment variable." json:"token,omitempty" toml:"token,omitempty" yaml:"token,omitempty" loggable:"false"`
+	Namespace string           `description:"Namespace of the secrets." json:"namespace,omitempty" toml:"namespace,omitempty" yaml:"namespace,omitempty"`
+	KVVersion int              `description:"Version of the KV secrets engine (1 or 2)." json:"kvVersion,omitempty" toml:"kvVersion,omitempty" yaml:"kvVersion,omitempty" export:"true"`
+	Timeout   ptypes.Duration  `description:"Timeout of the requests to the Vault server." json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty" export:"true"`
+	TLS       *types.ClientTLS `description:"Enable TLS support." json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" export:"true"`
+
+	client *http.Client
+}
+
+// SetDefaults sets the default values.
+func (v *Vault) SetDefaults() {
+	v.KVVersion = 2
+	v.Timeout = ptypes.Duration(5 * time.Second)
+}
+
+// Init the backend.
+func (v *Vault) Init() error {
+	if v.Address == "" {
+		v.Address = os.Getenv("VAULT_ADDR")
+	}
+	if v.Address == "" {
+		return errors.New("non-empty address is required")
+	}
+
+	if v.Token == "" {
+		v.Token = os.Getenv("VAULT_TOKEN")
+	}
+
+	if v.KVVersion != 1 && v.KVVersion != 2 {
+		return fmt.Errorf("unsupported KV version %d", v.KVVersion)
+	}
+
+	v.client = &http.Client{Timeout: time.Duration(v.Timeout)}
+
+	if v.TLS != nil {
+		tlsConfig, err := v.TLS.CreateTLSConfig(context.Background())
+		if err != nil {
+			return fmt.Errorf("unable to create client TLS configuration: %w", err)
+		}
+
+		v.client.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+	}
+
+	return nil
+}
+
+// Get returns the value of the given key of the secret at the given path.
+func (v *Vault) Get(ctx context.Context, path, key string) (string, error) {
+	apiPath := path
+	if v.KVVersion == 2 {
+		mount, secretPath, ok := strings.Cut(path, "/")
+		if !ok {
+			return "", fmt.Errorf("missing mount path in %s", path)
+		}
+		apiPath = mount + "/data/" + secretPath
+	}
+
+	endpoint, err := url.JoinPath(v.Address, "v1", apiPath)
+	if err != nil {
+		return "", fmt.Errorf("creating request URL: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+
+	if v.Token != "" {
+		req.Header.Set("X-Vault-Token", v.Token)
+	}
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("reading secret %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("reading secret %s: unexpected status code %d", path, resp.StatusCode)
+	}
+
+	var secret struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return "", fmt.Errorf("decoding secret %s: %w", path, err)
+	}
+
+	data := secret.Data
+	if v.KVVersion == 2 {
+		var versioned struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(secret.Data, &versioned); err != nil {
+			return "", fmt.Errorf("decoding secret %s: %w", path, err)
+		}
+		data = versioned.Data
+	}
+
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return "", fmt.Errorf("decoding secret %s: %w", path, err)
+	}
+
+	value, ok := values[key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in secret %s", key, path)
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("key %s of secret %s is not a string", key, path)
+	}
+
+	return s, nil
+}
@@ -0,0 +1,297 @@
+package snapshot
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+)
+
+// Snapshot persists the last known good dynamic configurations of the providers on disk.
+// At startup, the persisted configurations are used as provisional configurations,
+// until the corresponding providers deliver their own.
+type Snapshot struct {
+	filePath           string
+	aead               cipher.AEAD
+	provisionalTimeout time.Duration
+
+	mu          sync.RWMutex
+	savedAt     time.Time
+	provisional map[string]struct{}
+
+	// saved holds the last saved configurations, so that they are not written again when they have not changed.
+	saved dynamic.Configurations
+}
+
+// Status holds the state of the snapshot.
+type Status struct {
+	FilePath  string    `json:"filePath"`
+	Encrypted bool      `json:"encrypted"`
+	SavedAt   time.Time `json:"savedAt,omitzero"`
+	// ProvisionalProviders are the providers whose configuration comes from the snapshot,
+	// as they have not delivered any configuration yet.
+	ProvisionalProviders []string `json:"provisionalProviders,omitempty"`
+}
+
+// file is the content of the snapshot file.
+type file struct {
+	SavedAt        time.Time              `json:"savedAt"`
+	Configurations dynamic.Configurations `json:"configurations"`
+}
+
+// New creates a new Snapshot stored in the given file.
+// When encryptionKey is not empty, it is a base64 encoded AES key (16, 24 or 32 bytes),
+// and the snapshot is encrypted at rest with AES-GCM.
+func New(filePath, encryptionKey string) (*Snapshot, error) {
+	if filePath == "" {
+		return nil, errors.New("snapshot file path is required")
+	}
+
+	s := &Snapshot{filePath: filePath}
+
+	if encryptionKey != "" {
+		key, err := base64.StdEncoding.DecodeString(encryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("decoding snapshot encryption key: %w", err)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("creating snapshot cipher: %w", err)
+		}
+
+		s.aead, err = cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("creating snapshot cipher: %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+// SetProvisionalTimeout sets the duration after which the provisional configurations are expired,
+// so that the configurations of the providers which are no longer configured, or never deliver theirs, are dropped.
+// Zero means that the provisional configurations never expire.
+func (s *Snapshot) SetProvisionalTimeout(timeout time.Duration) {
+	s.provisionalTimeout = timeout
+}
+
+// ProvisionalTimeout returns the duration after which the provisional configurations are expired, zero meaning never.
+func (s *Snapshot) ProvisionalTimeout() time.Duration {
+	if s == nil {
+		return 0
+	}
+
+	return s.provisionalTimeout
+}
+
+// Load reads the snapshot file and returns the persisted configurations, except the ones of the excluded providers.
+// The returned configurations are provisional until Delivered is called for their provider.
+// No configuration is returned when the snapshot file does not exist.
+func (s *Snapshot) Load(excludedProviders ...string) (dynamic.Configurations, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(s.filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot file: %w", err)
+	}
+
+	if s.aead != nil {
+		data, err = s.decrypt(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var content file
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("decoding snapshot file: %w", err)
+	}
+
+	for _, name := range excludedProviders {
+		delete(content.Configurations, name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.savedAt = content.SavedAt
+	s.provisional = make(map[string]struct{}, len(content.Configurations))
+	for name := range content.Configurations {
+		s.provisional[name] = struct{}{}
+	}
+
+	return content.Configurations, nil
+}
+
+// Save persists the given configurations, unless they have not changed since they were last saved.
+// The file is replaced atomically, so that a crash never leaves a partial snapshot behind.
+func (s *Snapshot) Save(configurations dynamic.Configurations) error {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.RLock()
+	unchanged := s.saved != nil && reflect.DeepEqual(s.saved, configurations)
+	s.mu.RUnlock()
+
+	if unchanged {
+		return nil
+	}
+
+	savedAt := time.Now().UTC()
+
+	data, err := json.Marshal(file{
+		SavedAt:        savedAt,
+		Configurations: configurations,
+	})
+	if err != nil {
+		return fmt.Errorf("encoding snapshot: %w", err)
+	}
+
+	if s.aead != nil {
+		data, err = s.encrypt(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(s.filePath), filepath.Base(s.filePath)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("creating snapshot file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmpFile.Name()) }()
+
+	if _, err := tmpFile.Write(data); err != nil {
+		_ = tmpFile.Close()
+		return fmt.Errorf("writing snapshot file: %w", err)
+	}
+
+	// The content is flushed to the disk before the rename,
+	// so that a crash cannot leave the snapshot file renamed but empty.
+	if err := tmpFile.Sync(); err != nil {
+		_ = tmpFile.Close()
+		return fmt.Errorf("writing snapshot file: %w", err)
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("writing snapshot file: %w", err)
+	}
+
+	if err := os.Rename(tmpFile.Name(), s.filePath); err != nil {
+		return fmt.Errorf("writing snapshot file: %w", err)
+	}
+
+	syncDir(filepath.Dir(s.filePath))
+
+	s.mu.Lock()
+	s.savedAt = savedAt
+	s.saved = configurations.DeepCopy()
+	s.mu.Unlock()
+
+	return nil
+}
+
+// syncDir flushes the given directory to the disk, so that a rename in it survives a crash.
+// This is best effort, as not every platform supports the synchronization of a directory.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+
+	_ = d.Sync()
+	_ = d.Close()
+}
+
+// Delivered marks the configuration of the given provider as delivered by the provider itself,
+// and returns whether its configuration was provisional.
+func (s *Snapshot) Delivered(providerName string) bool {
+	if s == nil {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.provisional[providerName]
+	delete(s.provisional, providerName)
+
+	return ok
+}
+
+// Expire marks the provisional configurations as expired, and returns the providers whose configuration was still provisional.
+func (s *Snapshot) Expire() []string {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expired := slices.Sorted(maps.Keys(s.provisional))
+	clear(s.provisional)
+
+	return expired
+}
+
+// Status returns the state of the snapshot.
+func (s *Snapshot) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	status := Status{
+		FilePath:  s.filePath,
+		Encrypted: s.aead != nil,
+		SavedAt:   s.savedAt,
+	}
+
+	for name := range s.provisional {
+		status.ProvisionalProviders = append(status.ProvisionalProviders, name)
+	}
+	slices.Sort(status.ProvisionalProviders)
+
+	return status
+}
+
+func (s *Snapshot) encrypt(data []byte) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generating snapshot nonce: %w", err)
+	}
+
+	return s.aead.Seal(nonce, nonce, data, nil), nil
+}
+
+func (s *Snapshot) decrypt(data []byte) ([]byte, error) {
+	if len(data) < s.aead.NonceSize() {
+		return nil, errors.New("decrypting snapshot file: invalid content")
+	}
+
+	nonce, ciphertext := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+
+	plaintext, err := s.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting snapshot file: %w", err)
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,154 @@
+package snapshot
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+)
+
+func TestSnapshot(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc          string
+		encryptionKey string
+	}{
+		{
+			desc: "plain",
+		},
+		{
+			desc:          "encrypted",
+			encryptionKey: base64.StdEncoding.EncodeToString(key),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			filePath := filepath.Join(t.TempDir(), "snapshot.json")
+
+			s, err := New(filePath, test.encryptionKey)
+			require.NoError(t, err)
+
+			configurations, err := s.Load()
+			require.NoError(t, err)
+			assert.Empty(t, configurations)
+
+			err = s.Save(dynamic.Configurations{
+				"internal": &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{}},
+				"file": &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{
+					Routers: map[string]*dynamic.Router{"foo": {Rule: "Host(`foo.bar`)", Service: "foo"}},
+				}},
+			})
+			require.NoError(t, err)
+
+			content, err := os.ReadFile(filePath)
+			require.NoError(t, err)
+			assert.Equal(t, test.encryptionKey == "", json.Valid(content))
+
+			s, err = New(filePath, test.encryptionKey)
+			require.NoError(t, err)
+
+			configurations, err = s.Load("internal")
+			require.NoError(t, err)
+
+			assert.Equal(t, dynamic.Configurations{
+				"file": &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{
+					Routers: map[string]*dynamic.Router{"foo": {Rule: "Host(`foo.bar`)", Service: "foo"}},
+				}},
+			}, configurations)
+
+			status := s.Status()
+			assert.Equal(t, test.encryptionKey != "", status.Encrypted)
+			assert.False(t, status.SavedAt.IsZero())
+			assert.Equal(t, []string{"file"}, status.ProvisionalProviders)
+
+			assert.True(t, s.Delivered("file"))
+			assert.False(t, s.Delivered("file"))
+			assert.Empty(t, s.Status().ProvisionalProviders)
+		})
+	}
+}
+
+func TestSnapshot_Save_unchanged(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "snapshot.json")
+
+	s, err := New(filePath, "")
+	require.NoError(t, err)
+
+	configurations := dynamic.Configurations{
+		"file": &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{
+			Routers: map[string]*dynamic.Router{"foo": {Rule: "Host(`foo.bar`)", Service: "foo"}},
+		}},
+	}
+
+	require.NoError(t, s.Save(configurations))
+	savedAt := s.Status().SavedAt
+
+	// The unchanged configurations are not written again.
+	require.NoError(t, os.Remove(filePath))
+	require.NoError(t, s.Save(configurations.DeepCopy()))
+	assert.NoFileExists(t, filePath)
+	assert.Equal(t, savedAt, s.Status().SavedAt)
+
+	// The saved configurations are not altered by a change of the given ones.
+	configurations["file"].HTTP.Routers["foo"].Rule = "Host(`bar.foo`)"
+	require.NoError(t, s.Save(configurations))
+	assert.FileExists(t, filePath)
+}
+
+func TestSnapshot_Expire(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "snapshot.json")
+
+	s, err := New(filePath, "")
+	require.NoError(t, err)
+
+	err = s.Save(dynamic.Configurations{
+		"file":    &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{}},
+		"removed": &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{}},
+		"docker":  &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{}},
+	})
+	require.NoError(t, err)
+
+	_, err = s.Load()
+	require.NoError(t, err)
+
+	assert.True(t, s.Delivered("file"))
+
+	assert.Equal(t, []string{"docker", "removed"}, s.Expire())
+	assert.Empty(t, s.Status().ProvisionalProviders)
+	assert.False(t, s.Delivered("docker"))
+	assert.Empty(t, s.Expire())
+}
+
+func TestSnapshot_wrongKey(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "snapshot.json")
+
+	s, err := New(filePath, base64.StdEncoding.EncodeToString(make([]byte, 32)))
+	require.NoError(t, err)
+	require.NoError(t, s.Save(dynamic.Configurations{}))
+
+	key := make([]byte, 32)
+	key[0] = 1
+
+	s, err = New(filePath, base64.StdEncoding.EncodeToString(key))
+	require.NoError(t, err)
+
+	_, err = s.Load()
+	require.Error(t, err)
+}
+
+func TestNew_invalidKey(t *testing.T) {
+	_, err := New("snapshot.json", base64.StdEncoding.EncodeToString([]byte("short")))
+	require.Error(t, err)
+}
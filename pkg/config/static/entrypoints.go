@@ -73,6 +73,7 @@ type HTTPConfig struct {
 	EncodeQuerySemicolons bool               `description:"Defines whether request query semicolons should be URLEncoded." json:"encodeQuerySemicolons,omitempty" toml:"encodeQuerySemicolons,omitempty" yaml:"encodeQuerySemicolons,omitempty" export:"true"`
 	SanitizePath          *bool              `description:"Defines whether to enable request path sanitization (removal of /./, /../ and multiple slash sequences)." json:"sanitizePath,omitempty" toml:"sanitizePath,omitempty" yaml:"sanitizePath,omitempty" export:"true"`
 	MaxHeaderBytes        int                `description:"Maximum size of request headers in bytes." json:"maxHeaderBytes,omitempty" toml:"maxHeaderBytes,omitempty" yaml:"maxHeaderBytes,omitempty" export:"true"`
+	RequestID             *RequestID         `description:"Assigns an ID to each request." json:"requestID,omitempty" toml:"requestID,omitempty" yaml:"requestID,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 }
 
 // SetDefaults sets the default values.
@@ -81,6 +82,19 @@ func (c *HTTPConfig) SetDefaults() {
 	c.MaxHeaderBytes = http.DefaultMaxHeaderBytes
 }
 
+// RequestID configures the ID assigned to each request.
+// The inbound request ID is kept when the request comes from an IP trusted by the ForwardedHeaders configuration.
+type RequestID struct {
+	HeaderName string `description:"Name of the header holding the request ID." json:"headerName,omitempty" toml:"headerName,omitempty" yaml:"headerName,omitempty" export:"true"`
+	Generator  string `description:"Request ID generator: uuidv7 | ulid." json:"generator,omitempty" toml:"generator,omitempty" yaml:"generator,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (r *RequestID) SetDefaults() {
+	r.HeaderName = "X-Request-Id"
+	r.Generator = "uuidv7"
+}
+
 // EncodedCharacters configures which encoded characters are allowed in the request path.
 type EncodedCharacters struct {
 	AllowEncodedSlash         bool `description:"Defines whether requests with encoded slash characters in the path are allowed." json:"allowEncodedSlash,omitempty" toml:"allowEncodedSlash,omitempty" yaml:"allowEncodedSlash,omitempty" export:"true"`
@@ -11,6 +11,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	ptypes "github.com/hanzoai/ingress-parser/types"
+	"github.com/hanzoai/ingress/pkg/config/secret"
 	"github.com/hanzoai/ingress/pkg/observability/logs"
 	otypes "github.com/hanzoai/ingress/pkg/observability/types"
 	"github.com/hanzoai/ingress/pkg/ping"
@@ -19,11 +20,13 @@ import (
 	"github.com/hanzoai/ingress/pkg/provider/docker"
 	"github.com/hanzoai/ingress/pkg/provider/ecs"
 	"github.com/hanzoai/ingress/pkg/provider/file"
+	"github.com/hanzoai/ingress/pkg/provider/git"
 	"github.com/hanzoai/ingress/pkg/provider/http"
 	"github.com/hanzoai/ingress/pkg/provider/kubernetes/crd"
 	"github.com/hanzoai/ingress/pkg/provider/kubernetes/gateway"
 	"github.com/hanzoai/ingress/pkg/provider/kubernetes/ingress"
 	ingressnginx "github.com/hanzoai/ingress/pkg/provider/kubernetes/ingress-nginx"
+	"github.com/hanzoai/ingress/pkg/provider/kubernetes/k8s"
 	"github.com/hanzoai/ingress/pkg/provider/kubernetes/knative"
 	"github.com/hanzoai/ingress/pkg/provider/kv/consul"
 	"github.com/hanzoai/ingress/pkg/provider/kv/etcd"
@@ -61,10 +64,11 @@ const (
 type Configuration struct {
 	Global *Global `description:"Global configuration options" json:"global,omitempty" toml:"global,omitempty" yaml:"global,omitempty" export:"true"`
 
-	ServersTransport    *ServersTransport    `description:"Servers default transport." json:"serversTransport,omitempty" toml:"serversTransport,omitempty" yaml:"serversTransport,omitempty" export:"true"`
-	TCPServersTransport *TCPServersTransport `description:"TCP servers default transport." json:"tcpServersTransport,omitempty" toml:"tcpServersTransport,omitempty" yaml:"tcpServersTransport,omitempty" export:"true"`
-	EntryPoints         EntryPoints          `description:"Entry points definition." json:"entryPoints,omitempty" toml:"entryPoints,omitempty" yaml:"entryPoints,omitempty" export:"true"`
-	Providers           *Providers           `description:"Providers configuration." json:"providers,omitempty" toml:"providers,omitempty" yaml:"providers,omitempty" export:"true"`
+	ServersTransport    *ServersTransport     `description:"Servers default transport." json:"serversTransport,omitempty" toml:"serversTransport,omitempty" yaml:"serversTransport,omitempty" export:"true"`
+	TCPServersTransport *TCPServersTransport  `description:"TCP servers default transport." json:"tcpServersTransport,omitempty" toml:"tcpServersTransport,omitempty" yaml:"tcpServersTransport,omitempty" export:"true"`
+	EntryPoints         EntryPoints           `description:"Entry points definition." json:"entryPoints,omitempty" toml:"entryPoints,omitempty" yaml:"entryPoints,omitempty" export:"true"`
+	Providers           *Providers            `description:"Providers configuration." json:"providers,omitempty" toml:"providers,omitempty" yaml:"providers,omitempty" export:"true"`
+	Secrets             *secret.Configuration `description:"Resolves the secret references of the dynamic configuration." json:"secrets,omitempty" toml:"secrets,omitempty" yaml:"secrets,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 
 	API     *API            `description:"Enable api/dashboard." json:"api,omitempty" toml:"api,omitempty" yaml:"api,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 	Metrics *otypes.Metrics `description:"Enable a metrics exporter." json:"metrics,omitempty" toml:"metrics,omitempty" yaml:"metrics,omitempty" export:"true"`
@@ -112,9 +116,10 @@ type CertificateResolver struct {
 
 // Global holds the global configuration.
 type Global struct {
-	CheckNewVersion        bool `description:"Periodically check if a new version has been released." json:"checkNewVersion,omitempty" toml:"checkNewVersion,omitempty" yaml:"checkNewVersion,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
-	SendAnonymousUsage     bool `description:"Periodically send anonymous usage statistics. If the option is not specified, it will be disabled by default." json:"sendAnonymousUsage,omitempty" toml:"sendAnonymousUsage,omitempty" yaml:"sendAnonymousUsage,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
-	NotAppendXForwardedFor bool `description:"Disable appending RemoteAddr to X-Forwarded-For header. Defaults to false (appending is enabled)." json:"notAppendXForwardedFor,omitempty" toml:"notAppendXForwardedFor,omitempty" yaml:"notAppendXForwardedFor,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	CheckNewVersion        bool   `description:"Periodically check if a new version has been released." json:"checkNewVersion,omitempty" toml:"checkNewVersion,omitempty" yaml:"checkNewVersion,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	SendAnonymousUsage     bool   `description:"Periodically send anonymous usage statistics. If the option is not specified, it will be disabled by default." json:"sendAnonymousUsage,omitempty" toml:"sendAnonymousUsage,omitempty" yaml:"sendAnonymousUsage,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	NotAppendXForwardedFor bool   `description:"Disable appending RemoteAddr to X-Forwarded-For header. Defaults to false (appending is enabled)." json:"notAppendXForwardedFor,omitempty" toml:"notAppendXForwardedFor,omitempty" yaml:"notAppendXForwardedFor,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	Zone                   string `description:"Zone of the instance, for the load-balancers with locality enabled to prefer the servers of this zone." json:"zone,omitempty" toml:"zone,omitempty" yaml:"zone,omitempty" export:"true"`
 }
 
 // ServersTransport options to configure communication between Ingress and the servers.
@@ -210,14 +215,15 @@ func (a *LifeCycle) SetDefaults() {
 
 // Tracing holds the tracing configuration.
 type Tracing struct {
-	ServiceName             string              `description:"Defines the service name resource attribute." json:"serviceName,omitempty" toml:"serviceName,omitempty" yaml:"serviceName,omitempty" export:"true"`
-	ResourceAttributes      map[string]string   `description:"Defines additional resource attributes (key:value)." json:"resourceAttributes,omitempty" toml:"resourceAttributes,omitempty" yaml:"resourceAttributes,omitempty" export:"true"`
-	CapturedRequestHeaders  []string            `description:"Request headers to add as attributes for server and client spans." json:"capturedRequestHeaders,omitempty" toml:"capturedRequestHeaders,omitempty" yaml:"capturedRequestHeaders,omitempty" export:"true"`
-	CapturedResponseHeaders []string            `description:"Response headers to add as attributes for server and client spans." json:"capturedResponseHeaders,omitempty" toml:"capturedResponseHeaders,omitempty" yaml:"capturedResponseHeaders,omitempty" export:"true"`
-	SafeQueryParams         []string            `description:"Query params to not redact." json:"safeQueryParams,omitempty" toml:"safeQueryParams,omitempty" yaml:"safeQueryParams,omitempty" export:"true"`
-	SampleRate              float64             `description:"Sets the rate between 0.0 and 1.0 of requests to trace." json:"sampleRate,omitempty" toml:"sampleRate,omitempty" yaml:"sampleRate,omitempty" export:"true"`
-	AddInternals            bool                `description:"Enables tracing for internal services (ping, dashboard, etc...)." json:"addInternals,omitempty" toml:"addInternals,omitempty" yaml:"addInternals,omitempty" export:"true"`
-	OTLP                    *otypes.OTelTracing `description:"Settings for OpenTelemetry." json:"otlp,omitempty" toml:"otlp,omitempty" yaml:"otlp,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	ServiceName             string               `description:"Defines the service name resource attribute." json:"serviceName,omitempty" toml:"serviceName,omitempty" yaml:"serviceName,omitempty" export:"true"`
+	ResourceAttributes      map[string]string    `description:"Defines additional resource attributes (key:value)." json:"resourceAttributes,omitempty" toml:"resourceAttributes,omitempty" yaml:"resourceAttributes,omitempty" export:"true"`
+	CapturedRequestHeaders  []string             `description:"Request headers to add as attributes for server and client spans." json:"capturedRequestHeaders,omitempty" toml:"capturedRequestHeaders,omitempty" yaml:"capturedRequestHeaders,omitempty" export:"true"`
+	CapturedResponseHeaders []string             `description:"Response headers to add as attributes for server and client spans." json:"capturedResponseHeaders,omitempty" toml:"capturedResponseHeaders,omitempty" yaml:"capturedResponseHeaders,omitempty" export:"true"`
+	SafeQueryParams         []string             `description:"Query params to not redact." json:"safeQueryParams,omitempty" toml:"safeQueryParams,omitempty" yaml:"safeQueryParams,omitempty" export:"true"`
+	SampleRate              float64              `description:"Sets the rate between 0.0 and 1.0 of requests to trace." json:"sampleRate,omitempty" toml:"sampleRate,omitempty" yaml:"sampleRate,omitempty" export:"true"`
+	TailSampling            *otypes.TailSampling `description:"Enables tail-based sampling, taking the sampling decision once traces are complete. The sampleRate option then only applies to the sampling decision propagated downstream." json:"tailSampling,omitempty" toml:"tailSampling,omitempty" yaml:"tailSampling,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	AddInternals            bool                 `description:"Enables tracing for internal services (ping, dashboard, etc...)." json:"addInternals,omitempty" toml:"addInternals,omitempty" yaml:"addInternals,omitempty" export:"true"`
+	OTLP                    *otypes.OTelTracing  `description:"Settings for OpenTelemetry." json:"otlp,omitempty" toml:"otlp,omitempty" yaml:"otlp,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 
 	// Deprecated: please use ResourceAttributes instead.
 	GlobalAttributes map[string]string `description:"(Deprecated) Defines additional resource attributes (key:value)." json:"globalAttributes,omitempty" toml:"globalAttributes,omitempty" yaml:"globalAttributes,omitempty" export:"true"`
@@ -236,6 +242,8 @@ func (t *Tracing) SetDefaults() {
 type Providers struct {
 	ProvidersThrottleDuration ptypes.Duration `description:"Backends throttle duration: minimum duration between 2 events from providers before applying a new configuration. It avoids unnecessary reloads if multiples events are sent in a short amount of time." json:"providersThrottleDuration,omitempty" toml:"providersThrottleDuration,omitempty" yaml:"providersThrottleDuration,omitempty" export:"true"`
 
+	Snapshot *ConfigurationSnapshot `description:"Persists the last known good dynamic configuration, used at startup until the providers deliver theirs." json:"snapshot,omitempty" toml:"snapshot,omitempty" yaml:"snapshot,omitempty" export:"true"`
+
 	Docker                 *docker.Provider               `description:"Enables Docker provider." json:"docker,omitempty" toml:"docker,omitempty" yaml:"docker,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 	Swarm                  *docker.SwarmProvider          `description:"Enables Docker Swarm provider." json:"swarm,omitempty" toml:"swarm,omitempty" yaml:"swarm,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 	File                   *file.Provider                 `description:"Enables File provider." json:"file,omitempty" toml:"file,omitempty" yaml:"file,omitempty" export:"true"`
@@ -253,10 +261,18 @@ type Providers struct {
 	ZooKeeper              *zk.Provider                   `description:"Enables ZooKeeper provider." json:"zooKeeper,omitempty" toml:"zooKeeper,omitempty" yaml:"zooKeeper,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 	Redis                  *redis.Provider                `description:"Enables Redis provider." json:"redis,omitempty" toml:"redis,omitempty" yaml:"redis,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 	HTTP                   *http.Provider                 `description:"Enables HTTP provider." json:"http,omitempty" toml:"http,omitempty" yaml:"http,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	Git                    *git.Provider                  `description:"Enables Git provider." json:"git,omitempty" toml:"git,omitempty" yaml:"git,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 
 	Plugin map[string]PluginConf `description:"Plugins configuration." json:"plugin,omitempty" toml:"plugin,omitempty" yaml:"plugin,omitempty"`
 }
 
+// ConfigurationSnapshot configures the persistence of the last known good dynamic configuration.
+type ConfigurationSnapshot struct {
+	FilePath           string          `description:"Path of the snapshot file." json:"filePath,omitempty" toml:"filePath,omitempty" yaml:"filePath,omitempty" export:"true"`
+	EncryptionKey      string          `description:"Base64 encoded AES key (16, 24 or 32 bytes) used to encrypt the snapshot at rest." json:"encryptionKey,omitempty" toml:"encryptionKey,omitempty" yaml:"encryptionKey,omitempty" loggable:"false"`
+	ProvisionalTimeout ptypes.Duration `description:"Duration after which the provisional configurations of the providers which did not deliver theirs are dropped. Zero means never." json:"provisionalTimeout,omitempty" toml:"provisionalTimeout,omitempty" yaml:"provisionalTimeout,omitempty" export:"true"`
+}
+
 // SetEffectiveConfiguration adds missing configuration parameters derived from existing ones.
 // It also takes care of maintaining backwards compatibility.
 func (c *Configuration) SetEffectiveConfiguration() {
@@ -275,7 +291,8 @@ func (c *Configuration) SetEffectiveConfiguration() {
 	if (c.API != nil && c.API.Insecure) ||
 		(c.Ping != nil && !c.Ping.ManualRouting && c.Ping.EntryPoint == DefaultInternalEntryPointName) ||
 		(c.Metrics != nil && c.Metrics.Prometheus != nil && !c.Metrics.Prometheus.ManualRouting && c.Metrics.Prometheus.EntryPoint == DefaultInternalEntryPointName) ||
-		(c.Providers != nil && c.Providers.Rest != nil && c.Providers.Rest.Insecure) {
+		(c.Providers != nil && c.Providers.Rest != nil && c.Providers.Rest.Insecure) ||
+		(c.Providers != nil && c.Providers.Git != nil && c.Providers.Git.Webhook != nil && c.Providers.Git.Webhook.Insecure) {
 		if _, ok := c.EntryPoints[DefaultInternalEntryPointName]; !ok {
 			ep := &EntryPoint{Address: ":8080"}
 			ep.SetDefaults()
@@ -287,6 +304,20 @@ func (c *Configuration) SetEffectiveConfiguration() {
 		c.Tracing.ResourceAttributes = c.Tracing.GlobalAttributes
 	}
 
+	// With tail sampling, the sampling flag of the requests is not the final sampling decision,
+	// thus the exemplars would point to traces which are possibly dropped.
+	if c.Tracing != nil && c.Tracing.TailSampling != nil && c.Metrics != nil {
+		if c.Metrics.Prometheus != nil && c.Metrics.Prometheus.AddExemplars {
+			log.Warn().Msg("Prometheus exemplars are disabled, as they are not supported with tail sampling")
+			c.Metrics.Prometheus.AddExemplars = false
+		}
+
+		if c.Metrics.OTLP != nil && c.Metrics.OTLP.AddExemplars {
+			log.Warn().Msg("OpenTelemetry exemplars are disabled, as they are not supported with tail sampling")
+			c.Metrics.OTLP.AddExemplars = false
+		}
+	}
+
 	if c.Providers.Docker != nil {
 		if c.Providers.Docker.HTTPClientTimeout < 0 {
 			c.Providers.Docker.HTTPClientTimeout = 0
@@ -423,12 +454,34 @@ func (c *Configuration) ValidateConfiguration() error {
 		}
 	}
 
+	if c.Providers != nil && c.Providers.Snapshot != nil && c.Providers.Snapshot.FilePath == "" {
+		return errors.New("the snapshot file path is required to persist the dynamic configuration")
+	}
+
 	if c.Providers != nil && c.Providers.KubernetesIngressNGINX != nil {
 		if c.Providers.KubernetesIngressNGINX.WatchNamespace != "" && c.Providers.KubernetesIngressNGINX.WatchNamespaceSelector != "" {
 			return errors.New("watchNamespace and watchNamespaceSelector options are mutually exclusive")
 		}
 	}
 
+	if c.Providers != nil && c.Providers.KubernetesIngress != nil {
+		if err := k8s.ValidateClusters(c.Providers.KubernetesIngress.Clusters); err != nil {
+			return fmt.Errorf("kubernetes Ingress provider: %w", err)
+		}
+	}
+
+	if c.Providers != nil && c.Providers.KubernetesCRD != nil {
+		if err := k8s.ValidateClusters(c.Providers.KubernetesCRD.Clusters); err != nil {
+			return fmt.Errorf("kubernetes CRD provider: %w", err)
+		}
+	}
+
+	if c.Providers != nil && c.Providers.KubernetesGateway != nil {
+		if err := k8s.ValidateClusters(c.Providers.KubernetesGateway.Clusters); err != nil {
+			return fmt.Errorf("kubernetes Gateway provider: %w", err)
+		}
+	}
+
 	if c.Providers != nil && c.Providers.Knative != nil {
 		if c.Experimental == nil || !c.Experimental.Knative {
 			return errors.New("the experimental Knative feature must be enabled to use the Knative provider")
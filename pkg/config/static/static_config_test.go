@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	otypes "github.com/hanzoai/ingress/pkg/observability/types"
 	"github.com/hanzoai/ingress/pkg/provider/acme"
 )
 
@@ -282,3 +283,41 @@ func TestConfiguration_SetEffectiveConfiguration(t *testing.T) {
 		})
 	}
 }
+
+func TestConfiguration_SetEffectiveConfiguration_exemplars(t *testing.T) {
+	testCases := []struct {
+		desc              string
+		tracing           *Tracing
+		expectedExemplars bool
+	}{
+		{
+			desc:              "head sampling",
+			tracing:           &Tracing{},
+			expectedExemplars: true,
+		},
+		{
+			desc:    "tail sampling",
+			tracing: &Tracing{TailSampling: &otypes.TailSampling{}},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			conf := &Configuration{
+				Providers: &Providers{},
+				Tracing:   test.tracing,
+				Metrics: &otypes.Metrics{
+					Prometheus: &otypes.Prometheus{AddExemplars: true},
+					OTLP:       &otypes.OTLP{AddExemplars: true},
+				},
+			}
+
+			conf.SetEffectiveConfiguration()
+
+			assert.Equal(t, test.expectedExemplars, conf.Metrics.Prometheus.AddExemplars)
+			assert.Equal(t, test.expectedExemplars, conf.Metrics.OTLP.AddExemplars)
+		})
+	}
+}
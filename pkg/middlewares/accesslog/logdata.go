@@ -82,6 +82,8 @@ const (
 	TraceID = "TraceId"
 	// SpanID is the unique identifier for Ingress’s root span (EntryPoint) within a request trace, formatted as a 16-hex digit string.
 	SpanID = "SpanId"
+	// RequestID is the identifier assigned to the request by the entry point, when enabled.
+	RequestID = "RequestId"
 )
 
 // These are written out in the default case when no config is provided to specify keys of interest.
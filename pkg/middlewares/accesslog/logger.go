@@ -21,6 +21,7 @@ import (
 	ptypes "github.com/hanzoai/ingress-parser/types"
 	"github.com/hanzoai/ingress/pkg/middlewares/capture"
 	"github.com/hanzoai/ingress/pkg/middlewares/observability"
+	"github.com/hanzoai/ingress/pkg/middlewares/requestid"
 	"github.com/hanzoai/ingress/pkg/observability/logs"
 	otypes "github.com/hanzoai/ingress/pkg/observability/types"
 	ingresstls "github.com/hanzoai/ingress/pkg/tls"
@@ -212,6 +213,10 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request, next http
 		}
 	}
 
+	if requestID := requestid.FromContext(req.Context()); requestID != "" {
+		logDataTable.Core[RequestID] = requestID
+	}
+
 	reqWithDataTable := req.WithContext(context.WithValue(req.Context(), DataTableKey, logDataTable))
 
 	core[RequestCount] = nextRequestCount()
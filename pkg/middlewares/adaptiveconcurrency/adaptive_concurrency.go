@@ -0,0 +1,154 @@
+// Package adaptiveconcurrency implements a middleware limiting the number of requests being processed concurrently
+// to a limit adjusted from the observed latencies.
+package adaptiveconcurrency
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/middlewares"
+	"github.com/hanzoai/ingress/pkg/middlewares/observability"
+)
+
+const typeName = "AdaptiveConcurrency"
+
+type adaptiveConcurrency struct {
+	name       string
+	next       http.Handler
+	limiter    *limiter
+	priority   *middlewares.PriorityExtractor
+	retryAfter string
+}
+
+// New creates an adaptive concurrency middleware.
+// The middlewares of the same group share the limiter held by the given limiters.
+func New(ctx context.Context, next http.Handler, limiters *Limiters, config dynamic.AdaptiveConcurrency, name string) (http.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	if limiters == nil {
+		return nil, errors.New("no limiters to share between the middlewares")
+	}
+
+	priority, err := middlewares.NewPriorityExtractor(config.Priority, config.PriorityHeader, "")
+	if err != nil {
+		return nil, err
+	}
+
+	group := config.Group
+	if group == "" {
+		group = name
+	}
+
+	limiter, err := limiters.get(group, config)
+	if err != nil {
+		return nil, fmt.Errorf("creating limiter: %w", err)
+	}
+
+	var retryAfter string
+	if config.RetryAfter > 0 {
+		retryAfter = strconv.FormatFloat(math.Ceil(time.Duration(config.RetryAfter).Seconds()), 'f', 0, 64)
+	}
+
+	return &adaptiveConcurrency{
+		name:       name,
+		next:       next,
+		limiter:    limiter,
+		priority:   priority,
+		retryAfter: retryAfter,
+	}, nil
+}
+
+func (a *adaptiveConcurrency) GetTracingInformation() (string, string) {
+	return a.name, typeName
+}
+
+func (a *adaptiveConcurrency) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	priority := a.priority.Extract(req)
+
+	inFlight, ok := a.limiter.acquire(priority)
+	if !ok {
+		logger := middlewares.GetLogger(req.Context(), a.name, typeName)
+		logger.Debug().Msgf("Shedding %s request: %d requests in progress", priority, inFlight)
+
+		observability.SetStatusErrorf(req.Context(), "Concurrency limit reached")
+		if a.retryAfter != "" {
+			rw.Header().Set("Retry-After", a.retryAfter)
+		}
+		http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	recorder := &statusRecorder{ResponseWriter: rw, status: http.StatusOK}
+	start := time.Now()
+
+	var completed bool
+	defer func() {
+		// The latency of the requests which have not completed, because of a panic or of the client going away,
+		// tells nothing about the load of the servers.
+		if !completed || req.Context().Err() != nil {
+			a.limiter.release(nil)
+			return
+		}
+
+		a.limiter.release(&sample{
+			rtt:      time.Since(start),
+			inFlight: inFlight,
+			dropped:  recorder.status >= http.StatusInternalServerError,
+		})
+	}()
+
+	a.next.ServeHTTP(recorder, req)
+	completed = true
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+
+	status      int
+	wroteHeader bool
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if !s.wroteHeader && status >= http.StatusOK {
+		s.status = status
+		s.wroteHeader = true
+	}
+
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	s.wroteHeader = true
+	return s.ResponseWriter.Write(b)
+}
+
+// Hijack hijacks the connection.
+func (s *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := s.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", s.ResponseWriter)
+	}
+
+	return hijacker.Hijack()
+}
+
+// Flush sends any buffered data to the client.
+func (s *statusRecorder) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying response writer.
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
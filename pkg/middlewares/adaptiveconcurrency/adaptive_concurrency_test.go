@@ -0,0 +1,277 @@
+package adaptiveconcurrency
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/hanzoai/ingress-parser/types"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/middlewares"
+)
+
+func newConfig() dynamic.AdaptiveConcurrency {
+	config := dynamic.AdaptiveConcurrency{}
+	config.SetDefaults()
+	return config
+}
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		config  func(config *dynamic.AdaptiveConcurrency)
+		wantErr bool
+	}{
+		{
+			desc:   "defaults",
+			config: func(config *dynamic.AdaptiveConcurrency) {},
+		},
+		{
+			desc: "aimd",
+			config: func(config *dynamic.AdaptiveConcurrency) {
+				config.Algorithm = "aimd"
+			},
+		},
+		{
+			desc: "vegas",
+			config: func(config *dynamic.AdaptiveConcurrency) {
+				config.Algorithm = "vegas"
+			},
+		},
+		{
+			desc: "unknown algorithm",
+			config: func(config *dynamic.AdaptiveConcurrency) {
+				config.Algorithm = "foo"
+			},
+			wantErr: true,
+		},
+		{
+			desc: "unknown priority",
+			config: func(config *dynamic.AdaptiveConcurrency) {
+				config.Priority = "foo"
+			},
+			wantErr: true,
+		},
+		{
+			desc: "min limit below one",
+			config: func(config *dynamic.AdaptiveConcurrency) {
+				config.MinLimit = 0
+			},
+			wantErr: true,
+		},
+		{
+			desc: "max limit below min limit",
+			config: func(config *dynamic.AdaptiveConcurrency) {
+				config.MinLimit = 10
+				config.MaxLimit = 5
+			},
+			wantErr: true,
+		},
+		{
+			desc: "initial limit above max limit",
+			config: func(config *dynamic.AdaptiveConcurrency) {
+				config.InitialLimit = 2000
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := newConfig()
+			test.config(&config)
+
+			_, err := New(t.Context(), http.NotFoundHandler(), NewLimiters(), config, "limiter")
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestAdaptiveConcurrency_ShedsLowestPriorityFirst(t *testing.T) {
+	config := newConfig()
+	config.InitialLimit = 10
+	config.PriorityHeader = "X-Priority"
+	config.RetryAfter = ptypes.Duration(1500 * time.Millisecond)
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	handler, err := New(t.Context(), http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		started <- struct{}{}
+		<-release
+	}), NewLimiters(), config, "limiter")
+	require.NoError(t, err)
+
+	serve := func(priority string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Priority", priority)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	done := make(chan struct{})
+	inFlight := func(priority string) {
+		go func() {
+			serve(priority)
+			done <- struct{}{}
+		}()
+		<-started
+	}
+
+	// Sheddable requests use at most half of the limit.
+	for range 5 {
+		inFlight("sheddable")
+	}
+	recorder := serve("sheddable")
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Equal(t, "2", recorder.Header().Get("Retry-After"))
+
+	// Standard requests use at most 90% of the limit, which leaves room for critical requests.
+	for range 4 {
+		inFlight("")
+	}
+	assert.Equal(t, http.StatusServiceUnavailable, serve("standard").Code)
+
+	inFlight("critical")
+	assert.Equal(t, http.StatusServiceUnavailable, serve("critical").Code)
+
+	close(release)
+	for range 10 {
+		<-done
+	}
+
+	assert.Equal(t, http.StatusOK, serve("sheddable").Code)
+}
+
+func TestAdaptiveConcurrency_Group(t *testing.T) {
+	limiters := NewLimiters()
+
+	config := newConfig()
+	config.Group = "service"
+
+	first, err := New(t.Context(), http.NotFoundHandler(), limiters, config, "first")
+	require.NoError(t, err)
+
+	// The priority class can differ between the middlewares of a group.
+	config.Priority = "critical"
+	second, err := New(t.Context(), http.NotFoundHandler(), limiters, config, "second")
+	require.NoError(t, err)
+
+	assert.Same(t, first.(*adaptiveConcurrency).limiter, second.(*adaptiveConcurrency).limiter)
+	assert.Equal(t, middlewares.PriorityCritical, second.(*adaptiveConcurrency).priority.Extract(httptest.NewRequest(http.MethodGet, "/", nil)))
+
+	// The limit configuration cannot.
+	config.MaxLimit = 100
+	_, err = New(t.Context(), http.NotFoundHandler(), limiters, config, "third")
+	require.Error(t, err)
+}
+
+func TestLimiters_Prune(t *testing.T) {
+	limiters := NewLimiters()
+
+	config := newConfig()
+	config.Group = "service"
+
+	first, err := New(t.Context(), http.NotFoundHandler(), limiters, config, "first")
+	require.NoError(t, err)
+
+	other := newConfig()
+	other.Group = "other"
+	_, err = New(t.Context(), http.NotFoundHandler(), limiters, other, "other")
+	require.NoError(t, err)
+
+	limiters.Prune()
+
+	// The limiter of a group is kept across the reloads.
+	reloaded, err := New(t.Context(), http.NotFoundHandler(), limiters, config, "first")
+	require.NoError(t, err)
+	assert.Same(t, first.(*adaptiveConcurrency).limiter, reloaded.(*adaptiveConcurrency).limiter)
+
+	limiters.Prune()
+
+	// The limiter of a group which is no longer used is dropped.
+	assert.NotContains(t, limiters.limiters, "other")
+
+	// The limiter of a group is replaced when its configuration changes with a reload.
+	config.MaxLimit = 100
+	changed, err := New(t.Context(), http.NotFoundHandler(), limiters, config, "first")
+	require.NoError(t, err)
+	assert.NotSame(t, first.(*adaptiveConcurrency).limiter, changed.(*adaptiveConcurrency).limiter)
+}
+
+func TestLimiter_Algorithms(t *testing.T) {
+	for _, name := range []string{"gradient", "aimd", "vegas"} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lim, err := newLimiter(limiterConfig{algorithm: name, initialLimit: 20, minLimit: 1, maxLimit: 1000})
+			require.NoError(t, err)
+
+			record := func(rtt time.Duration) {
+				inFlight, ok := lim.acquire(middlewares.PriorityCritical)
+				require.True(t, ok)
+				lim.release(&sample{rtt: rtt, inFlight: max(inFlight, lim.currentLimit())})
+			}
+
+			// The limit grows while the latency is stable.
+			for range 100 {
+				record(10 * time.Millisecond)
+			}
+			grown := lim.currentLimit()
+			assert.Greater(t, grown, 20)
+
+			// The limit shrinks once the latency increases.
+			for range 100 {
+				record(100 * time.Millisecond)
+			}
+			assert.Less(t, lim.currentLimit(), grown)
+		})
+	}
+}
+
+func TestLimiter_ZeroRTT(t *testing.T) {
+	for _, name := range []string{"gradient", "aimd", "vegas"} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lim, err := newLimiter(limiterConfig{algorithm: name, initialLimit: 20, minLimit: 1, maxLimit: 1000})
+			require.NoError(t, err)
+
+			for _, rtt := range []time.Duration{0, 10 * time.Millisecond, 0} {
+				lim.acquire(middlewares.PriorityCritical)
+				lim.release(&sample{rtt: rtt, inFlight: 20})
+			}
+
+			assert.False(t, math.IsNaN(lim.limit))
+			assert.GreaterOrEqual(t, lim.currentLimit(), 1)
+		})
+	}
+}
+
+func TestLimiter_Bounds(t *testing.T) {
+	lim, err := newLimiter(limiterConfig{algorithm: "aimd", initialLimit: 5, minLimit: 2, maxLimit: 6})
+	require.NoError(t, err)
+
+	for range 10 {
+		lim.acquire(middlewares.PriorityCritical)
+		lim.release(&sample{rtt: time.Millisecond, inFlight: 10})
+	}
+	assert.Equal(t, 6, lim.currentLimit())
+
+	for range 20 {
+		lim.acquire(middlewares.PriorityCritical)
+		lim.release(&sample{rtt: time.Millisecond, inFlight: 10, dropped: true})
+	}
+	assert.Equal(t, 2, lim.currentLimit())
+}
@@ -0,0 +1,164 @@
+package adaptiveconcurrency
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// longWindow is the number of samples over which the long-term latency is averaged.
+	longWindow = 600
+	// gradientTolerance is the ratio of the long-term latency above which the gradient algorithm decreases the limit.
+	gradientTolerance = 1.5
+	// gradientSmoothing is the weight of a new limit computed by the gradient algorithm.
+	gradientSmoothing = 0.2
+	// aimdTolerance is the ratio of the long-term latency above which the AIMD algorithm considers a request as dropped.
+	aimdTolerance = 2
+	// aimdBackoff is the ratio by which the AIMD algorithm decreases the limit.
+	aimdBackoff = 0.9
+	// vegasProbeInterval is the number of samples after which the Vegas algorithm measures the no-load latency again.
+	vegasProbeInterval = 1000
+)
+
+// sample is the measure of a request.
+type sample struct {
+	rtt time.Duration
+	// inFlight is the number of requests in progress when the request started, including it.
+	inFlight int
+	// dropped is true when the request failed.
+	dropped bool
+}
+
+// algorithm adjusts the concurrency limit from the latency samples.
+type algorithm interface {
+	// update returns the new limit from the current limit and the given sample.
+	update(limit float64, s sample) float64
+}
+
+func newAlgorithm(name string) (algorithm, error) {
+	switch name {
+	case "", "gradient":
+		return &gradient{}, nil
+	case "aimd":
+		return &aimd{}, nil
+	case "vegas":
+		return &vegas{}, nil
+	default:
+		return nil, fmt.Errorf("unknown algorithm %q", name)
+	}
+}
+
+// ewma is an exponentially weighted moving average over a window of samples.
+type ewma struct {
+	value   float64
+	samples int
+}
+
+func (e *ewma) add(v float64) {
+	if e.samples < longWindow {
+		e.samples++
+	}
+
+	if e.samples == 1 {
+		e.value = v
+		return
+	}
+
+	factor := 2 / float64(e.samples+1)
+	e.value = e.value*(1-factor) + v*factor
+}
+
+// gradient adjusts the limit from the ratio between the long-term and the current latencies,
+// the limit decreasing as soon as the current latency grows beyond the tolerance.
+type gradient struct {
+	longRTT ewma
+}
+
+func (g *gradient) update(limit float64, s sample) float64 {
+	// A sample without latency does not tell anything about the latency trend.
+	if s.rtt <= 0 {
+		return limit
+	}
+
+	rtt := float64(s.rtt)
+	g.longRTT.add(rtt)
+
+	// Once the latency has increased for good, the long-term latency converges faster toward it.
+	if g.longRTT.value/rtt > 2 {
+		g.longRTT.value *= 0.95
+	}
+
+	// The limit is not reached, so the latency does not tell whether it should grow.
+	if float64(s.inFlight) < limit/2 {
+		return limit
+	}
+
+	ratio := math.Max(0.5, math.Min(1, gradientTolerance*g.longRTT.value/rtt))
+	newLimit := limit*ratio + math.Sqrt(limit)
+
+	return limit*(1-gradientSmoothing) + newLimit*gradientSmoothing
+}
+
+// aimd increases the limit by one for each successful request, and decreases it by a ratio for each dropped request:
+// a failed request, or a request which is much slower than the long-term latency.
+type aimd struct {
+	longRTT ewma
+}
+
+func (a *aimd) update(limit float64, s sample) float64 {
+	rtt := float64(s.rtt)
+	slow := a.longRTT.samples > 0 && rtt > aimdTolerance*a.longRTT.value
+	a.longRTT.add(rtt)
+
+	if s.dropped || slow {
+		return limit * aimdBackoff
+	}
+
+	if float64(s.inFlight) < limit/2 {
+		return limit
+	}
+
+	return limit + 1
+}
+
+// vegas estimates the number of requests queued by the servers from the ratio between the no-load and the current latencies,
+// and adjusts the limit to keep it between a lower and an upper threshold.
+type vegas struct {
+	minRTT  float64
+	samples int
+}
+
+func (v *vegas) update(limit float64, s sample) float64 {
+	// A sample without latency does not tell anything about the queued requests.
+	if s.rtt <= 0 {
+		return limit
+	}
+
+	rtt := float64(s.rtt)
+
+	v.samples++
+	if v.minRTT == 0 || rtt < v.minRTT || v.samples > vegasProbeInterval {
+		v.minRTT = rtt
+		v.samples = 0
+	}
+
+	step := math.Max(1, math.Log10(limit))
+	if s.dropped {
+		return limit - step
+	}
+
+	queue := math.Ceil(limit * (1 - v.minRTT/rtt))
+	switch {
+	case queue > 6*step:
+		return limit - step
+	case float64(s.inFlight) < limit/2:
+		return limit
+	case queue <= step:
+		return limit + 6*step
+	case queue < 3*step:
+		return limit + step
+	default:
+		return limit
+	}
+}
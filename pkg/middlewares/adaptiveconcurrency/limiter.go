@@ -0,0 +1,164 @@
+package adaptiveconcurrency
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/middlewares"
+)
+
+// shares are the shares of the limit up to which the requests of each priority class are accepted,
+// the lowest priority requests being shed first to leave room for the highest priority ones.
+var shares = map[middlewares.Priority]float64{
+	middlewares.PrioritySheddable: 0.5,
+	middlewares.PriorityStandard:  0.9,
+	middlewares.PriorityCritical:  1,
+}
+
+// limiterConfig is the part of the configuration which must be the same for all the middlewares of a group.
+type limiterConfig struct {
+	algorithm    string
+	initialLimit int
+	minLimit     int
+	maxLimit     int
+}
+
+// limiter limits the number of requests being processed concurrently.
+type limiter struct {
+	config    limiterConfig
+	algorithm algorithm
+
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+}
+
+func newLimiter(config limiterConfig) (*limiter, error) {
+	algorithm, err := newAlgorithm(config.algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.minLimit < 1 {
+		return nil, errors.New("minLimit must be greater than or equal to 1")
+	}
+
+	if config.maxLimit < config.minLimit {
+		return nil, errors.New("maxLimit must be greater than or equal to minLimit")
+	}
+
+	if config.initialLimit < config.minLimit || config.initialLimit > config.maxLimit {
+		return nil, errors.New("initialLimit must be between minLimit and maxLimit")
+	}
+
+	return &limiter{
+		config:    config,
+		algorithm: algorithm,
+		limit:     float64(config.initialLimit),
+	}, nil
+}
+
+// acquire reserves a slot for a request of the given priority.
+// It returns the number of requests in progress including this one, and false if the request is to be shed.
+func (l *limiter) acquire(priority middlewares.Priority) (int, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight >= max(1, int(l.limit*shares[priority])) {
+		return l.inFlight, false
+	}
+
+	l.inFlight++
+	return l.inFlight, true
+}
+
+// release frees the slot of a request, and adjusts the limit from its sample, unless it is nil.
+func (l *limiter) release(s *sample) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+	if s == nil {
+		return
+	}
+
+	limit := l.algorithm.update(l.limit, *s)
+	l.limit = math.Max(float64(l.config.minLimit), math.Min(float64(l.config.maxLimit), limit))
+}
+
+// currentLimit returns the current concurrency limit.
+func (l *limiter) currentLimit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return int(l.limit)
+}
+
+// Limiters holds the limiters shared by the middlewares of the same group.
+// The limiters are meant to be kept across the configuration reloads, so that the limits learned are not lost,
+// the limiters of the groups which are no longer used being dropped by Prune.
+type Limiters struct {
+	mu       sync.Mutex
+	limiters map[string]*limiter
+	// used holds the groups used since the last call to Prune.
+	used map[string]struct{}
+}
+
+// NewLimiters creates a new Limiters.
+func NewLimiters() *Limiters {
+	return &Limiters{
+		limiters: make(map[string]*limiter),
+		used:     make(map[string]struct{}),
+	}
+}
+
+// get returns the limiter of the given group, creating it if needed.
+// The limiter of a group is replaced when its configuration has changed since the last call to Prune.
+func (l *Limiters) get(group string, config dynamic.AdaptiveConcurrency) (*limiter, error) {
+	lConfig := limiterConfig{
+		algorithm:    config.Algorithm,
+		initialLimit: config.InitialLimit,
+		minLimit:     config.MinLimit,
+		maxLimit:     config.MaxLimit,
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if lim, ok := l.limiters[group]; ok {
+		if lim.config == lConfig {
+			l.used[group] = struct{}{}
+			return lim, nil
+		}
+
+		if _, used := l.used[group]; used {
+			return nil, fmt.Errorf("group %q is already limited with a different configuration", group)
+		}
+	}
+
+	lim, err := newLimiter(lConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	l.limiters[group] = lim
+	l.used[group] = struct{}{}
+	return lim, nil
+}
+
+// Prune drops the limiters of the groups which have not been used since the last call to Prune.
+func (l *Limiters) Prune() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for group := range l.limiters {
+		if _, ok := l.used[group]; !ok {
+			delete(l.limiters, group)
+		}
+	}
+
+	clear(l.used)
+}
@@ -179,7 +179,7 @@ func (m *metricsMiddleware) ServeHTTP(rw http.ResponseWriter, req *http.Request)
 	}
 
 	labels = append(labels, "code", strconv.Itoa(code))
-	m.reqDurationHistogram.With(labels...).ObserveFromStart(start)
+	m.reqDurationHistogram.With(labels...).ObserveFromStart(ctx, start)
 	m.reqsCounter.With(req.Header, labels...).Add(1)
 	m.respsBytesCounter.With(labels...).Add(float64(capt.ResponseSize()))
 	m.reqsBytesCounter.With(labels...).Add(float64(capt.RequestSize()))
@@ -8,6 +8,7 @@ import (
 	"github.com/containous/alice"
 	"github.com/rs/zerolog/log"
 	"github.com/hanzoai/ingress/pkg/middlewares"
+	"github.com/hanzoai/ingress/pkg/middlewares/requestid"
 	"github.com/hanzoai/ingress/pkg/observability/tracing"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -69,6 +70,10 @@ func (e *entryPointTracing) ServeHTTP(rw http.ResponseWriter, req *http.Request)
 
 	span.SetAttributes(attribute.String("entry_point", e.entryPoint))
 
+	if requestID := requestid.FromContext(req.Context()); requestID != "" {
+		span.SetAttributes(attribute.String("request_id", requestID))
+	}
+
 	e.tracer.CaptureServerRequest(span, req)
 
 	recorder := newStatusCodeRecorder(rw, http.StatusOK)
@@ -0,0 +1,119 @@
+package middlewares
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Priority is the priority class of a request.
+// The requests of the lowest priority classes are the first to be shed, or the last to be served.
+type Priority int
+
+// Priority classes, from the lowest to the highest.
+const (
+	PrioritySheddable Priority = iota
+	PriorityStandard
+	PriorityCritical
+)
+
+// ParsePriority returns the priority class with the given name: critical, standard or sheddable.
+func ParsePriority(name string) (Priority, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "sheddable":
+		return PrioritySheddable, nil
+	case "standard":
+		return PriorityStandard, nil
+	case "critical":
+		return PriorityCritical, nil
+	default:
+		return 0, fmt.Errorf("unknown priority class %q", name)
+	}
+}
+
+func (p Priority) String() string {
+	switch p {
+	case PrioritySheddable:
+		return "sheddable"
+	case PriorityStandard:
+		return "standard"
+	case PriorityCritical:
+		return "critical"
+	default:
+		return fmt.Sprintf("Priority(%d)", int(p))
+	}
+}
+
+// PriorityExtractor extracts the priority class of the requests.
+type PriorityExtractor struct {
+	priority Priority
+	header   string
+	claim    string
+}
+
+// NewPriorityExtractor creates a PriorityExtractor taking the priority class of a request from the given header,
+// or else from the given claim of its bearer token, and defaulting to the given priority class.
+// The bearer token is not verified, so it must have been verified by a previous middleware.
+func NewPriorityExtractor(priority, header, claim string) (*PriorityExtractor, error) {
+	extractor := &PriorityExtractor{
+		priority: PriorityStandard,
+		header:   header,
+		claim:    claim,
+	}
+
+	if priority != "" {
+		var err error
+		extractor.priority, err = ParsePriority(priority)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return extractor, nil
+}
+
+// Extract returns the priority class of the given request.
+// An unknown priority class is ignored.
+func (e *PriorityExtractor) Extract(req *http.Request) Priority {
+	if e.header != "" {
+		if priority, err := ParsePriority(req.Header.Get(e.header)); err == nil {
+			return priority
+		}
+	}
+
+	if e.claim != "" {
+		if priority, err := ParsePriority(bearerClaim(req, e.claim)); err == nil {
+			return priority
+		}
+	}
+
+	return e.priority
+}
+
+// bearerClaim returns the value of the given string claim of the JWT bearer token of the request, without verifying it.
+func bearerClaim(req *http.Request, claim string) string {
+	scheme, token, ok := strings.Cut(req.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return ""
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+
+	var claims map[string]any
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ""
+	}
+
+	value, _ := claims[claim].(string)
+	return value
+}
@@ -0,0 +1,103 @@
+package middlewares
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriorityExtractor(t *testing.T) {
+	token := func(payload string) string {
+		return "Bearer header." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".signature"
+	}
+
+	testCases := []struct {
+		desc          string
+		priority      string
+		header        string
+		claim         string
+		reqHeaders    map[string]string
+		expected      Priority
+		expectedError bool
+	}{
+		{
+			desc:     "default",
+			expected: PriorityStandard,
+		},
+		{
+			desc:     "configured priority",
+			priority: "sheddable",
+			expected: PrioritySheddable,
+		},
+		{
+			desc:          "unknown configured priority",
+			priority:      "foo",
+			expectedError: true,
+		},
+		{
+			desc:       "header",
+			header:     "X-Priority",
+			reqHeaders: map[string]string{"X-Priority": "Critical"},
+			expected:   PriorityCritical,
+		},
+		{
+			desc:       "unknown header priority",
+			priority:   "sheddable",
+			header:     "X-Priority",
+			reqHeaders: map[string]string{"X-Priority": "foo"},
+			expected:   PrioritySheddable,
+		},
+		{
+			desc:       "claim",
+			claim:      "priority",
+			reqHeaders: map[string]string{"Authorization": token(`{"priority":"critical"}`)},
+			expected:   PriorityCritical,
+		},
+		{
+			desc:   "header before claim",
+			header: "X-Priority",
+			claim:  "priority",
+			reqHeaders: map[string]string{
+				"X-Priority":    "sheddable",
+				"Authorization": token(`{"priority":"critical"}`),
+			},
+			expected: PrioritySheddable,
+		},
+		{
+			desc:       "invalid token",
+			claim:      "priority",
+			reqHeaders: map[string]string{"Authorization": "Bearer foo"},
+			expected:   PriorityStandard,
+		},
+		{
+			desc:       "not a bearer token",
+			claim:      "priority",
+			reqHeaders: map[string]string{"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte("user:critical"))},
+			expected:   PriorityStandard,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			extractor, err := NewPriorityExtractor(test.priority, test.header, test.claim)
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for name, value := range test.reqHeaders {
+				req.Header.Set(name, value)
+			}
+
+			assert.Equal(t, test.expected, extractor.Extract(req))
+		})
+	}
+}
@@ -0,0 +1,287 @@
+// Package queue implements a middleware limiting the number of requests being processed concurrently,
+// which queues the requests above the limit instead of rejecting them.
+package queue
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/middlewares"
+	"github.com/hanzoai/ingress/pkg/middlewares/observability"
+	"github.com/hanzoai/ingress/pkg/observability/metrics"
+	"github.com/vulcand/oxy/v2/utils"
+)
+
+const typeName = "Queue"
+
+// Results of the requests which have waited in the queue.
+const (
+	resultServed   = "served"
+	resultRejected = "rejected"
+	resultTimeout  = "timeout"
+	resultCanceled = "canceled"
+)
+
+// source holds the requests of a source.
+type source struct {
+	inFlight int64
+	waiting  waiters
+}
+
+type queue struct {
+	name          string
+	next          http.Handler
+	sourceMatcher utils.SourceExtractor
+	priority      *middlewares.PriorityExtractor
+	byPriority    bool
+	maxInFlight   int64
+	maxSize       int
+	maxWait       time.Duration
+
+	depthGauge    gokitmetrics.Gauge
+	waitHistogram metrics.ScalableHistogram
+
+	mu      sync.Mutex
+	seq     uint64
+	sources map[string]*source
+}
+
+// New creates a queue middleware.
+// If no source criterion is provided in the config, it defaults to RequestHost.
+func New(ctx context.Context, next http.Handler, registry metrics.Registry, config dynamic.Queue, name string) (http.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	ctxLog := logger.WithContext(ctx)
+
+	if config.MaxInFlight <= 0 {
+		return nil, errors.New("maxInFlight must be greater than zero")
+	}
+
+	if config.MaxSize < 0 {
+		return nil, errors.New("maxSize must be positive")
+	}
+
+	if config.MaxWait <= 0 {
+		return nil, errors.New("maxWait must be greater than zero")
+	}
+
+	var byPriority bool
+	switch config.Order {
+	case "", "fifo":
+	case "priority":
+		byPriority = true
+	default:
+		return nil, fmt.Errorf("unknown order %q", config.Order)
+	}
+
+	priority, err := middlewares.NewPriorityExtractor(config.Priority, config.PriorityHeader, config.PriorityClaim)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.SourceCriterion == nil ||
+		config.SourceCriterion.IPStrategy == nil &&
+			config.SourceCriterion.RequestHeaderName == "" && !config.SourceCriterion.RequestHost {
+		config.SourceCriterion = &dynamic.SourceCriterion{
+			RequestHost: true,
+		}
+	}
+
+	sourceMatcher, err := middlewares.GetSourceExtractor(ctxLog, config.SourceCriterion)
+	if err != nil {
+		return nil, fmt.Errorf("error creating requests queue: %w", err)
+	}
+
+	if registry == nil {
+		registry = metrics.NewVoidRegistry()
+	}
+
+	return &queue{
+		name:          name,
+		next:          next,
+		sourceMatcher: sourceMatcher,
+		priority:      priority,
+		byPriority:    byPriority,
+		maxInFlight:   config.MaxInFlight,
+		maxSize:       config.MaxSize,
+		maxWait:       time.Duration(config.MaxWait),
+		depthGauge:    registry.MiddlewareQueueDepthGauge().With("middleware", name),
+		waitHistogram: registry.MiddlewareQueueWaitHistogram().With("middleware", name),
+		sources:       make(map[string]*source),
+	}, nil
+}
+
+func (q *queue) GetTracingInformation() (string, string) {
+	return q.name, typeName
+}
+
+func (q *queue) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), q.name, typeName)
+
+	key, _, err := q.sourceMatcher.Extract(req)
+	if err != nil {
+		logger.Error().Err(err).Msg("Could not extract source of request")
+		http.Error(rw, "could not extract source of request", http.StatusInternalServerError)
+		return
+	}
+
+	priority := q.priority.Extract(req)
+
+	w, ok := q.enqueue(key, priority)
+	if !ok {
+		q.reject(rw, req, priority)
+		return
+	}
+
+	if w == nil {
+		q.serve(rw, req, key)
+		return
+	}
+
+	start := time.Now()
+	timer := time.NewTimer(q.maxWait)
+	defer timer.Stop()
+
+	select {
+	case <-w.ready:
+	case <-timer.C:
+		if q.dequeue(key, w) {
+			q.waitHistogram.With("priority", priority.String(), "result", resultTimeout).ObserveFromStart(req.Context(), start)
+
+			logger.Debug().Msgf("Request timed out in the queue after %s", q.maxWait)
+			observability.SetStatusErrorf(req.Context(), "Request timed out in the queue")
+			http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+		<-w.ready
+	case <-req.Context().Done():
+		if q.dequeue(key, w) {
+			q.waitHistogram.With("priority", priority.String(), "result", resultCanceled).ObserveFromStart(req.Context(), start)
+			return
+		}
+		<-w.ready
+	}
+
+	if !w.admitted {
+		q.waitHistogram.With("priority", priority.String(), "result", resultRejected).ObserveFromStart(req.Context(), start)
+		q.reject(rw, req, priority)
+		return
+	}
+
+	q.waitHistogram.With("priority", priority.String(), "result", resultServed).ObserveFromStart(req.Context(), start)
+	q.serve(rw, req, key)
+}
+
+// enqueue admits a request of the given source and priority, or queues it.
+// It returns a nil waiter if the request is admitted right away, and false if the request does not fit in the queue.
+func (q *queue) enqueue(key string, priority middlewares.Priority) (*waiter, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	src, ok := q.sources[key]
+	if !ok {
+		src = &source{}
+		q.sources[key] = src
+	}
+
+	if src.inFlight < q.maxInFlight && src.waiting.Len() == 0 {
+		src.inFlight++
+		return nil, true
+	}
+
+	q.seq++
+	w := &waiter{
+		priority:   priority,
+		seq:        q.seq,
+		byPriority: q.byPriority,
+		ready:      make(chan struct{}),
+	}
+
+	if src.waiting.Len() >= q.maxSize {
+		if !q.byPriority {
+			return nil, false
+		}
+
+		// The lowest priority request, and the newest among them, leaves the queue for a higher priority request.
+		worst := src.waiting.worst()
+		if worst == nil || worst.priority >= priority {
+			return nil, false
+		}
+
+		heap.Remove(&src.waiting, worst.index)
+		close(worst.ready)
+		q.depthGauge.Add(-1)
+	}
+
+	heap.Push(&src.waiting, w)
+	q.depthGauge.Add(1)
+
+	return w, true
+}
+
+// dequeue removes the given waiter from the queue of the given source.
+// It returns false if the waiter has already left the queue.
+func (q *queue) dequeue(key string, w *waiter) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if w.index < 0 {
+		return false
+	}
+
+	src := q.sources[key]
+	heap.Remove(&src.waiting, w.index)
+	q.depthGauge.Add(-1)
+	q.forget(key, src)
+
+	return true
+}
+
+// serve forwards an admitted request, and admits the next queued request of its source once done.
+func (q *queue) serve(rw http.ResponseWriter, req *http.Request, key string) {
+	defer q.release(key)
+
+	q.next.ServeHTTP(rw, req)
+}
+
+func (q *queue) release(key string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	src := q.sources[key]
+	src.inFlight--
+
+	for src.inFlight < q.maxInFlight && src.waiting.Len() > 0 {
+		w := heap.Pop(&src.waiting).(*waiter)
+		w.admitted = true
+		src.inFlight++
+		close(w.ready)
+		q.depthGauge.Add(-1)
+	}
+
+	q.forget(key, src)
+}
+
+// forget removes the given source once it has no more requests.
+// It must be called with the lock held.
+func (q *queue) forget(key string, src *source) {
+	if src.inFlight == 0 && src.waiting.Len() == 0 {
+		delete(q.sources, key)
+	}
+}
+
+func (q *queue) reject(rw http.ResponseWriter, req *http.Request, priority middlewares.Priority) {
+	logger := middlewares.GetLogger(req.Context(), q.name, typeName)
+	logger.Debug().Msgf("Rejecting %s request: the queue is full", priority)
+
+	observability.SetStatusErrorf(req.Context(), "Queue is full")
+	http.Error(rw, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+}
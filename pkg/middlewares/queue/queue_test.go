@@ -0,0 +1,303 @@
+package queue
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/hanzoai/ingress-parser/types"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+)
+
+func newConfig() dynamic.Queue {
+	config := dynamic.Queue{}
+	config.SetDefaults()
+	config.MaxInFlight = 1
+	config.PriorityHeader = "X-Priority"
+	return config
+}
+
+// blockingHandler records the order in which the requests are served, and blocks them until released.
+type blockingHandler struct {
+	started chan string
+	release chan struct{}
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{
+		started: make(chan string, 10),
+		release: make(chan struct{}),
+	}
+}
+
+func (b *blockingHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	b.started <- req.Header.Get("X-Name")
+	<-b.release
+}
+
+func newRequest(name, priority string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Name", name)
+	req.Header.Set("X-Priority", priority)
+	return req
+}
+
+// serveAsync serves the given request, and returns the channel receiving its response status code.
+func serveAsync(handler http.Handler, req *http.Request) <-chan int {
+	code := make(chan int, 1)
+	go func() {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		code <- recorder.Code
+	}()
+	return code
+}
+
+// waitQueued waits until the given number of requests are waiting in the queue.
+func waitQueued(t *testing.T, handler http.Handler, queued int) {
+	t.Helper()
+
+	q := handler.(*queue)
+	require.Eventually(t, func() bool {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+
+		var count int
+		for _, src := range q.sources {
+			count += src.waiting.Len()
+		}
+		return count == queued
+	}, time.Second, time.Millisecond)
+}
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		config  func(config *dynamic.Queue)
+		wantErr bool
+	}{
+		{
+			desc:   "defaults",
+			config: func(config *dynamic.Queue) {},
+		},
+		{
+			desc: "priority order",
+			config: func(config *dynamic.Queue) {
+				config.Order = "priority"
+			},
+		},
+		{
+			desc: "unknown order",
+			config: func(config *dynamic.Queue) {
+				config.Order = "lifo"
+			},
+			wantErr: true,
+		},
+		{
+			desc: "unknown priority",
+			config: func(config *dynamic.Queue) {
+				config.Priority = "foo"
+			},
+			wantErr: true,
+		},
+		{
+			desc: "no max in-flight requests",
+			config: func(config *dynamic.Queue) {
+				config.MaxInFlight = 0
+			},
+			wantErr: true,
+		},
+		{
+			desc: "no max wait",
+			config: func(config *dynamic.Queue) {
+				config.MaxWait = 0
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := newConfig()
+			test.config(&config)
+
+			_, err := New(t.Context(), http.NotFoundHandler(), nil, config, "queue")
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestQueue_FIFO(t *testing.T) {
+	next := newBlockingHandler()
+	handler, err := New(t.Context(), next, nil, newConfig(), "queue")
+	require.NoError(t, err)
+
+	first := serveAsync(handler, newRequest("first", "sheddable"))
+	assert.Equal(t, "first", <-next.started)
+
+	second := serveAsync(handler, newRequest("second", "sheddable"))
+	waitQueued(t, handler, 1)
+	third := serveAsync(handler, newRequest("third", "critical"))
+	waitQueued(t, handler, 2)
+
+	close(next.release)
+
+	assert.Equal(t, "second", <-next.started)
+	assert.Equal(t, "third", <-next.started)
+	assert.Equal(t, http.StatusOK, <-first)
+	assert.Equal(t, http.StatusOK, <-second)
+	assert.Equal(t, http.StatusOK, <-third)
+}
+
+func TestQueue_Priority(t *testing.T) {
+	config := newConfig()
+	config.Order = "priority"
+
+	next := newBlockingHandler()
+	handler, err := New(t.Context(), next, nil, config, "queue")
+	require.NoError(t, err)
+
+	codes := []<-chan int{serveAsync(handler, newRequest("first", "standard"))}
+	assert.Equal(t, "first", <-next.started)
+
+	for i, req := range []*http.Request{
+		newRequest("sheddable", "sheddable"),
+		newRequest("standard", ""),
+		newRequest("critical", "critical"),
+		newRequest("other critical", "critical"),
+	} {
+		codes = append(codes, serveAsync(handler, req))
+		waitQueued(t, handler, i+1)
+	}
+
+	close(next.release)
+
+	var served []string
+	for range 4 {
+		served = append(served, <-next.started)
+	}
+	assert.Equal(t, []string{"critical", "other critical", "standard", "sheddable"}, served)
+
+	for _, code := range codes {
+		assert.Equal(t, http.StatusOK, <-code)
+	}
+}
+
+func TestQueue_Full(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		order         string
+		expectedFirst int
+		expectedLast  int
+	}{
+		{
+			desc:          "fifo",
+			order:         "fifo",
+			expectedFirst: http.StatusOK,
+			expectedLast:  http.StatusTooManyRequests,
+		},
+		{
+			desc:          "priority",
+			order:         "priority",
+			expectedFirst: http.StatusTooManyRequests,
+			expectedLast:  http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := newConfig()
+			config.MaxSize = 1
+			config.Order = test.order
+
+			next := newBlockingHandler()
+			handler, err := New(t.Context(), next, nil, config, "queue")
+			require.NoError(t, err)
+
+			inFlight := serveAsync(handler, newRequest("in-flight", ""))
+			<-next.started
+
+			first := serveAsync(handler, newRequest("sheddable", "sheddable"))
+			waitQueued(t, handler, 1)
+
+			// A higher priority request takes the place of the sheddable request, if the queue is ordered by priority.
+			last := serveAsync(handler, newRequest("critical", "critical"))
+			if test.expectedFirst == http.StatusTooManyRequests {
+				assert.Equal(t, test.expectedFirst, <-first)
+			} else {
+				assert.Equal(t, test.expectedLast, <-last)
+			}
+
+			close(next.release)
+
+			assert.Equal(t, http.StatusOK, <-inFlight)
+			if test.expectedFirst == http.StatusTooManyRequests {
+				assert.Equal(t, test.expectedLast, <-last)
+			} else {
+				assert.Equal(t, test.expectedFirst, <-first)
+			}
+		})
+	}
+}
+
+func TestQueue_Timeout(t *testing.T) {
+	config := newConfig()
+	config.MaxWait = ptypes.Duration(20 * time.Millisecond)
+
+	next := newBlockingHandler()
+	handler, err := New(t.Context(), next, nil, config, "queue")
+	require.NoError(t, err)
+
+	inFlight := serveAsync(handler, newRequest("in-flight", ""))
+	<-next.started
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, newRequest("queued", ""))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	close(next.release)
+	assert.Equal(t, http.StatusOK, <-inFlight)
+
+	q := handler.(*queue)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	assert.Empty(t, q.sources)
+}
+
+func TestQueue_Sources(t *testing.T) {
+	config := newConfig()
+	config.SourceCriterion = &dynamic.SourceCriterion{RequestHeaderName: "X-Source"}
+
+	next := newBlockingHandler()
+	handler, err := New(t.Context(), next, nil, config, "queue")
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for _, src := range []string{"a", "b"} {
+		req := newRequest(src, "")
+		req.Header.Set("X-Source", src)
+
+		code := serveAsync(handler, req)
+		wg.Go(func() {
+			assert.Equal(t, http.StatusOK, <-code)
+		})
+	}
+
+	// Each source has its own limit of in-flight requests.
+	assert.ElementsMatch(t, []string{"a", "b"}, []string{<-next.started, <-next.started})
+
+	close(next.release)
+	wg.Wait()
+}
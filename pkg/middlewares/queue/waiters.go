@@ -0,0 +1,68 @@
+package queue
+
+import (
+	"github.com/hanzoai/ingress/pkg/middlewares"
+)
+
+// waiter is a request waiting in the queue.
+type waiter struct {
+	priority   middlewares.Priority
+	seq        uint64
+	byPriority bool
+
+	// ready is closed when the request leaves the queue, admitted or not.
+	ready    chan struct{}
+	admitted bool
+	// index is the index of the waiter in the queue, or -1 once it has left the queue.
+	index int
+}
+
+// before reports whether the waiter is to be served before the given one.
+func (w *waiter) before(other *waiter) bool {
+	if w.byPriority && w.priority != other.priority {
+		return w.priority > other.priority
+	}
+
+	return w.seq < other.seq
+}
+
+// waiters is a heap of waiters, the next waiter to be served first.
+type waiters []*waiter
+
+func (w waiters) Len() int { return len(w) }
+
+func (w waiters) Less(i, j int) bool { return w[i].before(w[j]) }
+
+func (w waiters) Swap(i, j int) {
+	w[i], w[j] = w[j], w[i]
+	w[i].index = i
+	w[j].index = j
+}
+
+func (w *waiters) Push(x any) {
+	wt := x.(*waiter)
+	wt.index = len(*w)
+	*w = append(*w, wt)
+}
+
+func (w *waiters) Pop() any {
+	old := *w
+	n := len(old)
+	wt := old[n-1]
+	old[n-1] = nil
+	wt.index = -1
+	*w = old[:n-1]
+	return wt
+}
+
+// worst returns the waiter to be served last.
+func (w waiters) worst() *waiter {
+	var worst *waiter
+	for _, wt := range w {
+		if worst == nil || worst.before(wt) {
+			worst = wt
+		}
+	}
+
+	return worst
+}
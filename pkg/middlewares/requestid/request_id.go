@@ -0,0 +1,154 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hanzoai/ingress/pkg/ip"
+)
+
+const (
+	// DefaultHeaderName is the default name of the header holding the request ID.
+	DefaultHeaderName = "X-Request-Id"
+
+	// GeneratorUUIDv7 generates UUID version 7 request IDs.
+	GeneratorUUIDv7 = "uuidv7"
+	// GeneratorULID generates ULID request IDs.
+	GeneratorULID = "ulid"
+
+	// maxLength is the maximum length of a trusted inbound request ID.
+	maxLength = 128
+)
+
+type key struct{}
+
+// FromContext returns the request ID stored in the given context, if any.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(key{}).(string)
+	return id
+}
+
+// RequestID is an HTTP handler wrapper assigning an ID to each request.
+// The ID is set on the request forwarded to the upstream, on the response, and in the request context.
+// Unless insecure is set, the inbound request ID is only kept when the remote address is one of the trusted ones.
+type RequestID struct {
+	headerName string
+	generate   func() (string, error)
+	insecure   bool
+	ipChecker  *ip.Checker
+	next       http.Handler
+}
+
+// New creates a new RequestID.
+func New(headerName, generator string, insecure bool, trustedIPs []string, next http.Handler) (*RequestID, error) {
+	if headerName == "" {
+		headerName = DefaultHeaderName
+	}
+
+	var generate func() (string, error)
+	switch generator {
+	case "", GeneratorUUIDv7:
+		generate = newUUIDv7
+	case GeneratorULID:
+		generate = newULID
+	default:
+		return nil, fmt.Errorf("unsupported request ID generator: %s", generator)
+	}
+
+	var ipChecker *ip.Checker
+	if len(trustedIPs) > 0 {
+		var err error
+		ipChecker, err = ip.NewChecker(trustedIPs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &RequestID{
+		headerName: http.CanonicalHeaderKey(headerName),
+		generate:   generate,
+		insecure:   insecure,
+		ipChecker:  ipChecker,
+		next:       next,
+	}, nil
+}
+
+func (r *RequestID) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	id := req.Header.Get(r.headerName)
+	if id == "" || !validID(id) || !r.isTrusted(req.RemoteAddr) {
+		var err error
+		id, err = r.generate()
+		if err != nil {
+			// The request is still served, without request ID.
+			req.Header.Del(r.headerName)
+			r.next.ServeHTTP(rw, req)
+			return
+		}
+	}
+
+	req.Header.Set(r.headerName, id)
+	rw.Header().Set(r.headerName, id)
+
+	r.next.ServeHTTP(rw, req.WithContext(context.WithValue(req.Context(), key{}, id)))
+}
+
+func (r *RequestID) isTrusted(remoteAddr string) bool {
+	if r.insecure {
+		return true
+	}
+
+	return r.ipChecker != nil && r.ipChecker.IsAuthorized(remoteAddr) == nil
+}
+
+// validID returns whether the given inbound request ID is made of a reasonable amount of visible ASCII characters.
+func validID(id string) bool {
+	if len(id) > maxLength {
+		return false
+	}
+
+	for i := range len(id) {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newUUIDv7() (string, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+// crockford is the Crockford's Base32 alphabet used by ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// newULID returns a ULID, as defined by https://github.com/ulid/spec.
+func newULID() (string, error) {
+	var data [16]byte
+	binary.BigEndian.PutUint64(data[:8], uint64(time.Now().UnixMilli())<<16)
+	if _, err := rand.Read(data[6:]); err != nil {
+		return "", err
+	}
+
+	// The 128 bits are encoded as 26 characters of 5 bits, the first character only holding 3 bits.
+	var encoded [26]byte
+	hi := binary.BigEndian.Uint64(data[:8])
+	lo := binary.BigEndian.Uint64(data[8:])
+	for i := 25; i >= 0; i-- {
+		encoded[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+
+	return string(encoded[:]), nil
+}
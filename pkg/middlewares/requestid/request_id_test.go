@@ -0,0 +1,107 @@
+package requestid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	uuidv7Regexp = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	ulidRegexp   = regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)
+)
+
+func TestRequestID(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		generator  string
+		insecure   bool
+		trustedIPs []string
+		remoteAddr string
+		inboundID  string
+		expectedID *regexp.Regexp
+	}{
+		{
+			desc:       "generates a UUIDv7 by default",
+			remoteAddr: "10.0.0.1:1234",
+			expectedID: uuidv7Regexp,
+		},
+		{
+			desc:       "generates a ULID",
+			generator:  GeneratorULID,
+			remoteAddr: "10.0.0.1:1234",
+			expectedID: ulidRegexp,
+		},
+		{
+			desc:       "replaces an untrusted inbound ID",
+			remoteAddr: "10.0.0.1:1234",
+			inboundID:  "foo",
+			expectedID: uuidv7Regexp,
+		},
+		{
+			desc:       "keeps an inbound ID from a trusted IP",
+			trustedIPs: []string{"10.0.0.0/8"},
+			remoteAddr: "10.0.0.1:1234",
+			inboundID:  "foo",
+			expectedID: regexp.MustCompile(`^foo$`),
+		},
+		{
+			desc:       "replaces an inbound ID from an untrusted IP",
+			trustedIPs: []string{"10.0.0.0/8"},
+			remoteAddr: "192.168.0.1:1234",
+			inboundID:  "foo",
+			expectedID: uuidv7Regexp,
+		},
+		{
+			desc:       "keeps an inbound ID in insecure mode",
+			insecure:   true,
+			remoteAddr: "192.168.0.1:1234",
+			inboundID:  "foo",
+			expectedID: regexp.MustCompile(`^foo$`),
+		},
+		{
+			desc:       "replaces an invalid inbound ID",
+			insecure:   true,
+			remoteAddr: "192.168.0.1:1234",
+			inboundID:  "foo bar",
+			expectedID: uuidv7Regexp,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var upstreamID, contextID string
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				upstreamID = req.Header.Get(DefaultHeaderName)
+				contextID = FromContext(req.Context())
+			})
+
+			handler, err := New("", test.generator, test.insecure, test.trustedIPs, next)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://foo.bar", nil)
+			req.RemoteAddr = test.remoteAddr
+			if test.inboundID != "" {
+				req.Header.Set(DefaultHeaderName, test.inboundID)
+			}
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Regexp(t, test.expectedID, upstreamID)
+			assert.Equal(t, upstreamID, contextID)
+			assert.Equal(t, upstreamID, rw.Header().Get(DefaultHeaderName))
+		})
+	}
+}
+
+func TestNew_unsupportedGenerator(t *testing.T) {
+	_, err := New("", "snowflake", false, nil, http.NotFoundHandler())
+	require.Error(t, err)
+}
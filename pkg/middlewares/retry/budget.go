@@ -0,0 +1,108 @@
+package retry
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+)
+
+type budgetContextKey struct{}
+
+// Budget limits the number of concurrent retries sent to a service to a percentage of its active requests.
+type Budget struct {
+	percent        float64
+	minConcurrency int64
+
+	active  atomic.Int64
+	retries atomic.Int64
+}
+
+// NewBudget creates a new retry Budget allowing percent of the active requests to be retries,
+// while always allowing minConcurrency concurrent retries.
+func NewBudget(percent float64, minConcurrency int) *Budget {
+	return &Budget{
+		percent:        percent,
+		minConcurrency: int64(minConcurrency),
+	}
+}
+
+// WrapHandler wraps a given http.Handler to count the active requests of the service,
+// and to make the Budget known to the retry middleware in the chain.
+func (b *Budget) WrapHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		b.active.Add(1)
+		defer b.active.Add(-1)
+
+		if holder, ok := req.Context().Value(budgetContextKey{}).(*budgetHolder); ok {
+			holder.budget.Store(b)
+		}
+
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// reserve reserves a slot for a new retry, if it fits in the budget.
+func (b *Budget) reserve() bool {
+	limit := max(b.minConcurrency, int64(b.percent*float64(b.active.Load())/100))
+
+	for {
+		retries := b.retries.Load()
+		if retries >= limit {
+			return false
+		}
+
+		if b.retries.CompareAndSwap(retries, retries+1) {
+			return true
+		}
+	}
+}
+
+// release releases the slot of a retry.
+func (b *Budget) release() {
+	b.retries.Add(-1)
+}
+
+// budgetHolder holds the Budget of the service a request is sent to, once it is known,
+// and the slot reserved in it for the next retry of the request.
+type budgetHolder struct {
+	budget   atomic.Pointer[Budget]
+	reserved *Budget
+}
+
+func withBudgetHolder(ctx context.Context) (context.Context, *budgetHolder) {
+	holder := &budgetHolder{}
+	return context.WithValue(ctx, budgetContextKey{}, holder), holder
+}
+
+// reserve reserves a slot for the next retry in the Budget, if any, and reports whether the retry is allowed.
+// The slot is held from the retry decision, through the backoff, until the retry is done or abandoned.
+func (h *budgetHolder) reserve() bool {
+	budget := h.budget.Load()
+	if budget == nil || h.reserved != nil {
+		return true
+	}
+
+	if !budget.reserve() {
+		return false
+	}
+
+	h.reserved = budget
+	return true
+}
+
+// retrying takes the slot reserved for the retry about to be sent, if any,
+// and returns the function to call once it is done.
+func (h *budgetHolder) retrying() func() {
+	reserved := h.reserved
+	if reserved == nil {
+		return func() {}
+	}
+
+	h.reserved = nil
+	return reserved.release
+}
+
+// release releases the slot reserved for a retry which is abandoned, if any.
+func (h *budgetHolder) release() {
+	h.retrying()()
+}
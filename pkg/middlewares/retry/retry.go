@@ -59,6 +59,12 @@ func ContextShouldRetry(ctx context.Context) ShouldRetry {
 	return f
 }
 
+// WithoutShouldRetry returns a copy of the given context without the ShouldRetry function set by the Retry middleware,
+// for the requests which must not enable/disable the retry mechanism, such as the hedged requests.
+func WithoutShouldRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, shouldRetryContextKey{}, ShouldRetry(nil))
+}
+
 // WrapHandler wraps a given http.Handler to inject the httptrace.ClientTrace in the request context when it is needed
 // by the retry middleware.
 func WrapHandler(next http.Handler) http.Handler {
@@ -173,6 +179,12 @@ func (r *retry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 
 	attempts := 1
 
+	// The retry budget of the service, if any, is made known by the handlers down the chain.
+	budgetCtx, budget := withBudgetHolder(req.Context())
+	req = req.WithContext(budgetCtx)
+	// The slot reserved for a retry which is not sent, because of the timeout or the backoff, is released.
+	defer budget.release()
+
 	initialCtx := req.Context()
 	tracer := tracing.TracerFromContext(initialCtx)
 
@@ -199,6 +211,10 @@ func (r *retry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 
 		remainAttempts := attempts < r.attempts
 
+		if attempts > 1 {
+			defer budget.retrying()()
+		}
+
 		var statusCodes types.HTTPCodeRanges
 		isIdempotent := req.Method != http.MethodPost && req.Method != http.MethodPatch && req.Method != "LOCK"
 		if r.retryNonIdempotentMethod || isIdempotent {
@@ -207,7 +223,7 @@ func (r *retry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 			statusCodes = r.statusCode
 		}
 
-		retryResponseWriter := newResponseWriter(rw, statusCodes, remainAttempts, start, r.timeout)
+		retryResponseWriter := newResponseWriter(rw, statusCodes, remainAttempts, budget, start, r.timeout)
 
 		if reusableReq != nil {
 			req = reusableReq.Clone(req.Context())
@@ -217,7 +233,7 @@ func (r *retry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		if !r.disableRetryOnNetworkError {
 			var shouldRetry ShouldRetry = func(shouldRetry bool) {
 				timedOut := r.timeout > 0 && time.Since(start) >= r.timeout
-				retryResponseWriter.SetShouldRetry(shouldRetry && remainAttempts && !timedOut)
+				retryResponseWriter.SetShouldRetry(shouldRetry && remainAttempts && !timedOut && budget.reserve())
 			}
 			retryReq = req.Clone(context.WithValue(req.Context(), shouldRetryContextKey{}, shouldRetry))
 		}
@@ -269,12 +285,13 @@ func (r *retry) newBackOff() backoff.BackOff {
 	return b
 }
 
-func newResponseWriter(rw http.ResponseWriter, statusCodeRanges types.HTTPCodeRanges, remainAttempts bool, start time.Time, timeout time.Duration) *responseWriter {
+func newResponseWriter(rw http.ResponseWriter, statusCodeRanges types.HTTPCodeRanges, remainAttempts bool, budget *budgetHolder, start time.Time, timeout time.Duration) *responseWriter {
 	return &responseWriter{
 		responseWriter:  rw,
 		headers:         make(http.Header),
 		statusCodeRange: statusCodeRanges,
 		remainAttempts:  remainAttempts,
+		budget:          budget,
 		start:           start,
 		timeout:         timeout,
 	}
@@ -287,6 +304,7 @@ type responseWriter struct {
 	written         bool
 	statusCodeRange types.HTTPCodeRanges
 	remainAttempts  bool
+	budget          *budgetHolder
 	start           time.Time
 	timeout         time.Duration
 }
@@ -341,7 +359,7 @@ func (r *responseWriter) WriteHeader(code int) {
 
 	if r.statusCodeRange != nil {
 		timedOut := r.timeout > 0 && time.Since(r.start) >= r.timeout
-		r.shouldRetry = r.statusCodeRange.Contains(code) && r.remainAttempts && !timedOut
+		r.shouldRetry = r.statusCodeRange.Contains(code) && r.remainAttempts && !timedOut && r.budget.reserve()
 	}
 
 	if r.shouldRetry {
@@ -1,6 +1,7 @@
 package retry
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -8,6 +9,7 @@ import (
 	"net/http/httptrace"
 	"net/textproto"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -765,3 +767,111 @@ func TestRetryHTTPStatusCodesLargeBodyError(t *testing.T) {
 	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
 	assert.Equal(t, 0, retryListener.timesCalled)
 }
+
+func TestRetryBudget(t *testing.T) {
+	testCases := []struct {
+		desc              string
+		minConcurrency    int
+		percent           float64
+		ongoingRetries    int64
+		wantRetryAttempts int
+	}{
+		{
+			desc:              "retries within the minimum concurrency",
+			minConcurrency:    3,
+			wantRetryAttempts: 2,
+		},
+		{
+			desc:              "retries within the percentage of active requests",
+			percent:           100,
+			wantRetryAttempts: 1,
+		},
+		{
+			desc:              "no retry when the budget is exhausted",
+			minConcurrency:    3,
+			ongoingRetries:    3,
+			wantRetryAttempts: 0,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			budget := NewBudget(test.percent, test.minConcurrency)
+			budget.retries.Store(test.ongoingRetries)
+
+			next := budget.WrapHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusServiceUnavailable)
+			}))
+
+			retryListener := &countingRetryListener{}
+			retry, err := New(t.Context(), next, dynamic.Retry{Attempts: 3, Status: []string{"503"}}, retryListener, "ingressTest")
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			retry.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost:3000/ok", nil))
+
+			assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+			assert.Equal(t, test.wantRetryAttempts, retryListener.timesCalled)
+			assert.Equal(t, test.ongoingRetries, budget.retries.Load())
+			assert.Zero(t, budget.active.Load())
+		})
+	}
+}
+
+func TestRetryBudget_reservation(t *testing.T) {
+	budget := NewBudget(0, 1)
+
+	_, first := withBudgetHolder(t.Context())
+	first.budget.Store(budget)
+
+	_, second := withBudgetHolder(t.Context())
+	second.budget.Store(budget)
+
+	// The slot is reserved as soon as the retry is decided, before the backoff.
+	assert.True(t, first.reserve())
+	assert.True(t, first.reserve())
+	assert.False(t, second.reserve())
+	assert.Equal(t, int64(1), budget.retries.Load())
+
+	// The slot is held until the retry is done.
+	done := first.retrying()
+	assert.False(t, second.reserve())
+
+	done()
+	assert.True(t, second.reserve())
+
+	// An abandoned retry releases its slot.
+	second.release()
+	assert.Zero(t, budget.retries.Load())
+}
+
+func TestRetryBudget_backoff(t *testing.T) {
+	budget := NewBudget(0, 1)
+
+	var calls atomic.Int32
+	next := budget.WrapHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		calls.Add(1)
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	retry, err := New(t.Context(), next, dynamic.Retry{Attempts: 2, InitialInterval: ptypes.Duration(100 * time.Millisecond), Status: []string{"503"}}, &countingRetryListener{}, "ingressTest")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		retry.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost:3000/ok", nil).WithContext(ctx))
+	}()
+
+	// The retry waiting for its backoff holds the slot of the budget.
+	require.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, int64(1), budget.retries.Load())
+
+	// The slot is released when the retry is abandoned.
+	cancel()
+	<-done
+	assert.Zero(t, budget.retries.Load())
+}
@@ -0,0 +1,268 @@
+package tap
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hanzoai/ingress/pkg/middlewares/accesslog"
+	muxhttp "github.com/hanzoai/ingress/pkg/muxer/http"
+	otypes "github.com/hanzoai/ingress/pkg/observability/types"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// MaxSubscriptions is the maximum number of concurrent tap subscriptions.
+	MaxSubscriptions = 8
+	// MaxRate is the maximum number of events per second streamed by a subscription.
+	MaxRate = 50
+	// MaxTTL is the maximum lifetime of a subscription.
+	MaxTTL = 10 * time.Minute
+
+	defaultRate = 5
+	defaultTTL  = time.Minute
+
+	eventsBufferSize = 64
+)
+
+// ErrTooManySubscriptions is returned when the maximum number of concurrent subscriptions is reached.
+var ErrTooManySubscriptions = errors.New("too many tap subscriptions")
+
+// Event holds the metadata of a tapped request and its response.
+type Event struct {
+	Time            time.Time     `json:"time"`
+	Router          string        `json:"router"`
+	Method          string        `json:"method"`
+	Host            string        `json:"host"`
+	Path            string        `json:"path"`
+	Protocol        string        `json:"protocol"`
+	ClientAddr      string        `json:"clientAddr,omitempty"`
+	RequestHeaders  http.Header   `json:"requestHeaders,omitempty"`
+	StatusCode      int           `json:"statusCode"`
+	ResponseHeaders http.Header   `json:"responseHeaders,omitempty"`
+	RequestSize     int64         `json:"requestSize"`
+	ResponseSize    int64         `json:"responseSize"`
+	Duration        time.Duration `json:"duration"`
+}
+
+// SubscriptionOptions holds the options of a tap subscription.
+type SubscriptionOptions struct {
+	// Filter is a rule, using the router rule syntax, matching the requests to tap.
+	// All the requests are tapped when empty.
+	Filter string
+	// Rate is the maximum number of events per second.
+	Rate float64
+	// SampleRate is the proportion, between 0.0 and 1.0, of the matching requests to tap.
+	SampleRate float64
+	// TTL is the duration after which the subscription is closed.
+	TTL time.Duration
+}
+
+// Subscription streams the events of the requests handled by a router.
+type Subscription struct {
+	router     string
+	matcher    muxhttp.MatcherFunc
+	limiter    *rate.Limiter
+	sampleRate float64
+
+	events    chan Event
+	done      chan struct{}
+	closeOnce sync.Once
+	timer     *time.Timer
+	hub       *Hub
+}
+
+// Events returns the channel of tapped events.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Done returns a channel closed when the subscription has been closed, on TTL expiration or by Close.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close closes the subscription.
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() {
+		s.timer.Stop()
+		s.hub.unsubscribe(s)
+		close(s.done)
+	})
+}
+
+func (s *Subscription) wants(req *http.Request) bool {
+	if s.matcher != nil && !s.matcher(req) {
+		return false
+	}
+
+	if s.sampleRate < 1 && rand.Float64() >= s.sampleRate {
+		return false
+	}
+
+	return s.limiter.Allow()
+}
+
+func (s *Subscription) publish(event Event) {
+	select {
+	case <-s.done:
+	case s.events <- event:
+	default:
+		// The consumer is too slow, the event is dropped.
+	}
+}
+
+// Hub dispatches the requests handled by routers to the tap subscriptions.
+type Hub struct {
+	fields *otypes.AccessLogFields
+	parser muxhttp.SyntaxParser
+
+	// active is the number of subscriptions,
+	// used to skip any processing when no subscription exists.
+	active atomic.Int32
+
+	mu            sync.RWMutex
+	subscriptions map[string]map[*Subscription]struct{}
+}
+
+// NewHub creates a new Hub.
+// The given access log fields configuration defines which headers are kept, dropped or redacted in the events.
+// When it is nil, all headers are dropped.
+func NewHub(fields *otypes.AccessLogFields) (*Hub, error) {
+	if fields == nil {
+		fields = &otypes.AccessLogFields{}
+		fields.SetDefaults()
+	}
+
+	parser, err := muxhttp.NewSyntaxParser()
+	if err != nil {
+		return nil, fmt.Errorf("creating rule parser: %w", err)
+	}
+
+	return &Hub{
+		fields:        fields,
+		parser:        parser,
+		subscriptions: make(map[string]map[*Subscription]struct{}),
+	}, nil
+}
+
+// Subscribe creates a new subscription to the requests handled by the given router.
+// The subscription must be closed by the caller.
+func (h *Hub) Subscribe(router string, opts SubscriptionOptions) (*Subscription, error) {
+	var matcher muxhttp.MatcherFunc
+	if opts.Filter != "" {
+		var err error
+		matcher, err = h.parser.NewMatcher("v3", opts.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter: %w", err)
+		}
+	}
+
+	limit := opts.Rate
+	if limit <= 0 {
+		limit = defaultRate
+	}
+	limit = min(limit, MaxRate)
+
+	sampleRate := opts.SampleRate
+	if sampleRate <= 0 || sampleRate > 1 {
+		sampleRate = 1
+	}
+
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	ttl = min(ttl, MaxTTL)
+
+	sub := &Subscription{
+		router:     router,
+		matcher:    matcher,
+		limiter:    rate.NewLimiter(rate.Limit(limit), max(1, int(limit))),
+		sampleRate: sampleRate,
+		events:     make(chan Event, eventsBufferSize),
+		done:       make(chan struct{}),
+		hub:        h,
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.active.Load() >= MaxSubscriptions {
+		return nil, ErrTooManySubscriptions
+	}
+
+	if h.subscriptions[router] == nil {
+		h.subscriptions[router] = make(map[*Subscription]struct{})
+	}
+	h.subscriptions[router][sub] = struct{}{}
+	h.active.Add(1)
+
+	sub.timer = time.AfterFunc(ttl, sub.Close)
+
+	return sub, nil
+}
+
+func (h *Hub) unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subscriptions[sub.router][sub]; !ok {
+		return
+	}
+
+	delete(h.subscriptions[sub.router], sub)
+	if len(h.subscriptions[sub.router]) == 0 {
+		delete(h.subscriptions, sub.router)
+	}
+	h.active.Add(-1)
+}
+
+// interested returns the subscriptions of the given router wanting the given request.
+func (h *Hub) interested(router string, req *http.Request) []*Subscription {
+	if h == nil || h.active.Load() == 0 {
+		return nil
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var subs []*Subscription
+	for sub := range h.subscriptions[router] {
+		if sub.wants(req) {
+			subs = append(subs, sub)
+		}
+	}
+
+	return subs
+}
+
+func (h *Hub) redactHeaders(headers http.Header) http.Header {
+	redacted := make(http.Header)
+	for name, values := range headers {
+		switch h.fields.KeepHeader(name) {
+		case otypes.AccessLogKeep:
+			redacted[name] = values
+		case otypes.AccessLogRedact:
+			redacted[name] = []string{"REDACTED"}
+		}
+	}
+
+	if len(redacted) == 0 {
+		return nil
+	}
+
+	return redacted
+}
+
+func (h *Hub) clientAddr(req *http.Request) string {
+	if !h.fields.Keep(accesslog.ClientAddr) {
+		return ""
+	}
+
+	return req.RemoteAddr
+}
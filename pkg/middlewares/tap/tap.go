@@ -0,0 +1,73 @@
+package tap
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/containous/alice"
+	"github.com/hanzoai/ingress/pkg/middlewares"
+	"github.com/hanzoai/ingress/pkg/middlewares/capture"
+)
+
+const typeName = "Tap"
+
+type tap struct {
+	next   http.Handler
+	hub    *Hub
+	router string
+}
+
+// WrapHandler returns an alice.Constructor publishing the requests handled by the given router to the hub subscriptions.
+// It is a passthrough when the hub is nil.
+func WrapHandler(ctx context.Context, hub *Hub, router string) alice.Constructor {
+	return func(next http.Handler) (http.Handler, error) {
+		if hub == nil {
+			return next, nil
+		}
+
+		middlewares.GetLogger(ctx, router, typeName).Debug().Msg("Creating middleware")
+
+		return &tap{
+			next:   next,
+			hub:    hub,
+			router: router,
+		}, nil
+	}
+}
+
+func (t *tap) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	subs := t.hub.interested(t.router, req)
+	if len(subs) == 0 {
+		t.next.ServeHTTP(rw, req)
+		return
+	}
+
+	event := Event{
+		Time:           time.Now(),
+		Router:         t.router,
+		Method:         req.Method,
+		Host:           req.Host,
+		Path:           req.URL.Path,
+		Protocol:       req.Proto,
+		ClientAddr:     t.hub.clientAddr(req),
+		RequestHeaders: t.hub.redactHeaders(req.Header),
+	}
+
+	var capt capture.Capture
+	handler, _ := capture.Wrap(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		t.next.ServeHTTP(rw, req)
+		capt, _ = capture.FromContext(req.Context())
+	}))
+	handler.ServeHTTP(rw, req)
+
+	event.Duration = time.Since(event.Time)
+	event.StatusCode = capt.StatusCode()
+	event.RequestSize = capt.RequestSize()
+	event.ResponseSize = capt.ResponseSize()
+	event.ResponseHeaders = t.hub.redactHeaders(rw.Header())
+
+	for _, sub := range subs {
+		sub.publish(event)
+	}
+}
@@ -0,0 +1,102 @@
+package tap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	otypes "github.com/hanzoai/ingress/pkg/observability/types"
+)
+
+func TestTap(t *testing.T) {
+	hub, err := NewHub(&otypes.AccessLogFields{
+		Headers: &otypes.FieldHeaders{
+			DefaultMode: otypes.AccessLogDrop,
+			Names: map[string]string{
+				"X-Keep":          otypes.AccessLogKeep,
+				"X-Authorization": otypes.AccessLogRedact,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	handler, err := WrapHandler(t.Context(), hub, "foo@file")(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Keep", "response")
+		rw.WriteHeader(http.StatusTeapot)
+		_, _ = rw.Write([]byte("hello"))
+	}))
+	require.NoError(t, err)
+
+	sub, err := hub.Subscribe("foo@file", SubscriptionOptions{Filter: "PathPrefix(`/api`)", Rate: MaxRate})
+	require.NoError(t, err)
+	t.Cleanup(sub.Close)
+
+	other, err := hub.Subscribe("bar@file", SubscriptionOptions{})
+	require.NoError(t, err)
+	t.Cleanup(other.Close)
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/foo", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest(http.MethodGet, "http://example.com/api/foo", nil)
+	req.Header.Set("X-Keep", "request")
+	req.Header.Set("X-Authorization", "secret")
+	req.Header.Set("X-Drop", "dropped")
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusTeapot, rw.Code)
+
+	select {
+	case event := <-sub.Events():
+		assert.Equal(t, "foo@file", event.Router)
+		assert.Equal(t, "/api/foo", event.Path)
+		assert.Equal(t, http.StatusTeapot, event.StatusCode)
+		assert.Equal(t, int64(5), event.ResponseSize)
+		assert.Equal(t, http.Header{"X-Keep": {"request"}, "X-Authorization": {"REDACTED"}}, event.RequestHeaders)
+		assert.Equal(t, http.Header{"X-Keep": {"response"}}, event.ResponseHeaders)
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+	}
+
+	assert.Empty(t, sub.Events())
+	assert.Empty(t, other.Events())
+}
+
+func TestHub_Subscribe(t *testing.T) {
+	hub, err := NewHub(nil)
+	require.NoError(t, err)
+
+	_, err = hub.Subscribe("foo@file", SubscriptionOptions{Filter: "Invalid(`foo`)"})
+	require.Error(t, err)
+
+	var subs []*Subscription
+	for range MaxSubscriptions {
+		sub, err := hub.Subscribe("foo@file", SubscriptionOptions{})
+		require.NoError(t, err)
+		subs = append(subs, sub)
+	}
+
+	_, err = hub.Subscribe("foo@file", SubscriptionOptions{})
+	require.ErrorIs(t, err, ErrTooManySubscriptions)
+
+	subs[0].Close()
+	<-subs[0].Done()
+
+	sub, err := hub.Subscribe("foo@file", SubscriptionOptions{TTL: 10 * time.Millisecond})
+	require.NoError(t, err)
+
+	select {
+	case <-sub.Done():
+	case <-time.After(time.Second):
+		t.Fatal("subscription not closed on TTL expiration")
+	}
+
+	for _, sub := range subs {
+		sub.Close()
+	}
+	assert.Equal(t, int32(0), hub.active.Load())
+}
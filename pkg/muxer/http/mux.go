@@ -59,6 +59,31 @@ func (m *Muxer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	m.defaultHandler.ServeHTTP(rw, req)
 }
 
+// RouteMatch is a route matching a request.
+type RouteMatch struct {
+	// Name is the name of the route, as given to AddNamedRoute.
+	Name     string
+	Priority int
+}
+
+// Explain returns the routes matching the request, in the order they are evaluated by ServeHTTP,
+// the first one being the route the request is forwarded to.
+func (m *Muxer) Explain(req *http.Request) ([]RouteMatch, error) {
+	req, err := withRoutingPath(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []RouteMatch
+	for _, route := range m.routes {
+		if route.matchers.match(req) {
+			matches = append(matches, RouteMatch{Name: route.name, Priority: route.priority})
+		}
+	}
+
+	return matches, nil
+}
+
 // SetDefaultHandler sets the muxer default handler.
 func (m *Muxer) SetDefaultHandler(handler http.Handler) {
 	m.defaultHandler = handler
@@ -72,12 +97,18 @@ func GetRulePriority(rule string) int {
 
 // AddRoute add a new route to the router.
 func (m *Muxer) AddRoute(rule string, syntax string, priority int, handler http.Handler) error {
+	return m.AddNamedRoute("", rule, syntax, priority, handler)
+}
+
+// AddNamedRoute adds a new route to the router, under the given name reported by Explain.
+func (m *Muxer) AddNamedRoute(name, rule, syntax string, priority int, handler http.Handler) error {
 	matchers, err := m.parser.parse(syntax, rule)
 	if err != nil {
 		return fmt.Errorf("error while parsing rule %s: %w", rule, err)
 	}
 
 	m.routes = append(m.routes, &route{
+		name:     name,
 		handler:  handler,
 		matchers: matchers,
 		priority: priority,
@@ -211,6 +242,8 @@ func (r routes) Less(i, j int) bool { return r[i].priority > r[j].priority }
 // route holds the matchers to match HTTP route,
 // and the handler that will serve the request.
 type route struct {
+	// name of the route, reported by Explain.
+	name string
 	// matchers tree structure reflecting the rule.
 	matchers matchersTree
 	// handler responsible for handling the route.
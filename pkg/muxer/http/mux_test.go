@@ -408,6 +408,68 @@ func Test_addRoutePriority(t *testing.T) {
 	}
 }
 
+func TestMuxer_Explain(t *testing.T) {
+	parser, err := NewSyntaxParser()
+	require.NoError(t, err)
+
+	muxer := NewMuxer(parser)
+
+	routes := map[string]string{
+		"api":     "Host(`example.com`) && PathPrefix(`/api`)",
+		"site":    "Host(`example.com`)",
+		"other":   "Host(`example.org`)",
+		"encoded": "PathPrefix(`/foo bar`)",
+	}
+	for name, rule := range routes {
+		require.NoError(t, muxer.AddNamedRoute(name, rule, "", GetRulePriority(rule), http.NotFoundHandler()))
+	}
+
+	require.NoError(t, muxer.AddNamedRoute("catchall", "PathPrefix(`/`)", "", 1, http.NotFoundHandler()))
+
+	testCases := []struct {
+		desc     string
+		target   string
+		expected []RouteMatch
+	}{
+		{
+			desc:   "matching routes by priority",
+			target: "http://example.com/api/users",
+			expected: []RouteMatch{
+				{Name: "api", Priority: 41},
+				{Name: "site", Priority: 19},
+				{Name: "catchall", Priority: 1},
+			},
+		},
+		{
+			desc:   "routing path",
+			target: "http://localhost/foo%20bar",
+			expected: []RouteMatch{
+				{Name: "encoded", Priority: 22},
+				{Name: "catchall", Priority: 1},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, test.target, http.NoBody)
+
+			var (
+				matches    []RouteMatch
+				explainErr error
+			)
+			requestdecorator.New(nil).ServeHTTP(nil, req, func(_ http.ResponseWriter, req *http.Request) {
+				matches, explainErr = muxer.Explain(req)
+			})
+			require.NoError(t, explainErr)
+
+			assert.Equal(t, test.expected, matches)
+		})
+	}
+}
+
 func TestParseDomains(t *testing.T) {
 	testCases := []struct {
 		description   string
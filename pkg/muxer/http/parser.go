@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"net/http"
 	"slices"
 	"strings"
 
@@ -57,6 +58,27 @@ func NewSyntaxParser(opts ...Options) (SyntaxParser, error) {
 	}, nil
 }
 
+// NewMatcher parses the given rule and returns a MatcherFunc evaluating it against requests,
+// outside of any Muxer.
+func (s SyntaxParser) NewMatcher(syntax string, rule string) (MatcherFunc, error) {
+	matchers, err := s.parse(syntax, rule)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(req *http.Request) bool {
+		if getRoutingPath(req) == nil {
+			var err error
+			req, err = withRoutingPath(req)
+			if err != nil {
+				return false
+			}
+		}
+
+		return matchers.match(req)
+	}, nil
+}
+
 func (s SyntaxParser) parse(syntax string, rule string) (matchersTree, error) {
 	parser, ok := s.parsers[syntax]
 	if !ok {
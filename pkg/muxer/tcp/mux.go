@@ -27,6 +27,11 @@ func NewConnData(serverName string, conn tcp.WriteCloser, alpnProtos []string) (
 		return ConnData{}, fmt.Errorf("error while parsing remote address %q: %w", conn.RemoteAddr().String(), err)
 	}
 
+	return NewConnDataFromIP(serverName, remoteIP, alpnProtos), nil
+}
+
+// NewConnDataFromIP builds a connData struct from the given parameters, without any underlying connection.
+func NewConnDataFromIP(serverName, remoteIP string, alpnProtos []string) ConnData {
 	// as per https://datatracker.ietf.org/doc/html/rfc6066:
 	// > The hostname is represented as a byte string using ASCII encoding without a trailing dot.
 	// so there is no need to trim a potential trailing dot
@@ -36,7 +41,7 @@ func NewConnData(serverName string, conn tcp.WriteCloser, alpnProtos []string) (
 		serverName: types.CanonicalDomain(serverName),
 		remoteIP:   remoteIP,
 		alpnProtos: alpnProtos,
-	}, nil
+	}
 }
 
 // Muxer defines a muxer that handles TCP routing with rules.
@@ -86,6 +91,28 @@ func (m *Muxer) Match(meta ConnData) (tcp.Handler, bool) {
 	return nil, false
 }
 
+// RouteMatch is a route matching the connection metadata.
+type RouteMatch struct {
+	// Name is the name of the route, as given to AddNamedRoute.
+	Name     string
+	Priority int
+	// CatchAll reports whether the route rule is exactly HostSNI(*).
+	CatchAll bool
+}
+
+// Explain returns the routes matching the connection metadata, in the order they are evaluated by Match,
+// the first one being the route the connection is forwarded to.
+func (m *Muxer) Explain(meta ConnData) []RouteMatch {
+	var matches []RouteMatch
+	for _, route := range m.routes {
+		if route.matchers.match(meta) {
+			matches = append(matches, RouteMatch{Name: route.name, Priority: route.priority, CatchAll: route.catchAll})
+		}
+	}
+
+	return matches
+}
+
 // GetRulePriority computes the priority for a given rule.
 // The priority is calculated using the length of rule.
 // There is a special case where the HostSNI(`*`) has a priority of -1.
@@ -121,6 +148,12 @@ func GetRulePriority(rule string) int {
 // AddRoute adds a new route, associated to the given handler, at the given
 // priority, to the muxer.
 func (m *Muxer) AddRoute(rule string, syntax string, priority int, handler tcp.Handler) error {
+	return m.AddNamedRoute("", rule, syntax, priority, handler)
+}
+
+// AddNamedRoute adds a new route, associated to the given handler, at the given
+// priority, to the muxer, under the given name reported by Explain.
+func (m *Muxer) AddNamedRoute(name, rule, syntax string, priority int, handler tcp.Handler) error {
 	var parse any
 	var err error
 	var matcherFuncs map[string]func(*matchersTree, ...string) error
@@ -161,6 +194,7 @@ func (m *Muxer) AddRoute(rule string, syntax string, priority int, handler tcp.H
 	}
 
 	newRoute := &route{
+		name:     name,
 		handler:  handler,
 		matchers: matchers,
 		catchAll: catchAll,
@@ -222,6 +256,8 @@ func (r routes) Less(i, j int) bool { return r[i].priority > r[j].priority }
 // route holds the matchers to match TCP route,
 // and the handler that will serve the connection.
 type route struct {
+	// name of the route, reported by Explain.
+	name string
 	// matchers tree structure reflecting the rule.
 	matchers matchersTree
 	// handler responsible for handling the route.
@@ -458,6 +458,30 @@ func Test_Priority(t *testing.T) {
 	}
 }
 
+func TestMuxer_Explain(t *testing.T) {
+	muxer, err := NewMuxer()
+	require.NoError(t, err)
+
+	routes := map[string]string{
+		"db":       "HostSNI(`db.example.com`)",
+		"internal": "HostSNI(`db.example.com`) && ClientIP(`10.0.0.0/8`)",
+		"other":    "HostSNI(`example.org`)",
+		"catchall": "HostSNI(`*`)",
+	}
+	for name, rule := range routes {
+		require.NoError(t, muxer.AddNamedRoute(name, rule, "", GetRulePriority(rule), tcp.HandlerFunc(func(tcp.WriteCloser) {})))
+	}
+
+	matches := muxer.Explain(NewConnDataFromIP("db.example.com", "10.1.2.3", nil))
+
+	expected := []RouteMatch{
+		{Name: "internal", Priority: 51},
+		{Name: "db", Priority: 25},
+		{Name: "catchall", Priority: -1, CatchAll: true},
+	}
+	assert.Equal(t, expected, matches)
+}
+
 func TestGetRulePriority(t *testing.T) {
 	testCases := []struct {
 		desc     string
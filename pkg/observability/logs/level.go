@@ -0,0 +1,314 @@
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// acmeResolverSuffix is the suffix of the provider name used by the ACME resolvers in the logs.
+const acmeResolverSuffix = ".acme"
+
+// Levels holds the log levels, globally and per component.
+type Levels struct {
+	// Level is the global log level.
+	Level string `json:"level"`
+	// Providers holds the log levels by provider name.
+	Providers map[string]string `json:"providers,omitempty"`
+	// Routers holds the log levels by router name.
+	Routers map[string]string `json:"routers,omitempty"`
+	// Services holds the log levels by service name.
+	Services map[string]string `json:"services,omitempty"`
+	// ACMEResolvers holds the log levels by ACME certificates resolver name.
+	ACMEResolvers map[string]string `json:"acmeResolvers,omitempty"`
+}
+
+// levels is the parsed representation of Levels.
+type levels struct {
+	global    zerolog.Level
+	providers map[string]zerolog.Level
+	routers   map[string]zerolog.Level
+	services  map[string]zerolog.Level
+}
+
+// min returns the lowest level of the global level and the component levels.
+func (l levels) min() zerolog.Level {
+	lowest := l.global
+	for _, byName := range []map[string]zerolog.Level{l.providers, l.routers, l.services} {
+		for _, level := range byName {
+			lowest = min(lowest, level)
+		}
+	}
+
+	return lowest
+}
+
+func (l levels) hasComponents() bool {
+	return len(l.providers) > 0 || len(l.routers) > 0 || len(l.services) > 0
+}
+
+// LevelController controls the log level at runtime, globally and per component.
+// The log events of a component are identified by their ProviderName, RouterName and ServiceName fields.
+type LevelController struct {
+	base zerolog.Level
+
+	mu       sync.RWMutex
+	current  levels
+	revertAt time.Time
+	timer    *time.Timer
+
+	// components is true when component levels are set,
+	// used to skip the inspection of the events otherwise.
+	components atomic.Bool
+}
+
+// NewLevelController creates a new LevelController, using the given level as global level,
+// and sets the zerolog global level accordingly.
+func NewLevelController(level zerolog.Level) *LevelController {
+	c := &LevelController{base: level}
+	c.apply(levels{global: level})
+
+	return c
+}
+
+// Levels returns the current log levels, and the time at which they are reverted, if any.
+func (c *LevelController) Levels() (Levels, time.Time) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	result := Levels{
+		Level:     c.current.global.String(),
+		Providers: make(map[string]string),
+		Routers:   formatLevels(c.current.routers),
+		Services:  formatLevels(c.current.services),
+	}
+
+	for name, level := range c.current.providers {
+		if resolver, ok := strings.CutSuffix(name, acmeResolverSuffix); ok {
+			if result.ACMEResolvers == nil {
+				result.ACMEResolvers = make(map[string]string)
+			}
+			result.ACMEResolvers[resolver] = level.String()
+			continue
+		}
+
+		result.Providers[name] = level.String()
+	}
+
+	if len(result.Providers) == 0 {
+		result.Providers = nil
+	}
+
+	return result, c.revertAt
+}
+
+// SetLevels sets the log levels.
+// The global level defined at startup is used when the global level is empty.
+// When revertAfter is positive, the previous log levels are restored after this duration.
+func (c *LevelController) SetLevels(config Levels, revertAfter time.Duration) error {
+	next, err := parseLevels(config, c.base)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	c.revertAt = time.Time{}
+
+	previous := c.current
+	c.apply(next)
+
+	if revertAfter > 0 {
+		c.revertAt = time.Now().Add(revertAfter)
+		c.timer = time.AfterFunc(revertAfter, func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+
+			c.timer = nil
+			c.revertAt = time.Time{}
+			c.apply(previous)
+		})
+	}
+
+	return nil
+}
+
+// Reset restores the log level defined at startup and removes the component levels.
+func (c *LevelController) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	c.revertAt = time.Time{}
+
+	c.apply(levels{global: c.base})
+}
+
+// Modified returns whether the current log levels differ from the ones defined at startup.
+func (c *LevelController) Modified() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.current.global != c.base || c.current.hasComponents()
+}
+
+// apply must be called with the lock held, or before the controller is shared.
+func (c *LevelController) apply(next levels) {
+	c.current = next
+	c.components.Store(next.hasComponents())
+
+	// The zerolog global level is the lowest level, to let the component events reach the hook.
+	zerolog.SetGlobalLevel(next.min())
+}
+
+// Run implements zerolog.Hook.
+// It discards the events which are below the level of their component,
+// or below the global level when they do not belong to any component with a level.
+func (c *LevelController) Run(e *zerolog.Event, level zerolog.Level, _ string) {
+	if level == zerolog.Disabled || !c.components.Load() {
+		return
+	}
+
+	c.mu.RLock()
+	current := c.current
+	c.mu.RUnlock()
+
+	if level < componentLevel(e, current) {
+		e.Discard()
+	}
+}
+
+// componentLevel returns the level of the component of the given event,
+// the lowest one when the event belongs to several components with a level,
+// and the global level when it does not belong to any of them.
+func componentLevel(e *zerolog.Event, current levels) zerolog.Level {
+	// See otelLoggerHook for the rationale of this workaround.
+	var fields struct {
+		ProviderName string `json:"providerName"`
+		RouterName   string `json:"routerName"`
+		ServiceName  string `json:"serviceName"`
+	}
+	eventBuffer := fmt.Sprintf("%s}", reflect.ValueOf(e).Elem().FieldByName("buf"))
+	if err := json.Unmarshal([]byte(eventBuffer), &fields); err != nil {
+		return current.global
+	}
+
+	matched := false
+	lowest := zerolog.Disabled
+	for _, component := range []struct {
+		name   string
+		byName map[string]zerolog.Level
+	}{
+		{name: fields.ProviderName, byName: current.providers},
+		{name: fields.RouterName, byName: current.routers},
+		{name: fields.ServiceName, byName: current.services},
+	} {
+		if level, ok := component.byName[component.name]; ok {
+			matched = true
+			lowest = min(lowest, level)
+		}
+	}
+
+	if !matched {
+		return current.global
+	}
+
+	return lowest
+}
+
+func parseLevels(config Levels, base zerolog.Level) (levels, error) {
+	result := levels{global: base}
+
+	var err error
+	if config.Level != "" {
+		result.global, err = parseLevel(config.Level)
+		if err != nil {
+			return levels{}, err
+		}
+	}
+
+	result.providers, err = parseComponentLevels("provider", config.Providers, "")
+	if err != nil {
+		return levels{}, err
+	}
+
+	resolvers, err := parseComponentLevels("ACME resolver", config.ACMEResolvers, acmeResolverSuffix)
+	if err != nil {
+		return levels{}, err
+	}
+	for name, level := range resolvers {
+		if result.providers == nil {
+			result.providers = make(map[string]zerolog.Level)
+		}
+		result.providers[name] = level
+	}
+
+	result.routers, err = parseComponentLevels("router", config.Routers, "")
+	if err != nil {
+		return levels{}, err
+	}
+
+	result.services, err = parseComponentLevels("service", config.Services, "")
+	if err != nil {
+		return levels{}, err
+	}
+
+	return result, nil
+}
+
+func parseComponentLevels(kind string, config map[string]string, suffix string) (map[string]zerolog.Level, error) {
+	if len(config) == 0 {
+		return nil, nil
+	}
+
+	result := make(map[string]zerolog.Level, len(config))
+	for name, value := range config {
+		level, err := parseLevel(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", kind, name, err)
+		}
+		result[name+suffix] = level
+	}
+
+	return result, nil
+}
+
+func parseLevel(value string) (zerolog.Level, error) {
+	level, err := zerolog.ParseLevel(strings.ToLower(value))
+	if err != nil {
+		return zerolog.NoLevel, fmt.Errorf("invalid log level %q: %w", value, err)
+	}
+
+	if level == zerolog.NoLevel {
+		return zerolog.NoLevel, fmt.Errorf("invalid log level %q", value)
+	}
+
+	return level, nil
+}
+
+func formatLevels(byName map[string]zerolog.Level) map[string]string {
+	if len(byName) == 0 {
+		return nil
+	}
+
+	result := make(map[string]string, len(byName))
+	for name, level := range byName {
+		result[name] = level.String()
+	}
+
+	return result
+}
@@ -0,0 +1,111 @@
+package logs
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelController(t *testing.T) {
+	globalLevel := zerolog.GlobalLevel()
+	t.Cleanup(func() { zerolog.SetGlobalLevel(globalLevel) })
+
+	testCases := []struct {
+		desc     string
+		levels   Levels
+		log      func(logger zerolog.Logger)
+		expected bool
+	}{
+		{
+			desc:     "below the static level",
+			log:      func(logger zerolog.Logger) { logger.Debug().Msg("test") },
+			expected: false,
+		},
+		{
+			desc:     "above the static level",
+			log:      func(logger zerolog.Logger) { logger.Error().Msg("test") },
+			expected: true,
+		},
+		{
+			desc:     "global level raised",
+			levels:   Levels{Level: "debug"},
+			log:      func(logger zerolog.Logger) { logger.Debug().Msg("test") },
+			expected: true,
+		},
+		{
+			desc:     "provider level raised",
+			levels:   Levels{Providers: map[string]string{"docker": "debug"}},
+			log:      func(logger zerolog.Logger) { logger.Debug().Str(ProviderName, "docker").Msg("test") },
+			expected: true,
+		},
+		{
+			desc:     "other provider",
+			levels:   Levels{Providers: map[string]string{"docker": "debug"}},
+			log:      func(logger zerolog.Logger) { logger.Debug().Str(ProviderName, "file").Msg("test") },
+			expected: false,
+		},
+		{
+			desc:   "router level raised through a logger context",
+			levels: Levels{Routers: map[string]string{"foo@file": "trace"}},
+			log: func(logger zerolog.Logger) {
+				routerLogger := logger.With().Str(RouterName, "foo@file").Logger()
+				routerLogger.Trace().Msg("test")
+			},
+			expected: true,
+		},
+		{
+			desc:     "service level lowered",
+			levels:   Levels{Level: "debug", Services: map[string]string{"foo@file": "error"}},
+			log:      func(logger zerolog.Logger) { logger.Info().Str(ServiceName, "foo@file").Msg("test") },
+			expected: false,
+		},
+		{
+			desc:     "ACME resolver level raised",
+			levels:   Levels{ACMEResolvers: map[string]string{"le": "debug"}},
+			log:      func(logger zerolog.Logger) { logger.Debug().Str(ProviderName, "le.acme").Msg("test") },
+			expected: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			controller := NewLevelController(zerolog.WarnLevel)
+			require.NoError(t, controller.SetLevels(test.levels, 0))
+
+			var buf bytes.Buffer
+			test.log(zerolog.New(&buf).Hook(controller))
+
+			assert.Equal(t, test.expected, buf.Len() > 0)
+		})
+	}
+}
+
+func TestLevelController_revert(t *testing.T) {
+	globalLevel := zerolog.GlobalLevel()
+	t.Cleanup(func() { zerolog.SetGlobalLevel(globalLevel) })
+
+	controller := NewLevelController(zerolog.WarnLevel)
+
+	err := controller.SetLevels(Levels{Level: "invalid"}, 0)
+	require.Error(t, err)
+
+	err = controller.SetLevels(Levels{Level: "debug", Routers: map[string]string{"foo@file": "trace"}}, 50*time.Millisecond)
+	require.NoError(t, err)
+
+	levels, revertAt := controller.Levels()
+	assert.Equal(t, Levels{Level: "debug", Routers: map[string]string{"foo@file": "trace"}}, levels)
+	assert.False(t, revertAt.IsZero())
+	assert.True(t, controller.Modified())
+	assert.Equal(t, zerolog.TraceLevel, zerolog.GlobalLevel())
+
+	assert.Eventually(t, func() bool { return !controller.Modified() }, time.Second, 10*time.Millisecond)
+
+	levels, revertAt = controller.Levels()
+	assert.Equal(t, Levels{Level: "warn"}, levels)
+	assert.True(t, revertAt.IsZero())
+	assert.Equal(t, zerolog.WarnLevel, zerolog.GlobalLevel())
+}
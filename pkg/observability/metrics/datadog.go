@@ -34,6 +34,13 @@ const (
 
 	ddTLSCertsNotAfterTimestampName = "tls.certs.notAfterTimestamp"
 
+	ddProviderUpName                  = "provider.up"
+	ddProviderLastUpdateTimestampName = "provider.lastUpdateTimestamp"
+	ddProviderErrorsName              = "provider.errors.total"
+
+	ddMiddlewareQueueDepthName        = "middleware.queue.depth"
+	ddMiddlewareQueueWaitDurationName = "middleware.queue.wait.duration"
+
 	ddEntryPointReqsName        = "entrypoint.request.total"
 	ddEntryPointReqsTLSName     = "entrypoint.request.tls.total"
 	ddEntryPointReqDurationName = "entrypoint.request.duration"
@@ -50,6 +57,8 @@ const (
 	ddServiceReqsTLSName      = "service.request.tls.total"
 	ddServiceReqsDurationName = "service.request.duration"
 	ddServiceRetriesName      = "service.retries.total"
+	ddServiceHedgesName       = "service.hedges.total"
+	ddServiceHedgesWonName    = "service.hedges.won.total"
 	ddServiceServerUpName     = "service.server.up"
 	ddServiceReqsBytesName    = "service.requests.bytes.total"
 	ddServiceRespsBytesName   = "service.responses.bytes.total"
@@ -75,7 +84,12 @@ func RegisterDatadog(ctx context.Context, config *otypes.Datadog) Registry {
 		lastConfigReloadSuccessGauge:   datadogClient.NewGauge(ddLastConfigReloadSuccessName),
 		openConnectionsGauge:           datadogClient.NewGauge(ddOpenConnsName),
 		tlsCertsNotAfterTimestampGauge: datadogClient.NewGauge(ddTLSCertsNotAfterTimestampName),
+		providerUpGauge:                datadogClient.NewGauge(ddProviderUpName),
+		providerLastUpdateGauge:        datadogClient.NewGauge(ddProviderLastUpdateTimestampName),
+		providerErrorsCounter:          datadogClient.NewCounter(ddProviderErrorsName, 1.0),
+		middlewareQueueDepthGauge:      datadogClient.NewGauge(ddMiddlewareQueueDepthName),
 	}
+	registry.middlewareQueueWaitHistogram, _ = NewHistogramWithScale(datadogClient.NewHistogram(ddMiddlewareQueueWaitDurationName, 1.0), time.Second)
 
 	if config.AddEntryPointsLabels {
 		registry.epEnabled = config.AddEntryPointsLabels
@@ -101,6 +115,8 @@ func RegisterDatadog(ctx context.Context, config *otypes.Datadog) Registry {
 		registry.serviceReqsTLSCounter = datadogClient.NewCounter(ddServiceReqsTLSName, 1.0)
 		registry.serviceReqDurationHistogram, _ = NewHistogramWithScale(datadogClient.NewHistogram(ddServiceReqsDurationName, 1.0), time.Second)
 		registry.serviceRetriesCounter = datadogClient.NewCounter(ddServiceRetriesName, 1.0)
+		registry.serviceHedgesCounter = datadogClient.NewCounter(ddServiceHedgesName, 1.0)
+		registry.serviceHedgesWonCounter = datadogClient.NewCounter(ddServiceHedgesWonName, 1.0)
 		registry.serviceServerUpGauge = datadogClient.NewGauge(ddServiceServerUpName)
 		registry.serviceReqsBytesCounter = datadogClient.NewCounter(ddServiceReqsBytesName, 1.0)
 		registry.serviceRespsBytesCounter = datadogClient.NewCounter(ddServiceRespsBytesName, 1.0)
@@ -98,6 +98,10 @@ func testDatadogRegistry(t *testing.T, metricsPrefix string, datadogRegistry Reg
 
 		metricsPrefix + ".tls.certs.notAfterTimestamp:1.000000|g|#key:value\n",
 
+		metricsPrefix + ".provider.up:1.000000|g|#provider:docker\n",
+		metricsPrefix + ".provider.lastUpdateTimestamp:1.000000|g|#provider:docker\n",
+		metricsPrefix + ".provider.errors.total:1.000000|c|#provider:docker\n",
+
 		metricsPrefix + ".entrypoint.request.total:1.000000|c|#entrypoint:test\n",
 		metricsPrefix + ".entrypoint.request.tls.total:1.000000|c|#entrypoint:test,tls_version:foo,tls_cipher:bar\n",
 		metricsPrefix + ".entrypoint.request.duration:10000.000000|h|#entrypoint:test\n",
@@ -129,6 +133,10 @@ func testDatadogRegistry(t *testing.T, metricsPrefix string, datadogRegistry Reg
 
 		datadogRegistry.TLSCertsNotAfterTimestampGauge().With("key", "value").Set(1)
 
+		datadogRegistry.ProviderUpGauge().With("provider", "docker").Set(1)
+		datadogRegistry.ProviderLastUpdateGauge().With("provider", "docker").Set(1)
+		datadogRegistry.ProviderErrorsCounter().With("provider", "docker").Add(1)
+
 		datadogRegistry.EntryPointReqsCounter().With(nil, "entrypoint", "test").Add(1)
 		datadogRegistry.EntryPointReqsTLSCounter().With("entrypoint", "test", "tls_version", "foo", "tls_cipher", "bar").Add(1)
 		datadogRegistry.EntryPointReqDurationHistogram().With("entrypoint", "test").Observe(10000)
@@ -31,6 +31,13 @@ const (
 
 	influxDBTLSCertsNotAfterTimestampName = "ingress.tls.certs.notAfterTimestamp"
 
+	influxDBProviderUpName                  = "ingress.provider.up"
+	influxDBProviderLastUpdateTimestampName = "ingress.provider.lastUpdateTimestamp"
+	influxDBProviderErrorsName              = "ingress.provider.errors.total"
+
+	influxDBMiddlewareQueueDepthName        = "ingress.middleware.queue.depth"
+	influxDBMiddlewareQueueWaitDurationName = "ingress.middleware.queue.wait.duration"
+
 	influxDBEntryPointReqsName        = "ingress.entrypoint.requests.total"
 	influxDBEntryPointReqsTLSName     = "ingress.entrypoint.requests.tls.total"
 	influxDBEntryPointReqDurationName = "ingress.entrypoint.request.duration"
@@ -47,6 +54,8 @@ const (
 	influxDBServiceReqsTLSName      = "ingress.service.requests.tls.total"
 	influxDBServiceReqsDurationName = "ingress.service.request.duration"
 	influxDBServiceRetriesTotalName = "ingress.service.retries.total"
+	influxDBServiceHedgesTotalName  = "ingress.service.hedges.total"
+	influxDBServiceHedgesWonName    = "ingress.service.hedges.won.total"
 	influxDBServiceServerUpName     = "ingress.service.server.up"
 	influxDBServiceReqsBytesName    = "ingress.service.requests.bytes.total"
 	influxDBServiceRespsBytesName   = "ingress.service.responses.bytes.total"
@@ -84,7 +93,12 @@ func RegisterInfluxDB2(ctx context.Context, config *otypes.InfluxDB2) Registry {
 		lastConfigReloadSuccessGauge:   influxDB2Store.NewGauge(influxDBLastConfigReloadSuccessName),
 		openConnectionsGauge:           influxDB2Store.NewGauge(influxDBOpenConnsName),
 		tlsCertsNotAfterTimestampGauge: influxDB2Store.NewGauge(influxDBTLSCertsNotAfterTimestampName),
+		providerUpGauge:                influxDB2Store.NewGauge(influxDBProviderUpName),
+		providerLastUpdateGauge:        influxDB2Store.NewGauge(influxDBProviderLastUpdateTimestampName),
+		providerErrorsCounter:          influxDB2Store.NewCounter(influxDBProviderErrorsName),
+		middlewareQueueDepthGauge:      influxDB2Store.NewGauge(influxDBMiddlewareQueueDepthName),
 	}
+	registry.middlewareQueueWaitHistogram, _ = NewHistogramWithScale(influxDB2Store.NewHistogram(influxDBMiddlewareQueueWaitDurationName), time.Second)
 
 	if config.AddEntryPointsLabels {
 		registry.epEnabled = config.AddEntryPointsLabels
@@ -110,6 +124,8 @@ func RegisterInfluxDB2(ctx context.Context, config *otypes.InfluxDB2) Registry {
 		registry.serviceReqsTLSCounter = influxDB2Store.NewCounter(influxDBServiceReqsTLSName)
 		registry.serviceReqDurationHistogram, _ = NewHistogramWithScale(influxDB2Store.NewHistogram(influxDBServiceReqsDurationName), time.Second)
 		registry.serviceRetriesCounter = influxDB2Store.NewCounter(influxDBServiceRetriesTotalName)
+		registry.serviceHedgesCounter = influxDB2Store.NewCounter(influxDBServiceHedgesTotalName)
+		registry.serviceHedgesWonCounter = influxDB2Store.NewCounter(influxDBServiceHedgesWonName)
 		registry.serviceServerUpGauge = influxDB2Store.NewGauge(influxDBServiceServerUpName)
 		registry.serviceReqsBytesCounter = influxDB2Store.NewCounter(influxDBServiceReqsBytesName)
 		registry.serviceRespsBytesCounter = influxDB2Store.NewCounter(influxDBServiceRespsBytesName)
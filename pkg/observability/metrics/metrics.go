@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -29,6 +30,17 @@ type Registry interface {
 
 	TLSCertsNotAfterTimestampGauge() metrics.Gauge
 
+	// provider metrics
+
+	ProviderUpGauge() metrics.Gauge
+	ProviderLastUpdateGauge() metrics.Gauge
+	ProviderErrorsCounter() metrics.Counter
+
+	// middleware metrics
+
+	MiddlewareQueueDepthGauge() metrics.Gauge
+	MiddlewareQueueWaitHistogram() ScalableHistogram
+
 	// entry point metrics
 
 	EntryPointReqsCounter() CounterWithHeaders
@@ -51,6 +63,8 @@ type Registry interface {
 	ServiceReqsTLSCounter() metrics.Counter
 	ServiceReqDurationHistogram() ScalableHistogram
 	ServiceRetriesCounter() metrics.Counter
+	ServiceHedgesCounter() metrics.Counter
+	ServiceHedgesWonCounter() metrics.Counter
 	ServiceServerUpGauge() metrics.Gauge
 	ServiceReqsBytesCounter() metrics.Counter
 	ServiceRespsBytesCounter() metrics.Counter
@@ -70,6 +84,11 @@ func NewMultiRegistry(registries []Registry) Registry {
 	var lastConfigReloadSuccessGauge []metrics.Gauge
 	var openConnectionsGauge []metrics.Gauge
 	var tlsCertsNotAfterTimestampGauge []metrics.Gauge
+	var providerUpGauge []metrics.Gauge
+	var providerLastUpdateGauge []metrics.Gauge
+	var providerErrorsCounter []metrics.Counter
+	var middlewareQueueDepthGauge []metrics.Gauge
+	var middlewareQueueWaitHistogram []ScalableHistogram
 	var entryPointReqsCounter []CounterWithHeaders
 	var entryPointReqsTLSCounter []metrics.Counter
 	var entryPointReqDurationHistogram []ScalableHistogram
@@ -84,6 +103,8 @@ func NewMultiRegistry(registries []Registry) Registry {
 	var serviceReqsTLSCounter []metrics.Counter
 	var serviceReqDurationHistogram []ScalableHistogram
 	var serviceRetriesCounter []metrics.Counter
+	var serviceHedgesCounter []metrics.Counter
+	var serviceHedgesWonCounter []metrics.Counter
 	var serviceServerUpGauge []metrics.Gauge
 	var serviceReqsBytesCounter []metrics.Counter
 	var serviceRespsBytesCounter []metrics.Counter
@@ -101,6 +122,21 @@ func NewMultiRegistry(registries []Registry) Registry {
 		if r.TLSCertsNotAfterTimestampGauge() != nil {
 			tlsCertsNotAfterTimestampGauge = append(tlsCertsNotAfterTimestampGauge, r.TLSCertsNotAfterTimestampGauge())
 		}
+		if r.ProviderUpGauge() != nil {
+			providerUpGauge = append(providerUpGauge, r.ProviderUpGauge())
+		}
+		if r.ProviderLastUpdateGauge() != nil {
+			providerLastUpdateGauge = append(providerLastUpdateGauge, r.ProviderLastUpdateGauge())
+		}
+		if r.ProviderErrorsCounter() != nil {
+			providerErrorsCounter = append(providerErrorsCounter, r.ProviderErrorsCounter())
+		}
+		if r.MiddlewareQueueDepthGauge() != nil {
+			middlewareQueueDepthGauge = append(middlewareQueueDepthGauge, r.MiddlewareQueueDepthGauge())
+		}
+		if r.MiddlewareQueueWaitHistogram() != nil {
+			middlewareQueueWaitHistogram = append(middlewareQueueWaitHistogram, r.MiddlewareQueueWaitHistogram())
+		}
 		if r.EntryPointReqsCounter() != nil {
 			entryPointReqsCounter = append(entryPointReqsCounter, r.EntryPointReqsCounter())
 		}
@@ -143,6 +179,12 @@ func NewMultiRegistry(registries []Registry) Registry {
 		if r.ServiceRetriesCounter() != nil {
 			serviceRetriesCounter = append(serviceRetriesCounter, r.ServiceRetriesCounter())
 		}
+		if r.ServiceHedgesCounter() != nil {
+			serviceHedgesCounter = append(serviceHedgesCounter, r.ServiceHedgesCounter())
+		}
+		if r.ServiceHedgesWonCounter() != nil {
+			serviceHedgesWonCounter = append(serviceHedgesWonCounter, r.ServiceHedgesWonCounter())
+		}
 		if r.ServiceServerUpGauge() != nil {
 			serviceServerUpGauge = append(serviceServerUpGauge, r.ServiceServerUpGauge())
 		}
@@ -156,12 +198,17 @@ func NewMultiRegistry(registries []Registry) Registry {
 
 	return &standardRegistry{
 		epEnabled:                      len(entryPointReqsCounter) > 0 || len(entryPointReqDurationHistogram) > 0,
-		svcEnabled:                     len(serviceReqsCounter) > 0 || len(serviceReqDurationHistogram) > 0 || len(serviceRetriesCounter) > 0 || len(serviceServerUpGauge) > 0,
+		svcEnabled:                     len(serviceReqsCounter) > 0 || len(serviceReqDurationHistogram) > 0 || len(serviceRetriesCounter) > 0 || len(serviceHedgesCounter) > 0 || len(serviceServerUpGauge) > 0,
 		routerEnabled:                  len(routerReqsCounter) > 0 || len(routerReqDurationHistogram) > 0,
 		configReloadsCounter:           multi.NewCounter(configReloadsCounter...),
 		lastConfigReloadSuccessGauge:   multi.NewGauge(lastConfigReloadSuccessGauge...),
 		openConnectionsGauge:           multi.NewGauge(openConnectionsGauge...),
 		tlsCertsNotAfterTimestampGauge: multi.NewGauge(tlsCertsNotAfterTimestampGauge...),
+		providerUpGauge:                multi.NewGauge(providerUpGauge...),
+		providerLastUpdateGauge:        multi.NewGauge(providerLastUpdateGauge...),
+		providerErrorsCounter:          multi.NewCounter(providerErrorsCounter...),
+		middlewareQueueDepthGauge:      multi.NewGauge(middlewareQueueDepthGauge...),
+		middlewareQueueWaitHistogram:   MultiHistogram(middlewareQueueWaitHistogram),
 		entryPointReqsCounter:          NewMultiCounterWithHeaders(entryPointReqsCounter...),
 		entryPointReqsTLSCounter:       multi.NewCounter(entryPointReqsTLSCounter...),
 		entryPointReqDurationHistogram: MultiHistogram(entryPointReqDurationHistogram),
@@ -176,6 +223,8 @@ func NewMultiRegistry(registries []Registry) Registry {
 		serviceReqsTLSCounter:          multi.NewCounter(serviceReqsTLSCounter...),
 		serviceReqDurationHistogram:    MultiHistogram(serviceReqDurationHistogram),
 		serviceRetriesCounter:          multi.NewCounter(serviceRetriesCounter...),
+		serviceHedgesCounter:           multi.NewCounter(serviceHedgesCounter...),
+		serviceHedgesWonCounter:        multi.NewCounter(serviceHedgesWonCounter...),
 		serviceServerUpGauge:           multi.NewGauge(serviceServerUpGauge...),
 		serviceReqsBytesCounter:        multi.NewCounter(serviceReqsBytesCounter...),
 		serviceRespsBytesCounter:       multi.NewCounter(serviceRespsBytesCounter...),
@@ -190,6 +239,11 @@ type standardRegistry struct {
 	lastConfigReloadSuccessGauge   metrics.Gauge
 	openConnectionsGauge           metrics.Gauge
 	tlsCertsNotAfterTimestampGauge metrics.Gauge
+	providerUpGauge                metrics.Gauge
+	providerLastUpdateGauge        metrics.Gauge
+	providerErrorsCounter          metrics.Counter
+	middlewareQueueDepthGauge      metrics.Gauge
+	middlewareQueueWaitHistogram   ScalableHistogram
 	entryPointReqsCounter          CounterWithHeaders
 	entryPointReqsTLSCounter       metrics.Counter
 	entryPointReqDurationHistogram ScalableHistogram
@@ -204,6 +258,8 @@ type standardRegistry struct {
 	serviceReqsTLSCounter          metrics.Counter
 	serviceReqDurationHistogram    ScalableHistogram
 	serviceRetriesCounter          metrics.Counter
+	serviceHedgesCounter           metrics.Counter
+	serviceHedgesWonCounter        metrics.Counter
 	serviceServerUpGauge           metrics.Gauge
 	serviceReqsBytesCounter        metrics.Counter
 	serviceRespsBytesCounter       metrics.Counter
@@ -237,6 +293,26 @@ func (r *standardRegistry) TLSCertsNotAfterTimestampGauge() metrics.Gauge {
 	return r.tlsCertsNotAfterTimestampGauge
 }
 
+func (r *standardRegistry) ProviderUpGauge() metrics.Gauge {
+	return r.providerUpGauge
+}
+
+func (r *standardRegistry) ProviderLastUpdateGauge() metrics.Gauge {
+	return r.providerLastUpdateGauge
+}
+
+func (r *standardRegistry) ProviderErrorsCounter() metrics.Counter {
+	return r.providerErrorsCounter
+}
+
+func (r *standardRegistry) MiddlewareQueueDepthGauge() metrics.Gauge {
+	return r.middlewareQueueDepthGauge
+}
+
+func (r *standardRegistry) MiddlewareQueueWaitHistogram() ScalableHistogram {
+	return r.middlewareQueueWaitHistogram
+}
+
 func (r *standardRegistry) EntryPointReqsCounter() CounterWithHeaders {
 	return r.entryPointReqsCounter
 }
@@ -293,6 +369,14 @@ func (r *standardRegistry) ServiceRetriesCounter() metrics.Counter {
 	return r.serviceRetriesCounter
 }
 
+func (r *standardRegistry) ServiceHedgesCounter() metrics.Counter {
+	return r.serviceHedgesCounter
+}
+
+func (r *standardRegistry) ServiceHedgesWonCounter() metrics.Counter {
+	return r.serviceHedgesWonCounter
+}
+
 func (r *standardRegistry) ServiceServerUpGauge() metrics.Gauge {
 	return r.serviceServerUpGauge
 }
@@ -310,7 +394,16 @@ func (r *standardRegistry) ServiceRespsBytesCounter() metrics.Counter {
 type ScalableHistogram interface {
 	With(labelValues ...string) ScalableHistogram
 	Observe(v float64)
-	ObserveFromStart(start time.Time)
+	// ObserveFromStart observes the duration elapsed since start.
+	// The given context carries the span of the request, if any,
+	// which is used by histograms supporting exemplars.
+	ObserveFromStart(ctx context.Context, start time.Time)
+}
+
+// exemplarHistogram is implemented by histograms able to attach
+// the trace context of an observation as an exemplar.
+type exemplarHistogram interface {
+	ObserveWithContext(ctx context.Context, v float64)
 }
 
 // HistogramWithScale is a histogram that will convert its observed value to the specified unit.
@@ -326,7 +419,7 @@ func (s *HistogramWithScale) With(labelValues ...string) ScalableHistogram {
 }
 
 // ObserveFromStart implements ScalableHistogram.
-func (s *HistogramWithScale) ObserveFromStart(start time.Time) {
+func (s *HistogramWithScale) ObserveFromStart(ctx context.Context, start time.Time) {
 	if s.unit <= 0 {
 		return
 	}
@@ -335,6 +428,11 @@ func (s *HistogramWithScale) ObserveFromStart(start time.Time) {
 	if d < 0 {
 		d = 0
 	}
+
+	if h, ok := s.histogram.(exemplarHistogram); ok {
+		h.ObserveWithContext(ctx, d)
+		return
+	}
 	s.histogram.Observe(d)
 }
 
@@ -358,9 +456,9 @@ func NewHistogramWithScale(histogram metrics.Histogram, unit time.Duration) (Sca
 type MultiHistogram []ScalableHistogram
 
 // ObserveFromStart implements ScalableHistogram.
-func (h MultiHistogram) ObserveFromStart(start time.Time) {
+func (h MultiHistogram) ObserveFromStart(ctx context.Context, start time.Time) {
 	for _, histogram := range h {
-		histogram.ObserveFromStart(start)
+		histogram.ObserveFromStart(ctx, start)
 	}
 }
 
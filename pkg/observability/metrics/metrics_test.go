@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"bytes"
+	"context"
 	"net/http"
 	"strings"
 	"testing"
@@ -22,7 +23,7 @@ func TestScalableHistogram(t *testing.T) {
 	<-ticker.C
 	start := time.Now()
 	<-ticker.C
-	sh.ObserveFromStart(start)
+	sh.ObserveFromStart(t.Context(), start)
 
 	var b bytes.Buffer
 	h.Print(&b)
@@ -114,7 +115,7 @@ func (c *histogramMock) With(labelValues ...string) ScalableHistogram {
 
 func (c *histogramMock) Start() {}
 
-func (c *histogramMock) ObserveFromStart(t time.Time) {}
+func (c *histogramMock) ObserveFromStart(_ context.Context, t time.Time) {}
 
 func (c *histogramMock) Observe(v float64) {
 	c.lastHistogramValue = v
@@ -21,6 +21,7 @@ import (
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/metric"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
 	"go.opentelemetry.io/otel/sdk/resource"
 	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/semconv/v1.37.0/httpconv"
@@ -126,7 +127,14 @@ func RegisterOpenTelemetry(ctx context.Context, config *otypes.OTLP) Registry {
 		lastConfigReloadSuccessGauge:   newOTLPGaugeFrom(meter, configLastReloadSuccessName, "Last config reload success", "ms"),
 		openConnectionsGauge:           newOTLPGaugeFrom(meter, openConnectionsName, "How many open connections exist, by entryPoint and protocol", "1"),
 		tlsCertsNotAfterTimestampGauge: newOTLPGaugeFrom(meter, tlsCertsNotAfterTimestampName, "Certificate expiration timestamp", "s"),
+		providerUpGauge:                newOTLPGaugeFrom(meter, providerUpName, "Provider is up, partitioned by provider. Value is 0 when the provider last reported an error.", "1"),
+		providerLastUpdateGauge:        newOTLPGaugeFrom(meter, providerLastUpdateName, "Last configuration received from a provider, partitioned by provider.", "s"),
+		providerErrorsCounter:          newOTLPCounterFrom(meter, providerErrorsName, "How many errors were reported by a provider, partitioned by provider."),
+		middlewareQueueDepthGauge:      newOTLPGaugeFrom(meter, middlewareQueueDepthName, "How many requests are waiting in the queue of a middleware.", "1"),
 	}
+	reg.middlewareQueueWaitHistogram, _ = NewHistogramWithScale(newOTLPHistogramFrom(meter, middlewareQueueWaitDurationName,
+		"How long the requests waited in the queue of a middleware, partitioned by priority and result.",
+		"s"), time.Second)
 
 	if config.AddEntryPointsLabels {
 		reg.entryPointReqsCounter = NewCounterWithNoopHeaders(newOTLPCounterFrom(meter, entryPointReqsTotalName,
@@ -166,6 +174,10 @@ func RegisterOpenTelemetry(ctx context.Context, config *otypes.OTLP) Registry {
 			"s"), time.Second)
 		reg.serviceRetriesCounter = newOTLPCounterFrom(meter, serviceRetriesTotalName,
 			"How many request retries happened on a service.")
+		reg.serviceHedgesCounter = newOTLPCounterFrom(meter, serviceHedgesTotalName,
+			"How many hedged requests were sent on a service.")
+		reg.serviceHedgesWonCounter = newOTLPCounterFrom(meter, serviceHedgesWonTotalName,
+			"How many hedged requests returned the first response on a service.")
 		reg.serviceServerUpGauge = newOTLPGaugeFrom(meter, serviceServerUpName,
 			"service server is up, described by gauge value of 0 or 1.",
 			"1")
@@ -239,8 +251,16 @@ func newOpenTelemetryMeterProvider(ctx context.Context, config *otypes.OTLP) (*s
 		sdkmetric.WithInterval(time.Duration(config.PushInterval)),
 	}
 
+	// The trace based filter only offers measurements recorded within a sampled span,
+	// so that exemplars always point to a trace which has been exported.
+	exemplarFilter := exemplar.AlwaysOffFilter
+	if config.AddExemplars {
+		exemplarFilter = exemplar.TraceBasedFilter
+	}
+
 	meterProvider := sdkmetric.NewMeterProvider(
 		sdkmetric.WithResource(res),
+		sdkmetric.WithExemplarFilter(exemplarFilter),
 		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, opts...)),
 		// View to customize histogram buckets and rename a single histogram instrument.
 		sdkmetric.WithView(sdkmetric.NewView(
@@ -483,7 +503,13 @@ func (h *otelHistogram) With(labelValues ...string) metrics.Histogram {
 }
 
 func (h *otelHistogram) Observe(incr float64) {
-	h.ip.Record(context.Background(), incr, metric.WithAttributes(h.labelNamesValues.ToLabels()...))
+	h.ObserveWithContext(context.Background(), incr)
+}
+
+// ObserveWithContext records the given value with the context of the request,
+// which lets the SDK exemplar reservoir pick the span of sampled requests.
+func (h *otelHistogram) ObserveWithContext(ctx context.Context, incr float64) {
+	h.ip.Record(ctx, incr, metric.WithAttributes(h.labelNamesValues.ToLabels()...))
 }
 
 // otelLabelNamesValues is the equivalent of prometheus' labelNamesValues
@@ -14,6 +14,7 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/hanzoai/ingress/pkg/config/dynamic"
 	otypes "github.com/hanzoai/ingress/pkg/observability/types"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -30,6 +31,17 @@ const (
 	metricsTLSPrefix              = MetricNamePrefix + "tls_"
 	tlsCertsNotAfterTimestampName = metricsTLSPrefix + "certs_not_after"
 
+	// provider level.
+	metricProviderPrefix   = MetricNamePrefix + "provider_"
+	providerUpName         = metricProviderPrefix + "up"
+	providerLastUpdateName = metricProviderPrefix + "last_update_timestamp_seconds"
+	providerErrorsName     = metricProviderPrefix + "errors_total"
+
+	// middleware level.
+	metricMiddlewarePrefix          = MetricNamePrefix + "middleware_"
+	middlewareQueueDepthName        = metricMiddlewarePrefix + "queue_depth"
+	middlewareQueueWaitDurationName = metricMiddlewarePrefix + "queue_wait_duration_seconds"
+
 	// entry point.
 	metricEntryPointPrefix        = MetricNamePrefix + "entrypoint_"
 	entryPointReqsTotalName       = metricEntryPointPrefix + "requests_total"
@@ -52,6 +64,8 @@ const (
 	serviceReqsTLSTotalName    = metricServicePrefix + "requests_tls_total"
 	serviceReqDurationName     = metricServicePrefix + "request_duration_seconds"
 	serviceRetriesTotalName    = metricServicePrefix + "retries_total"
+	serviceHedgesTotalName     = metricServicePrefix + "hedges_total"
+	serviceHedgesWonTotalName  = metricServicePrefix + "hedges_won_total"
 	serviceServerUpName        = metricServicePrefix + "server_up"
 	serviceReqsBytesTotalName  = metricServicePrefix + "requests_bytes_total"
 	serviceRespsBytesTotalName = metricServicePrefix + "responses_bytes_total"
@@ -74,8 +88,12 @@ var promState = newPrometheusState()
 var promRegistry = stdprometheus.NewRegistry()
 
 // PrometheusHandler exposes Prometheus routes.
-func PrometheusHandler() http.Handler {
-	return promhttp.HandlerFor(promRegistry, promhttp.HandlerOpts{})
+// The OpenMetrics exposition format is negotiated when exemplars are enabled,
+// as the Prometheus text format cannot carry them.
+func PrometheusHandler(config *otypes.Prometheus) http.Handler {
+	return promhttp.HandlerFor(promRegistry, promhttp.HandlerOpts{
+		EnableOpenMetrics: config != nil && config.AddExemplars,
+	})
 }
 
 // RegisterPrometheus registers all Prometheus metrics.
@@ -126,12 +144,38 @@ func initStandardRegistry(config *otypes.Prometheus) Registry {
 		Name: openConnectionsName,
 		Help: "How many open connections exist, by entryPoint and protocol",
 	}, []string{"entrypoint", "protocol"})
+	providerUp := newGaugeFrom(stdprometheus.GaugeOpts{
+		Name: providerUpName,
+		Help: "Provider is up, partitioned by provider. Value is 0 when the provider last reported an error.",
+	}, []string{"provider"})
+	providerLastUpdate := newGaugeFrom(stdprometheus.GaugeOpts{
+		Name: providerLastUpdateName,
+		Help: "Last configuration received from a provider, partitioned by provider.",
+	}, []string{"provider"})
+	providerErrors := newCounterFrom(stdprometheus.CounterOpts{
+		Name: providerErrorsName,
+		Help: "How many errors were reported by a provider, partitioned by provider.",
+	}, []string{"provider"})
+	middlewareQueueDepth := newGaugeFrom(stdprometheus.GaugeOpts{
+		Name: middlewareQueueDepthName,
+		Help: "How many requests are waiting in the queue of a middleware.",
+	}, []string{"middleware"})
+	middlewareQueueWaitDurations := newHistogramFrom(stdprometheus.HistogramOpts{
+		Name:    middlewareQueueWaitDurationName,
+		Help:    "How long the requests waited in the queue of a middleware, partitioned by priority and result.",
+		Buckets: buckets,
+	}, []string{"middleware", "priority", "result"}, false)
 
 	promState.vectors = []vector{
 		configReloads.cv,
 		lastConfigReloadSuccess.gv,
 		tlsCertsNotAfterTimestamp.gv,
 		openConnections.gv,
+		providerUp.gv,
+		providerLastUpdate.gv,
+		providerErrors.cv,
+		middlewareQueueDepth.gv,
+		middlewareQueueWaitDurations.hv,
 	}
 
 	reg := &standardRegistry{
@@ -142,7 +186,12 @@ func initStandardRegistry(config *otypes.Prometheus) Registry {
 		lastConfigReloadSuccessGauge:   lastConfigReloadSuccess,
 		tlsCertsNotAfterTimestampGauge: tlsCertsNotAfterTimestamp,
 		openConnectionsGauge:           openConnections,
+		providerUpGauge:                providerUp,
+		providerLastUpdateGauge:        providerLastUpdate,
+		providerErrorsCounter:          providerErrors,
+		middlewareQueueDepthGauge:      middlewareQueueDepth,
 	}
+	reg.middlewareQueueWaitHistogram, _ = NewHistogramWithScale(middlewareQueueWaitDurations, time.Second)
 
 	if config.AddEntryPointsLabels {
 		entryPointReqs := newCounterWithHeadersFrom(stdprometheus.CounterOpts{
@@ -157,7 +206,7 @@ func initStandardRegistry(config *otypes.Prometheus) Registry {
 			Name:    entryPointReqDurationName,
 			Help:    "How long it took to process the request on an entrypoint, partitioned by status code, protocol, and method.",
 			Buckets: buckets,
-		}, []string{"code", "method", "protocol", "entrypoint"})
+		}, []string{"code", "method", "protocol", "entrypoint"}, config.AddExemplars)
 		entryPointReqsBytesTotal := newCounterFrom(stdprometheus.CounterOpts{
 			Name: entryPointReqsBytesTotalName,
 			Help: "The total size of requests in bytes handled by an entrypoint, partitioned by status code, protocol, and method.",
@@ -195,7 +244,7 @@ func initStandardRegistry(config *otypes.Prometheus) Registry {
 			Name:    routerReqDurationName,
 			Help:    "How long it took to process the request on a router, partitioned by service, status code, protocol, and method.",
 			Buckets: buckets,
-		}, []string{"code", "method", "protocol", "router", "service"})
+		}, []string{"code", "method", "protocol", "router", "service"}, config.AddExemplars)
 		routerReqsBytesTotal := newCounterFrom(stdprometheus.CounterOpts{
 			Name: routerReqsBytesTotalName,
 			Help: "The total size of requests in bytes handled by a router, partitioned by service, status code, protocol, and method.",
@@ -232,11 +281,19 @@ func initStandardRegistry(config *otypes.Prometheus) Registry {
 			Name:    serviceReqDurationName,
 			Help:    "How long it took to process the request on a service, partitioned by status code, protocol, and method.",
 			Buckets: buckets,
-		}, []string{"code", "method", "protocol", "service"})
+		}, []string{"code", "method", "protocol", "service"}, config.AddExemplars)
 		serviceRetries := newCounterFrom(stdprometheus.CounterOpts{
 			Name: serviceRetriesTotalName,
 			Help: "How many request retries happened on a service.",
 		}, []string{"service"})
+		serviceHedges := newCounterFrom(stdprometheus.CounterOpts{
+			Name: serviceHedgesTotalName,
+			Help: "How many hedged requests were sent on a service.",
+		}, []string{"service"})
+		serviceHedgesWon := newCounterFrom(stdprometheus.CounterOpts{
+			Name: serviceHedgesWonTotalName,
+			Help: "How many hedged requests returned the first response on a service.",
+		}, []string{"service"})
 		serviceServerUp := newGaugeFrom(stdprometheus.GaugeOpts{
 			Name: serviceServerUpName,
 			Help: "service server is up, described by gauge value of 0 or 1.",
@@ -255,6 +312,8 @@ func initStandardRegistry(config *otypes.Prometheus) Registry {
 			serviceReqsTLS.cv,
 			serviceReqDurations.hv,
 			serviceRetries.cv,
+			serviceHedges.cv,
+			serviceHedgesWon.cv,
 			serviceServerUp.gv,
 			serviceReqsBytesTotal.cv,
 			serviceRespsBytesTotal.cv,
@@ -264,6 +323,8 @@ func initStandardRegistry(config *otypes.Prometheus) Registry {
 		reg.serviceReqsTLSCounter = serviceReqsTLS
 		reg.serviceReqDurationHistogram, _ = NewHistogramWithScale(serviceReqDurations, time.Second)
 		reg.serviceRetriesCounter = serviceRetries
+		reg.serviceHedgesCounter = serviceHedges
+		reg.serviceHedgesWonCounter = serviceHedgesWon
 		reg.serviceServerUpGauge = serviceServerUp
 		reg.serviceReqsBytesCounter = serviceReqsBytesTotal
 		reg.serviceRespsBytesCounter = serviceRespsBytesTotal
@@ -610,17 +671,19 @@ func (g *gauge) Describe(ch chan<- *stdprometheus.Desc) {
 	g.gv.Describe(ch)
 }
 
-func newHistogramFrom(opts stdprometheus.HistogramOpts, labelNames []string) *histogram {
+func newHistogramFrom(opts stdprometheus.HistogramOpts, labelNames []string, exemplars bool) *histogram {
 	hv := stdprometheus.NewHistogramVec(opts, labelNames)
 	return &histogram{
-		name: opts.Name,
-		hv:   hv,
+		name:      opts.Name,
+		hv:        hv,
+		exemplars: exemplars,
 	}
 }
 
 type histogram struct {
 	name             string
 	hv               *stdprometheus.HistogramVec
+	exemplars        bool
 	labelNamesValues labelNamesValues
 	collector        stdprometheus.Observer
 }
@@ -630,6 +693,7 @@ func (h *histogram) With(labelValues ...string) metrics.Histogram {
 	return &histogram{
 		name:             h.name,
 		hv:               h.hv,
+		exemplars:        h.exemplars,
 		labelNamesValues: lnv,
 		collector:        h.hv.With(lnv.ToLabels()),
 	}
@@ -639,6 +703,23 @@ func (h *histogram) Observe(value float64) {
 	h.collector.Observe(value)
 }
 
+// ObserveWithContext observes the given value,
+// attaching the trace and span IDs of the span found in the context as an exemplar,
+// only if exemplars are enabled and the span has been sampled.
+func (h *histogram) ObserveWithContext(ctx context.Context, value float64) {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	observer, ok := h.collector.(stdprometheus.ExemplarObserver)
+	if !h.exemplars || !ok || !spanCtx.IsSampled() {
+		h.collector.Observe(value)
+		return
+	}
+
+	observer.ObserveWithExemplar(value, stdprometheus.Labels{
+		"trace_id": spanCtx.TraceID().String(),
+		"span_id":  spanCtx.SpanID().String(),
+	})
+}
+
 func (h *histogram) Describe(ch chan<- *stdprometheus.Desc) {
 	h.hv.Describe(ch)
 }
@@ -10,9 +10,11 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/hanzoai/ingress/pkg/config/dynamic"
 	otypes "github.com/hanzoai/ingress/pkg/observability/types"
 	th "github.com/hanzoai/ingress/pkg/testhelpers"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestRegisterPromState(t *testing.T) {
@@ -114,6 +116,10 @@ func TestPrometheus(t *testing.T) {
 		With("cn", "value", "serial", "value", "sans", "value").
 		Set(float64(time.Now().Unix()))
 
+	prometheusRegistry.ProviderUpGauge().With("provider", "docker").Set(1)
+	prometheusRegistry.ProviderLastUpdateGauge().With("provider", "docker").Set(float64(time.Now().Unix()))
+	prometheusRegistry.ProviderErrorsCounter().With("provider", "docker").Add(1)
+
 	prometheusRegistry.
 		EntryPointReqsCounter().
 		With(map[string][]string{"User-Agent": {"foobar"}}, "code", strconv.Itoa(http.StatusOK), "method", http.MethodGet, "protocol", "http", "entrypoint", "http").
@@ -168,6 +174,14 @@ func TestPrometheus(t *testing.T) {
 		ServiceRetriesCounter().
 		With("service", "service1").
 		Add(1)
+	prometheusRegistry.
+		ServiceHedgesCounter().
+		With("service", "service1").
+		Add(1)
+	prometheusRegistry.
+		ServiceHedgesWonCounter().
+		With("service", "service1").
+		Add(1)
 	prometheusRegistry.
 		ServiceServerUpGauge().
 		With("service", "service1", "url", "http://127.0.0.10:80").
@@ -181,6 +195,15 @@ func TestPrometheus(t *testing.T) {
 		With("service", "service1", "code", strconv.Itoa(http.StatusOK), "method", http.MethodGet, "protocol", "http").
 		Add(1)
 
+	prometheusRegistry.
+		MiddlewareQueueDepthGauge().
+		With("middleware", "queue").
+		Set(1)
+	prometheusRegistry.
+		MiddlewareQueueWaitHistogram().
+		With("middleware", "queue", "priority", "standard", "result", "served").
+		Observe(1)
+
 	delayForTrackingCompletion()
 
 	metricsFamilies := mustScrape()
@@ -215,6 +238,21 @@ func TestPrometheus(t *testing.T) {
 			},
 			assert: buildTimestampAssert(t, tlsCertsNotAfterTimestampName),
 		},
+		{
+			name:   providerUpName,
+			labels: map[string]string{"provider": "docker"},
+			assert: buildGaugeAssert(t, providerUpName, 1),
+		},
+		{
+			name:   "ingress_provider_last_update_timestamp_seconds",
+			labels: map[string]string{"provider": "docker"},
+			assert: buildTimestampAssert(t, providerLastUpdateName),
+		},
+		{
+			name:   providerErrorsName,
+			labels: map[string]string{"provider": "docker"},
+			assert: buildCounterAssert(t, providerErrorsName, 1),
+		},
 		{
 			name: entryPointReqsTotalName,
 			labels: map[string]string{
@@ -348,6 +386,20 @@ func TestPrometheus(t *testing.T) {
 			},
 			assert: buildGreaterThanCounterAssert(t, serviceRetriesTotalName, 1),
 		},
+		{
+			name: serviceHedgesTotalName,
+			labels: map[string]string{
+				"service": "service1",
+			},
+			assert: buildCounterAssert(t, serviceHedgesTotalName, 1),
+		},
+		{
+			name: serviceHedgesWonTotalName,
+			labels: map[string]string{
+				"service": "service1",
+			},
+			assert: buildCounterAssert(t, serviceHedgesWonTotalName, 1),
+		},
 		{
 			name: serviceServerUpName,
 			labels: map[string]string{
@@ -376,6 +428,22 @@ func TestPrometheus(t *testing.T) {
 			},
 			assert: buildCounterAssert(t, serviceRespsBytesTotalName, 1),
 		},
+		{
+			name: middlewareQueueDepthName,
+			labels: map[string]string{
+				"middleware": "queue",
+			},
+			assert: buildGaugeAssert(t, middlewareQueueDepthName, 1),
+		},
+		{
+			name: middlewareQueueWaitDurationName,
+			labels: map[string]string{
+				"middleware": "queue",
+				"priority":   "standard",
+				"result":     "served",
+			},
+			assert: buildHistogramAssert(t, middlewareQueueWaitDurationName, 1),
+		},
 	}
 
 	for _, test := range testCases {
@@ -578,6 +646,86 @@ func TestPrometheusRemovedMetricsReset(t *testing.T) {
 	assertCounterValue(t, 1, findMetricFamily(serviceReqsTotalName, metricsFamilies), labelNamesValues...)
 }
 
+func TestPrometheusHistogramExemplars(t *testing.T) {
+	traceID, err := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	require.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("0102030405060708")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc             string
+		exemplars        bool
+		traceFlags       trace.TraceFlags
+		expectedExemplar bool
+	}{
+		{
+			desc:       "exemplars disabled",
+			traceFlags: trace.FlagsSampled,
+		},
+		{
+			desc:      "span not sampled",
+			exemplars: true,
+		},
+		{
+			desc:             "span sampled",
+			exemplars:        true,
+			traceFlags:       trace.FlagsSampled,
+			expectedExemplar: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			h := newHistogramFrom(prometheus.HistogramOpts{
+				Name:    "test_histogram",
+				Buckets: []float64{1},
+			}, []string{"service"}, test.exemplars)
+
+			spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID:    traceID,
+				SpanID:     spanID,
+				TraceFlags: test.traceFlags,
+			})
+			ctx := trace.ContextWithSpanContext(t.Context(), spanCtx)
+
+			sh, err := NewHistogramWithScale(h.With("service", "foo"), time.Second)
+			require.NoError(t, err)
+			sh.ObserveFromStart(ctx, time.Now())
+
+			reg := prometheus.NewPedanticRegistry()
+			require.NoError(t, reg.Register(h.hv))
+
+			families, err := reg.Gather()
+			require.NoError(t, err)
+			require.Len(t, families, 1)
+
+			var exemplar *dto.Exemplar
+			for _, bucket := range families[0].GetMetric()[0].GetHistogram().GetBucket() {
+				if bucket.GetExemplar() != nil {
+					exemplar = bucket.GetExemplar()
+				}
+			}
+
+			if !test.expectedExemplar {
+				assert.Nil(t, exemplar)
+				return
+			}
+
+			require.NotNil(t, exemplar)
+			labels := make(map[string]string)
+			for _, label := range exemplar.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			assert.Equal(t, map[string]string{
+				"trace_id": traceID.String(),
+				"span_id":  spanID.String(),
+			}, labels)
+		})
+	}
+}
+
 // reset is a utility method for unit testing.
 // It should be called after each test run that changes promState internally
 // in order to avoid dependencies between unit tests.
@@ -23,6 +23,13 @@ const (
 
 	statsdTLSCertsNotAfterTimestampName = "tls.certs.notAfterTimestamp"
 
+	statsdProviderUpName                  = "provider.up"
+	statsdProviderLastUpdateTimestampName = "provider.lastUpdateTimestamp"
+	statsdProviderErrorsName              = "provider.errors.total"
+
+	statsdMiddlewareQueueDepthName        = "middleware.queue.depth"
+	statsdMiddlewareQueueWaitDurationName = "middleware.queue.wait.duration"
+
 	statsdEntryPointReqsName        = "entrypoint.request.total"
 	statsdEntryPointReqsTLSName     = "entrypoint.request.tls.total"
 	statsdEntryPointReqDurationName = "entrypoint.request.duration"
@@ -39,6 +46,8 @@ const (
 	statsdServiceReqsTLSName      = "service.request.tls.total"
 	statsdServiceReqsDurationName = "service.request.duration"
 	statsdServiceRetriesTotalName = "service.retries.total"
+	statsdServiceHedgesTotalName  = "service.hedges.total"
+	statsdServiceHedgesWonName    = "service.hedges.won.total"
 	statsdServiceServerUpName     = "service.server.up"
 	statsdServiceReqsBytesName    = "service.requests.bytes.total"
 	statsdServiceRespsBytesName   = "service.responses.bytes.total"
@@ -62,7 +71,12 @@ func RegisterStatsd(ctx context.Context, config *otypes.Statsd) Registry {
 		lastConfigReloadSuccessGauge:   statsdClient.NewGauge(statsdLastConfigReloadSuccessName),
 		tlsCertsNotAfterTimestampGauge: statsdClient.NewGauge(statsdTLSCertsNotAfterTimestampName),
 		openConnectionsGauge:           statsdClient.NewGauge(statsdOpenConnectionsName),
+		providerUpGauge:                statsdClient.NewGauge(statsdProviderUpName),
+		providerLastUpdateGauge:        statsdClient.NewGauge(statsdProviderLastUpdateTimestampName),
+		providerErrorsCounter:          statsdClient.NewCounter(statsdProviderErrorsName, 1.0),
+		middlewareQueueDepthGauge:      statsdClient.NewGauge(statsdMiddlewareQueueDepthName),
 	}
+	registry.middlewareQueueWaitHistogram, _ = NewHistogramWithScale(statsdClient.NewTiming(statsdMiddlewareQueueWaitDurationName, 1.0), time.Millisecond)
 
 	if config.AddEntryPointsLabels {
 		registry.epEnabled = config.AddEntryPointsLabels
@@ -88,6 +102,8 @@ func RegisterStatsd(ctx context.Context, config *otypes.Statsd) Registry {
 		registry.serviceReqsTLSCounter = statsdClient.NewCounter(statsdServiceReqsTLSName, 1.0)
 		registry.serviceReqDurationHistogram, _ = NewHistogramWithScale(statsdClient.NewTiming(statsdServiceReqsDurationName, 1.0), time.Millisecond)
 		registry.serviceRetriesCounter = statsdClient.NewCounter(statsdServiceRetriesTotalName, 1.0)
+		registry.serviceHedgesCounter = statsdClient.NewCounter(statsdServiceHedgesTotalName, 1.0)
+		registry.serviceHedgesWonCounter = statsdClient.NewCounter(statsdServiceHedgesWonName, 1.0)
 		registry.serviceServerUpGauge = statsdClient.NewGauge(statsdServiceServerUpName)
 		registry.serviceReqsBytesCounter = statsdClient.NewCounter(statsdServiceReqsBytesName, 1.0)
 		registry.serviceRespsBytesCounter = statsdClient.NewCounter(statsdServiceRespsBytesName, 1.0)
@@ -53,6 +53,10 @@ func testRegistry(t *testing.T, metricsPrefix string, registry Registry) {
 
 		metricsPrefix + ".tls.certs.notAfterTimestamp:1.000000|g\n",
 
+		metricsPrefix + ".provider.up:1.000000|g\n",
+		metricsPrefix + ".provider.lastUpdateTimestamp:1.000000|g\n",
+		metricsPrefix + ".provider.errors.total:1.000000|c\n",
+
 		metricsPrefix + ".entrypoint.request.total:1.000000|c\n",
 		metricsPrefix + ".entrypoint.request.tls.total:1.000000|c\n",
 		metricsPrefix + ".entrypoint.request.duration:10000.000000|ms",
@@ -81,6 +85,10 @@ func testRegistry(t *testing.T, metricsPrefix string, registry Registry) {
 
 		registry.TLSCertsNotAfterTimestampGauge().With("key", "value").Set(1)
 
+		registry.ProviderUpGauge().With("provider", "docker").Set(1)
+		registry.ProviderLastUpdateGauge().With("provider", "docker").Set(1)
+		registry.ProviderErrorsCounter().With("provider", "docker").Add(1)
+
 		registry.EntryPointReqsCounter().With(nil, "entrypoint", "test", "code", strconv.Itoa(http.StatusOK), "method", http.MethodGet).Add(1)
 		registry.EntryPointReqsTLSCounter().With("entrypoint", "test", "tls_version", "foo", "tls_cipher", "bar").Add(1)
 		registry.EntryPointReqDurationHistogram().With("entrypoint", "test").Observe(10000)
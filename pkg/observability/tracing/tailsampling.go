@@ -0,0 +1,306 @@
+package tracing
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	otypes "github.com/hanzoai/ingress/pkg/observability/types"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordingSampler is a sdktrace.Sampler recording the spans the wrapped sampler drops.
+// It allows the tail sampling processor to take its decision on every trace,
+// while the decision of the wrapped sampler is still the one propagated downstream.
+type recordingSampler struct {
+	sdktrace.Sampler
+}
+
+// ShouldSample implements sdktrace.Sampler.
+func (s recordingSampler) ShouldSample(parameters sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	result := s.Sampler.ShouldSample(parameters)
+	if result.Decision == sdktrace.Drop {
+		result.Decision = sdktrace.RecordOnly
+	}
+
+	return result
+}
+
+// Description implements sdktrace.Sampler.
+func (s recordingSampler) Description() string {
+	return "Recording{" + s.Sampler.Description() + "}"
+}
+
+// sampledSpan is a span kept by the tail sampling processor, flagged as sampled for the exporters.
+type sampledSpan struct {
+	sdktrace.ReadOnlySpan
+}
+
+// SpanContext returns the span context flagged as sampled.
+func (s sampledSpan) SpanContext() trace.SpanContext {
+	spanCtx := s.ReadOnlySpan.SpanContext()
+
+	return spanCtx.WithTraceFlags(spanCtx.TraceFlags().WithSampled(true))
+}
+
+// bufferedTrace holds the spans of a trace waiting for the sampling decision.
+type bufferedTrace struct {
+	spans       []sdktrace.ReadOnlySpan
+	interesting bool
+	deadline    time.Time
+	elem        *list.Element
+}
+
+// tailSamplingProcessor is a sdktrace.SpanProcessor buffering the ended spans per trace,
+// and forwarding them to the next processor only if the trace is sampled.
+// The decision is taken when the local root span ends, when the decision wait is elapsed,
+// or when the buffer is full.
+type tailSamplingProcessor struct {
+	next             sdktrace.SpanProcessor
+	sampler          sdktrace.Sampler
+	decisionWait     time.Duration
+	maxTraces        int
+	maxSpansPerTrace int
+	latencyThreshold time.Duration
+
+	mu     sync.Mutex
+	traces map[trace.TraceID]*bufferedTrace
+	// order keeps the trace IDs by arrival, the oldest being at the front.
+	order *list.List
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	stopped  chan struct{}
+}
+
+func newTailSamplingProcessor(next sdktrace.SpanProcessor, config *otypes.TailSampling) *tailSamplingProcessor {
+	p := &tailSamplingProcessor{
+		next:             next,
+		sampler:          sdktrace.TraceIDRatioBased(config.SampleRate),
+		decisionWait:     time.Duration(config.DecisionWait),
+		maxTraces:        config.MaxTraces,
+		maxSpansPerTrace: config.MaxSpansPerTrace,
+		latencyThreshold: time.Duration(config.LatencyThreshold),
+		traces:           make(map[trace.TraceID]*bufferedTrace),
+		order:            list.New(),
+		stop:             make(chan struct{}),
+		stopped:          make(chan struct{}),
+	}
+
+	go p.expireLoop()
+
+	return p
+}
+
+// OnStart implements sdktrace.SpanProcessor.
+func (p *tailSamplingProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+// OnEnd implements sdktrace.SpanProcessor.
+func (p *tailSamplingProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	traceID := s.SpanContext().TraceID()
+
+	var decided []*bufferedTrace
+
+	p.mu.Lock()
+
+	bt, ok := p.traces[traceID]
+	if !ok {
+		if p.maxTraces > 0 && len(p.traces) >= p.maxTraces {
+			if oldest := p.order.Front(); oldest != nil {
+				decided = append(decided, p.remove(oldest.Value.(trace.TraceID)))
+			}
+		}
+
+		bt = &bufferedTrace{deadline: time.Now().Add(p.decisionWait)}
+		bt.elem = p.order.PushBack(traceID)
+		p.traces[traceID] = bt
+	}
+
+	if isInterestingSpan(s) {
+		bt.interesting = true
+	}
+
+	// The local root span is the last one to end in the proxy,
+	// there is no need to wait any longer to take the decision.
+	parent := s.Parent()
+	localRoot := !parent.IsValid() || parent.IsRemote()
+
+	switch {
+	case p.maxSpansPerTrace <= 0 || len(bt.spans) < p.maxSpansPerTrace:
+		bt.spans = append(bt.spans, s)
+	case localRoot:
+		// The local root span is always kept, the last buffered child span is evicted instead.
+		bt.spans[len(bt.spans)-1] = s
+	}
+
+	if localRoot {
+		if p.latencyThreshold > 0 && s.EndTime().Sub(s.StartTime()) >= p.latencyThreshold {
+			bt.interesting = true
+		}
+
+		decided = append(decided, p.remove(traceID))
+	}
+
+	p.mu.Unlock()
+
+	for _, bt := range decided {
+		p.decide(bt)
+	}
+}
+
+// Shutdown implements sdktrace.SpanProcessor.
+func (p *tailSamplingProcessor) Shutdown(ctx context.Context) error {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+		<-p.stopped
+	})
+
+	p.flush()
+
+	return p.next.Shutdown(ctx)
+}
+
+// ForceFlush implements sdktrace.SpanProcessor.
+func (p *tailSamplingProcessor) ForceFlush(ctx context.Context) error {
+	p.flush()
+
+	return p.next.ForceFlush(ctx)
+}
+
+// flush takes the sampling decision for all the buffered traces.
+func (p *tailSamplingProcessor) flush() {
+	p.mu.Lock()
+	decided := make([]*bufferedTrace, 0, len(p.traces))
+	for p.order.Len() > 0 {
+		decided = append(decided, p.remove(p.order.Front().Value.(trace.TraceID)))
+	}
+	p.mu.Unlock()
+
+	for _, bt := range decided {
+		p.decide(bt)
+	}
+}
+
+func (p *tailSamplingProcessor) expireLoop() {
+	defer close(p.stopped)
+
+	interval := min(p.decisionWait, time.Second)
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case now := <-ticker.C:
+			p.expire(now)
+		}
+	}
+}
+
+// expire takes the sampling decision for the traces whose decision wait is elapsed.
+func (p *tailSamplingProcessor) expire(now time.Time) {
+	var decided []*bufferedTrace
+
+	p.mu.Lock()
+	for p.order.Len() > 0 {
+		traceID := p.order.Front().Value.(trace.TraceID)
+		if p.traces[traceID].deadline.After(now) {
+			break
+		}
+
+		decided = append(decided, p.remove(traceID))
+	}
+	p.mu.Unlock()
+
+	for _, bt := range decided {
+		p.decide(bt)
+	}
+}
+
+// remove removes the given trace from the buffer.
+// It must be called with the lock held.
+func (p *tailSamplingProcessor) remove(traceID trace.TraceID) *bufferedTrace {
+	bt := p.traces[traceID]
+	p.order.Remove(bt.elem)
+	delete(p.traces, traceID)
+
+	return bt
+}
+
+// decide forwards the spans of the given trace to the next processor if the trace is sampled.
+func (p *tailSamplingProcessor) decide(bt *bufferedTrace) {
+	if len(bt.spans) == 0 {
+		return
+	}
+
+	if !bt.interesting && !p.isSlow(bt.spans) && !p.sampled(bt.spans[0].SpanContext().TraceID()) {
+		return
+	}
+
+	for _, s := range bt.spans {
+		if !s.SpanContext().IsSampled() {
+			s = sampledSpan{ReadOnlySpan: s}
+		}
+
+		p.next.OnEnd(s)
+	}
+}
+
+// isSlow reports whether the buffered spans of a trace span longer than the latency threshold.
+func (p *tailSamplingProcessor) isSlow(spans []sdktrace.ReadOnlySpan) bool {
+	if p.latencyThreshold <= 0 {
+		return false
+	}
+
+	start, end := spans[0].StartTime(), spans[0].EndTime()
+	for _, s := range spans[1:] {
+		if s.StartTime().Before(start) {
+			start = s.StartTime()
+		}
+		if s.EndTime().After(end) {
+			end = s.EndTime()
+		}
+	}
+
+	return end.Sub(start) >= p.latencyThreshold
+}
+
+func (p *tailSamplingProcessor) sampled(traceID trace.TraceID) bool {
+	result := p.sampler.ShouldSample(sdktrace.SamplingParameters{TraceID: traceID})
+
+	return result.Decision == sdktrace.RecordAndSample
+}
+
+// isInterestingSpan reports whether the span contains an error, a 5xx response or a retry,
+// in which case the whole trace is kept.
+func isInterestingSpan(s sdktrace.ReadOnlySpan) bool {
+	if s.Status().Code == codes.Error {
+		return true
+	}
+
+	for _, attr := range s.Attributes() {
+		switch attr.Key {
+		case semconv.HTTPResponseStatusCodeKey:
+			if attr.Value.AsInt64() >= 500 {
+				return true
+			}
+		case semconv.HTTPRequestResendCountKey:
+			if attr.Value.AsInt64() > 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,200 @@
+package tracing
+
+import (
+	"testing"
+	"time"
+
+	ptypes "github.com/hanzoai/ingress-parser/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	otypes "github.com/hanzoai/ingress/pkg/observability/types"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTailSamplingProcessor(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		sampleRate    float64
+		childSpan     func(span trace.Span)
+		rootDuration  time.Duration
+		expectedSpans int
+	}{
+		{
+			desc:          "uninteresting trace is dropped",
+			childSpan:     func(span trace.Span) {},
+			expectedSpans: 0,
+		},
+		{
+			desc:          "uninteresting trace is sampled",
+			sampleRate:    1,
+			childSpan:     func(span trace.Span) {},
+			expectedSpans: 2,
+		},
+		{
+			desc: "trace with an error is kept",
+			childSpan: func(span trace.Span) {
+				span.SetStatus(codes.Error, "boom")
+			},
+			expectedSpans: 2,
+		},
+		{
+			desc: "trace with a 5xx response is kept",
+			childSpan: func(span trace.Span) {
+				span.SetAttributes(semconv.HTTPResponseStatusCode(502))
+			},
+			expectedSpans: 2,
+		},
+		{
+			desc: "trace with a 4xx response is dropped",
+			childSpan: func(span trace.Span) {
+				span.SetAttributes(semconv.HTTPResponseStatusCode(404))
+			},
+			expectedSpans: 0,
+		},
+		{
+			desc: "trace with a retry is kept",
+			childSpan: func(span trace.Span) {
+				span.SetAttributes(semconv.HTTPRequestResendCount(1))
+			},
+			expectedSpans: 2,
+		},
+		{
+			desc:          "slow trace is kept",
+			childSpan:     func(span trace.Span) {},
+			rootDuration:  2 * time.Second,
+			expectedSpans: 2,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			exporter := tracetest.NewInMemoryExporter()
+			processor := newTailSamplingProcessor(sdktrace.NewSimpleSpanProcessor(exporter), &otypes.TailSampling{
+				DecisionWait:     ptypes.Duration(time.Minute),
+				MaxTraces:        10,
+				MaxSpansPerTrace: 10,
+				LatencyThreshold: ptypes.Duration(time.Second),
+				SampleRate:       test.sampleRate,
+			})
+
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(processor))
+			t.Cleanup(func() { _ = tp.Shutdown(t.Context()) })
+
+			tracer := tp.Tracer("test")
+
+			start := time.Now()
+			ctx, root := tracer.Start(t.Context(), "root", trace.WithTimestamp(start))
+
+			_, child := tracer.Start(ctx, "child")
+			test.childSpan(child)
+			child.End()
+
+			// The child span is buffered until the root span ends.
+			assert.Empty(t, exporter.GetSpans())
+
+			root.End(trace.WithTimestamp(start.Add(test.rootDuration)))
+
+			assert.Len(t, exporter.GetSpans(), test.expectedSpans)
+		})
+	}
+}
+
+func TestTailSamplingProcessor_limits(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	processor := newTailSamplingProcessor(sdktrace.NewSimpleSpanProcessor(exporter), &otypes.TailSampling{
+		DecisionWait:     ptypes.Duration(time.Minute),
+		MaxTraces:        1,
+		MaxSpansPerTrace: 2,
+	})
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(processor))
+	t.Cleanup(func() { _ = tp.Shutdown(t.Context()) })
+
+	tracer := tp.Tracer("test")
+
+	ctx, root := tracer.Start(t.Context(), "root")
+	for range 3 {
+		_, child := tracer.Start(ctx, "child")
+		child.SetStatus(codes.Error, "boom")
+		child.End()
+	}
+
+	// Starting a second trace evicts the first one, whose decision is taken early.
+	ctx2, root2 := tracer.Start(t.Context(), "root2")
+	_, child := tracer.Start(ctx2, "child2")
+	child.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	for _, span := range spans {
+		assert.Equal(t, root.SpanContext().TraceID(), span.SpanContext.TraceID())
+	}
+
+	root.End()
+	root2.End()
+}
+
+func TestTailSamplingProcessor_keepsLocalRoot(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	processor := newTailSamplingProcessor(sdktrace.NewSimpleSpanProcessor(exporter), &otypes.TailSampling{
+		DecisionWait:     ptypes.Duration(time.Minute),
+		MaxTraces:        10,
+		MaxSpansPerTrace: 2,
+	})
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(processor))
+	t.Cleanup(func() { _ = tp.Shutdown(t.Context()) })
+
+	tracer := tp.Tracer("test")
+
+	ctx, root := tracer.Start(t.Context(), "root")
+	for range 3 {
+		_, child := tracer.Start(ctx, "child")
+		child.SetStatus(codes.Error, "boom")
+		child.End()
+	}
+	root.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, "root", spans[1].Name)
+}
+
+func TestTailSamplingProcessor_headSampling(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	processor := newTailSamplingProcessor(sdktrace.NewSimpleSpanProcessor(exporter), &otypes.TailSampling{
+		DecisionWait:     ptypes.Duration(time.Minute),
+		MaxTraces:        10,
+		MaxSpansPerTrace: 10,
+	})
+
+	sampler := recordingSampler{Sampler: sdktrace.ParentBased(sdktrace.NeverSample())}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sampler), sdktrace.WithSpanProcessor(processor))
+	t.Cleanup(func() { _ = tp.Shutdown(t.Context()) })
+
+	tracer := tp.Tracer("test")
+
+	ctx, root := tracer.Start(t.Context(), "root")
+	_, child := tracer.Start(ctx, "child")
+
+	// The head sampling decision is the one propagated downstream.
+	assert.False(t, child.SpanContext().IsSampled())
+	assert.True(t, child.IsRecording())
+
+	child.SetStatus(codes.Error, "boom")
+	child.End()
+	root.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	for _, span := range spans {
+		assert.True(t, span.SpanContext.IsSampled())
+	}
+}
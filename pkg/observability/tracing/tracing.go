@@ -20,13 +20,14 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
 // Backend is an abstraction for tracking backend (OpenTelemetry, ...).
 type Backend interface {
-	Setup(ctx context.Context, serviceName string, sampleRate float64, resourceAttributes map[string]string) (trace.Tracer, io.Closer, error)
+	Setup(ctx context.Context, serviceName string, sampler sdktrace.Sampler, wrapProcessor func(sdktrace.SpanProcessor) sdktrace.SpanProcessor, resourceAttributes map[string]string) (trace.Tracer, io.Closer, error)
 }
 
 // Tracer is trace.Tracer with additional properties.
@@ -58,7 +59,19 @@ func NewTracing(ctx context.Context, conf *static.Tracing) (*Tracer, io.Closer,
 
 	otel.SetTextMapPropagator(autoprop.NewTextMapPropagator())
 
-	tr, closer, err := backend.Setup(ctx, conf.ServiceName, conf.SampleRate, conf.ResourceAttributes)
+	var sampler sdktrace.Sampler = sdktrace.ParentBased(sdktrace.TraceIDRatioBased(conf.SampleRate))
+
+	var wrapProcessor func(sdktrace.SpanProcessor) sdktrace.SpanProcessor
+	if conf.TailSampling != nil {
+		// Every span has to be recorded for the tail sampling processor to take its decision,
+		// but the head sampling decision is the one propagated downstream.
+		sampler = recordingSampler{Sampler: sampler}
+		wrapProcessor = func(next sdktrace.SpanProcessor) sdktrace.SpanProcessor {
+			return newTailSamplingProcessor(next, conf.TailSampling)
+		}
+	}
+
+	tr, closer, err := backend.Setup(ctx, conf.ServiceName, sampler, wrapProcessor, conf.ResourceAttributes)
 	if err != nil {
 		return nil, nil, err
 	}
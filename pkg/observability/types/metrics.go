@@ -29,6 +29,7 @@ type Prometheus struct {
 	EntryPoint           string            `description:"EntryPoint" json:"entryPoint,omitempty" toml:"entryPoint,omitempty" yaml:"entryPoint,omitempty" export:"true"`
 	ManualRouting        bool              `description:"Manual routing" json:"manualRouting,omitempty" toml:"manualRouting,omitempty" yaml:"manualRouting,omitempty" export:"true"`
 	HeaderLabels         map[string]string `description:"Defines the extra labels for the requests_total metrics, and for each of them, the request header containing the value for this label." json:"headerLabels,omitempty" toml:"headerLabels,omitempty" yaml:"headerLabels,omitempty" export:"true"`
+	AddExemplars         bool              `description:"Attach the trace and span IDs of sampled requests as exemplars to the request duration histograms (requires the OpenMetrics exposition format, ignored with tail sampling)." json:"addExemplars,omitempty" toml:"addExemplars,omitempty" yaml:"addExemplars,omitempty" export:"true"`
 }
 
 // SetDefaults sets the default values.
@@ -119,6 +120,7 @@ type OTLP struct {
 	PushInterval         types.Duration    `description:"Period between calls to collect a checkpoint." json:"pushInterval,omitempty" toml:"pushInterval,omitempty" yaml:"pushInterval,omitempty" export:"true"`
 	ServiceName          string            `description:"Defines the service name resource attribute." json:"serviceName,omitempty" toml:"serviceName,omitempty" yaml:"serviceName,omitempty" export:"true"`
 	ResourceAttributes   map[string]string `description:"Defines additional resource attributes (key:value)." json:"resourceAttributes,omitempty" toml:"resourceAttributes,omitempty" yaml:"resourceAttributes,omitempty" export:"true"`
+	AddExemplars         bool              `description:"Attach the trace and span IDs of sampled requests as exemplars to the request duration histograms (ignored with tail sampling)." json:"addExemplars,omitempty" toml:"addExemplars,omitempty" yaml:"addExemplars,omitempty" export:"true"`
 }
 
 // SetDefaults sets the default values.
@@ -131,6 +133,7 @@ func (o *OTLP) SetDefaults() {
 	o.ExplicitBoundaries = []float64{.005, .01, .025, .05, .075, .1, .25, .5, .75, 1, 2.5, 5, 7.5, 10}
 	o.PushInterval = types.Duration(10 * time.Second)
 	o.ServiceName = OTelIngressServiceName
+	o.AddExemplars = true
 }
 
 // Statistics provides options for monitoring request and response stats.
@@ -0,0 +1,25 @@
+package types
+
+import (
+	"time"
+
+	ptypes "github.com/hanzoai/ingress-parser/types"
+)
+
+// TailSampling configures the tail-based sampling of traces.
+type TailSampling struct {
+	DecisionWait     ptypes.Duration `description:"Maximum duration during which the spans of a trace are buffered before taking the sampling decision." json:"decisionWait,omitempty" toml:"decisionWait,omitempty" yaml:"decisionWait,omitempty" export:"true"`
+	MaxTraces        int             `description:"Maximum number of traces buffered at once. When reached, the sampling decision is taken early for the oldest trace." json:"maxTraces,omitempty" toml:"maxTraces,omitempty" yaml:"maxTraces,omitempty" export:"true"`
+	MaxSpansPerTrace int             `description:"Maximum number of spans buffered per trace. Extra spans are dropped, except the local root span which evicts the last buffered one." json:"maxSpansPerTrace,omitempty" toml:"maxSpansPerTrace,omitempty" yaml:"maxSpansPerTrace,omitempty" export:"true"`
+	LatencyThreshold ptypes.Duration `description:"Traces lasting longer than this duration are always kept." json:"latencyThreshold,omitempty" toml:"latencyThreshold,omitempty" yaml:"latencyThreshold,omitempty" export:"true"`
+	SampleRate       float64         `description:"Sets the rate between 0.0 and 1.0 of traces to keep among the ones without errors, 5xx responses, retries or high latency." json:"sampleRate,omitempty" toml:"sampleRate,omitempty" yaml:"sampleRate,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (t *TailSampling) SetDefaults() {
+	t.DecisionWait = ptypes.Duration(10 * time.Second)
+	t.MaxTraces = 10000
+	t.MaxSpansPerTrace = 512
+	t.LatencyThreshold = ptypes.Duration(time.Second)
+	t.SampleRate = 0.1
+}
@@ -53,7 +53,8 @@ func (c *OTelTracing) SetDefaults() {
 }
 
 // Setup sets up the tracer.
-func (c *OTelTracing) Setup(ctx context.Context, serviceName string, sampleRate float64, resourceAttributes map[string]string) (trace.Tracer, io.Closer, error) {
+// When wrapProcessor is not nil, it wraps the span processor exporting the spans.
+func (c *OTelTracing) Setup(ctx context.Context, serviceName string, sampler sdktrace.Sampler, wrapProcessor func(sdktrace.SpanProcessor) sdktrace.SpanProcessor, resourceAttributes map[string]string) (trace.Tracer, io.Closer, error) {
 	var (
 		err      error
 		exporter *otlptrace.Exporter
@@ -95,11 +96,15 @@ func (c *OTelTracing) Setup(ctx context.Context, serviceName string, sampleRate
 
 	// Register the trace exporter with a TracerProvider, using a batch
 	// span processor to aggregate spans before export.
-	bsp := sdktrace.NewBatchSpanProcessor(exporter)
+	var sp sdktrace.SpanProcessor = sdktrace.NewBatchSpanProcessor(exporter)
+	if wrapProcessor != nil {
+		sp = wrapProcessor(sp)
+	}
+
 	tracerProvider := sdktrace.NewTracerProvider(
-		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRate))),
+		sdktrace.WithSampler(sampler),
 		sdktrace.WithResource(res),
-		sdktrace.WithSpanProcessor(bsp),
+		sdktrace.WithSpanProcessor(sp),
 	)
 
 	otel.SetTracerProvider(tracerProvider)
@@ -4,14 +4,18 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+
+	"github.com/hanzoai/ingress/pkg/provider/status"
 )
 
 // Handler expose ping routes.
 type Handler struct {
-	EntryPoint            string `description:"EntryPoint" json:"entryPoint,omitempty" toml:"entryPoint,omitempty" yaml:"entryPoint,omitempty" export:"true"`
-	ManualRouting         bool   `description:"Manual routing" json:"manualRouting,omitempty" toml:"manualRouting,omitempty" yaml:"manualRouting,omitempty" export:"true"`
-	TerminatingStatusCode int    `description:"Terminating status code" json:"terminatingStatusCode,omitempty" toml:"terminatingStatusCode,omitempty" yaml:"terminatingStatusCode,omitempty" export:"true"`
+	EntryPoint            string   `description:"EntryPoint" json:"entryPoint,omitempty" toml:"entryPoint,omitempty" yaml:"entryPoint,omitempty" export:"true"`
+	ManualRouting         bool     `description:"Manual routing" json:"manualRouting,omitempty" toml:"manualRouting,omitempty" yaml:"manualRouting,omitempty" export:"true"`
+	TerminatingStatusCode int      `description:"Terminating status code" json:"terminatingStatusCode,omitempty" toml:"terminatingStatusCode,omitempty" yaml:"terminatingStatusCode,omitempty" export:"true"`
+	RequiredProviders     []string `description:"Providers which must have delivered their configuration before the ping endpoint reports ready." json:"requiredProviders,omitempty" toml:"requiredProviders,omitempty" yaml:"requiredProviders,omitempty" export:"true"`
 	terminating           bool
+	providerStatus        *status.Tracker
 }
 
 // SetDefaults sets the default values.
@@ -28,10 +32,18 @@ func (h *Handler) WithContext(ctx context.Context) {
 	}()
 }
 
+// UseProviderStatus sets the tracker used to know whether the required providers delivered their configuration.
+func (h *Handler) UseProviderStatus(t *status.Tracker) {
+	h.providerStatus = t
+}
+
 func (h *Handler) ServeHTTP(response http.ResponseWriter, request *http.Request) {
 	statusCode := http.StatusOK
-	if h.terminating {
+	switch {
+	case h.terminating:
 		statusCode = h.TerminatingStatusCode
+	case len(h.RequiredProviders) > 0 && !h.providerStatus.Synced(h.RequiredProviders...):
+		statusCode = http.StatusServiceUnavailable
 	}
 	response.WriteHeader(statusCode)
 	fmt.Fprint(response, http.StatusText(statusCode))
@@ -0,0 +1,44 @@
+package ping
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/provider/status"
+)
+
+func TestHandler_RequiredProviders(t *testing.T) {
+	handler := &Handler{RequiredProviders: []string{"file", "docker"}}
+	handler.SetDefaults()
+
+	// Without provider status, the required providers are never synced.
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rw.Code)
+
+	providerStatus := status.NewTracker(nil)
+	handler.UseProviderStatus(providerStatus)
+
+	providerStatus.Updated("file", &dynamic.Configuration{})
+
+	rw = httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rw.Code)
+
+	providerStatus.Updated("docker", &dynamic.Configuration{})
+
+	rw = httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+
+	// An error of a synced provider does not make the ping endpoint fail.
+	providerStatus.Failed("docker", errors.New("connection refused"))
+
+	rw = httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+}
@@ -65,6 +65,7 @@ type ProviderAggregator struct {
 	fileProvider              provider.Provider
 	providers                 []provider.Provider
 	providersThrottleDuration time.Duration
+	failureHandler            provider.FailureHandler
 }
 
 // NewProviderAggregator returns an aggregate of all the providers configured in the static configuration.
@@ -89,8 +90,14 @@ func NewProviderAggregator(conf static.Providers) *ProviderAggregator {
 		p.quietAddProvider(conf.Rest)
 	}
 
+	if conf.Git != nil {
+		p.quietAddProvider(conf.Git)
+	}
+
 	if conf.KubernetesIngress != nil {
-		p.quietAddProvider(conf.KubernetesIngress)
+		for _, pvd := range conf.KubernetesIngress.BuildProviders() {
+			p.quietAddProvider(pvd)
+		}
 	}
 
 	if conf.KubernetesIngressNGINX != nil {
@@ -98,7 +105,9 @@ func NewProviderAggregator(conf static.Providers) *ProviderAggregator {
 	}
 
 	if conf.KubernetesCRD != nil {
-		p.quietAddProvider(conf.KubernetesCRD)
+		for _, pvd := range conf.KubernetesCRD.BuildProviders() {
+			p.quietAddProvider(pvd)
+		}
 	}
 
 	if conf.Knative != nil {
@@ -106,7 +115,14 @@ func NewProviderAggregator(conf static.Providers) *ProviderAggregator {
 	}
 
 	if conf.KubernetesGateway != nil {
-		p.quietAddProvider(conf.KubernetesGateway)
+		for _, pvd := range conf.KubernetesGateway.BuildProviders() {
+			// The instances watching a named cluster reference the CRD resources of the same cluster.
+			if conf.KubernetesCRD != nil && pvd.ClusterName() != "" {
+				conf.KubernetesCRD.FillExtensionBuilderRegistry(pvd)
+			}
+
+			p.quietAddProvider(pvd)
+		}
 	}
 
 	if conf.Ecs != nil {
@@ -169,6 +185,11 @@ func (p *ProviderAggregator) AddProvider(provider provider.Provider) error {
 	return nil
 }
 
+// SetFailureHandler sets the handler called with the failures reported by the providers.
+func (p *ProviderAggregator) SetFailureHandler(handler provider.FailureHandler) {
+	p.failureHandler = handler
+}
+
 // Init the provider.
 func (p *ProviderAggregator) Init() error {
 	return nil
@@ -216,6 +237,10 @@ func (p *ProviderAggregator) launchProvider(configurationChan chan<- dynamic.Mes
 		}
 	}
 
+	if reporter, ok := prd.(provider.FailureReporter); ok {
+		reporter.SetFailureHandler(p.failureHandler)
+	}
+
 	log.Info().Msgf("Starting provider %T%s", prd, namespaceInfo)
 	log.Debug().RawJSON("config", []byte(jsonConf)).Msgf("%T provider configuration%s", prd, namespaceInfo)
 
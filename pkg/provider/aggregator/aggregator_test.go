@@ -2,6 +2,7 @@ package aggregator
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 	"time"
 
@@ -72,6 +73,29 @@ func TestLaunchNamespacedProvider(t *testing.T) {
 	assert.Contains(t, output, "Starting provider *aggregator.mockNamespacedProvider (namespace: test-namespace)")
 }
 
+func TestProviderAggregator_SetFailureHandler(t *testing.T) {
+	var failures []string
+
+	aggregator := ProviderAggregator{
+		providers: []provider.Provider{&failingProviderMock{name: "docker"}},
+	}
+	aggregator.SetFailureHandler(func(providerName string, err error) {
+		failures = append(failures, providerName+": "+err.Error())
+	})
+
+	cfgCh := make(chan dynamic.Message)
+	pool := safe.NewPool(t.Context())
+
+	t.Cleanup(pool.Stop)
+
+	err := aggregator.Provide(cfgCh, pool)
+	require.NoError(t, err)
+
+	requireReceivedMessageFromProviders(t, cfgCh, []string{"docker"})
+
+	assert.Equal(t, []string{"docker: connection refused"}, failures)
+}
+
 // requireReceivedMessageFromProviders makes sure the given providers have emitted a message on the given message channel.
 // Providers order is not enforced.
 func requireReceivedMessageFromProviders(t *testing.T, cfgCh <-chan dynamic.Message, names []string) {
@@ -109,6 +133,31 @@ func (p *providerMock) Provide(configurationChan chan<- dynamic.Message, pool *s
 	return nil
 }
 
+// failingProviderMock is a mock implementation of FailureReporter reporting a failure before its configuration.
+type failingProviderMock struct {
+	name           string
+	failureHandler provider.FailureHandler
+}
+
+func (p *failingProviderMock) Init() error {
+	return nil
+}
+
+func (p *failingProviderMock) SetFailureHandler(handler provider.FailureHandler) {
+	p.failureHandler = handler
+}
+
+func (p *failingProviderMock) Provide(configurationChan chan<- dynamic.Message, _ *safe.Pool) error {
+	p.failureHandler.Report(p.name, errors.New("connection refused"))
+
+	configurationChan <- dynamic.Message{
+		ProviderName:  p.name,
+		Configuration: &dynamic.Configuration{},
+	}
+
+	return nil
+}
+
 // mockNamespacedProvider is a mock implementation of NamespacedProvider for testing.
 type mockNamespacedProvider struct {
 	namespace string
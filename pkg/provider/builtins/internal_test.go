@@ -12,6 +12,7 @@ import (
 	"github.com/hanzoai/ingress/pkg/config/static"
 	otypes "github.com/hanzoai/ingress/pkg/observability/types"
 	"github.com/hanzoai/ingress/pkg/ping"
+	"github.com/hanzoai/ingress/pkg/provider/git"
 	"github.com/hanzoai/ingress/pkg/provider/rest"
 	"github.com/hanzoai/ingress/pkg/types"
 )
@@ -148,6 +149,26 @@ func Test_createConfiguration(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "git_webhook_insecure.json",
+			staticCfg: static.Configuration{
+				Providers: &static.Providers{
+					Git: &git.Provider{
+						Webhook: &git.Webhook{Insecure: true},
+					},
+				},
+			},
+		},
+		{
+			desc: "git_webhook_secure.json",
+			staticCfg: static.Configuration{
+				Providers: &static.Providers{
+					Git: &git.Provider{
+						Webhook: &git.Webhook{},
+					},
+				},
+			},
+		},
 		{
 			desc: "prometheus_simple.json",
 			staticCfg: static.Configuration{
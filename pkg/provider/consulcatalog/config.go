@@ -279,6 +279,10 @@ func (p *Provider) addServer(item itemData, loadBalancer *dynamic.ServersLoadBal
 		return errors.New("address is missing")
 	}
 
+	if loadBalancer.Servers[0].Zone == "" {
+		loadBalancer.Servers[0].Zone = item.Zone
+	}
+
 	if loadBalancer.Servers[0].URL != "" {
 		if loadBalancer.Servers[0].Scheme != "" || loadBalancer.Servers[0].Port != "" {
 			return errors.New("defining scheme or port is not allowed when URL is defined")
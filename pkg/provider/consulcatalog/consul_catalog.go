@@ -30,12 +30,16 @@ const defaultTemplateRule = "Host(`{{ normalize .Name }}`)"
 // providerName is the Consul Catalog provider name.
 const providerName = "consulcatalog"
 
+// zoneNodeMetaKey is the key of the node metadata holding the zone of the node.
+const zoneNodeMetaKey = "zone"
+
 var _ provider.Provider = (*Provider)(nil)
 
 type itemData struct {
 	ID         string
 	Node       string
 	Datacenter string
+	Zone       string
 	Name       string
 	Namespace  string
 	Address    string
@@ -113,6 +117,7 @@ type Provider struct {
 	defaultRuleTpl    *template.Template
 	certChan          chan *connectCert
 	watchServicesChan chan struct{}
+	failureHandler    provider.FailureHandler
 }
 
 // EndpointConfig holds configurations of the endpoint.
@@ -132,6 +137,11 @@ type EndpointHTTPAuthConfig struct {
 	Password string `description:"Basic Auth password" json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty" loggable:"false"`
 }
 
+// SetFailureHandler sets the handler called with the failures of the provider to connect to or to watch its source.
+func (p *Provider) SetFailureHandler(handler provider.FailureHandler) {
+	p.failureHandler = handler
+}
+
 // Init the provider.
 func (p *Provider) Init() error {
 	defaultRuleTpl, err := provider.MakeDefaultRuleTemplate(p.DefaultRule, nil)
@@ -237,6 +247,7 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 
 		notify := func(err error, time time.Duration) {
 			logger.Error().Err(err).Msgf("Provider error, retrying in %s", time)
+			p.failureHandler.Report(p.name, err)
 		}
 
 		err := backoff.RetryNotify(safe.OperationWithRecover(operation), backoff.WithContext(job.NewBackOff(backoff.NewExponentialBackOff()), ctxLog), notify)
@@ -334,6 +345,7 @@ func (p *Provider) getConsulServicesData(ctx context.Context) ([]itemData, error
 				ID:         consulService.Service.ID,
 				Node:       consulService.Node.Node,
 				Datacenter: consulService.Node.Datacenter,
+				Zone:       consulService.Node.Meta[zoneNodeMetaKey],
 				Namespace:  namespace,
 				Name:       name,
 				Address:    address,
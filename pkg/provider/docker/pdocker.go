@@ -29,6 +29,8 @@ var _ provider.Provider = (*Provider)(nil)
 type Provider struct {
 	Shared       `yaml:",inline" export:"true"`
 	ClientConfig `yaml:",inline" export:"true"`
+
+	failureHandler provider.FailureHandler
 }
 
 // SetDefaults sets the default values.
@@ -39,6 +41,11 @@ func (p *Provider) SetDefaults() {
 	p.DefaultRule = DefaultTemplateRule
 }
 
+// SetFailureHandler sets the handler called with the failures of the provider to connect to or to watch its source.
+func (p *Provider) SetFailureHandler(handler provider.FailureHandler) {
+	p.failureHandler = handler
+}
+
 // Init the provider.
 func (p *Provider) Init() error {
 	defaultRuleTpl, err := provider.MakeDefaultRuleTemplate(p.DefaultRule, nil)
@@ -146,6 +153,7 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 
 		notify := func(err error, time time.Duration) {
 			logger.Error().Err(err).Msgf("Provider error, retrying in %s", time)
+			p.failureHandler.Report(dockerName, err)
 		}
 		err := backoff.RetryNotify(safe.OperationWithRecover(operation), backoff.WithContext(job.NewBackOff(backoff.NewExponentialBackOff()), ctxLog), notify)
 		if err != nil {
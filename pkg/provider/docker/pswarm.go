@@ -32,6 +32,8 @@ type SwarmProvider struct {
 	ClientConfig `yaml:",inline" export:"true"`
 
 	RefreshSeconds ptypes.Duration `description:"Polling interval for swarm mode." json:"refreshSeconds,omitempty" toml:"refreshSeconds,omitempty" yaml:"refreshSeconds,omitempty" export:"true"`
+
+	failureHandler provider.FailureHandler
 }
 
 // SetDefaults sets the default values.
@@ -43,6 +45,11 @@ func (p *SwarmProvider) SetDefaults() {
 	p.DefaultRule = DefaultTemplateRule
 }
 
+// SetFailureHandler sets the handler called with the failures of the provider to connect to or to watch its source.
+func (p *SwarmProvider) SetFailureHandler(handler provider.FailureHandler) {
+	p.failureHandler = handler
+}
+
 // Init the provider.
 func (p *SwarmProvider) Init() error {
 	defaultRuleTpl, err := provider.MakeDefaultRuleTemplate(p.DefaultRule, nil)
@@ -140,6 +147,7 @@ func (p *SwarmProvider) Provide(configurationChan chan<- dynamic.Message, pool *
 
 		notify := func(err error, time time.Duration) {
 			logger.Error().Err(err).Msgf("Provider error, retrying in %s", time)
+			p.failureHandler.Report(swarmName, err)
 		}
 		err := backoff.RetryNotify(safe.OperationWithRecover(operation), backoff.WithContext(job.NewBackOff(backoff.NewExponentialBackOff()), ctxLog), notify)
 		if err != nil {
@@ -45,6 +45,7 @@ type Provider struct {
 	AccessKeyID          string   `description:"AWS credentials access key ID to use for making requests." json:"accessKeyID,omitempty" toml:"accessKeyID,omitempty" yaml:"accessKeyID,omitempty" loggable:"false"`
 	SecretAccessKey      string   `description:"AWS credentials access key to use for making requests." json:"secretAccessKey,omitempty" toml:"secretAccessKey,omitempty" yaml:"secretAccessKey,omitempty" loggable:"false"`
 	defaultRuleTpl       *template.Template
+	failureHandler       provider.FailureHandler
 }
 
 type ecsInstance struct {
@@ -93,6 +94,11 @@ func (p *Provider) SetDefaults() {
 	p.DefaultRule = DefaultTemplateRule
 }
 
+// SetFailureHandler sets the handler called with the failures of the provider to connect to or to watch its source.
+func (p *Provider) SetFailureHandler(handler provider.FailureHandler) {
+	p.failureHandler = handler
+}
+
 // Init the provider.
 func (p *Provider) Init() error {
 	defaultRuleTpl, err := provider.MakeDefaultRuleTemplate(p.DefaultRule, nil)
@@ -140,6 +146,7 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 
 		notify := func(err error, time time.Duration) {
 			logger.Error().Err(err).Msgf("Provider error, retrying in %s", time)
+			p.failureHandler.Report("ecs", err)
 		}
 		err := backoff.RetryNotify(safe.OperationWithRecover(operation), backoff.WithContext(job.NewBackOff(backoff.NewExponentialBackOff()), routineCtx), notify)
 		if err != nil {
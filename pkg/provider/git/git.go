@@ -0,0 +1,385 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	ptypes "github.com/hanzoai/ingress-parser/types"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/job"
+	"github.com/hanzoai/ingress/pkg/observability/logs"
+	"github.com/hanzoai/ingress/pkg/provider"
+	"github.com/hanzoai/ingress/pkg/provider/file"
+	"github.com/hanzoai/ingress/pkg/safe"
+)
+
+const providerName = "git"
+
+// WebhookPath is the path of the webhook triggering the synchronization of the repository.
+const WebhookPath = "/v1/ingress/git/webhook"
+
+// maxWebhookBodySize is the maximum size of the webhook payloads, only read to authenticate them.
+const maxWebhookBodySize = 10 << 20
+
+var _ provider.Provider = (*Provider)(nil)
+
+// Provider is a provider.Provider implementation that loads the dynamic configuration from a Git repository.
+type Provider struct {
+	Repository                string          `description:"URL of the Git repository, using HTTPS or SSH." json:"repository,omitempty" toml:"repository,omitempty" yaml:"repository,omitempty"`
+	Branch                    string          `description:"Branch to check out. Defaults to the default branch of the repository." json:"branch,omitempty" toml:"branch,omitempty" yaml:"branch,omitempty" export:"true"`
+	Tag                       string          `description:"Tag to check out, instead of a branch." json:"tag,omitempty" toml:"tag,omitempty" yaml:"tag,omitempty" export:"true"`
+	Directory                 string          `description:"Directory of the repository from which the .yml or .toml files are loaded. Defaults to the root of the repository." json:"directory,omitempty" toml:"directory,omitempty" yaml:"directory,omitempty" export:"true"`
+	PollInterval              ptypes.Duration `description:"Polling interval for the repository. Polling is disabled when set to 0, in which case the webhook must be enabled." json:"pollInterval,omitempty" toml:"pollInterval,omitempty" yaml:"pollInterval,omitempty" export:"true"`
+	Timeout                   ptypes.Duration `description:"Timeout of a synchronization of the repository." json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty" export:"true"`
+	Username                  string          `description:"Username for HTTPS authentication." json:"username,omitempty" toml:"username,omitempty" yaml:"username,omitempty" loggable:"false"`
+	Password                  string          `description:"Password or token for HTTPS authentication." json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty" loggable:"false"`
+	SSHKey                    string          `description:"Path of the private key (deploy key) for SSH authentication." json:"sshKey,omitempty" toml:"sshKey,omitempty" yaml:"sshKey,omitempty"`
+	KnownHosts                string          `description:"Path of the known hosts file used to verify the SSH server. Defaults to the SSH client one." json:"knownHosts,omitempty" toml:"knownHosts,omitempty" yaml:"knownHosts,omitempty"`
+	VerifyCommits             bool            `description:"Only apply commits signed by one of the trusted keys." json:"verifyCommits,omitempty" toml:"verifyCommits,omitempty" yaml:"verifyCommits,omitempty" export:"true"`
+	TrustedKeys               string          `description:"Path of the file holding the armored OpenPGP public keys trusted to sign commits." json:"trustedKeys,omitempty" toml:"trustedKeys,omitempty" yaml:"trustedKeys,omitempty"`
+	Webhook                   *Webhook        `description:"Enables the webhook triggering the synchronization of the repository." json:"webhook,omitempty" toml:"webhook,omitempty" yaml:"webhook,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	DebugLogGeneratedTemplate bool            `description:"Enable debug logging of generated configuration template." json:"debugLogGeneratedTemplate,omitempty" toml:"debugLogGeneratedTemplate,omitempty" yaml:"debugLogGeneratedTemplate,omitempty" export:"true"`
+
+	workDir        string
+	refresh        chan struct{}
+	lastCommit     string
+	failureHandler provider.FailureHandler
+}
+
+// Webhook holds the webhook configuration.
+type Webhook struct {
+	Insecure bool   `description:"Activate the webhook directly on the entryPoint named ingress." json:"insecure,omitempty" toml:"insecure,omitempty" yaml:"insecure,omitempty" export:"true"`
+	Secret   string `description:"Secret authenticating the webhook calls, as a GitHub or Gitea signature, or a GitLab token. Required." json:"secret,omitempty" toml:"secret,omitempty" yaml:"secret,omitempty" loggable:"false"`
+}
+
+// SetDefaults sets the default values.
+func (p *Provider) SetDefaults() {
+	p.PollInterval = ptypes.Duration(time.Minute)
+	p.Timeout = ptypes.Duration(time.Minute)
+}
+
+// SetFailureHandler sets the handler called with the failures of the provider to connect to or to watch its source.
+func (p *Provider) SetFailureHandler(handler provider.FailureHandler) {
+	p.failureHandler = handler
+}
+
+// Init the provider.
+func (p *Provider) Init() error {
+	if p.Repository == "" {
+		return errors.New("non-empty repository is required")
+	}
+
+	if p.Branch != "" && p.Tag != "" {
+		return errors.New("branch and tag are mutually exclusive")
+	}
+
+	if p.PollInterval < 0 {
+		return errors.New("poll interval must be greater than or equal to 0")
+	}
+
+	if p.PollInterval == 0 && p.Webhook == nil {
+		return errors.New("poll interval must be greater than 0 when the webhook is disabled")
+	}
+
+	if p.Webhook != nil && p.Webhook.Secret == "" {
+		return errors.New("webhook secret is required when the webhook is enabled")
+	}
+
+	if p.Directory != "" && !filepath.IsLocal(p.Directory) {
+		return fmt.Errorf("directory %s is outside of the repository", p.Directory)
+	}
+
+	if p.VerifyCommits && p.TrustedKeys == "" {
+		return errors.New("trusted keys are required to verify the commits")
+	}
+
+	var err error
+	p.workDir, err = os.MkdirTemp("", "ingress-git-")
+	if err != nil {
+		return fmt.Errorf("creating work directory: %w", err)
+	}
+
+	if err := os.Mkdir(p.workTree(), 0o700); err != nil {
+		return fmt.Errorf("creating work tree: %w", err)
+	}
+
+	if p.VerifyCommits {
+		if err := p.importTrustedKeys(); err != nil {
+			return fmt.Errorf("importing trusted keys: %w", err)
+		}
+	}
+
+	p.refresh = make(chan struct{}, 1)
+
+	return nil
+}
+
+// CreateRouter creates a router for the webhook.
+func (p *Provider) CreateRouter() *mux.Router {
+	router := mux.NewRouter()
+	router.Methods(http.MethodPost).Path(WebhookPath).HandlerFunc(p.serveWebhook)
+	return router
+}
+
+// Provide allows the provider to provide configurations to ingress using the given configuration channel.
+func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.Pool) error {
+	pool.GoCtx(func(routineCtx context.Context) {
+		defer os.RemoveAll(p.workDir)
+
+		logger := log.Ctx(routineCtx).With().Str(logs.ProviderName, providerName).Logger()
+		ctxLog := logger.WithContext(routineCtx)
+
+		operation := func() error {
+			if err := p.updateConfiguration(ctxLog, configurationChan); err != nil {
+				return err
+			}
+
+			var tick <-chan time.Time
+			if p.PollInterval > 0 {
+				ticker := time.NewTicker(time.Duration(p.PollInterval))
+				defer ticker.Stop()
+				tick = ticker.C
+			}
+
+			for {
+				select {
+				case <-tick:
+				case <-p.refresh:
+					logger.Debug().Msg("Synchronization triggered by the webhook")
+				case <-routineCtx.Done():
+					return nil
+				}
+
+				if err := p.updateConfiguration(ctxLog, configurationChan); err != nil {
+					return err
+				}
+			}
+		}
+
+		notify := func(err error, time time.Duration) {
+			logger.Error().Err(err).Msgf("Provider error, retrying in %s", time)
+			p.failureHandler.Report(providerName, err)
+		}
+		err := backoff.RetryNotify(safe.OperationWithRecover(operation), backoff.WithContext(job.NewBackOff(backoff.NewExponentialBackOff()), ctxLog), notify)
+		if err != nil {
+			logger.Error().Err(err).Msg("Cannot retrieve data")
+		}
+	})
+
+	return nil
+}
+
+// updateConfiguration fetches the repository, and sends the configuration of the fetched commit
+// to the given configurationChan when it differs from the last applied one.
+func (p *Provider) updateConfiguration(ctx context.Context, configurationChan chan<- dynamic.Message) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(p.Timeout))
+	defer cancel()
+
+	commit, err := p.fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching repository: %w", err)
+	}
+
+	if commit == p.lastCommit {
+		return nil
+	}
+
+	if p.VerifyCommits {
+		if _, err := p.git(ctx, "verify-commit", commit); err != nil {
+			return fmt.Errorf("verifying signature of commit %s: %w", commit, err)
+		}
+	}
+
+	if _, err := p.git(ctx, "--work-tree", p.workTree(), "checkout", "--force", commit); err != nil {
+		return fmt.Errorf("checking out commit %s: %w", commit, err)
+	}
+
+	renderer := &file.Provider{
+		Directory:                 filepath.Join(p.workTree(), filepath.FromSlash(p.Directory)),
+		DebugLogGeneratedTemplate: p.DebugLogGeneratedTemplate,
+	}
+
+	configuration, err := renderer.BuildConfiguration()
+	if err != nil {
+		return fmt.Errorf("building configuration of commit %s: %w", commit, err)
+	}
+
+	log.Ctx(ctx).Debug().Msgf("Applying configuration of commit %s", commit)
+
+	p.lastCommit = commit
+
+	configurationChan <- dynamic.Message{
+		ProviderName:  providerName,
+		Configuration: configuration,
+	}
+
+	return nil
+}
+
+// fetch fetches the configured branch or tag, and returns the hash of the fetched commit.
+func (p *Provider) fetch(ctx context.Context) (string, error) {
+	if _, err := os.Stat(p.gitDir()); os.IsNotExist(err) {
+		if _, err := p.git(ctx, "init", "--bare", "--quiet", p.gitDir()); err != nil {
+			return "", err
+		}
+	}
+
+	ref := "HEAD"
+	switch {
+	case p.Tag != "":
+		ref = "refs/tags/" + p.Tag
+	case p.Branch != "":
+		ref = "refs/heads/" + p.Branch
+	}
+
+	if _, err := p.git(ctx, "fetch", "--quiet", "--depth=1", "--no-tags", "--", p.Repository, ref); err != nil {
+		return "", err
+	}
+
+	commit, err := p.git(ctx, "rev-parse", "--verify", "FETCH_HEAD^{commit}")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(commit), nil
+}
+
+// git runs a git command against the local repository, and returns its output.
+func (p *Provider) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"--git-dir", p.gitDir()}, args...)...)
+	cmd.Env = append(os.Environ(), p.env()...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+
+	return stdout.String(), nil
+}
+
+// env returns the environment of the git commands, holding the credentials,
+// so that they are neither part of the arguments nor stored in the repository.
+func (p *Provider) env() []string {
+	env := []string{"GIT_TERMINAL_PROMPT=0"}
+
+	if p.VerifyCommits {
+		env = append(env, "GNUPGHOME="+p.gnupgHome())
+	}
+
+	if p.Username != "" || p.Password != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte(p.Username + ":" + p.Password))
+		env = append(env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic "+credentials,
+		)
+	}
+
+	if p.SSHKey != "" {
+		sshCommand := "ssh -o BatchMode=yes -o IdentitiesOnly=yes -i " + shellQuote(p.SSHKey)
+		if p.KnownHosts != "" {
+			sshCommand += " -o StrictHostKeyChecking=yes -o UserKnownHostsFile=" + shellQuote(p.KnownHosts)
+		}
+		env = append(env, "GIT_SSH_COMMAND="+sshCommand)
+	}
+
+	return env
+}
+
+// importTrustedKeys imports the trusted keys in a keyring dedicated to the provider.
+func (p *Provider) importTrustedKeys() error {
+	if err := os.Mkdir(p.gnupgHome(), 0o700); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("gpg", "--batch", "--quiet", "--import", p.TrustedKeys)
+	cmd.Env = append(os.Environ(), "GNUPGHOME="+p.gnupgHome())
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+func (p *Provider) serveWebhook(rw http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxWebhookBodySize))
+	if err != nil {
+		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if p.Webhook == nil || !authenticated(req, body, p.Webhook.Secret) {
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// A synchronization is already pending otherwise.
+	select {
+	case p.refresh <- struct{}{}:
+	default:
+	}
+
+	rw.WriteHeader(http.StatusAccepted)
+}
+
+func (p *Provider) gitDir() string {
+	return filepath.Join(p.workDir, "repository.git")
+}
+
+func (p *Provider) workTree() string {
+	return filepath.Join(p.workDir, "worktree")
+}
+
+func (p *Provider) gnupgHome() string {
+	return filepath.Join(p.workDir, "gnupg")
+}
+
+// authenticated checks the signature of the GitHub and Gitea webhooks, or the token of the GitLab ones.
+func authenticated(req *http.Request, body []byte, secret string) bool {
+	if token := req.Header.Get("X-Gitlab-Token"); token != "" {
+		return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+	}
+
+	signature, ok := strings.CutPrefix(req.Header.Get("X-Hub-Signature-256"), "sha256=")
+	if !ok {
+		signature = req.Header.Get("X-Gitea-Signature")
+	}
+
+	expected, err := hex.DecodeString(signature)
+	if err != nil || len(expected) == 0 {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,296 @@
+package git
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+)
+
+func TestProvider_updateConfiguration(t *testing.T) {
+	repository := newRepository(t)
+
+	repository.commit(t, map[string]string{
+		"README.md":           "Routing configuration.",
+		"dynamic/routers.yml": "http:\n  routers:\n    api:\n      rule: Host(`api.example.com`)\n      service: api\n",
+		"dynamic/services.toml": `[http.services.api.loadBalancer]
+  [[http.services.api.loadBalancer.servers]]
+    url = "http://{{ "10.0.0.1" }}"
+`,
+	})
+
+	p := &Provider{Repository: repository.url, Branch: "main", Directory: "dynamic"}
+	p.SetDefaults()
+	require.NoError(t, p.Init())
+	t.Cleanup(func() { _ = os.RemoveAll(p.workDir) })
+
+	configurationChan := make(chan dynamic.Message, 10)
+
+	require.NoError(t, p.updateConfiguration(t.Context(), configurationChan))
+	require.Len(t, configurationChan, 1)
+
+	msg := <-configurationChan
+	assert.Equal(t, "git", msg.ProviderName)
+	assert.Equal(t, "Host(`api.example.com`)", msg.Configuration.HTTP.Routers["api"].Rule)
+	assert.Equal(t, "http://10.0.0.1", msg.Configuration.HTTP.Services["api"].LoadBalancer.Servers[0].URL)
+
+	// Unchanged repository.
+	require.NoError(t, p.updateConfiguration(t.Context(), configurationChan))
+	assert.Empty(t, configurationChan)
+
+	repository.remove(t, "dynamic/services.toml")
+
+	require.NoError(t, p.updateConfiguration(t.Context(), configurationChan))
+	require.Len(t, configurationChan, 1)
+
+	msg = <-configurationChan
+	assert.Contains(t, msg.Configuration.HTTP.Routers, "api")
+	assert.Empty(t, msg.Configuration.HTTP.Services)
+}
+
+func TestProvider_updateConfiguration_tag(t *testing.T) {
+	repository := newRepository(t)
+
+	repository.commit(t, map[string]string{"routers.yml": "http:\n  routers:\n    v1:\n      rule: Path(`/v1`)\n      service: api\n"})
+	repository.run(t, "tag", "v1")
+	repository.commit(t, map[string]string{"routers.yml": "http:\n  routers:\n    v2:\n      rule: Path(`/v2`)\n      service: api\n"})
+
+	p := &Provider{Repository: repository.url, Tag: "v1"}
+	p.SetDefaults()
+	require.NoError(t, p.Init())
+	t.Cleanup(func() { _ = os.RemoveAll(p.workDir) })
+
+	configurationChan := make(chan dynamic.Message, 1)
+
+	require.NoError(t, p.updateConfiguration(t.Context(), configurationChan))
+
+	msg := <-configurationChan
+	assert.Contains(t, msg.Configuration.HTTP.Routers, "v1")
+	assert.NotContains(t, msg.Configuration.HTTP.Routers, "v2")
+}
+
+func TestProvider_updateConfiguration_verifyCommits(t *testing.T) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg is not available")
+	}
+
+	repository := newRepository(t)
+
+	gnupgHome, err := os.MkdirTemp("", "gnupg-")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(gnupgHome) })
+	t.Setenv("GNUPGHOME", gnupgHome)
+
+	gpg(t, "--quick-generate-key", "--passphrase", "", "alice@example.com", "ed25519", "sign", "never")
+	gpg(t, "--quick-generate-key", "--passphrase", "", "mallory@example.com", "ed25519", "sign", "never")
+
+	trustedKeys := filepath.Join(t.TempDir(), "trusted.asc")
+	require.NoError(t, os.WriteFile(trustedKeys, gpg(t, "--armor", "--export", "alice@example.com"), 0o600))
+
+	p := &Provider{Repository: repository.url, VerifyCommits: true, TrustedKeys: trustedKeys}
+	p.SetDefaults()
+	require.NoError(t, p.Init())
+	t.Cleanup(func() { _ = os.RemoveAll(p.workDir) })
+
+	configurationChan := make(chan dynamic.Message, 1)
+
+	repository.commit(t, map[string]string{"routers.yml": "http:\n  routers:\n    unsigned: {}\n"})
+	require.ErrorContains(t, p.updateConfiguration(t.Context(), configurationChan), "verifying signature of commit")
+
+	repository.commit(t, map[string]string{"routers.yml": "http:\n  routers:\n    untrusted: {}\n"}, "-Smallory@example.com")
+	require.ErrorContains(t, p.updateConfiguration(t.Context(), configurationChan), "verifying signature of commit")
+	assert.Empty(t, configurationChan)
+
+	repository.commit(t, map[string]string{"routers.yml": "http:\n  routers:\n    signed: {}\n"}, "-Salice@example.com")
+	require.NoError(t, p.updateConfiguration(t.Context(), configurationChan))
+
+	msg := <-configurationChan
+	assert.Contains(t, msg.Configuration.HTTP.Routers, "signed")
+}
+
+func TestProvider_Init(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		provider    Provider
+		expectedErr string
+	}{
+		{
+			desc:        "missing repository",
+			provider:    Provider{PollInterval: 1},
+			expectedErr: "non-empty repository is required",
+		},
+		{
+			desc:        "branch and tag",
+			provider:    Provider{Repository: "https://example.com/config.git", Branch: "main", Tag: "v1", PollInterval: 1},
+			expectedErr: "branch and tag are mutually exclusive",
+		},
+		{
+			desc:        "polling and webhook disabled",
+			provider:    Provider{Repository: "https://example.com/config.git"},
+			expectedErr: "poll interval must be greater than 0 when the webhook is disabled",
+		},
+		{
+			desc:        "webhook without secret",
+			provider:    Provider{Repository: "https://example.com/config.git", Webhook: &Webhook{}},
+			expectedErr: "webhook secret is required when the webhook is enabled",
+		},
+		{
+			desc:        "directory outside of the repository",
+			provider:    Provider{Repository: "https://example.com/config.git", Directory: "../etc", PollInterval: 1},
+			expectedErr: "directory ../etc is outside of the repository",
+		},
+		{
+			desc:        "verify commits without trusted keys",
+			provider:    Provider{Repository: "https://example.com/config.git", VerifyCommits: true, PollInterval: 1},
+			expectedErr: "trusted keys are required to verify the commits",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			require.EqualError(t, test.provider.Init(), test.expectedErr)
+		})
+	}
+}
+
+func TestProvider_webhook(t *testing.T) {
+	signature := func(body string) string {
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte(body))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	testCases := []struct {
+		desc           string
+		secret         string
+		headers        map[string]string
+		expectedStatus int
+	}{
+		{
+			desc:           "GitHub signature",
+			secret:         "secret",
+			headers:        map[string]string{"X-Hub-Signature-256": "sha256=" + signature("{}")},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			desc:           "Gitea signature",
+			secret:         "secret",
+			headers:        map[string]string{"X-Gitea-Signature": signature("{}")},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			desc:           "GitLab token",
+			secret:         "secret",
+			headers:        map[string]string{"X-Gitlab-Token": "secret"},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			desc:           "invalid signature",
+			secret:         "secret",
+			headers:        map[string]string{"X-Hub-Signature-256": "sha256=" + signature("other")},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "invalid token",
+			secret:         "secret",
+			headers:        map[string]string{"X-Gitlab-Token": "other"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "missing signature",
+			secret:         "secret",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p := &Provider{
+				Webhook: &Webhook{Secret: test.secret},
+				refresh: make(chan struct{}, 1),
+			}
+
+			req := httptest.NewRequest(http.MethodPost, WebhookPath, strings.NewReader("{}"))
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+
+			rec := httptest.NewRecorder()
+			p.CreateRouter().ServeHTTP(rec, req)
+
+			assert.Equal(t, test.expectedStatus, rec.Code)
+			assert.Equal(t, test.expectedStatus == http.StatusAccepted, len(p.refresh) == 1)
+		})
+	}
+}
+
+type repository struct {
+	dir string
+	url string
+}
+
+func newRepository(t *testing.T) *repository {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	dir := t.TempDir()
+	r := &repository{dir: dir, url: "file://" + dir}
+	r.run(t, "init", "--quiet", "--initial-branch", "main")
+
+	return r
+}
+
+func (r *repository) commit(t *testing.T, files map[string]string, args ...string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(r.dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	r.run(t, "add", "--all")
+	r.run(t, append([]string{"commit", "--quiet", "--message", "Update configuration"}, args...)...)
+}
+
+func (r *repository) remove(t *testing.T, name string) {
+	t.Helper()
+
+	r.run(t, "rm", "--quiet", name)
+	r.run(t, "commit", "--quiet", "--message", "Remove "+name)
+}
+
+func (r *repository) run(t *testing.T, args ...string) {
+	t.Helper()
+
+	cmd := exec.CommandContext(context.Background(), "git", append([]string{"-C", r.dir, "-c", "user.name=Test", "-c", "user.email=test@example.com", "-c", "commit.gpgSign=false", "-c", "tag.gpgSign=false"}, args...)...)
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+}
+
+func gpg(t *testing.T, args ...string) []byte {
+	t.Helper()
+
+	output, err := exec.CommandContext(context.Background(), "gpg", append([]string{"--batch", "--quiet"}, args...)...).Output()
+	require.NoError(t, err)
+
+	return output
+}
@@ -35,6 +35,7 @@ type Provider struct {
 
 	httpClient            *http.Client
 	lastConfigurationHash uint64
+	failureHandler        provider.FailureHandler
 }
 
 // SetDefaults sets the default values.
@@ -43,6 +44,11 @@ func (p *Provider) SetDefaults() {
 	p.PollTimeout = ptypes.Duration(5 * time.Second)
 }
 
+// SetFailureHandler sets the handler called with the failures of the provider to connect to or to watch its source.
+func (p *Provider) SetFailureHandler(handler provider.FailureHandler) {
+	p.failureHandler = handler
+}
+
 // Init the provider.
 func (p *Provider) Init() error {
 	if p.Endpoint == "" {
@@ -100,6 +106,7 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 
 		notify := func(err error, time time.Duration) {
 			logger.Error().Err(err).Msgf("Provider error, retrying in %s", time)
+			p.failureHandler.Report("http", err)
 		}
 		err := backoff.RetryNotify(safe.OperationWithRecover(operation), backoff.WithContext(job.NewBackOff(backoff.NewExponentialBackOff()), ctxLog), notify)
 		if err != nil {
@@ -40,7 +40,7 @@ import (
 
 const (
 	annotationKubernetesIngressClass = "kubernetes.io/ingress.class"
-	defaultIngressClass       = "ingress"
+	defaultIngressClass              = "ingress"
 )
 
 const (
@@ -62,21 +62,54 @@ type Provider struct {
 	AllowEmptyServices           bool                `description:"Allow the creation of services without endpoints." json:"allowEmptyServices,omitempty" toml:"allowEmptyServices,omitempty" yaml:"allowEmptyServices,omitempty" export:"true"`
 	NativeLBByDefault            bool                `description:"Defines whether to use Native Kubernetes load-balancing mode by default." json:"nativeLBByDefault,omitempty" toml:"nativeLBByDefault,omitempty" yaml:"nativeLBByDefault,omitempty" export:"true"`
 	DisableClusterScopeResources bool                `description:"Disables the lookup of cluster scope resources (incompatible with IngressClasses and NodePortLB enabled services)." json:"disableClusterScopeResources,omitempty" toml:"disableClusterScopeResources,omitempty" yaml:"disableClusterScopeResources,omitempty" export:"true"`
+	Clusters                     []k8s.Cluster       `description:"Kubernetes clusters to watch, each one with its own provider instance." json:"clusters,omitempty" toml:"clusters,omitempty" yaml:"clusters,omitempty" export:"true"`
 
-	lastConfiguration safe.Safe
+	name    string
+	cluster *k8s.Cluster
 
 	routerTransform k8s.RouterTransform
+	failureHandler  provider.FailureHandler
+}
+
+// BuildProviders builds one provider instance per configured cluster,
+// or returns the provider itself when no cluster is configured.
+func (p *Provider) BuildProviders() []*Provider {
+	if len(p.Clusters) == 0 {
+		return []*Provider{p}
+	}
+
+	var providers []*Provider
+	for _, cluster := range p.Clusters {
+		pvd := *p
+		pvd.Clusters = nil
+		pvd.name = providerName + "-" + cluster.Name
+		pvd.cluster = &cluster
+
+		providers = append(providers, &pvd)
+	}
+
+	return providers
+}
+
+// SetFailureHandler sets the handler called with the failures of the provider to connect to or to watch its source.
+func (p *Provider) SetFailureHandler(handler provider.FailureHandler) {
+	p.failureHandler = handler
 }
 
 // Init the provider.
 func (p *Provider) Init() error {
+	// In case they didn't initialize Provider with BuildProviders.
+	if p.name == "" {
+		p.name = providerName
+	}
+
 	return nil
 }
 
 // Provide allows the k8s provider to provide configurations to ingress
 // using the given configuration channel.
 func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.Pool) error {
-	logger := log.With().Str(logs.ProviderName, providerName).Logger()
+	logger := log.With().Str(logs.ProviderName, p.name).Logger()
 	ctxLog := logger.WithContext(context.Background())
 
 	k8sClient, err := p.newK8sClient(ctxLog)
@@ -93,6 +126,8 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 	}
 
 	pool.GoCtx(func(ctxPool context.Context) {
+		var lastConfiguration safe.Safe
+
 		operation := func() error {
 			eventsChan, err := k8sClient.WatchAll(p.Namespaces, ctxPool.Done())
 			if err != nil {
@@ -126,12 +161,12 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 					switch {
 					case err != nil:
 						logger.Error().Err(err).Msg("Unable to hash the configuration")
-					case p.lastConfiguration.Get() == confHash:
+					case lastConfiguration.Get() == confHash:
 						logger.Debug().Msgf("Skipping Kubernetes event kind %T", event)
 					default:
-						p.lastConfiguration.Set(confHash)
+						lastConfiguration.Set(confHash)
 						configurationChan <- dynamic.Message{
-							ProviderName:  providerName,
+							ProviderName:  p.name,
 							Configuration: conf,
 						}
 					}
@@ -146,6 +181,7 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 
 		notify := func(err error, time time.Duration) {
 			logger.Error().Err(err).Msgf("Provider error, retrying in %s", time)
+			p.failureHandler.Report(p.name, err)
 		}
 		err := backoff.RetryNotify(safe.OperationWithRecover(operation), backoff.WithContext(job.NewBackOff(backoff.NewExponentialBackOff()), ctxPool), notify)
 		if err != nil {
@@ -160,24 +196,71 @@ func (p *Provider) SetRouterTransform(routerTransform k8s.RouterTransform) {
 	p.routerTransform = routerTransform
 }
 
+// clusterRegistry is implemented by the extension builder registries of the provider instances watching a named cluster.
+type clusterRegistry interface {
+	ClusterName() string
+}
+
 func (p *Provider) FillExtensionBuilderRegistry(registry gateway.ExtensionBuilderRegistry) {
+	// The resources are referenced through the provider instance watching the cluster of the registry.
+	var cluster string
+	if r, ok := registry.(clusterRegistry); ok {
+		cluster = r.ClusterName()
+	}
+	pName, pNameErr := p.instanceName(cluster)
+
 	registry.RegisterFilterFuncs(hanzoaiv1alpha1.GroupName, "Middleware", func(name, namespace string) (string, *dynamic.Middleware, error) {
+		if pNameErr != nil {
+			return "", nil, pNameErr
+		}
+
 		if len(p.Namespaces) > 0 && !slices.Contains(p.Namespaces, namespace) {
 			return "", nil, fmt.Errorf("namespace %q is not allowed", namespace)
 		}
 
-		return makeID(namespace, name) + providerNamespaceSeparator + providerName, nil, nil
+		return makeID(namespace, name) + providerNamespaceSeparator + pName, nil, nil
 	})
 
 	registry.RegisterBackendFuncs(hanzoaiv1alpha1.GroupName, "IngressService", func(name, namespace string) (string, *dynamic.Service, error) {
+		if pNameErr != nil {
+			return "", nil, pNameErr
+		}
+
 		if len(p.Namespaces) > 0 && !slices.Contains(p.Namespaces, namespace) {
 			return "", nil, fmt.Errorf("namespace %q is not allowed", namespace)
 		}
 
-		return makeID(namespace, name) + providerNamespaceSeparator + providerName, nil, nil
+		return makeID(namespace, name) + providerNamespaceSeparator + pName, nil, nil
 	})
 }
 
+// instanceName returns the name of the provider instance watching the given cluster, an empty name meaning the default one.
+// Without configured clusters, the single provider instance is used whatever the cluster.
+func (p *Provider) instanceName(cluster string) (string, error) {
+	if len(p.Clusters) == 0 {
+		return providerName, nil
+	}
+
+	for _, c := range p.Clusters {
+		if c.Name == cluster {
+			return providerName + "-" + c.Name, nil
+		}
+	}
+
+	return "", fmt.Errorf("cluster %q is not watched by the Kubernetes CRD provider", cluster)
+}
+
+// hasProviderSuffix reports whether the given resource name is suffixed by the name of a Kubernetes CRD provider instance,
+// i.e. @kubernetescrd or @kubernetescrd-<cluster>.
+func hasProviderSuffix(name string) bool {
+	if !strings.Contains(name, providerNamespaceSeparator) {
+		return false
+	}
+
+	_, pName := splitSvcNameProvider(name)
+	return pName == providerName || strings.HasPrefix(pName, providerName+"-")
+}
+
 func (p *Provider) applyRouterTransform(ctx context.Context, rt *dynamic.Router, ingress *hanzoaiv1alpha1.IngressRoute) {
 	if p.routerTransform == nil {
 		return
@@ -203,6 +286,9 @@ func (p *Provider) newK8sClient(ctx context.Context) (*clientWrapper, error) {
 
 	var client *clientWrapper
 	switch {
+	case p.cluster != nil:
+		log.Ctx(ctx).Info().Msgf("Creating Provider client for cluster %s", p.cluster.Name)
+		client, err = newClusterClient(*p.cluster)
 	case os.Getenv("KUBERNETES_SERVICE_HOST") != "" && os.Getenv("KUBERNETES_SERVICE_PORT") != "":
 		log.Ctx(ctx).Info().Msgf("Creating in-cluster Provider client%s", withEndpoint)
 		client, err = newInClusterClient(p.Endpoint)
@@ -344,6 +430,7 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 
 	cb := configBuilder{
 		client:                    client,
+		providerName:              p.name,
 		allowCrossNamespace:       p.AllowCrossNamespace,
 		allowExternalNameServices: p.AllowExternalNameServices,
 		allowEmptyServices:        p.AllowEmptyServices,
@@ -658,6 +745,7 @@ func (p *Provider) createErrorPageMiddleware(ctx context.Context, client Client,
 
 	cb := configBuilder{
 		client:                    client,
+		providerName:              p.name,
 		allowCrossNamespace:       p.AllowCrossNamespace,
 		allowExternalNameServices: p.AllowExternalNameServices,
 		allowEmptyServices:        p.AllowEmptyServices,
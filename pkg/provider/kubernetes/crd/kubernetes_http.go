@@ -14,6 +14,7 @@ import (
 	"github.com/hanzoai/ingress/pkg/observability/logs"
 	"github.com/hanzoai/ingress/pkg/provider"
 	hanzoaiv1alpha1 "github.com/hanzoai/ingress/pkg/provider/kubernetes/crd/hanzoai/v1alpha1"
+	"github.com/hanzoai/ingress/pkg/provider/kubernetes/k8s"
 	"github.com/hanzoai/ingress/pkg/tls"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -56,6 +57,7 @@ func (p *Provider) loadIngressRouteConfiguration(ctx context.Context, client Cli
 
 		cb := configBuilder{
 			client:                       client,
+			providerName:                 p.name,
 			allowCrossNamespace:          p.AllowCrossNamespace,
 			allowExternalNameServices:    p.AllowExternalNameServices,
 			allowEmptyServices:           p.AllowEmptyServices,
@@ -178,7 +180,7 @@ func makeMiddlewareKeys(ctx context.Context, namespace string, middlewares []han
 	for _, mi := range middlewares {
 		name := mi.Name
 
-		if !allowCrossNamespace && strings.HasSuffix(mi.Name, providerNamespaceSeparator+providerName) {
+		if !allowCrossNamespace && hasProviderSuffix(mi.Name) {
 			// Since we are not able to know if another namespace is in the name (namespace-name@kubernetescrd),
 			// if the provider namespace kubernetescrd is used,
 			// we don't allow this format to avoid cross namespace references.
@@ -257,6 +259,7 @@ func resolveParentRouterNames(client Client, ingressRoute *hanzoaiv1alpha1.Ingre
 
 type configBuilder struct {
 	client                       Client
+	providerName                 string
 	allowCrossNamespace          bool
 	allowExternalNameServices    bool
 	allowEmptyServices           bool
@@ -519,7 +522,7 @@ func (c configBuilder) makeServersTransportKey(parentNamespace string, serversTr
 		return "", nil
 	}
 
-	if !c.allowCrossNamespace && strings.HasSuffix(serversTransportName, providerNamespaceSeparator+providerName) {
+	if !c.allowCrossNamespace && hasProviderSuffix(serversTransportName) {
 		// Since we are not able to know if another namespace is in the name (namespace-name@kubernetescrd),
 		// if the provider namespace kubernetescrd is used,
 		// we don't allow this format to avoid cross namespace references.
@@ -541,7 +544,10 @@ func (c configBuilder) loadServers(parentNamespace string, svc hanzoaiv1alpha1.L
 	}
 
 	// If the service uses explicitly the provider suffix
-	sanitizedName := strings.TrimSuffix(svc.Name, providerNamespaceSeparator+providerName)
+	sanitizedName := svc.Name
+	if name, pName := splitSvcNameProvider(svc.Name); strings.Contains(svc.Name, providerNamespaceSeparator) && c.isLocalProvider(pName) {
+		sanitizedName = name
+	}
 	service, exists, err := c.client.GetService(namespace, sanitizedName)
 	if err != nil {
 		return nil, err
@@ -670,6 +676,7 @@ func (c configBuilder) loadServers(parentNamespace string, svc hanzoaiv1alpha1.L
 				servers = append(servers, dynamic.Server{
 					URL:    fmt.Sprintf("%s://%s", protocol, net.JoinHostPort(address, strconv.Itoa(int(port)))),
 					Fenced: ptr.Deref(endpoint.Conditions.Terminating, false),
+					Zone:   k8s.EndpointZone(endpoint),
 				})
 			}
 		}
@@ -702,12 +709,12 @@ func (c configBuilder) nameAndService(ctx context.Context, parentNamespace strin
 			return "", nil, err
 		}
 
-		fullName := fullServiceName(svcCtx, namespace, service, service.Port)
+		fullName := c.fullServiceName(svcCtx, namespace, service, service.Port)
 
 		return fullName, serversLB, nil
 
 	case "IngressService":
-		return fullServiceName(svcCtx, namespace, service, intstr.FromInt(0)), nil, nil
+		return c.fullServiceName(svcCtx, namespace, service, intstr.FromInt(0)), nil, nil
 
 	default:
 		return "", nil, fmt.Errorf("unsupported service kind %s", service.Kind)
@@ -755,7 +762,12 @@ func splitSvcNameProvider(name string) (string, string) {
 	return svc, pvd
 }
 
-func fullServiceName(ctx context.Context, namespace string, service hanzoaiv1alpha1.LoadBalancerSpec, port intstr.IntOrString) string {
+// isLocalProvider reports whether the given provider name designates the provider instance building the configuration.
+func (c configBuilder) isLocalProvider(pName string) bool {
+	return pName == providerName || (c.providerName != "" && pName == c.providerName)
+}
+
+func (c configBuilder) fullServiceName(ctx context.Context, namespace string, service hanzoaiv1alpha1.LoadBalancerSpec, port intstr.IntOrString) string {
 	if (port.Type == intstr.Int && port.IntVal != 0) || (port.Type == intstr.String && port.StrVal != "") {
 		return provider.Normalize(fmt.Sprintf("%s-%s-%s", namespace, service.Name, &port))
 	}
@@ -765,7 +777,7 @@ func fullServiceName(ctx context.Context, namespace string, service hanzoaiv1alp
 	}
 
 	name, pName := splitSvcNameProvider(service.Name)
-	if pName == providerName {
+	if c.isLocalProvider(pName) {
 		return provider.Normalize(fmt.Sprintf("%s-%s", namespace, name))
 	}
 
@@ -9152,6 +9152,62 @@ func TestFillExtensionBuilderRegistry(t *testing.T) {
 	}
 }
 
+func TestFillExtensionBuilderRegistry_multiCluster(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		clusters        []k8s.Cluster
+		registryCluster string
+		expected        string
+		expectedErr     string
+	}{
+		{
+			desc:            "single cluster",
+			registryCluster: "clusterB",
+			expected:        "default-my-middleware@kubernetescrd",
+		},
+		{
+			desc:            "same cluster",
+			clusters:        []k8s.Cluster{{Name: "clusterA"}, {Name: "clusterB"}},
+			registryCluster: "clusterB",
+			expected:        "default-my-middleware@kubernetescrd-clusterB",
+		},
+		{
+			desc:            "unwatched cluster",
+			clusters:        []k8s.Cluster{{Name: "clusterA"}},
+			registryCluster: "clusterB",
+			expectedErr:     `cluster "clusterB" is not watched by the Kubernetes CRD provider`,
+		},
+		{
+			desc:        "default cluster",
+			clusters:    []k8s.Cluster{{Name: "clusterA"}},
+			expectedErr: `cluster "" is not watched by the Kubernetes CRD provider`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			r := &extensionBuilderRegistryMock{cluster: test.registryCluster}
+
+			p := Provider{Clusters: test.clusters}
+			p.FillExtensionBuilderRegistry(r)
+
+			filterFunc, ok := r.groupKindFilterFuncs[hanzoaiv1alpha1.SchemeGroupVersion.Group]["Middleware"]
+			require.True(t, ok)
+
+			name, _, err := filterFunc("my-middleware", "default")
+			if test.expectedErr != "" {
+				require.EqualError(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, name)
+		})
+	}
+}
+
 func readResources(t *testing.T, paths []string) ([]runtime.Object, []runtime.Object) {
 	t.Helper()
 
@@ -9178,10 +9234,16 @@ func readResources(t *testing.T, paths []string) ([]runtime.Object, []runtime.Ob
 }
 
 type extensionBuilderRegistryMock struct {
+	cluster               string
 	groupKindFilterFuncs  map[string]map[string]gateway.BuildFilterFunc
 	groupKindBackendFuncs map[string]map[string]gateway.BuildBackendFunc
 }
 
+// ClusterName returns the name of the cluster watched by the registry.
+func (p *extensionBuilderRegistryMock) ClusterName() string {
+	return p.cluster
+}
+
 // RegisterFilterFuncs registers an allowed Group, Kind, and builder for the Filter ExtensionRef objects.
 func (p *extensionBuilderRegistryMock) RegisterFilterFuncs(group, kind string, builderFunc gateway.BuildFilterFunc) {
 	if p.groupKindFilterFuncs == nil {
@@ -9677,3 +9739,89 @@ func TestGlobalNativeLB(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildProviders(t *testing.T) {
+	p := &Provider{
+		Namespaces: []string{"default"},
+		Clusters: []k8s.Cluster{
+			{Name: "clusterA", KubeConfig: "/etc/kube/a.yaml"},
+			{Name: "clusterB", KubeConfig: "/etc/kube/b.yaml", Context: "admin@b"},
+		},
+	}
+
+	providers := p.BuildProviders()
+	require.Len(t, providers, 2)
+
+	for i, pvd := range providers {
+		require.NoError(t, pvd.Init())
+
+		assert.Equal(t, providerName+"-"+p.Clusters[i].Name, pvd.name)
+		assert.Equal(t, &p.Clusters[i], pvd.cluster)
+		assert.Equal(t, []string{"default"}, pvd.Namespaces)
+		assert.Empty(t, pvd.Clusters)
+	}
+
+	p = &Provider{}
+	providers = p.BuildProviders()
+	require.Len(t, providers, 1)
+
+	require.NoError(t, providers[0].Init())
+	assert.Equal(t, providerName, providers[0].name)
+	assert.Nil(t, providers[0].cluster)
+}
+
+func TestProviderSuffix(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		name         string
+		providerName string
+		expected     string
+		hasSuffix    bool
+	}{
+		{
+			desc:         "without provider",
+			name:         "whoami",
+			providerName: providerName,
+			expected:     "default-whoami",
+		},
+		{
+			desc:         "default provider",
+			name:         "whoami@kubernetescrd",
+			providerName: providerName,
+			expected:     "default-whoami",
+			hasSuffix:    true,
+		},
+		{
+			desc:         "same cluster provider",
+			name:         "whoami@kubernetescrd-clusterA",
+			providerName: "kubernetescrd-clusterA",
+			expected:     "default-whoami",
+			hasSuffix:    true,
+		},
+		{
+			desc:         "other cluster provider",
+			name:         "default-whoami@kubernetescrd-clusterB",
+			providerName: "kubernetescrd-clusterA",
+			expected:     "default-whoami@kubernetescrd-clusterB",
+			hasSuffix:    true,
+		},
+		{
+			desc:         "other provider",
+			name:         "whoami@file",
+			providerName: "kubernetescrd-clusterA",
+			expected:     "whoami@file",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.hasSuffix, hasProviderSuffix(test.name))
+
+			cb := configBuilder{providerName: test.providerName}
+			service := hanzoaiv1alpha1.LoadBalancerSpec{Name: test.name}
+			assert.Equal(t, test.expected, cb.fullServiceName(t.Context(), "default", service, intstr.FromInt(0)))
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package crd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	hanzoaiv1alpha1 "github.com/hanzoai/ingress/pkg/provider/kubernetes/crd/hanzoai/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	kscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+var manifestDecoder = sync.OnceValues(func() (runtime.Decoder, error) {
+	scheme := runtime.NewScheme()
+	if err := kscheme.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := hanzoaiv1alpha1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+
+	return serializer.NewCodecFactory(scheme).UniversalDeserializer(), nil
+})
+
+// DecodeManifest decodes a single Kubernetes object from a YAML or JSON manifest.
+// The object must be a core Kubernetes kind or one of the provider custom resources.
+func DecodeManifest(content []byte) (runtime.Object, error) {
+	decoder, err := manifestDecoder()
+	if err != nil {
+		return nil, fmt.Errorf("creating manifest decoder: %w", err)
+	}
+
+	obj, _, err := decoder.Decode(content, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+// LoadManifests builds the dynamic configuration from the given Kubernetes objects,
+// as the provider does from a cluster holding them, without connecting to any cluster.
+func (p *Provider) LoadManifests(ctx context.Context, objects []runtime.Object) (*dynamic.Configuration, error) {
+	client, err := newManifestClient(objects, p.LabelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("loading manifests: %w", err)
+	}
+
+	if _, err := client.WatchAll(p.Namespaces, nil); err != nil {
+		return nil, fmt.Errorf("loading manifests: %w", err)
+	}
+
+	return p.loadConfigurationFromCRD(ctx, client), nil
+}
+
+// manifestClient is a Client serving the given Kubernetes objects,
+// filtered as the clientWrapper does with the watched namespaces and the label selector.
+type manifestClient struct {
+	objects       []runtime.Object
+	labelSelector labels.Selector
+
+	isNamespaceAll    bool
+	watchedNamespaces []string
+}
+
+func newManifestClient(objects []runtime.Object, labelSelector string) (*manifestClient, error) {
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("parsing label selector %q: %w", labelSelector, err)
+	}
+
+	return &manifestClient{objects: objects, labelSelector: selector}, nil
+}
+
+// WatchAll only records the watched namespaces, the objects never change.
+func (c *manifestClient) WatchAll(namespaces []string, _ <-chan struct{}) (<-chan any, error) {
+	c.isNamespaceAll = len(namespaces) == 0
+	c.watchedNamespaces = namespaces
+
+	return make(chan any), nil
+}
+
+func (c *manifestClient) GetIngressRoutes() []*hanzoaiv1alpha1.IngressRoute {
+	return listObjects[*hanzoaiv1alpha1.IngressRoute](c)
+}
+
+func (c *manifestClient) GetIngressRouteTCPs() []*hanzoaiv1alpha1.IngressRouteTCP {
+	return listObjects[*hanzoaiv1alpha1.IngressRouteTCP](c)
+}
+
+func (c *manifestClient) GetIngressRouteUDPs() []*hanzoaiv1alpha1.IngressRouteUDP {
+	return listObjects[*hanzoaiv1alpha1.IngressRouteUDP](c)
+}
+
+func (c *manifestClient) GetMiddlewares() []*hanzoaiv1alpha1.Middleware {
+	return listObjects[*hanzoaiv1alpha1.Middleware](c)
+}
+
+func (c *manifestClient) GetMiddlewareTCPs() []*hanzoaiv1alpha1.MiddlewareTCP {
+	return listObjects[*hanzoaiv1alpha1.MiddlewareTCP](c)
+}
+
+func (c *manifestClient) GetIngressService(namespace, name string) (*hanzoaiv1alpha1.IngressService, bool, error) {
+	return getObject[*hanzoaiv1alpha1.IngressService](c, "ingress service", namespace, name)
+}
+
+func (c *manifestClient) GetIngressServices() []*hanzoaiv1alpha1.IngressService {
+	return listObjects[*hanzoaiv1alpha1.IngressService](c)
+}
+
+func (c *manifestClient) GetTLSOptions() []*hanzoaiv1alpha1.TLSOption {
+	return listObjects[*hanzoaiv1alpha1.TLSOption](c)
+}
+
+func (c *manifestClient) GetServersTransports() []*hanzoaiv1alpha1.ServersTransport {
+	return listObjects[*hanzoaiv1alpha1.ServersTransport](c)
+}
+
+func (c *manifestClient) GetServersTransportTCPs() []*hanzoaiv1alpha1.ServersTransportTCP {
+	return listObjects[*hanzoaiv1alpha1.ServersTransportTCP](c)
+}
+
+func (c *manifestClient) GetTLSStores() []*hanzoaiv1alpha1.TLSStore {
+	return listObjects[*hanzoaiv1alpha1.TLSStore](c)
+}
+
+func (c *manifestClient) GetService(namespace, name string) (*corev1.Service, bool, error) {
+	return getObject[*corev1.Service](c, "service", namespace, name)
+}
+
+func (c *manifestClient) GetSecret(namespace, name string) (*corev1.Secret, bool, error) {
+	secret, exists, err := getObject[*corev1.Secret](c, "secret", namespace, name)
+	// The secrets owned by Helm are not watched by the clientWrapper.
+	if !exists || err != nil || secret.Labels["owner"] == "helm" {
+		return nil, false, err
+	}
+
+	return secret, true, nil
+}
+
+func (c *manifestClient) GetEndpointSlicesForService(namespace, serviceName string) ([]*discoveryv1.EndpointSlice, error) {
+	if !c.isWatchedNamespace(namespace) {
+		return nil, fmt.Errorf("failed to get endpointslices for service %s/%s: namespace is not within watched namespaces", namespace, serviceName)
+	}
+
+	var result []*discoveryv1.EndpointSlice
+	for _, endpointSlice := range listObjects[*discoveryv1.EndpointSlice](c) {
+		if endpointSlice.Namespace == namespace && endpointSlice.Labels[discoveryv1.LabelServiceName] == serviceName {
+			result = append(result, endpointSlice)
+		}
+	}
+
+	return result, nil
+}
+
+func (c *manifestClient) GetNodes() ([]*corev1.Node, bool, error) {
+	var nodes []*corev1.Node
+	for _, obj := range c.objects {
+		if node, ok := obj.(*corev1.Node); ok {
+			nodes = append(nodes, node)
+		}
+	}
+
+	return nodes, true, nil
+}
+
+func (c *manifestClient) GetConfigMap(namespace, name string) (*corev1.ConfigMap, bool, error) {
+	return getObject[*corev1.ConfigMap](c, "config map", namespace, name)
+}
+
+func (c *manifestClient) isWatchedNamespace(namespace string) bool {
+	return c.isNamespaceAll || slices.Contains(c.watchedNamespaces, namespace)
+}
+
+// listObjects returns the objects of the given type in the watched namespaces,
+// the custom resources being filtered with the label selector.
+func listObjects[T interface {
+	runtime.Object
+	metav1.Object
+}](c *manifestClient) []T {
+	var result []T
+	for _, obj := range c.objects {
+		object, ok := obj.(T)
+		if !ok || !c.isWatchedNamespace(object.GetNamespace()) {
+			continue
+		}
+
+		if object.GetObjectKind().GroupVersionKind().Group == hanzoaiv1alpha1.GroupName && !c.labelSelector.Matches(labels.Set(object.GetLabels())) {
+			continue
+		}
+
+		result = append(result, object)
+	}
+
+	return result
+}
+
+// getObject returns the named object of the given type from the given namespace.
+func getObject[T interface {
+	runtime.Object
+	metav1.Object
+}](c *manifestClient, kind, namespace, name string) (T, bool, error) {
+	var zero T
+	if !c.isWatchedNamespace(namespace) {
+		return zero, false, fmt.Errorf("failed to get %s %s/%s: namespace is not within watched namespaces", kind, namespace, name)
+	}
+
+	for _, object := range listObjects[T](c) {
+		if object.GetNamespace() == namespace && object.GetName() == name {
+			return object, true, nil
+		}
+	}
+
+	return zero, false, nil
+}
@@ -0,0 +1,84 @@
+package crd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestLoadManifests(t *testing.T) {
+	var objects []runtime.Object
+	for _, path := range []string{"services.yml", "simple.yml"} {
+		content, err := os.ReadFile(filepath.Join("fixtures", path))
+		require.NoError(t, err)
+
+		for _, document := range strings.Split(string(content), "---\n") {
+			if strings.TrimSpace(document) == "" {
+				continue
+			}
+
+			obj, err := DecodeManifest([]byte(document))
+			require.NoError(t, err)
+
+			objects = append(objects, obj)
+		}
+	}
+
+	testCases := []struct {
+		desc            string
+		provider        Provider
+		expectedRouters int
+	}{
+		{
+			desc:            "all namespaces",
+			expectedRouters: 1,
+		},
+		{
+			desc:            "watched namespace",
+			provider:        Provider{Namespaces: []string{"default"}},
+			expectedRouters: 1,
+		},
+		{
+			desc:     "other namespace",
+			provider: Provider{Namespaces: []string{"other"}},
+		},
+		{
+			desc:     "label selector",
+			provider: Provider{LabelSelector: "app=other"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			conf, err := test.provider.LoadManifests(t.Context(), objects)
+			require.NoError(t, err)
+
+			require.Len(t, conf.HTTP.Routers, test.expectedRouters)
+			for _, router := range conf.HTTP.Routers {
+				assert.Equal(t, "Host(`foo.com`) && PathPrefix(`/bar`)", router.Rule)
+				assert.Equal(t, []string{"foo"}, router.EntryPoints)
+			}
+
+			require.Len(t, conf.HTTP.Services, test.expectedRouters)
+			for _, service := range conf.HTTP.Services {
+				require.NotNil(t, service.LoadBalancer)
+				assert.Len(t, service.LoadBalancer.Servers, 2)
+			}
+		})
+	}
+}
+
+func TestDecodeManifest_unsupportedKind(t *testing.T) {
+	_, err := DecodeManifest([]byte(`apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: foo
+`))
+	require.Error(t, err)
+	assert.True(t, runtime.IsNotRegisteredError(err))
+}
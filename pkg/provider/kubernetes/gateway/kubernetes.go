@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"slices"
 	"sort"
@@ -20,6 +21,7 @@ import (
 	"github.com/hanzoai/ingress/pkg/config/dynamic"
 	"github.com/hanzoai/ingress/pkg/job"
 	"github.com/hanzoai/ingress/pkg/observability/logs"
+	"github.com/hanzoai/ingress/pkg/provider"
 	hanzoaiv1alpha1 "github.com/hanzoai/ingress/pkg/provider/kubernetes/crd/hanzoai/v1alpha1"
 	"github.com/hanzoai/ingress/pkg/provider/kubernetes/k8s"
 	"github.com/hanzoai/ingress/pkg/safe"
@@ -72,6 +74,7 @@ type Provider struct {
 	ExperimentalChannel bool                `description:"Toggles Experimental Channel resources support (TCPRoute, TLSRoute...)." json:"experimentalChannel,omitempty" toml:"experimentalChannel,omitempty" yaml:"experimentalChannel,omitempty" export:"true"`
 	StatusAddress       *StatusAddress      `description:"Defines the Kubernetes Gateway status address." json:"statusAddress,omitempty" toml:"statusAddress,omitempty" yaml:"statusAddress,omitempty" export:"true"`
 	NativeLBByDefault   bool                `description:"Defines whether to use Native Kubernetes load-balancing by default." json:"nativeLBByDefault,omitempty" toml:"nativeLBByDefault,omitempty" yaml:"nativeLBByDefault,omitempty" export:"true"`
+	Clusters            []k8s.Cluster       `description:"Kubernetes clusters to watch, each one with its own provider instance." json:"clusters,omitempty" toml:"clusters,omitempty" yaml:"clusters,omitempty" export:"true"`
 
 	EntryPoints map[string]Entrypoint `json:"-" toml:"-" yaml:"-" label:"-" file:"-"`
 
@@ -80,10 +83,12 @@ type Provider struct {
 	// groupKindBackendFuncs is the list of allowed Group and Kinds for the Backend ExtensionRef objects.
 	groupKindBackendFuncs map[string]map[string]BuildBackendFunc
 
-	lastConfiguration safe.Safe
+	name    string
+	cluster *k8s.Cluster
 
 	routerTransform k8s.RouterTransform
 	client          *clientWrapper
+	failureHandler  provider.FailureHandler
 }
 
 // Entrypoint defines the available entry points.
@@ -165,9 +170,66 @@ func (p *Provider) SetRouterTransform(routerTransform k8s.RouterTransform) {
 	p.routerTransform = routerTransform
 }
 
+// BuildProviders builds one provider instance per configured cluster,
+// or returns the provider itself when no cluster is configured.
+func (p *Provider) BuildProviders() []*Provider {
+	if len(p.Clusters) == 0 {
+		return []*Provider{p}
+	}
+
+	var providers []*Provider
+	for _, cluster := range p.Clusters {
+		pvd := *p
+		pvd.Clusters = nil
+		pvd.name = providerName + "-" + cluster.Name
+		pvd.cluster = &cluster
+
+		// Each instance has its own extension builders, which can be registered again to reference the resources of its cluster.
+		pvd.groupKindFilterFuncs = cloneFuncs(p.groupKindFilterFuncs)
+		pvd.groupKindBackendFuncs = cloneFuncs(p.groupKindBackendFuncs)
+
+		providers = append(providers, &pvd)
+	}
+
+	return providers
+}
+
+// cloneFuncs clones the given builders by group and kind.
+func cloneFuncs[F any](funcs map[string]map[string]F) map[string]map[string]F {
+	if funcs == nil {
+		return nil
+	}
+
+	cloned := make(map[string]map[string]F, len(funcs))
+	for group, kinds := range funcs {
+		cloned[group] = maps.Clone(kinds)
+	}
+
+	return cloned
+}
+
+// ClusterName returns the name of the cluster watched by the provider instance, which is empty for the default cluster.
+func (p *Provider) ClusterName() string {
+	if p.cluster == nil {
+		return ""
+	}
+
+	return p.cluster.Name
+}
+
+// SetFailureHandler sets the handler called with the failures of the provider to connect to or to watch its source.
+func (p *Provider) SetFailureHandler(handler provider.FailureHandler) {
+	p.failureHandler = handler
+}
+
 // Init the provider.
 func (p *Provider) Init() error {
-	logger := log.With().Str(logs.ProviderName, providerName).Logger()
+	// In case they didn't initialize Provider with BuildProviders.
+	if p.name == "" {
+		p.name = providerName
+	}
+
+	logger := log.With().Str(logs.ProviderName, p.name).Logger()
 
 	var err error
 	p.client, err = p.newK8sClient(logger.WithContext(context.Background()))
@@ -180,10 +242,12 @@ func (p *Provider) Init() error {
 
 // Provide allows the k8s provider to provide configurations to ingress using the given configuration channel.
 func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.Pool) error {
-	logger := log.With().Str(logs.ProviderName, providerName).Logger()
+	logger := log.With().Str(logs.ProviderName, p.name).Logger()
 	ctxLog := logger.WithContext(context.Background())
 
 	pool.GoCtx(func(ctxPool context.Context) {
+		var lastConfiguration safe.Safe
+
 		operation := func() error {
 			eventsChan, err := p.client.WatchAll(p.Namespaces, ctxPool.Done())
 			if err != nil {
@@ -217,12 +281,12 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 					switch {
 					case err != nil:
 						logger.Error().Msg("Unable to hash the configuration")
-					case p.lastConfiguration.Get() == confHash:
+					case lastConfiguration.Get() == confHash:
 						logger.Debug().Msgf("Skipping Kubernetes event kind %T", event)
 					default:
-						p.lastConfiguration.Set(confHash)
+						lastConfiguration.Set(confHash)
 						configurationChan <- dynamic.Message{
-							ProviderName:  providerName,
+							ProviderName:  p.name,
 							Configuration: conf,
 						}
 					}
@@ -237,6 +301,7 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 
 		notify := func(err error, time time.Duration) {
 			logger.Error().Err(err).Msgf("Provider error, retrying in %s", time)
+			p.failureHandler.Report(p.name, err)
 		}
 		err := backoff.RetryNotify(safe.OperationWithRecover(operation), backoff.WithContext(job.NewBackOff(backoff.NewExponentialBackOff()), ctxPool), notify)
 		if err != nil {
@@ -269,6 +334,9 @@ func (p *Provider) newK8sClient(ctx context.Context) (*clientWrapper, error) {
 
 	var client *clientWrapper
 	switch {
+	case p.cluster != nil:
+		logger.Info().Str("cluster", p.cluster.Name).Msg("Creating Provider client for cluster")
+		client, err = newClusterClient(*p.cluster)
 	case os.Getenv("KUBERNETES_SERVICE_HOST") != "" && os.Getenv("KUBERNETES_SERVICE_PORT") != "":
 		logger.Info().Str("endpoint", p.Endpoint).Msg("Creating in-cluster Provider client")
 		client, err = newInClusterClient(p.Endpoint)
@@ -881,6 +949,7 @@ func (p *Provider) allowedNamespaces(gatewayNamespace string, routeNamespaces *g
 type backendAddress struct {
 	IP   string
 	Port int32
+	Zone string
 }
 
 func (p *Provider) getBackendAddresses(namespace string, ref gatev1.BackendRef) ([]backendAddress, corev1.ServicePort, error) {
@@ -964,6 +1033,7 @@ func (p *Provider) getBackendAddresses(namespace string, ref gatev1.BackendRef)
 				backendServers = append(backendServers, backendAddress{
 					IP:   address,
 					Port: port,
+					Zone: k8s.EndpointZone(endpoint),
 				})
 			}
 		}
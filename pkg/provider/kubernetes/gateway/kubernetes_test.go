@@ -8399,3 +8399,38 @@ func readResources(t *testing.T, paths []string) ([]runtime.Object, []runtime.Ob
 
 	return k8sObjects, gwObjects
 }
+
+func TestBuildProviders(t *testing.T) {
+	p := &Provider{Clusters: []k8s.Cluster{{Name: "clusterA"}, {Name: "clusterB"}}}
+	p.RegisterFilterFuncs(hanzoaiv1alpha1.GroupName, "Middleware", func(name, namespace string) (string, *dynamic.Middleware, error) {
+		return name + "@default", nil, nil
+	})
+
+	providers := p.BuildProviders()
+	require.Len(t, providers, 2)
+
+	// Each instance references the resources of its own cluster.
+	for _, pvd := range providers {
+		cluster := pvd.ClusterName()
+		pvd.RegisterFilterFuncs(hanzoaiv1alpha1.GroupName, "Middleware", func(name, namespace string) (string, *dynamic.Middleware, error) {
+			return name + "@" + cluster, nil, nil
+		})
+	}
+
+	for i, pvd := range providers {
+		assert.Equal(t, p.Clusters[i].Name, pvd.ClusterName())
+
+		name, _, err := pvd.groupKindFilterFuncs[hanzoaiv1alpha1.GroupName]["Middleware"]("my-middleware", "default")
+		require.NoError(t, err)
+		assert.Equal(t, "my-middleware@"+p.Clusters[i].Name, name)
+	}
+
+	name, _, err := p.groupKindFilterFuncs[hanzoaiv1alpha1.GroupName]["Middleware"]("my-middleware", "default")
+	require.NoError(t, err)
+	assert.Equal(t, "my-middleware@default", name)
+
+	p = &Provider{}
+	providers = p.BuildProviders()
+	require.Len(t, providers, 1)
+	assert.Empty(t, providers[0].ClusterName())
+}
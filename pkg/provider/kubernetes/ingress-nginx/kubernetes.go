@@ -23,6 +23,7 @@ import (
 	"github.com/hanzoai/ingress/pkg/job"
 	"github.com/hanzoai/ingress/pkg/observability/logs"
 	"github.com/hanzoai/ingress/pkg/provider"
+	"github.com/hanzoai/ingress/pkg/provider/kubernetes/k8s"
 	"github.com/hanzoai/ingress/pkg/safe"
 	"github.com/hanzoai/ingress/pkg/tls"
 	"github.com/hanzoai/ingress/pkg/types"
@@ -63,6 +64,7 @@ var nginxSizeRegexp = regexp.MustCompile(`^(?i)\s*([0-9]+)\s*([bkmg]?)\s*$`)
 type backendAddress struct {
 	Address string
 	Fenced  bool
+	Zone    string
 }
 
 type namedServersTransport struct {
@@ -117,6 +119,7 @@ type Provider struct {
 
 	k8sClient         *clientWrapper
 	lastConfiguration safe.Safe
+	failureHandler    provider.FailureHandler
 }
 
 func (p *Provider) SetDefaults() {
@@ -131,6 +134,11 @@ func (p *Provider) SetDefaults() {
 	p.ProxyBuffersNumber = defaultProxyBuffersNumber
 }
 
+// SetFailureHandler sets the handler called with the failures of the provider to connect to or to watch its source.
+func (p *Provider) SetFailureHandler(handler provider.FailureHandler) {
+	p.failureHandler = handler
+}
+
 // Init the provider.
 func (p *Provider) Init() error {
 	// Validates and parses the default backend configuration.
@@ -214,6 +222,7 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 
 		notify := func(err error, time time.Duration) {
 			logger.Error().Err(err).Msgf("Provider error, retrying in %s", time)
+			p.failureHandler.Report(providerName, err)
 		}
 
 		err := backoff.RetryNotify(safe.OperationWithRecover(operation), backoff.WithContext(job.NewBackOff(backoff.NewExponentialBackOff()), ctxPool), notify)
@@ -653,7 +662,8 @@ func (p *Provider) buildService(namespace string, backend netv1.IngressBackend,
 	svc := &dynamic.Service{LoadBalancer: lb}
 	for _, addr := range backendAddresses {
 		svc.LoadBalancer.Servers = append(svc.LoadBalancer.Servers, dynamic.Server{
-			URL: fmt.Sprintf("%s://%s", scheme, addr.Address),
+			URL:  fmt.Sprintf("%s://%s", scheme, addr.Address),
+			Zone: addr.Zone,
 		})
 	}
 
@@ -748,6 +758,7 @@ func (p *Provider) getBackendAddresses(namespace string, backend netv1.IngressBa
 				addresses = append(addresses, backendAddress{
 					Address: net.JoinHostPort(address, strconv.Itoa(int(port))),
 					Fenced:  ptr.Deref(endpoint.Conditions.Terminating, false),
+					Zone:    k8s.EndpointZone(endpoint),
 				})
 			}
 		}
@@ -34,10 +34,12 @@ import (
 )
 
 const (
-	annotationKubernetesIngressClass     = "kubernetes.io/ingress.class"
-	defaultIngressClass           = "ingress"
-	defaultIngressClassController = "hanzo.ai/ingress-controller"
-	defaultPathMatcher                   = "PathPrefix"
+	providerName = "kubernetes"
+
+	annotationKubernetesIngressClass = "kubernetes.io/ingress.class"
+	defaultIngressClass              = "ingress"
+	defaultIngressClassController    = "hanzo.ai/ingress-controller"
+	defaultPathMatcher               = "PathPrefix"
 )
 
 // Provider holds configurations of the provider.
@@ -53,32 +55,65 @@ type Provider struct {
 	AllowEmptyServices        bool                `description:"Allow creation of services without endpoints." json:"allowEmptyServices,omitempty" toml:"allowEmptyServices,omitempty" yaml:"allowEmptyServices,omitempty" export:"true"`
 	AllowExternalNameServices bool                `description:"Allow ExternalName services." json:"allowExternalNameServices,omitempty" toml:"allowExternalNameServices,omitempty" yaml:"allowExternalNameServices,omitempty" export:"true"`
 	// Deprecated: please use DisableClusterScopeResources.
-	DisableIngressClassLookup    bool `description:"Disables the lookup of IngressClasses (Deprecated, please use DisableClusterScopeResources)." json:"disableIngressClassLookup,omitempty" toml:"disableIngressClassLookup,omitempty" yaml:"disableIngressClassLookup,omitempty" export:"true"`
-	DisableClusterScopeResources bool `description:"Disables the lookup of cluster scope resources (incompatible with IngressClasses and NodePortLB enabled services)." json:"disableClusterScopeResources,omitempty" toml:"disableClusterScopeResources,omitempty" yaml:"disableClusterScopeResources,omitempty" export:"true"`
-	NativeLBByDefault            bool `description:"Defines whether to use Native Kubernetes load-balancing mode by default." json:"nativeLBByDefault,omitempty" toml:"nativeLBByDefault,omitempty" yaml:"nativeLBByDefault,omitempty" export:"true"`
-	StrictPrefixMatching         bool `description:"Make prefix matching strictly comply with the Kubernetes Ingress specification (path-element-wise matching instead of character-by-character string matching)." json:"strictPrefixMatching,omitempty" toml:"strictPrefixMatching,omitempty" yaml:"strictPrefixMatching,omitempty" export:"true"`
+	DisableIngressClassLookup    bool          `description:"Disables the lookup of IngressClasses (Deprecated, please use DisableClusterScopeResources)." json:"disableIngressClassLookup,omitempty" toml:"disableIngressClassLookup,omitempty" yaml:"disableIngressClassLookup,omitempty" export:"true"`
+	DisableClusterScopeResources bool          `description:"Disables the lookup of cluster scope resources (incompatible with IngressClasses and NodePortLB enabled services)." json:"disableClusterScopeResources,omitempty" toml:"disableClusterScopeResources,omitempty" yaml:"disableClusterScopeResources,omitempty" export:"true"`
+	NativeLBByDefault            bool          `description:"Defines whether to use Native Kubernetes load-balancing mode by default." json:"nativeLBByDefault,omitempty" toml:"nativeLBByDefault,omitempty" yaml:"nativeLBByDefault,omitempty" export:"true"`
+	StrictPrefixMatching         bool          `description:"Make prefix matching strictly comply with the Kubernetes Ingress specification (path-element-wise matching instead of character-by-character string matching)." json:"strictPrefixMatching,omitempty" toml:"strictPrefixMatching,omitempty" yaml:"strictPrefixMatching,omitempty" export:"true"`
+	Clusters                     []k8s.Cluster `description:"Kubernetes clusters to watch, each one with its own provider instance." json:"clusters,omitempty" toml:"clusters,omitempty" yaml:"clusters,omitempty" export:"true"`
 
 	// The default rule syntax is initialized with the configuration defined by the user with the core.DefaultRuleSyntax option.
 	DefaultRuleSyntax string `json:"-" toml:"-" yaml:"-" label:"-" file:"-"`
 
-	lastConfiguration safe.Safe
+	name    string
+	cluster *k8s.Cluster
 
 	routerTransform k8s.RouterTransform
+	failureHandler  provider.FailureHandler
 }
 
 func (p *Provider) SetRouterTransform(routerTransform k8s.RouterTransform) {
 	p.routerTransform = routerTransform
 }
 
+// BuildProviders builds one provider instance per configured cluster,
+// or returns the provider itself when no cluster is configured.
+func (p *Provider) BuildProviders() []*Provider {
+	if len(p.Clusters) == 0 {
+		return []*Provider{p}
+	}
+
+	var providers []*Provider
+	for _, cluster := range p.Clusters {
+		pvd := *p
+		pvd.Clusters = nil
+		pvd.name = providerName + "-" + cluster.Name
+		pvd.cluster = &cluster
+
+		providers = append(providers, &pvd)
+	}
+
+	return providers
+}
+
+// SetFailureHandler sets the handler called with the failures of the provider to connect to or to watch its source.
+func (p *Provider) SetFailureHandler(handler provider.FailureHandler) {
+	p.failureHandler = handler
+}
+
 // Init the provider.
 func (p *Provider) Init() error {
+	// In case they didn't initialize Provider with BuildProviders.
+	if p.name == "" {
+		p.name = providerName
+	}
+
 	return nil
 }
 
 // Provide allows the k8s provider to provide configurations to ingress
 // using the given configuration channel.
 func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.Pool) error {
-	logger := log.With().Str(logs.ProviderName, "kubernetes").Logger()
+	logger := log.With().Str(logs.ProviderName, p.name).Logger()
 	ctxLog := logger.WithContext(context.Background())
 
 	k8sClient, err := p.newK8sClient(ctxLog)
@@ -91,6 +126,8 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 	}
 
 	pool.GoCtx(func(ctxPool context.Context) {
+		var lastConfiguration safe.Safe
+
 		operation := func() error {
 			eventsChan, err := k8sClient.WatchAll(p.Namespaces, ctxPool.Done())
 			if err != nil {
@@ -126,12 +163,12 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 					switch {
 					case err != nil:
 						logger.Error().Msg("Unable to hash the configuration")
-					case p.lastConfiguration.Get() == confHash:
+					case lastConfiguration.Get() == confHash:
 						logger.Debug().Msgf("Skipping Kubernetes event kind %T", event)
 					default:
-						p.lastConfiguration.Set(confHash)
+						lastConfiguration.Set(confHash)
 						configurationChan <- dynamic.Message{
-							ProviderName:  "kubernetes",
+							ProviderName:  p.name,
 							Configuration: conf,
 						}
 					}
@@ -146,6 +183,7 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 
 		notify := func(err error, time time.Duration) {
 			logger.Error().Err(err).Msgf("Provider error, retrying in %s", time)
+			p.failureHandler.Report(p.name, err)
 		}
 
 		err := backoff.RetryNotify(safe.OperationWithRecover(operation), backoff.WithContext(job.NewBackOff(backoff.NewExponentialBackOff()), ctxPool), notify)
@@ -192,6 +230,9 @@ func (p *Provider) newK8sClient(ctx context.Context) (*clientWrapper, error) {
 
 	var cl *clientWrapper
 	switch {
+	case p.cluster != nil:
+		logger.Info().Msgf("Creating Provider client for cluster %s", p.cluster.Name)
+		cl, err = newClusterClient(*p.cluster)
 	case os.Getenv("KUBERNETES_SERVICE_HOST") != "" && os.Getenv("KUBERNETES_SERVICE_PORT") != "":
 		logger.Info().Msgf("Creating in-cluster Provider client%s", withEndpoint)
 		cl, err = newInClusterClient(p.Endpoint)
@@ -679,6 +720,7 @@ func (p *Provider) loadService(client Client, namespace string, backend netv1.In
 				svc.LoadBalancer.Servers = append(svc.LoadBalancer.Servers, dynamic.Server{
 					URL:    fmt.Sprintf("%s://%s", protocol, net.JoinHostPort(address, strconv.Itoa(int(port)))),
 					Fenced: ptr.Deref(endpoint.Conditions.Terminating, false),
+					Zone:   k8s.EndpointZone(endpoint),
 				})
 			}
 		}
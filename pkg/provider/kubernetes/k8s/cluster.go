@@ -0,0 +1,55 @@
+package k8s
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Cluster holds the connection settings of a named Kubernetes cluster.
+type Cluster struct {
+	Name       string `description:"Name of the cluster, used as a suffix of the provider name." json:"name,omitempty" toml:"name,omitempty" yaml:"name,omitempty" export:"true"`
+	KubeConfig string `description:"Path to the kubeconfig file of the cluster (defaults to the KUBECONFIG environment variable or ~/.kube/config)." json:"kubeConfig,omitempty" toml:"kubeConfig,omitempty" yaml:"kubeConfig,omitempty"`
+	Context    string `description:"Name of the kubeconfig context to use (defaults to the current context)." json:"context,omitempty" toml:"context,omitempty" yaml:"context,omitempty" export:"true"`
+}
+
+// RESTConfig builds the client configuration of the cluster from its kubeconfig and context.
+func (c Cluster) RESTConfig() (*rest.Config, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if c.KubeConfig != "" {
+		rules.ExplicitPath = c.KubeConfig
+	}
+
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: c.Context}
+
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading kubeconfig of cluster %q: %w", c.Name, err)
+	}
+
+	return config, nil
+}
+
+// ValidateClusters checks that the given clusters have unique names which can be used in a provider name.
+func ValidateClusters(clusters []Cluster) error {
+	names := make(map[string]struct{}, len(clusters))
+	for _, cluster := range clusters {
+		if cluster.Name == "" {
+			return errors.New("cluster name is required")
+		}
+
+		if strings.Contains(cluster.Name, "@") {
+			return fmt.Errorf("cluster name %q must not contain @", cluster.Name)
+		}
+
+		if _, ok := names[cluster.Name]; ok {
+			return fmt.Errorf("cluster name %q is duplicated", cluster.Name)
+		}
+		names[cluster.Name] = struct{}{}
+	}
+
+	return nil
+}
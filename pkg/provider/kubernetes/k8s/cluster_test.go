@@ -0,0 +1,93 @@
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const kubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: a
+  cluster:
+    server: https://a.example.com:6443
+- name: b
+  cluster:
+    server: https://b.example.com:6443
+users:
+- name: admin
+  user:
+    token: secret
+contexts:
+- name: a
+  context:
+    cluster: a
+    user: admin
+- name: b
+  context:
+    cluster: b
+    user: admin
+current-context: a
+`
+
+func TestCluster_RESTConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(path, []byte(kubeConfig), 0o600))
+
+	config, err := Cluster{Name: "a", KubeConfig: path}.RESTConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "https://a.example.com:6443", config.Host)
+	assert.Equal(t, "secret", config.BearerToken)
+
+	config, err = Cluster{Name: "b", KubeConfig: path, Context: "b"}.RESTConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "https://b.example.com:6443", config.Host)
+
+	_, err = Cluster{Name: "c", KubeConfig: path, Context: "c"}.RESTConfig()
+	require.Error(t, err)
+}
+
+func TestValidateClusters(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		clusters []Cluster
+		wantErr  require.ErrorAssertionFunc
+	}{
+		{
+			desc:    "no cluster",
+			wantErr: require.NoError,
+		},
+		{
+			desc:     "unique names",
+			clusters: []Cluster{{Name: "a"}, {Name: "b"}},
+			wantErr:  require.NoError,
+		},
+		{
+			desc:     "empty name",
+			clusters: []Cluster{{Name: "a"}, {}},
+			wantErr:  require.Error,
+		},
+		{
+			desc:     "name with separator",
+			clusters: []Cluster{{Name: "a@b"}},
+			wantErr:  require.Error,
+		},
+		{
+			desc:     "duplicated name",
+			clusters: []Cluster{{Name: "a"}, {Name: "a"}},
+			wantErr:  require.Error,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			test.wantErr(t, ValidateClusters(test.clusters))
+		})
+	}
+}
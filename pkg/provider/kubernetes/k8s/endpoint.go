@@ -0,0 +1,16 @@
+package k8s
+
+import (
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/utils/ptr"
+)
+
+// EndpointZone returns the zone served by the given EndpointSlice endpoint:
+// the zone hinted by the topology aware routing when there is a single one, or the zone of the endpoint.
+func EndpointZone(endpoint discoveryv1.Endpoint) string {
+	if endpoint.Hints != nil && len(endpoint.Hints.ForZones) == 1 {
+		return endpoint.Hints.ForZones[0].Name
+	}
+
+	return ptr.Deref(endpoint.Zone, "")
+}
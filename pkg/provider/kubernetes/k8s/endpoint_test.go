@@ -0,0 +1,54 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestEndpointZone(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		endpoint discoveryv1.Endpoint
+		expected string
+	}{
+		{
+			desc: "no zone",
+		},
+		{
+			desc:     "zone",
+			endpoint: discoveryv1.Endpoint{Zone: ptr.To("zone-a")},
+			expected: "zone-a",
+		},
+		{
+			desc: "single zone hint",
+			endpoint: discoveryv1.Endpoint{
+				Zone: ptr.To("zone-a"),
+				Hints: &discoveryv1.EndpointHints{
+					ForZones: []discoveryv1.ForZone{{Name: "zone-b"}},
+				},
+			},
+			expected: "zone-b",
+		},
+		{
+			desc: "several zone hints",
+			endpoint: discoveryv1.Endpoint{
+				Zone: ptr.To("zone-a"),
+				Hints: &discoveryv1.EndpointHints{
+					ForZones: []discoveryv1.ForZone{{Name: "zone-a"}, {Name: "zone-b"}},
+				},
+			},
+			expected: "zone-a",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, EndpointZone(test.endpoint))
+		})
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/hanzoai/ingress/pkg/config/dynamic"
 	"github.com/hanzoai/ingress/pkg/job"
 	"github.com/hanzoai/ingress/pkg/observability/logs"
+	"github.com/hanzoai/ingress/pkg/provider"
 	"github.com/hanzoai/ingress/pkg/safe"
 	"github.com/hanzoai/ingress/pkg/tls"
 	"github.com/hanzoai/ingress/pkg/types"
@@ -57,6 +58,12 @@ type Provider struct {
 
 	client            *clientWrapper
 	lastConfiguration safe.Safe
+	failureHandler    provider.FailureHandler
+}
+
+// SetFailureHandler sets the handler called with the failures of the provider to connect to or to watch its source.
+func (p *Provider) SetFailureHandler(handler provider.FailureHandler) {
+	p.failureHandler = handler
 }
 
 // Init the provider.
@@ -140,6 +147,7 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 
 		notify := func(err error, time time.Duration) {
 			logger.Error().Msgf("Provider connection error: %v; retrying in %s", err, time)
+			p.failureHandler.Report(providerName, err)
 		}
 		err := backoff.RetryNotify(safe.OperationWithRecover(operation), backoff.WithContext(job.NewBackOff(backoff.NewExponentialBackOff()), ctxPool), notify)
 		if err != nil {
@@ -15,6 +15,7 @@ import (
 	"github.com/hanzoai/ingress/pkg/config/kv"
 	"github.com/hanzoai/ingress/pkg/job"
 	"github.com/hanzoai/ingress/pkg/observability/logs"
+	"github.com/hanzoai/ingress/pkg/provider"
 	"github.com/hanzoai/ingress/pkg/safe"
 )
 
@@ -24,8 +25,9 @@ type Provider struct {
 
 	Endpoints []string `description:"KV store endpoints." json:"endpoints,omitempty" toml:"endpoints,omitempty" yaml:"endpoints,omitempty"`
 
-	name     string
-	kvClient store.Store
+	name           string
+	kvClient       store.Store
+	failureHandler provider.FailureHandler
 }
 
 // SetDefaults sets the default values.
@@ -33,6 +35,11 @@ func (p *Provider) SetDefaults() {
 	p.RootKey = "ingress"
 }
 
+// SetFailureHandler sets the handler called with the failures of the provider to connect to or to watch its source.
+func (p *Provider) SetFailureHandler(handler provider.FailureHandler) {
+	p.failureHandler = handler
+}
+
 // Init the provider.
 func (p *Provider) Init(storeType, name string, config valkeyrie.Config) error {
 	ctx := log.With().Str(logs.ProviderName, name).Logger().WithContext(context.Background())
@@ -63,6 +70,7 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 
 	notify := func(err error, time time.Duration) {
 		logger.Error().Err(err).Msgf("KV connection error, retrying in %s", time)
+		p.failureHandler.Report(p.name, err)
 	}
 
 	err := backoff.RetryNotify(safe.OperationWithRecover(operation), backoff.WithContext(job.NewBackOff(backoff.NewExponentialBackOff()), ctx), notify)
@@ -125,6 +133,7 @@ func (p *Provider) watchKv(ctx context.Context, configurationChan chan<- dynamic
 
 	notify := func(err error, time time.Duration) {
 		log.Ctx(ctx).Error().Err(err).Msgf("Provider error, retrying in %s", time)
+		p.failureHandler.Report(p.name, err)
 	}
 
 	return backoff.RetryNotify(safe.OperationWithRecover(operation),
@@ -248,6 +248,11 @@ func (p *Provider) addServer(i item, lb *dynamic.ServersLoadBalancer) error {
 		return errors.New("address is missing")
 	}
 
+	// The Nomad datacenters are the failure domains of a region.
+	if lb.Servers[0].Zone == "" {
+		lb.Servers[0].Zone = i.Datacenter
+	}
+
 	if lb.Servers[0].URL != "" {
 		if lb.Servers[0].Scheme != "" || lb.Servers[0].Port != "" {
 			return errors.New("defining scheme or port is not allowed when URL is defined")
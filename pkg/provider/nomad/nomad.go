@@ -144,6 +144,7 @@ type Provider struct {
 	defaultRuleTpl *template.Template // default routing rule
 
 	lastConfiguration safe.Safe
+	failureHandler    provider.FailureHandler
 }
 
 // SetDefaults sets the default values for the Nomad Ingress Provider.
@@ -151,6 +152,11 @@ func (p *Provider) SetDefaults() {
 	p.Configuration.SetDefaults()
 }
 
+// SetFailureHandler sets the handler called with the failures of the provider to connect to or to watch its source.
+func (p *Provider) SetFailureHandler(handler provider.FailureHandler) {
+	p.failureHandler = handler
+}
+
 // Init the Nomad Ingress Provider.
 func (p *Provider) Init() error {
 	if p.namespace == api.AllNamespacesNamespace {
@@ -249,6 +255,7 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 
 		failure := func(err error, d time.Duration) {
 			logger.Error().Err(err).Msgf("Loading configuration, retrying in %s", d)
+			p.failureHandler.Report(p.name, err)
 		}
 
 		if retryErr := backoff.RetryNotify(
@@ -23,3 +23,22 @@ type NamespacedProvider interface {
 	// Namespace returns the specific namespace this provider instance is configured for.
 	Namespace() string
 }
+
+// FailureReporter is implemented by the providers reporting their failures to connect to or to watch their source,
+// on top of logging them.
+type FailureReporter interface {
+	Provider
+
+	// SetFailureHandler sets the handler called with each failure of the provider.
+	SetFailureHandler(handler FailureHandler)
+}
+
+// FailureHandler handles a failure of the named provider to connect to or to watch its source.
+type FailureHandler func(providerName string, err error)
+
+// Report calls the handler with the failure of the named provider, when the handler is set.
+func (h FailureHandler) Report(providerName string, err error) {
+	if h != nil {
+		h(providerName, err)
+	}
+}
@@ -0,0 +1,180 @@
+package status
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/observability/metrics"
+)
+
+// States of a provider.
+const (
+	// StateReady is the state of a provider which delivered a configuration since its last error.
+	StateReady = "ready"
+	// StateError is the state of a provider which reported an error since its last configuration.
+	StateError = "error"
+)
+
+// Objects holds the number of elements in the last configuration delivered by a provider.
+type Objects struct {
+	HTTPRouters     int `json:"httpRouters"`
+	HTTPServices    int `json:"httpServices"`
+	HTTPMiddlewares int `json:"httpMiddlewares"`
+	TCPRouters      int `json:"tcpRouters"`
+	TCPServices     int `json:"tcpServices"`
+	TCPMiddlewares  int `json:"tcpMiddlewares"`
+	UDPRouters      int `json:"udpRouters"`
+	UDPServices     int `json:"udpServices"`
+}
+
+// Status is the health status of a provider.
+type Status struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+	// LastUpdate is the last time the provider delivered a configuration, changed or not.
+	LastUpdate    *time.Time `json:"lastUpdate,omitempty"`
+	LastError     string     `json:"lastError,omitempty"`
+	LastErrorTime *time.Time `json:"lastErrorTime,omitempty"`
+	ErrorCount    uint64     `json:"errorCount"`
+	Objects       Objects    `json:"objects"`
+}
+
+// Tracker keeps track of the health status of the providers,
+// from the configurations they deliver and the failures they report.
+type Tracker struct {
+	registry metrics.Registry
+
+	mu        sync.RWMutex
+	providers map[string]*Status
+}
+
+// NewTracker creates a new Tracker reporting the status of the providers with the given metrics registry.
+func NewTracker(registry metrics.Registry) *Tracker {
+	if registry == nil {
+		registry = metrics.NewVoidRegistry()
+	}
+
+	return &Tracker{
+		registry:  registry,
+		providers: make(map[string]*Status),
+	}
+}
+
+// Updated records that the given provider delivered the given configuration.
+func (t *Tracker) Updated(name string, conf *dynamic.Configuration) {
+	if t == nil || name == "" {
+		return
+	}
+
+	now := time.Now().UTC()
+
+	t.mu.Lock()
+	status := t.status(name)
+	status.State = StateReady
+	status.LastUpdate = &now
+	status.Objects = countObjects(conf)
+	t.mu.Unlock()
+
+	t.registry.ProviderUpGauge().With("provider", name).Set(1)
+	t.registry.ProviderLastUpdateGauge().With("provider", name).Set(float64(now.Unix()))
+}
+
+// Failed records a failure reported by the given provider.
+// It is a provider.FailureHandler.
+func (t *Tracker) Failed(name string, err error) {
+	if t == nil || name == "" || err == nil {
+		return
+	}
+
+	now := time.Now().UTC()
+
+	t.mu.Lock()
+	status := t.status(name)
+	status.State = StateError
+	status.LastError = err.Error()
+	status.LastErrorTime = &now
+	status.ErrorCount++
+	t.mu.Unlock()
+
+	t.registry.ProviderUpGauge().With("provider", name).Set(0)
+	t.registry.ProviderErrorsCounter().With("provider", name).Add(1)
+}
+
+// Statuses returns the status of all the known providers, sorted by name.
+func (t *Tracker) Statuses() []Status {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	statuses := make([]Status, 0, len(t.providers))
+	for _, status := range t.providers {
+		statuses = append(statuses, *status)
+	}
+
+	slices.SortFunc(statuses, func(a, b Status) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return statuses
+}
+
+// Synced reports whether all the given providers delivered at least one configuration.
+func (t *Tracker) Synced(names ...string) bool {
+	if t == nil {
+		return len(names) == 0
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, name := range names {
+		status, ok := t.providers[name]
+		if !ok || status.LastUpdate == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// status returns the status of the given provider, which is created if unknown.
+// It must be called with the lock held.
+func (t *Tracker) status(name string) *Status {
+	status, ok := t.providers[name]
+	if !ok {
+		status = &Status{Name: name}
+		t.providers[name] = status
+	}
+
+	return status
+}
+
+func countObjects(conf *dynamic.Configuration) Objects {
+	var objects Objects
+	if conf == nil {
+		return objects
+	}
+
+	if conf.HTTP != nil {
+		objects.HTTPRouters = len(conf.HTTP.Routers)
+		objects.HTTPServices = len(conf.HTTP.Services)
+		objects.HTTPMiddlewares = len(conf.HTTP.Middlewares)
+	}
+	if conf.TCP != nil {
+		objects.TCPRouters = len(conf.TCP.Routers)
+		objects.TCPServices = len(conf.TCP.Services)
+		objects.TCPMiddlewares = len(conf.TCP.Middlewares)
+	}
+	if conf.UDP != nil {
+		objects.UDPRouters = len(conf.UDP.Routers)
+		objects.UDPServices = len(conf.UDP.Services)
+	}
+
+	return objects
+}
@@ -0,0 +1,52 @@
+package status
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+)
+
+func TestTracker(t *testing.T) {
+	tracker := NewTracker(nil)
+
+	assert.True(t, tracker.Synced())
+	assert.False(t, tracker.Synced("file"))
+
+	tracker.Failed("docker", errors.New("connection refused"))
+	assert.False(t, tracker.Synced("docker"))
+
+	tracker.Updated("file", &dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers:  map[string]*dynamic.Router{"foo": {}, "bar": {}},
+			Services: map[string]*dynamic.Service{"foo": {}},
+		},
+		UDP: &dynamic.UDPConfiguration{
+			Services: map[string]*dynamic.UDPService{"foo": {}},
+		},
+	})
+	assert.True(t, tracker.Synced("file"))
+	assert.False(t, tracker.Synced("file", "docker"))
+
+	tracker.Updated("docker", &dynamic.Configuration{})
+	tracker.Failed("docker", errors.New("connection reset"))
+	assert.True(t, tracker.Synced("file", "docker"))
+
+	statuses := tracker.Statuses()
+	require.Len(t, statuses, 2)
+
+	assert.Equal(t, "docker", statuses[0].Name)
+	assert.Equal(t, StateError, statuses[0].State)
+	assert.Equal(t, "connection reset", statuses[0].LastError)
+	assert.NotNil(t, statuses[0].LastErrorTime)
+	assert.NotNil(t, statuses[0].LastUpdate)
+	assert.Equal(t, uint64(2), statuses[0].ErrorCount)
+
+	assert.Equal(t, "file", statuses[1].Name)
+	assert.Equal(t, StateReady, statuses[1].State)
+	assert.NotNil(t, statuses[1].LastUpdate)
+	assert.Nil(t, statuses[1].LastErrorTime)
+	assert.Equal(t, Objects{HTTPRouters: 2, HTTPServices: 1, UDPServices: 1}, statuses[1].Objects)
+}
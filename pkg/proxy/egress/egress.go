@@ -0,0 +1,230 @@
+// Package egress reaches the backend servers through a forward proxy.
+package egress
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/proxy"
+)
+
+// Proxy selects the forward proxy of the backend servers, and dials the connections through it.
+type Proxy struct {
+	proxyFunc func(*url.URL) (*url.URL, error)
+	tlsConfig *tls.Config
+	secure    bool
+}
+
+// New creates a Proxy from the given configuration.
+func New(config *dynamic.ForwardProxy) (*Proxy, error) {
+	proxyURL, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing forward proxy URL: %w", err)
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("unsupported forward proxy scheme %q", proxyURL.Scheme)
+	}
+
+	if proxyURL.Host == "" {
+		return nil, errors.New("forward proxy URL must have a host")
+	}
+
+	if config.Username != "" || config.Password != "" {
+		proxyURL.User = url.UserPassword(config.Username, config.Password)
+	}
+
+	// The certificate of a forward proxy with the https scheme is verified with the system roots by default,
+	// as the forward proxy is not one of the backend servers.
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+	if len(config.RootCAs) > 0 {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		for _, rootCA := range config.RootCAs {
+			content, err := rootCA.Read()
+			if err != nil {
+				return nil, fmt.Errorf("reading forward proxy root CA: %w", err)
+			}
+
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(content) {
+				return nil, errors.New("forward proxy root CA does not contain any PEM certificate")
+			}
+		}
+	}
+
+	proxyConfig := &httpproxy.Config{
+		HTTPProxy:  proxyURL.String(),
+		HTTPSProxy: proxyURL.String(),
+		NoProxy:    strings.Join(config.NoProxy, ","),
+	}
+
+	return &Proxy{
+		proxyFunc: proxyConfig.ProxyFunc(),
+		tlsConfig: tlsConfig,
+		secure:    proxyURL.Scheme == "https",
+	}, nil
+}
+
+// Secure reports whether the forward proxy is reached over TLS, with the https scheme.
+// The connections to such a forward proxy must be established with DialContext,
+// which verifies the forward proxy with its own TLS options.
+func (p *Proxy) Secure() bool {
+	return p.secure
+}
+
+// URL returns the URL of the forward proxy used to reach the given server URL,
+// or nil when the server is reached without the forward proxy.
+// The localhost and loopback addresses are always reached without the forward proxy.
+func (p *Proxy) URL(serverURL *url.URL) (*url.URL, error) {
+	// The servers are matched with the rules of the proxy environment variables, which only apply to the http and https schemes.
+	scheme := "https"
+	if serverURL.Scheme == "http" || serverURL.Scheme == "h2c" {
+		scheme = "http"
+	}
+
+	return p.proxyFunc(&url.URL{Scheme: scheme, Host: serverURL.Host})
+}
+
+// HTTPProxy returns the forward proxy to use with the given request, as expected by http.Transport.
+func (p *Proxy) HTTPProxy(req *http.Request) (*url.URL, error) {
+	return p.URL(req.URL)
+}
+
+// DialContext connects to the given address through the forward proxy, with an HTTP CONNECT tunnel or SOCKS5,
+// and uses the given dialer to reach the forward proxy.
+// It connects directly to the address when it is reached without the forward proxy.
+func (p *Proxy) DialContext(ctx context.Context, dialer *net.Dialer, network, addr string) (net.Conn, error) {
+	proxyURL, err := p.URL(&url.URL{Host: addr})
+	if err != nil {
+		return nil, err
+	}
+
+	if proxyURL == nil {
+		return dialer.DialContext(ctx, network, addr)
+	}
+
+	if proxyURL.Scheme == "socks5" {
+		var auth *proxy.Auth
+		if u := proxyURL.User; u != nil {
+			auth = &proxy.Auth{User: u.Username()}
+			auth.Password, _ = u.Password()
+		}
+
+		// The SOCKS5 implementation does not return errors.
+		socksDialer, _ := proxy.SOCKS5("tcp", proxyAddr(proxyURL), auth, dialer)
+
+		return socksDialer.(proxy.ContextDialer).DialContext(ctx, network, addr)
+	}
+
+	return p.dialConnect(ctx, dialer, proxyURL, addr)
+}
+
+// dialConnect opens an HTTP CONNECT tunnel to the given address through the forward proxy.
+func (p *Proxy) dialConnect(ctx context.Context, dialer *net.Dialer, proxyURL *url.URL, addr string) (net.Conn, error) {
+	conn, err := dialer.DialContext(ctx, "tcp", proxyAddr(proxyURL))
+	if err != nil {
+		return nil, fmt.Errorf("dialing forward proxy: %w", err)
+	}
+
+	if proxyURL.Scheme == "https" {
+		tlsConfig := p.tlsConfig.Clone()
+		tlsConfig.ServerName = proxyURL.Hostname()
+
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("TLS handshake with forward proxy: %w", err)
+		}
+		conn = tlsConn
+	}
+
+	// The context cancellation interrupts the CONNECT request.
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Unix(1, 0))
+	})
+	defer stop()
+
+	connectReq := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+
+	if u := proxyURL.User; u != nil {
+		password, _ := u.Password()
+		auth := u.Username() + ":" + password
+		connectReq.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(auth)))
+	}
+
+	if err := connectReq.Write(conn); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("writing CONNECT request: %w", err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, connectReq)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("reading CONNECT response: %w", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		_ = conn.Close()
+		return nil, fmt.Errorf("forward proxy refused CONNECT: %s", resp.Status)
+	}
+
+	if !stop() {
+		_ = conn.Close()
+		return nil, ctx.Err()
+	}
+
+	// The server may have already sent data through the tunnel.
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+
+	return conn, nil
+}
+
+func proxyAddr(proxyURL *url.URL) string {
+	if proxyURL.Port() != "" {
+		return proxyURL.Host
+	}
+
+	switch proxyURL.Scheme {
+	case "https":
+		return net.JoinHostPort(proxyURL.Hostname(), "443")
+	case "socks5":
+		return net.JoinHostPort(proxyURL.Hostname(), "1080")
+	default:
+		return net.JoinHostPort(proxyURL.Hostname(), "80")
+	}
+}
+
+// bufferedConn is a connection which data has been partially read into a buffer.
+type bufferedConn struct {
+	net.Conn
+
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
@@ -7,10 +7,13 @@ import (
 	"net/http"
 	"net/url"
 	"reflect"
+	"sync"
 	"time"
 
 	"github.com/hanzoai/ingress/pkg/config/dynamic"
 	"github.com/hanzoai/ingress/pkg/config/static"
+	"github.com/hanzoai/ingress/pkg/proxy/egress"
+	"golang.org/x/net/http2"
 )
 
 // TransportManager manages transport used for backend communications.
@@ -24,11 +27,12 @@ type ProxyBuilder struct {
 	debug            bool
 	transportManager TransportManager
 
-	// lock isn't needed because ProxyBuilder is not called concurrently.
-	pools map[string]map[string]*connPool
-	proxy func(*http.Request) (*url.URL, error)
+	// The pools are locked as the load-balancers of the servers discovered through DNS
+	// are built concurrently with the configuration updates.
+	poolsMu sync.Mutex
+	pools   map[string]map[string]*connPool
+	proxy   func(*http.Request) (*url.URL, error)
 
-	// not goroutine safe.
 	configs map[string]*dynamic.ServersTransport
 }
 
@@ -46,6 +50,9 @@ func NewProxyBuilder(transportManager TransportManager, config static.FastProxyC
 // Update updates all the round-tripper corresponding to the given configs.
 // This method must not be used concurrently.
 func (r *ProxyBuilder) Update(newConfigs map[string]*dynamic.ServersTransport) {
+	r.poolsMu.Lock()
+	defer r.poolsMu.Unlock()
+
 	for configName := range r.configs {
 		if _, ok := newConfigs[configName]; !ok {
 			for _, c := range r.pools[configName] {
@@ -69,11 +76,6 @@ func (r *ProxyBuilder) Update(newConfigs map[string]*dynamic.ServersTransport) {
 
 // Build builds a new ReverseProxy with the given configuration.
 func (r *ProxyBuilder) Build(cfgName string, targetURL *url.URL, passHostHeader, preservePath bool) (http.Handler, error) {
-	proxyURL, err := r.proxy(&http.Request{URL: targetURL})
-	if err != nil {
-		return nil, fmt.Errorf("getting proxy: %w", err)
-	}
-
 	cfg, err := r.transportManager.Get(cfgName)
 	if err != nil {
 		return nil, fmt.Errorf("getting ServersTransport: %w", err)
@@ -84,11 +86,34 @@ func (r *ProxyBuilder) Build(cfgName string, targetURL *url.URL, passHostHeader,
 		return nil, fmt.Errorf("getting TLS config: %w", err)
 	}
 
+	proxyURL, err := r.proxyURL(cfg, targetURL)
+	if err != nil {
+		return nil, fmt.Errorf("getting proxy: %w", err)
+	}
+
 	pool := r.getPool(cfgName, cfg, tlsConfig, targetURL, proxyURL)
 	return NewReverseProxy(targetURL, proxyURL, r.debug, passHostHeader, preservePath, pool)
 }
 
+// proxyURL returns the forward proxy of the ServersTransport used to reach the target URL,
+// or the one defined by the environment variables when the ServersTransport has none.
+func (r *ProxyBuilder) proxyURL(cfg *dynamic.ServersTransport, targetURL *url.URL) (*url.URL, error) {
+	if cfg.Proxy == nil {
+		return r.proxy(&http.Request{URL: targetURL})
+	}
+
+	egressProxy, err := egress.New(cfg.Proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	return egressProxy.URL(targetURL)
+}
+
 func (r *ProxyBuilder) getPool(cfgName string, config *dynamic.ServersTransport, tlsConfig *tls.Config, targetURL *url.URL, proxyURL *url.URL) *connPool {
+	r.poolsMu.Lock()
+	defer r.poolsMu.Unlock()
+
 	pool, ok := r.pools[cfgName]
 	if !ok {
 		pool = make(map[string]*connPool)
@@ -108,18 +133,35 @@ func (r *ProxyBuilder) getPool(cfgName string, config *dynamic.ServersTransport,
 		responseHeaderTimeout = time.Duration(config.ForwardingTimeouts.ResponseHeaderTimeout)
 	}
 
+	// HTTP/2 is negotiated through ALPN with the https servers, unless disabled, and used with prior knowledge for h2c.
+	useHTTP2 := targetURL.Scheme == schemeH2C || targetURL.Scheme == schemeHTTPS && !config.DisableHTTP2
+	if targetURL.Scheme == schemeHTTPS && !config.DisableHTTP2 {
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		} else {
+			tlsConfig = tlsConfig.Clone()
+		}
+		tlsConfig.NextProtos = []string{http2.NextProtoTLS, "http/1.1"}
+	}
+
 	proxyDialer := newDialer(dialerConfig{
 		DialKeepAlive: 0,
 		DialTimeout:   dialTimeout,
-		HTTP:          true,
-		TLS:           targetURL.Scheme == "https",
-		ProxyURL:      proxyURL,
+		// The h2c requests cannot be sent to an HTTP forward proxy, they are tunneled with CONNECT.
+		HTTP:     targetURL.Scheme != schemeH2C,
+		TLS:      targetURL.Scheme == "https",
+		ProxyURL: proxyURL,
 	}, tlsConfig)
 
 	connPool := newConnPool(config.MaxIdleConnsPerHost, idleConnTimeout, responseHeaderTimeout, func() (net.Conn, error) {
 		return proxyDialer.Dial("tcp", addrFromURL(targetURL))
 	})
 
+	if useHTTP2 {
+		connPool.h2Transport = newHTTP2Transport(config, idleConnTimeout)
+		connPool.h2PriorKnowledge = targetURL.Scheme == schemeH2C
+	}
+
 	r.pools[cfgName][targetURL.String()] = connPool
 
 	return connPool
@@ -2,6 +2,7 @@ package fast
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/rs/zerolog/log"
 	"github.com/valyala/fasthttp"
+	"golang.org/x/net/http2"
 )
 
 // rwWithUpgrade contains a ResponseWriter and an upgradeHandler,
@@ -317,6 +319,18 @@ type connPool struct {
 	bufferPool            pool[[]byte]
 	limitedReaderPool     pool[*io.LimitedReader]
 	doneCh                chan struct{}
+
+	// h2Transport multiplexes the requests over HTTP/2 connections,
+	// negotiated through ALPN or used with prior knowledge (h2c).
+	// It is nil when HTTP/2 is not used with the server.
+	h2Transport      *http2.Transport
+	h2PriorKnowledge bool
+	h2Mu             sync.Mutex
+	h2Conns          []*http2.ClientConn
+	// h2Dial is the pending dial of an HTTP/2 connection, if any.
+	h2Dial *h2Dial
+	// http1 is set once the server negotiated HTTP/1.1 through ALPN.
+	http1 atomic.Bool
 }
 
 // newConnPool creates a new connPool.
@@ -346,12 +360,27 @@ func newConnPool(maxIdleConn int, idleConnTimeout, responseHeaderTimeout time.Du
 	return c
 }
 
-// Close closes stop the cleanIdleConn goroutine.
+// Close closes stop the cleanIdleConn goroutine,
+// and gracefully shuts down the HTTP/2 connections.
 func (c *connPool) Close() {
 	if c.idleConnTimeout > 0 {
 		close(c.doneCh)
 		c.ticker.Stop()
 	}
+
+	c.h2Mu.Lock()
+	defer c.h2Mu.Unlock()
+
+	for _, cc := range c.h2Conns {
+		go func() {
+			if err := cc.Shutdown(context.Background()); err != nil {
+				log.Debug().
+					Err(err).
+					Msg("Unexpected error while shutting down the HTTP/2 connection")
+			}
+		}()
+	}
+	c.h2Conns = nil
 }
 
 // AcquireConn returns an idle net.Conn from the pool.
@@ -451,6 +480,11 @@ func (c *connPool) askForNewConn(errCh chan<- error) {
 		return
 	}
 
+	c.releaseConn(c.newConn(co))
+}
+
+// newConn wraps the given connection and starts its read loop.
+func (c *connPool) newConn(co net.Conn) *conn {
 	newConn := &conn{
 		Conn:                  co,
 		br:                    bufio.NewReaderSize(co, bufioSize),
@@ -464,7 +498,7 @@ func (c *connPool) askForNewConn(errCh chan<- error) {
 	}
 	go newConn.readLoop()
 
-	c.releaseConn(newConn)
+	return newConn
 }
 
 // isBodyAllowedForStatus reports whether a given response status code permits a body.
@@ -146,6 +146,10 @@ func newDialer(cfg dialerConfig, tlsConfig *tls.Config) dialer {
 				return nil, errors.New(statusText)
 			}
 
+			if !cfg.TLS {
+				return conn, nil
+			}
+
 			c := &tls.Config{}
 			if tlsConfig != nil {
 				c = tlsConfig.Clone()
@@ -188,6 +192,10 @@ func addrFromURL(u *url.URL) string {
 			return addr + ":80"
 		case schemeHTTPS:
 			return addr + ":443"
+		case schemeH2C:
+			return addr + ":80"
+		case schemeSocks5:
+			return addr + ":1080"
 		}
 	}
 
@@ -0,0 +1,365 @@
+package fast
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/valyala/fasthttp"
+	"golang.org/x/net/http2"
+)
+
+const schemeH2C = "h2c"
+
+// newHTTP2Transport creates the transport multiplexing the requests over the HTTP/2 connections of a pool.
+func newHTTP2Transport(config *dynamic.ServersTransport, idleConnTimeout time.Duration) *http2.Transport {
+	transport := &http2.Transport{
+		// AllowHTTP allows the h2c connections, the TLS connections being established by the pool dialer.
+		AllowHTTP:       true,
+		IdleConnTimeout: idleConnTimeout,
+	}
+
+	if config.ForwardingTimeouts != nil {
+		transport.ReadIdleTimeout = time.Duration(config.ForwardingTimeouts.ReadIdleTimeout)
+		transport.PingTimeout = time.Duration(config.ForwardingTimeouts.PingTimeout)
+	}
+
+	return transport
+}
+
+// h2Dial is a pending dial of an HTTP/2 connection,
+// waited for by the requests finding no available connection in the meantime.
+type h2Dial struct {
+	done chan struct{}
+	err  error
+}
+
+// AcquireH2Conn returns an HTTP/2 client connection with a reserved stream,
+// and dials a new connection when none of the existing ones can take a new request.
+// It returns a nil connection when the requests must be sent with HTTP/1.1,
+// along with the dialed connection when the server negotiated HTTP/1.1 through ALPN.
+func (c *connPool) AcquireH2Conn() (*http2.ClientConn, *conn, error) {
+	for {
+		if c.h2Transport == nil || c.http1.Load() {
+			return nil, nil, nil
+		}
+
+		c.h2Mu.Lock()
+
+		if reserved := c.reserveH2Conn(); reserved != nil {
+			c.h2Mu.Unlock()
+			return reserved, nil, nil
+		}
+
+		// The connection being dialed may take the request once established, so the request waits for it,
+		// instead of dialing another connection.
+		if pending := c.h2Dial; pending != nil {
+			c.h2Mu.Unlock()
+
+			<-pending.done
+			if pending.err != nil {
+				return nil, nil, pending.err
+			}
+			continue
+		}
+
+		pending := &h2Dial{done: make(chan struct{})}
+		c.h2Dial = pending
+		c.h2Mu.Unlock()
+
+		// The dial and the TLS handshake are done outside the lock,
+		// so that the requests using the existing connections are not blocked by them.
+		cc, co, err := c.dialH2Conn()
+
+		c.h2Mu.Lock()
+		c.h2Dial = nil
+		if cc != nil {
+			c.h2Conns = append(c.h2Conns, cc)
+		}
+		c.h2Mu.Unlock()
+
+		pending.err = err
+		close(pending.done)
+
+		return cc, co, err
+	}
+}
+
+// reserveH2Conn drops the closing connections from the pool,
+// and returns the first connection which can take a new request, if any.
+// It must be called with h2Mu held.
+func (c *connPool) reserveH2Conn() *http2.ClientConn {
+	var reserved *http2.ClientConn
+	conns := c.h2Conns[:0]
+	for _, cc := range c.h2Conns {
+		// A closing connection is dropped from the pool, and closes itself once its streams are done.
+		if state := cc.State(); state.Closed || state.Closing {
+			continue
+		}
+
+		conns = append(conns, cc)
+		if reserved == nil && cc.ReserveNewRequest() {
+			reserved = cc
+		}
+	}
+	c.h2Conns = conns
+
+	return reserved
+}
+
+// dialH2Conn dials a new HTTP/2 connection, and reserves a stream on it.
+// It returns the dialed connection instead when the server negotiated HTTP/1.1 through ALPN.
+func (c *connPool) dialH2Conn() (*http2.ClientConn, *conn, error) {
+	co, err := c.dialer()
+	if err != nil {
+		return nil, nil, fmt.Errorf("create conn: %w", err)
+	}
+
+	if !c.h2PriorKnowledge {
+		negotiated, err := negotiatedHTTP2(co)
+		if err != nil {
+			co.Close()
+			return nil, nil, fmt.Errorf("create conn: %w", err)
+		}
+
+		if !negotiated {
+			// The server does not support HTTP/2,
+			// thus the connections are used for HTTP/1.1 requests from now on.
+			c.http1.Store(true)
+			return nil, c.newConn(co), nil
+		}
+	}
+
+	cc, err := c.h2Transport.NewClientConn(co)
+	if err != nil {
+		co.Close()
+		return nil, nil, fmt.Errorf("create HTTP/2 conn: %w", err)
+	}
+
+	if !cc.ReserveNewRequest() {
+		cc.Close()
+		return nil, nil, errors.New("new HTTP/2 conn cannot take a request")
+	}
+
+	return cc, nil, nil
+}
+
+// negotiatedHTTP2 returns whether HTTP/2 has been negotiated through ALPN on the given connection.
+func negotiatedHTTP2(co net.Conn) (bool, error) {
+	tlsConn, ok := co.(*tls.Conn)
+	if !ok {
+		return false, nil
+	}
+
+	// The connections tunneled through a proxy are not yet handshaked.
+	if err := tlsConn.Handshake(); err != nil {
+		return false, err
+	}
+
+	return tlsConn.ConnectionState().NegotiatedProtocol == http2.NextProtoTLS, nil
+}
+
+// roundTripH2 sends the request over the given HTTP/2 connection and writes the response.
+// Unlike the HTTP/1.1 round trip, the request and response bodies are streamed concurrently (e.g. gRPC streaming).
+func (p *ReverseProxy) roundTripH2(rw http.ResponseWriter, req *http.Request, outReq *fasthttp.Request, cc *http2.ClientConn) error {
+	ctx := req.Context()
+
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			h := rw.Header()
+			copyHeader(h, http.Header(header))
+			rw.WriteHeader(code)
+
+			// Clear headers, it's not automatically done by ResponseWriter.WriteHeader() for 1xx responses.
+			clear(h)
+			return nil
+		},
+	})
+
+	var stopTimer func() bool
+	if p.connPool.responseHeaderTimeout > 0 {
+		var cancel context.CancelCauseFunc
+		ctx, cancel = context.WithCancelCause(ctx)
+		defer cancel(nil)
+
+		timer := time.AfterFunc(p.connPool.responseHeaderTimeout, func() {
+			cancel(timeoutError{errors.New("timeout awaiting response headers")})
+		})
+		stopTimer = timer.Stop
+	}
+
+	h2Req, err := p.newH2Request(ctx, req, outReq)
+	if err != nil {
+		return err
+	}
+
+	var res *http.Response
+	for {
+		res, err = cc.RoundTrip(h2Req)
+		if err == nil {
+			break
+		}
+
+		if cause := context.Cause(ctx); cause != nil {
+			return cause
+		}
+
+		// A connection going away before the request is sent is expected when reusing a connection,
+		// thus the request is retried on another connection when this is safe.
+		if state := cc.State(); !isReplayable(req) || !state.Closed && !state.Closing {
+			return err
+		}
+
+		log.Ctx(ctx).Debug().Err(err).Msg("Error while sending request on the HTTP/2 connection")
+
+		var co *conn
+		cc, co, err = p.connPool.AcquireH2Conn()
+		if err != nil {
+			return fmt.Errorf("acquire connection: %w", err)
+		}
+		if cc == nil {
+			if co != nil {
+				co.Close()
+			}
+			return errors.New("HTTP/2 is no longer supported by the server")
+		}
+	}
+	defer res.Body.Close()
+
+	if stopTimer != nil {
+		stopTimer()
+	}
+
+	removeHopHeaders(res.Header)
+	copyHeader(rw.Header(), res.Header)
+
+	// The "Trailer" header isn't included in the response headers, it is built from the announced trailers.
+	announcedTrailers := len(res.Trailer)
+	if announcedTrailers > 0 {
+		trailerKeys := make([]string, 0, len(res.Trailer))
+		for k := range res.Trailer {
+			trailerKeys = append(trailerKeys, k)
+		}
+		rw.Header().Add("Trailer", strings.Join(trailerKeys, ", "))
+
+		// The trailers of an HTTP/1.1 response can only be sent with the chunked transfer encoding.
+		rw.Header().Del("Content-Length")
+	}
+
+	rw.WriteHeader(res.StatusCode)
+
+	if announcedTrailers > 0 {
+		if f, ok := rw.(http.Flusher); ok {
+			f.Flush()
+		}
+	}
+
+	b := p.connPool.bufferPool.Get()
+	if b == nil {
+		b = make([]byte, bufferSize)
+	}
+	defer p.connPool.bufferPool.Put(b)
+
+	// A response with an unknown length is a streamed response (e.g. gRPC),
+	// which is flushed to the client as soon as the data is received.
+	var dst io.Writer = rw
+	if res.ContentLength == -1 {
+		dst = &writeFlusher{rw}
+	}
+
+	if _, err := io.CopyBuffer(dst, res.Body, b); err != nil {
+		return err
+	}
+
+	if len(res.Trailer) == announcedTrailers {
+		copyHeader(rw.Header(), res.Trailer)
+		return nil
+	}
+
+	for k, vv := range res.Trailer {
+		k = http.TrailerPrefix + k
+		for _, v := range vv {
+			rw.Header().Add(k, v)
+		}
+	}
+
+	return nil
+}
+
+// newH2Request creates the HTTP/2 request from the request prepared for the HTTP/1.1 round trip.
+func (p *ReverseProxy) newH2Request(ctx context.Context, req *http.Request, outReq *fasthttp.Request) (*http.Request, error) {
+	u, err := url.ParseRequestURI(string(outReq.RequestURI()))
+	if err != nil {
+		return nil, fmt.Errorf("parsing request URI: %w", err)
+	}
+
+	u.Scheme = schemeHTTP
+	if p.targetURL.Scheme == schemeHTTPS {
+		u.Scheme = schemeHTTPS
+	}
+	u.Host = string(outReq.Host())
+
+	header := make(http.Header)
+	outReq.Header.VisitAll(func(key, value []byte) {
+		// The Host, Content-Length and connection-specific headers are not sent as HTTP/2 fields.
+		switch textproto.CanonicalMIMEHeaderKey(string(key)) {
+		case "Host", "Content-Length", "Connection", "Transfer-Encoding":
+			return
+		}
+
+		header.Add(string(key), string(value))
+	})
+
+	body := req.Body
+	if req.ContentLength == 0 {
+		body = http.NoBody
+	}
+
+	h2Req := (&http.Request{
+		Method:        req.Method,
+		URL:           u,
+		Proto:         "HTTP/2.0",
+		ProtoMajor:    2,
+		Header:        header,
+		Body:          body,
+		ContentLength: req.ContentLength,
+		Trailer:       req.Trailer,
+		Host:          string(outReq.Header.Host()),
+	}).WithContext(ctx)
+
+	return h2Req, nil
+}
+
+// removeHopHeaders removes the hop-by-hop headers of h, including the ones listed in its "Connection" header.
+func removeHopHeaders(h http.Header) {
+	for _, f := range h["Connection"] {
+		for sf := range strings.SplitSeq(f, ",") {
+			if sf = textproto.TrimString(sf); sf != "" {
+				h.Del(sf)
+			}
+		}
+	}
+
+	for _, header := range hopHeaders {
+		h.Del(header)
+	}
+}
+
+func copyHeader(dst, src http.Header) {
+	for k, vv := range src {
+		for _, v := range vv {
+			dst.Add(k, v)
+		}
+	}
+}
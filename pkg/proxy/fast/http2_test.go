@@ -0,0 +1,270 @@
+package fast
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/config/static"
+	"github.com/hanzoai/ingress/pkg/testhelpers"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func TestHTTP2_Multiplexing(t *testing.T) {
+	const concurrency = 5
+
+	var (
+		mu          sync.Mutex
+		remoteAddrs = make(map[string]struct{})
+		arrived     int
+		allArrived  = make(chan struct{})
+	)
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		remoteAddrs[req.RemoteAddr] = struct{}{}
+		arrived++
+		if arrived == concurrency {
+			close(allArrived)
+		}
+		mu.Unlock()
+
+		// All the requests are in flight at the same time, thus they are multiplexed.
+		select {
+		case <-allArrived:
+		case <-time.After(5 * time.Second):
+		}
+
+		rw.Header().Set("X-Proto", req.Proto)
+		_, _ = rw.Write([]byte("backendTLS"))
+	}))
+	backend.EnableHTTP2 = true
+	backend.StartTLS()
+	t.Cleanup(backend.Close)
+
+	certPool := x509.NewCertPool()
+	certPool.AddCert(backend.Certificate())
+
+	builder := NewProxyBuilder(&transportManagerMock{tlsConfig: &tls.Config{RootCAs: certPool}}, static.FastProxyConfig{})
+	reverseProxy, err := builder.Build("foo", testhelpers.MustParseURL(backend.URL), false, false)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for range concurrency {
+		wg.Go(func() {
+			rw := httptest.NewRecorder()
+			reverseProxy.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+			assert.Equal(t, http.StatusOK, rw.Code)
+			assert.Equal(t, "HTTP/2.0", rw.Header().Get("X-Proto"))
+			assert.Equal(t, "backendTLS", rw.Body.String())
+		})
+	}
+	wg.Wait()
+
+	assert.Len(t, remoteAddrs, 1)
+}
+
+func TestHTTP2_Trailers(t *testing.T) {
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, 2, req.ProtoMajor)
+
+		rw.Header().Set("Trailer", "Grpc-Status")
+		rw.Header().Set("Content-Type", "application/grpc")
+		rw.WriteHeader(http.StatusOK)
+
+		_, _ = rw.Write([]byte("backendTLS"))
+
+		rw.Header().Set("Grpc-Status", "0")
+		rw.Header().Set(http.TrailerPrefix+"Grpc-Message", "ok")
+	}))
+	backend.EnableHTTP2 = true
+	backend.StartTLS()
+	t.Cleanup(backend.Close)
+
+	certPool := x509.NewCertPool()
+	certPool.AddCert(backend.Certificate())
+
+	builder := NewProxyBuilder(&transportManagerMock{tlsConfig: &tls.Config{RootCAs: certPool}}, static.FastProxyConfig{})
+	reverseProxy, err := builder.Build("foo", testhelpers.MustParseURL(backend.URL), false, false)
+	require.NoError(t, err)
+
+	proxyServer := httptest.NewServer(reverseProxy)
+	t.Cleanup(proxyServer.Close)
+
+	resp, err := http.Get(proxyServer.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "backendTLS", string(body))
+	assert.Equal(t, "0", resp.Trailer.Get("Grpc-Status"))
+	assert.Equal(t, "ok", resp.Trailer.Get("Grpc-Message"))
+}
+
+func TestHTTP2_H2CStreaming(t *testing.T) {
+	next := make(chan struct{})
+	backend := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, 2, req.ProtoMajor)
+
+		_, _ = rw.Write([]byte("first\n"))
+		rw.(http.Flusher).Flush()
+
+		select {
+		case <-next:
+		case <-time.After(5 * time.Second):
+		}
+
+		_, _ = rw.Write([]byte("second\n"))
+	}), &http2.Server{}))
+	t.Cleanup(backend.Close)
+
+	targetURL := testhelpers.MustParseURL(backend.URL)
+	targetURL.Scheme = "h2c"
+
+	builder := NewProxyBuilder(&transportManagerMock{}, static.FastProxyConfig{})
+	reverseProxy, err := builder.Build("foo", targetURL, false, false)
+	require.NoError(t, err)
+
+	proxyServer := httptest.NewServer(reverseProxy)
+	t.Cleanup(proxyServer.Close)
+
+	resp, err := http.Get(proxyServer.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// The first message is received while the backend is still streaming the response.
+	br := bufio.NewReader(resp.Body)
+	line, err := br.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "first\n", line)
+
+	close(next)
+
+	line, err = br.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "second\n", line)
+}
+
+func TestHTTP2_H2CForwardProxy(t *testing.T) {
+	backend := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, 2, req.ProtoMajor)
+
+		_, _ = rw.Write([]byte("backend"))
+	}), &http2.Server{}))
+	t.Cleanup(backend.Close)
+
+	_, port, err := net.SplitHostPort(backend.Listener.Addr().String())
+	require.NoError(t, err)
+
+	var connectHost atomic.Value
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		connectHost.Store(req.Host)
+
+		if !assert.Equal(t, http.MethodConnect, req.Method) {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		// The backend host name is resolved by the forward proxy.
+		conn, err := net.Dial("tcp", backend.Listener.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+
+		hj, ok := rw.(http.Hijacker)
+		require.True(t, ok)
+
+		clientConn, _, err := hj.Hijack()
+		require.NoError(t, err)
+		defer clientConn.Close()
+
+		_, err = clientConn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		require.NoError(t, err)
+
+		go func() {
+			_, _ = io.Copy(conn, clientConn)
+			_ = conn.Close()
+		}()
+		_, _ = io.Copy(clientConn, conn)
+	}))
+	t.Cleanup(proxyServer.Close)
+
+	builder := NewProxyBuilder(&transportManagerMock{
+		serversTransport: &dynamic.ServersTransport{
+			Proxy: &dynamic.ForwardProxy{URL: proxyServer.URL},
+		},
+	}, static.FastProxyConfig{})
+
+	reverseProxy, err := builder.Build("foo", testhelpers.MustParseURL("h2c://backend.example.com:"+port), false, false)
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	reverseProxy.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "backend", rw.Body.String())
+	assert.Equal(t, "backend.example.com:"+port, connectHost.Load())
+}
+
+func TestConnPool_AcquireH2Conn_pendingDial(t *testing.T) {
+	backend := httptest.NewServer(h2c.NewHandler(http.NotFoundHandler(), &http2.Server{}))
+	t.Cleanup(backend.Close)
+
+	var dials atomic.Int32
+	release := make(chan struct{})
+	connPool := newConnPool(0, 0, 0, func() (net.Conn, error) {
+		dials.Add(1)
+		<-release
+		return net.Dial("tcp", backend.Listener.Addr().String())
+	})
+	connPool.h2Transport = newHTTP2Transport(&dynamic.ServersTransport{}, 0)
+	connPool.h2PriorKnowledge = true
+	t.Cleanup(connPool.Close)
+
+	const concurrency = 5
+
+	var wg sync.WaitGroup
+	conns := make(chan *http2.ClientConn, concurrency)
+	for range concurrency {
+		wg.Go(func() {
+			cc, co, err := connPool.AcquireH2Conn()
+			require.NoError(t, err)
+			assert.Nil(t, co)
+			conns <- cc
+		})
+	}
+
+	// The dial is pending without holding the pool lock.
+	require.Eventually(t, func() bool { return dials.Load() == 1 }, time.Second, time.Millisecond)
+	require.True(t, connPool.h2Mu.TryLock())
+	connPool.h2Mu.Unlock()
+
+	close(release)
+	wg.Wait()
+	close(conns)
+
+	// The requests waiting for the pending dial share the dialed connection.
+	assert.Equal(t, int32(1), dials.Load())
+
+	var first *http2.ClientConn
+	for cc := range conns {
+		if first == nil {
+			first = cc
+		}
+		assert.Same(t, first, cc)
+	}
+}
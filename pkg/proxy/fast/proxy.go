@@ -240,22 +240,43 @@ func (p *ReverseProxy) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 //     client is asking for an uncompressed response, as we will have to un-compress it, and nowadays most clients are
 //     already asking for compressed response (allowing "passthrough" compression).
 func (p *ReverseProxy) roundTrip(rw http.ResponseWriter, req *http.Request, outReq *fasthttp.Request, reqUpType string) error {
+	// Upgrade requests (e.g. WebSocket) are always sent with HTTP/1.1,
+	// as HTTP/2 does not support the Upgrade mechanism.
+	// The connection dialed while negotiating HTTP/2, if any, is used for the first attempt.
+	var co *conn
+	if reqUpType == "" {
+		cc, h1Conn, err := p.connPool.AcquireH2Conn()
+		if err != nil {
+			return fmt.Errorf("acquire connection: %w", err)
+		}
+
+		if cc != nil {
+			return p.roundTripH2(rw, req, outReq, cc)
+		}
+
+		co = h1Conn
+	}
+
 	ctx := req.Context()
 	trace := httptrace.ContextClientTrace(ctx)
 
-	var co *conn
 	for {
 		select {
 		case <-ctx.Done():
+			if co != nil {
+				co.Close()
+			}
 			return ctx.Err()
 
 		default:
 		}
 
 		var err error
-		co, err = p.connPool.AcquireConn()
-		if err != nil {
-			return fmt.Errorf("acquire connection: %w", err)
+		if co == nil {
+			co, err = p.connPool.AcquireConn()
+			if err != nil {
+				return fmt.Errorf("acquire connection: %w", err)
+			}
 		}
 
 		// Before writing the request,
@@ -279,6 +300,7 @@ func (p *ReverseProxy) roundTrip(rw http.ResponseWriter, req *http.Request, outR
 		log.Ctx(ctx).Debug().Err(err).Msg("Error while writing request")
 
 		co.Close()
+		co = nil
 
 		if wd.written && !isReplayable(req) {
 			return err
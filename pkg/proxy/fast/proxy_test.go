@@ -492,7 +492,8 @@ func TestXForwardedFor(t *testing.T) {
 }
 
 type transportManagerMock struct {
-	tlsConfig *tls.Config
+	tlsConfig        *tls.Config
+	serversTransport *dynamic.ServersTransport
 }
 
 func (r *transportManagerMock) GetTLSConfig(_ string) (*tls.Config, error) {
@@ -500,5 +501,9 @@ func (r *transportManagerMock) GetTLSConfig(_ string) (*tls.Config, error) {
 }
 
 func (r *transportManagerMock) Get(_ string) (*dynamic.ServersTransport, error) {
+	if r.serversTransport != nil {
+		return r.serversTransport, nil
+	}
+
 	return &dynamic.ServersTransport{}, nil
 }
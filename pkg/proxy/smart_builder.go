@@ -2,7 +2,6 @@ package proxy
 
 import (
 	"crypto/tls"
-	"fmt"
 	"net/http"
 	"net/url"
 	"time"
@@ -10,7 +9,6 @@ import (
 	"github.com/hanzoai/ingress/pkg/config/dynamic"
 	"github.com/hanzoai/ingress/pkg/config/static"
 	"github.com/hanzoai/ingress/pkg/proxy/fast"
-	"github.com/hanzoai/ingress/pkg/proxy/httputil"
 	"github.com/hanzoai/ingress/pkg/server/service"
 )
 
@@ -21,13 +19,13 @@ type TransportManager interface {
 	GetTLSConfig(name string) (*tls.Config, error)
 }
 
-// SmartBuilder is a proxy builder which returns a fast proxy or httputil proxy corresponding
-// to the ServersTransport configuration.
+// SmartBuilder is a proxy builder which returns a fast proxy corresponding to the ServersTransport configuration.
+// The fast proxy negotiates HTTP/2 through ALPN with the https servers, unless HTTP/2 is disabled,
+// and uses HTTP/2 with prior knowledge for the h2c servers.
+// The h3 servers, which are contacted over QUIC, are handled by the given proxy builder.
 type SmartBuilder struct {
 	fastProxyBuilder *fast.ProxyBuilder
 	proxyBuilder     service.ProxyBuilder
-
-	transportManager httputil.TransportManager
 }
 
 // NewSmartBuilder creates and returns a new SmartBuilder instance.
@@ -35,7 +33,6 @@ func NewSmartBuilder(transportManager TransportManager, proxyBuilder service.Pro
 	return &SmartBuilder{
 		fastProxyBuilder: fast.NewProxyBuilder(transportManager, fastProxyConfig),
 		proxyBuilder:     proxyBuilder,
-		transportManager: transportManager,
 	}
 }
 
@@ -46,16 +43,10 @@ func (b *SmartBuilder) Update(newConfigs map[string]*dynamic.ServersTransport) {
 
 // Build builds an HTTP proxy for the given URL using the ServersTransport with the given name.
 func (b *SmartBuilder) Build(configName string, targetURL *url.URL, passHostHeader, preservePath bool, flushInterval time.Duration) (http.Handler, error) {
-	serversTransport, err := b.transportManager.Get(configName)
-	if err != nil {
-		return nil, fmt.Errorf("getting ServersTransport: %w", err)
-	}
-
-	// The fast proxy implementation cannot handle HTTP/2 requests for now.
-	// For the https scheme we cannot guess if the backend communication will use HTTP2,
-	// thus we check if HTTP/2 is disabled to use the fast proxy implementation when this is possible.
-	if targetURL.Scheme == "h2c" || (targetURL.Scheme == "https" && !serversTransport.DisableHTTP2) {
+	// The fast proxy implementation cannot handle HTTP/3.
+	if targetURL.Scheme == "h3" {
 		return b.proxyBuilder.Build(configName, targetURL, passHostHeader, preservePath, flushInterval)
 	}
+
 	return b.fastProxyBuilder.Build(configName, targetURL, passHostHeader, preservePath)
 }
@@ -25,32 +25,41 @@ func TestSmartBuilder_Build(t *testing.T) {
 		serversTransport dynamic.ServersTransport
 		fastProxyConfig  static.FastProxyConfig
 		https            bool
+		http2            bool
 		h2c              bool
-		wantFastProxy    bool
+		wantProtoMajor   int
 	}{
 		{
 			desc:            "fastproxy",
 			fastProxyConfig: static.FastProxyConfig{Debug: true},
-			wantFastProxy:   true,
+			wantProtoMajor:  1,
 		},
 		{
-			desc:            "fastproxy with https and without DisableHTTP2",
+			desc:            "fastproxy with https and a server without HTTP/2",
 			https:           true,
 			fastProxyConfig: static.FastProxyConfig{Debug: true},
-			wantFastProxy:   false,
+			wantProtoMajor:  1,
+		},
+		{
+			desc:            "fastproxy with https and a server with HTTP/2",
+			https:           true,
+			http2:           true,
+			fastProxyConfig: static.FastProxyConfig{Debug: true},
+			wantProtoMajor:  2,
 		},
 		{
 			desc:             "fastproxy with https and DisableHTTP2",
 			https:            true,
+			http2:            true,
 			serversTransport: dynamic.ServersTransport{DisableHTTP2: true},
 			fastProxyConfig:  static.FastProxyConfig{Debug: true},
-			wantFastProxy:    true,
+			wantProtoMajor:   1,
 		},
 		{
 			desc:            "fastproxy with h2c",
 			h2c:             true,
 			fastProxyConfig: static.FastProxyConfig{Debug: true},
-			wantFastProxy:   false,
+			wantProtoMajor:  2,
 		},
 	}
 
@@ -61,18 +70,15 @@ func TestSmartBuilder_Build(t *testing.T) {
 			var callCount int
 			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				callCount++
-				if test.wantFastProxy {
-					assert.Contains(t, r.Header, "X-Ingress-Fast-Proxy")
-				} else {
-					assert.NotContains(t, r.Header, "X-Ingress-Fast-Proxy")
-				}
+				assert.Contains(t, r.Header, "X-Ingress-Fast-Proxy")
+				assert.Equal(t, test.wantProtoMajor, r.ProtoMajor)
 			})
 
 			var server *httptest.Server
 
 			if test.https {
 				server = httptest.NewUnstartedServer(handler)
-				server.EnableHTTP2 = false
+				server.EnableHTTP2 = test.http2
 				server.StartTLS()
 
 				certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.TLS.Certificates[0].Certificate[0]})
@@ -107,6 +113,7 @@ func TestSmartBuilder_Build(t *testing.T) {
 			rw := httptest.NewRecorder()
 			proxyHandler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
 
+			assert.Equal(t, http.StatusOK, rw.Code)
 			assert.Equal(t, 1, callCount)
 		})
 	}
@@ -3,13 +3,18 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"maps"
 	"reflect"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/config/history"
+	"github.com/hanzoai/ingress/pkg/config/snapshot"
 	"github.com/hanzoai/ingress/pkg/observability/logs"
 	"github.com/hanzoai/ingress/pkg/provider"
+	"github.com/hanzoai/ingress/pkg/provider/status"
 	"github.com/hanzoai/ingress/pkg/safe"
 	"github.com/hanzoai/ingress/pkg/tls"
 	"github.com/hanzoai/ingress/pkg/types"
@@ -23,16 +28,31 @@ type ConfigurationWatcher struct {
 
 	allProvidersConfigs chan dynamic.Message
 
-	newConfigs chan dynamic.Configurations
+	newConfigs chan configurations
+
+	refresh chan struct{}
 
 	requiredProvider       string
 	configurationListeners []func(dynamic.Configuration)
 
 	configurationTransformers []func(context.Context, dynamic.Configurations) dynamic.Configurations
 
+	snapshot *snapshot.Snapshot
+
+	history *history.History
+
+	providerStatus *status.Tracker
+
 	routinesPool *safe.Pool
 }
 
+// configurations holds the configurations of the providers, as delivered by the providers, and as transformed to be applied.
+// Only the delivered ones are persisted or exposed, as the transformed ones may hold resolved secrets.
+type configurations struct {
+	provided    dynamic.Configurations
+	transformed dynamic.Configurations
+}
+
 // NewConfigurationWatcher creates a new ConfigurationWatcher.
 func NewConfigurationWatcher(
 	routinesPool *safe.Pool,
@@ -43,7 +63,8 @@ func NewConfigurationWatcher(
 	return &ConfigurationWatcher{
 		providerAggregator:  pvd,
 		allProvidersConfigs: make(chan dynamic.Message, 100),
-		newConfigs:          make(chan dynamic.Configurations),
+		newConfigs:          make(chan configurations),
+		refresh:             make(chan struct{}, 1),
 		routinesPool:        routinesPool,
 		defaultEntryPoints:  defaultEntryPoints,
 		requiredProvider:    requiredProvider,
@@ -73,6 +94,32 @@ func (c *ConfigurationWatcher) AddTransformer(transformer func(context.Context,
 	c.configurationTransformers = append(c.configurationTransformers, transformer)
 }
 
+// UseSnapshot sets the snapshot in which the applied configurations are persisted,
+// and from which the provisional configurations are loaded at startup.
+func (c *ConfigurationWatcher) UseSnapshot(s *snapshot.Snapshot) {
+	c.snapshot = s
+}
+
+// UseHistory sets the history in which the changes of the applied configurations are recorded.
+func (c *ConfigurationWatcher) UseHistory(h *history.History) {
+	c.history = h
+}
+
+// UseProviderStatus sets the tracker in which the configurations delivered by the providers are recorded.
+func (c *ConfigurationWatcher) UseProviderStatus(t *status.Tracker) {
+	c.providerStatus = t
+}
+
+// Refresh transforms and applies again the last received configurations,
+// e.g. when the secrets resolved by a transformer changed.
+func (c *ConfigurationWatcher) Refresh() {
+	// A refresh is already pending otherwise.
+	select {
+	case c.refresh <- struct{}{}:
+	default:
+	}
+}
+
 func (c *ConfigurationWatcher) startProviderAggregator() {
 	log.Info().Msgf("Starting provider aggregator %T", c.providerAggregator)
 
@@ -95,14 +142,39 @@ func (c *ConfigurationWatcher) receiveConfigurations(ctx context.Context) {
 	newConfigurations := make(dynamic.Configurations)
 	transformedConfigurations := make(dynamic.Configurations)
 
-	var output chan dynamic.Configurations
+	var output chan configurations
+	var provisionalExpired <-chan time.Time
+
+	// The configurations of the last known good snapshot are used until their providers deliver theirs.
+	// The required provider is excluded, so that nothing is applied before it delivers its configuration.
+	provisionalConfigurations, err := c.snapshot.Load(c.requiredProvider)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Unable to load the configuration snapshot")
+	}
+
+	if len(provisionalConfigurations) > 0 {
+		log.Ctx(ctx).Warn().Msgf("Using provisional configurations from snapshot for %d provider(s)", len(provisionalConfigurations))
+
+		maps.Copy(newConfigurations, provisionalConfigurations)
+
+		transformedConfigurations = newConfigurations
+		for _, transform := range c.configurationTransformers {
+			transformedConfigurations = transform(ctx, transformedConfigurations.DeepCopy())
+		}
+
+		output = c.newConfigs
+
+		if timeout := c.snapshot.ProvisionalTimeout(); timeout > 0 {
+			provisionalExpired = time.After(timeout)
+		}
+	}
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		// DeepCopy is necessary because transformedConfigurations gets modified later by the consumer of c.newConfigs.
-		case output <- transformedConfigurations.DeepCopy():
+		case output <- configurations{provided: newConfigurations.DeepCopy(), transformed: transformedConfigurations.DeepCopy()}:
 			output = nil
 
 		default:
@@ -121,13 +193,29 @@ func (c *ConfigurationWatcher) receiveConfigurations(ctx context.Context) {
 					continue
 				}
 
+				c.providerStatus.Updated(configMsg.ProviderName, configMsg.Configuration)
+
 				if isEmptyConfiguration(configMsg.Configuration) {
+					// The provisional configuration of the provider is dropped, as it is outdated.
+					if c.snapshot.Delivered(configMsg.ProviderName) {
+						delete(newConfigurations, configMsg.ProviderName)
+
+						transformedConfigurations = newConfigurations
+						for _, transform := range c.configurationTransformers {
+							transformedConfigurations = transform(logger.WithContext(ctx), transformedConfigurations.DeepCopy())
+						}
+
+						output = c.newConfigs
+					}
+
 					logger.Debug().Msg("Skipping empty configuration")
 					continue
 				}
 
 				logConfiguration(logger, configMsg)
 
+				c.snapshot.Delivered(configMsg.ProviderName)
+
 				if reflect.DeepEqual(newConfigurations[configMsg.ProviderName], configMsg.Configuration) {
 					// no change, do nothing.
 					logger.Debug().Msg("Skipping unchanged configuration")
@@ -143,8 +231,42 @@ func (c *ConfigurationWatcher) receiveConfigurations(ctx context.Context) {
 
 				output = c.newConfigs
 
+			case <-provisionalExpired:
+				provisionalExpired = nil
+
+				// The providers which did not deliver any configuration in time are most likely no longer configured.
+				expired := c.snapshot.Expire()
+				if len(expired) == 0 {
+					continue
+				}
+
+				log.Ctx(ctx).Warn().Strs("providers", expired).Msg("Dropping expired provisional configurations from snapshot")
+
+				for _, name := range expired {
+					delete(newConfigurations, name)
+				}
+
+				transformedConfigurations = newConfigurations
+				for _, transform := range c.configurationTransformers {
+					transformedConfigurations = transform(ctx, transformedConfigurations.DeepCopy())
+				}
+
+				output = c.newConfigs
+
+			case <-c.refresh:
+				if len(newConfigurations) == 0 {
+					continue
+				}
+
+				transformedConfigurations = newConfigurations
+				for _, transform := range c.configurationTransformers {
+					transformedConfigurations = transform(ctx, transformedConfigurations.DeepCopy())
+				}
+
+				output = c.newConfigs
+
 			// DeepCopy is necessary because newConfigurations gets modified later by the consumer of c.newConfigs.
-			case output <- transformedConfigurations.DeepCopy():
+			case output <- configurations{provided: newConfigurations.DeepCopy(), transformed: transformedConfigurations.DeepCopy()}:
 				output = nil
 			}
 		}
@@ -155,16 +277,18 @@ func (c *ConfigurationWatcher) receiveConfigurations(ctx context.Context) {
 // receiveConfigurations and applies them if they differ from the previous set.
 // It waits for the required provider's configuration before applying any configs.
 func (c *ConfigurationWatcher) applyConfigurations(ctx context.Context) {
-	var lastConfigurations dynamic.Configurations
+	var lastConfigurations, lastProvidedConfigurations dynamic.Configurations
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case newConfigs, ok := <-c.newConfigs:
+		case configs, ok := <-c.newConfigs:
 			if !ok {
 				return
 			}
 
+			newConfigs := configs.transformed
+
 			// We wait for first configuration of the required provider before applying configurations.
 			if _, ok := newConfigs[c.requiredProvider]; c.requiredProvider != "" && !ok {
 				continue
@@ -181,7 +305,14 @@ func (c *ConfigurationWatcher) applyConfigurations(ctx context.Context) {
 				listener(conf)
 			}
 
+			c.history.Record(lastProvidedConfigurations, configs.provided)
+
+			if err := c.snapshot.Save(configs.provided); err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("Unable to save the configuration snapshot")
+			}
+
 			lastConfigurations = newConfigs
+			lastProvidedConfigurations = configs.provided
 		}
 	}
 }
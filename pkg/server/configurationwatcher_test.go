@@ -3,14 +3,22 @@ package server
 import (
 	"context"
 	"errors"
+	"maps"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/config/history"
+	"github.com/hanzoai/ingress/pkg/config/snapshot"
 	"github.com/hanzoai/ingress/pkg/provider/aggregator"
+	"github.com/hanzoai/ingress/pkg/provider/status"
 	"github.com/hanzoai/ingress/pkg/safe"
 	th "github.com/hanzoai/ingress/pkg/testhelpers"
 	"github.com/hanzoai/ingress/pkg/tls"
@@ -992,3 +1000,280 @@ func TestConfigurationWatcher_MultipleTransformers(t *testing.T) {
 	assert.Equal(t, 1, callCount1)
 	assert.Equal(t, 1, callCount2)
 }
+
+func TestConfigurationWatcher_Snapshot(t *testing.T) {
+	routinesPool := safe.NewPool(t.Context())
+	t.Cleanup(routinesPool.Stop)
+
+	configSnapshot, err := snapshot.New(filepath.Join(t.TempDir(), "snapshot.json"), "")
+	require.NoError(t, err)
+
+	err = configSnapshot.Save(dynamic.Configurations{
+		"stale": &dynamic.Configuration{
+			HTTP: th.BuildConfiguration(
+				th.WithRouters(th.WithRouter("foo", th.WithEntryPoints("ep"), th.WithServiceName("foo"))),
+			),
+		},
+	})
+	require.NoError(t, err)
+
+	pvd := &mockProvider{
+		messages: []dynamic.Message{{
+			ProviderName: "mock",
+			Configuration: &dynamic.Configuration{
+				HTTP: th.BuildConfiguration(
+					th.WithRouters(th.WithRouter("bar", th.WithEntryPoints("ep"), th.WithServiceName("bar"))),
+				),
+			},
+		}},
+	}
+
+	watcher := NewConfigurationWatcher(routinesPool, pvd, []string{}, "")
+	watcher.UseSnapshot(configSnapshot)
+
+	// The transformed configurations, which may hold resolved secrets, are never persisted.
+	watcher.AddTransformer(func(_ context.Context, configurations dynamic.Configurations) dynamic.Configurations {
+		for _, conf := range configurations {
+			for _, router := range conf.HTTP.Routers {
+				router.Rule = "Host(`transformed.example.com`)"
+			}
+		}
+		return configurations
+	})
+
+	var (
+		mu      sync.Mutex
+		routers []string
+	)
+	watcher.AddListener(func(conf dynamic.Configuration) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		routers = slices.Sorted(maps.Keys(conf.HTTP.Routers))
+	})
+
+	watcher.Start()
+	t.Cleanup(watcher.Stop)
+
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		assert.Equal(c, []string{"bar@mock", "foo@stale"}, routers)
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, []string{"stale"}, configSnapshot.Status().ProvisionalProviders)
+
+	// The applied configurations, including the provisional ones, are persisted.
+	configurations, err := configSnapshot.Load()
+	require.NoError(t, err)
+	require.Contains(t, configurations, "mock")
+	assert.Contains(t, configurations, "stale")
+	assert.Empty(t, configurations["mock"].HTTP.Routers["bar"].Rule)
+}
+
+func TestConfigurationWatcher_SnapshotExpiration(t *testing.T) {
+	routinesPool := safe.NewPool(t.Context())
+	t.Cleanup(routinesPool.Stop)
+
+	configSnapshot, err := snapshot.New(filepath.Join(t.TempDir(), "snapshot.json"), "")
+	require.NoError(t, err)
+	configSnapshot.SetProvisionalTimeout(50 * time.Millisecond)
+
+	err = configSnapshot.Save(dynamic.Configurations{
+		"removed": &dynamic.Configuration{
+			HTTP: th.BuildConfiguration(
+				th.WithRouters(th.WithRouter("foo", th.WithEntryPoints("ep"), th.WithServiceName("foo"))),
+			),
+		},
+	})
+	require.NoError(t, err)
+
+	pvd := &mockProvider{
+		messages: []dynamic.Message{{
+			ProviderName: "mock",
+			Configuration: &dynamic.Configuration{
+				HTTP: th.BuildConfiguration(
+					th.WithRouters(th.WithRouter("bar", th.WithEntryPoints("ep"), th.WithServiceName("bar"))),
+				),
+			},
+		}},
+	}
+
+	watcher := NewConfigurationWatcher(routinesPool, pvd, []string{}, "")
+	watcher.UseSnapshot(configSnapshot)
+
+	var (
+		mu      sync.Mutex
+		routers []string
+	)
+	watcher.AddListener(func(conf dynamic.Configuration) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		routers = slices.Sorted(maps.Keys(conf.HTTP.Routers))
+	})
+
+	watcher.Start()
+	t.Cleanup(watcher.Stop)
+
+	// The provisional configuration of the provider which never delivers its own is dropped.
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		assert.Equal(c, []string{"bar@mock"}, routers)
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Empty(t, configSnapshot.Status().ProvisionalProviders)
+
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		configurations, err := configSnapshot.Load()
+		require.NoError(c, err)
+		assert.NotContains(c, configurations, "removed")
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestConfigurationWatcher_History(t *testing.T) {
+	routinesPool := safe.NewPool(t.Context())
+	t.Cleanup(routinesPool.Stop)
+
+	pvd := &mockProvider{
+		messages: []dynamic.Message{
+			{
+				ProviderName: "mock",
+				Configuration: &dynamic.Configuration{
+					HTTP: th.BuildConfiguration(
+						th.WithRouters(th.WithRouter("foo", th.WithEntryPoints("ep"), th.WithServiceName("foo"))),
+					),
+				},
+			},
+			{
+				ProviderName: "mock",
+				Configuration: &dynamic.Configuration{
+					HTTP: th.BuildConfiguration(
+						th.WithRouters(
+							th.WithRouter("foo", th.WithEntryPoints("ep"), th.WithServiceName("bar")),
+							th.WithRouter("bar", th.WithEntryPoints("ep"), th.WithServiceName("bar")),
+						),
+					),
+				},
+			},
+		},
+	}
+
+	configHistory := history.New(history.DefaultSize)
+
+	watcher := NewConfigurationWatcher(routinesPool, pvd, []string{}, "")
+	watcher.UseHistory(configHistory)
+
+	watcher.Start()
+	t.Cleanup(watcher.Stop)
+
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		entries := configHistory.Entries()
+		require.Len(c, entries, 2)
+
+		assert.Equal(c, []history.ProviderDiff{{
+			Provider: "mock",
+			Changes: []history.Change{
+				{Kind: "http.routers", Name: "bar", Action: history.ActionAdded},
+				{Kind: "http.routers", Name: "foo", Action: history.ActionModified, Fields: []string{"service"}},
+			},
+		}}, entries[0].Providers)
+
+		assert.Equal(c, []history.ProviderDiff{{
+			Provider: "mock",
+			Changes: []history.Change{
+				{Kind: "http.routers", Name: "foo", Action: history.ActionAdded},
+			},
+		}}, entries[1].Providers)
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestConfigurationWatcher_ProviderStatus(t *testing.T) {
+	routinesPool := safe.NewPool(t.Context())
+	t.Cleanup(routinesPool.Stop)
+
+	pvd := &mockProvider{
+		messages: []dynamic.Message{
+			{
+				ProviderName: "mock",
+				Configuration: &dynamic.Configuration{
+					HTTP: th.BuildConfiguration(
+						th.WithRouters(th.WithRouter("foo", th.WithEntryPoints("ep"), th.WithServiceName("foo"))),
+						th.WithServices(th.WithService("foo", th.WithServiceServersLoadBalancer(th.WithServers(th.WithServer("http://127.0.0.1"))))),
+					),
+				},
+			},
+			{ProviderName: "empty", Configuration: &dynamic.Configuration{}},
+			{ProviderName: "nil"},
+		},
+	}
+
+	tracker := status.NewTracker(nil)
+
+	watcher := NewConfigurationWatcher(routinesPool, pvd, []string{}, "")
+	watcher.UseProviderStatus(tracker)
+
+	watcher.Start()
+	t.Cleanup(watcher.Stop)
+
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		assert.True(c, tracker.Synced("mock", "empty"))
+	}, time.Second, 10*time.Millisecond)
+
+	assert.False(t, tracker.Synced("nil"))
+
+	statuses := tracker.Statuses()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "empty", statuses[0].Name)
+	assert.Equal(t, status.Objects{}, statuses[0].Objects)
+	assert.Equal(t, "mock", statuses[1].Name)
+	assert.Equal(t, status.StateReady, statuses[1].State)
+	assert.Equal(t, status.Objects{HTTPRouters: 1, HTTPServices: 1}, statuses[1].Objects)
+}
+
+func TestConfigurationWatcher_Refresh(t *testing.T) {
+	routinesPool := safe.NewPool(t.Context())
+	t.Cleanup(routinesPool.Stop)
+
+	pvd := &mockProvider{
+		messages: []dynamic.Message{{
+			ProviderName: "mock",
+			Configuration: &dynamic.Configuration{
+				HTTP: th.BuildConfiguration(
+					th.WithRouters(th.WithRouter("foo", th.WithEntryPoints("ep"), th.WithServiceName("foo"))),
+				),
+			},
+		}},
+	}
+
+	watcher := NewConfigurationWatcher(routinesPool, pvd, []string{}, "")
+
+	var rule atomic.Value
+	rule.Store("Host(`v1.example.com`)")
+	watcher.AddTransformer(func(_ context.Context, configurations dynamic.Configurations) dynamic.Configurations {
+		configurations["mock"].HTTP.Routers["foo"].Rule = rule.Load().(string)
+		return configurations
+	})
+
+	var lastRule atomic.Value
+	watcher.AddListener(func(conf dynamic.Configuration) {
+		lastRule.Store(conf.HTTP.Routers["foo@mock"].Rule)
+	})
+
+	watcher.Start()
+	t.Cleanup(watcher.Stop)
+
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		assert.Equal(c, "Host(`v1.example.com`)", lastRule.Load())
+	}, time.Second, 10*time.Millisecond)
+
+	rule.Store("Host(`v2.example.com`)")
+	watcher.Refresh()
+
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		assert.Equal(c, "Host(`v2.example.com`)", lastRule.Load())
+	}, time.Second, 10*time.Millisecond)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/containous/alice"
 	"github.com/rs/zerolog/log"
 	"github.com/hanzoai/ingress/pkg/config/runtime"
+	"github.com/hanzoai/ingress/pkg/middlewares/adaptiveconcurrency"
 	"github.com/hanzoai/ingress/pkg/middlewares/addprefix"
 	"github.com/hanzoai/ingress/pkg/middlewares/auth"
 	"github.com/hanzoai/ingress/pkg/middlewares/buffering"
@@ -30,6 +31,7 @@ import (
 	"github.com/hanzoai/ingress/pkg/middlewares/ipwhitelist"
 	"github.com/hanzoai/ingress/pkg/middlewares/observability"
 	"github.com/hanzoai/ingress/pkg/middlewares/passtlsclientcert"
+	"github.com/hanzoai/ingress/pkg/middlewares/queue"
 	"github.com/hanzoai/ingress/pkg/middlewares/ratelimiter"
 	"github.com/hanzoai/ingress/pkg/middlewares/redirect"
 	"github.com/hanzoai/ingress/pkg/middlewares/replacepath"
@@ -38,6 +40,7 @@ import (
 	"github.com/hanzoai/ingress/pkg/middlewares/staticfiles"
 	"github.com/hanzoai/ingress/pkg/middlewares/stripprefix"
 	"github.com/hanzoai/ingress/pkg/middlewares/stripprefixregex"
+	"github.com/hanzoai/ingress/pkg/observability/metrics"
 	"github.com/hanzoai/ingress/pkg/server/provider"
 	"github.com/hanzoai/ingress/pkg/server/recursion"
 )
@@ -47,6 +50,9 @@ type Builder struct {
 	configs        map[string]*runtime.MiddlewareInfo
 	pluginBuilder  PluginsBuilder
 	serviceBuilder serviceBuilder
+	// concurrencyLimiters holds the limiters shared by the adaptive concurrency middlewares.
+	concurrencyLimiters *adaptiveconcurrency.Limiters
+	metricsRegistry     metrics.Registry
 }
 
 type serviceBuilder interface {
@@ -55,7 +61,23 @@ type serviceBuilder interface {
 
 // NewBuilder creates a new Builder.
 func NewBuilder(configs map[string]*runtime.MiddlewareInfo, serviceBuilder serviceBuilder, pluginBuilder PluginsBuilder) *Builder {
-	return &Builder{configs: configs, serviceBuilder: serviceBuilder, pluginBuilder: pluginBuilder}
+	return &Builder{
+		configs:             configs,
+		serviceBuilder:      serviceBuilder,
+		pluginBuilder:       pluginBuilder,
+		concurrencyLimiters: adaptiveconcurrency.NewLimiters(),
+	}
+}
+
+// SetConcurrencyLimiters sets the limiters shared by the adaptive concurrency middlewares,
+// which are kept across the configuration reloads.
+func (b *Builder) SetConcurrencyLimiters(limiters *adaptiveconcurrency.Limiters) {
+	b.concurrencyLimiters = limiters
+}
+
+// SetMetricsRegistry sets the registry of the metrics reported by the middlewares.
+func (b *Builder) SetMetricsRegistry(registry metrics.Registry) {
+	b.metricsRegistry = registry
 }
 
 // BuildMiddlewareChain creates a middleware chain.
@@ -381,6 +403,26 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// AdaptiveConcurrency
+	if config.AdaptiveConcurrency != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return adaptiveconcurrency.New(ctx, next, b.concurrencyLimiters, *config.AdaptiveConcurrency, middlewareName)
+		}
+	}
+
+	// Queue
+	if config.Queue != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return queue.New(ctx, next, b.metricsRegistry, *config.Queue, middlewareName)
+		}
+	}
+
 	// Plugin
 	if config.Plugin != nil && !reflect.ValueOf(b.pluginBuilder).IsNil() { // Using "reflect" because "b.pluginBuilder" is an interface.
 		if middleware != nil {
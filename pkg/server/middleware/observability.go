@@ -13,6 +13,7 @@ import (
 	"github.com/hanzoai/ingress/pkg/middlewares/capture"
 	mmetrics "github.com/hanzoai/ingress/pkg/middlewares/metrics"
 	"github.com/hanzoai/ingress/pkg/middlewares/observability"
+	"github.com/hanzoai/ingress/pkg/middlewares/tap"
 	"github.com/hanzoai/ingress/pkg/observability/logs"
 	"github.com/hanzoai/ingress/pkg/observability/metrics"
 	"github.com/hanzoai/ingress/pkg/observability/tracing"
@@ -27,10 +28,26 @@ type ObservabilityMgr struct {
 	semConvMetricRegistry  *metrics.SemConvMetricsRegistry
 	tracer                 *tracing.Tracer
 	tracerCloser           io.Closer
+	tapHub                 *tap.Hub
+	logLevels              *logs.LevelController
 }
 
 // NewObservabilityMgr creates a new ObservabilityMgr.
-func NewObservabilityMgr(config static.Configuration, metricsRegistry metrics.Registry, semConvMetricRegistry *metrics.SemConvMetricsRegistry, accessLoggerMiddleware *accesslog.Handler, tracer *tracing.Tracer, tracerCloser io.Closer) *ObservabilityMgr {
+func NewObservabilityMgr(config static.Configuration, metricsRegistry metrics.Registry, semConvMetricRegistry *metrics.SemConvMetricsRegistry, accessLoggerMiddleware *accesslog.Handler, tracer *tracing.Tracer, tracerCloser io.Closer, logLevels *logs.LevelController) *ObservabilityMgr {
+	var tapHub *tap.Hub
+	if config.API != nil {
+		var fields *otypes.AccessLogFields
+		if config.AccessLog != nil {
+			fields = config.AccessLog.Fields
+		}
+
+		var err error
+		tapHub, err = tap.NewHub(fields)
+		if err != nil {
+			log.Error().Err(err).Msg("Could not create the request tap hub")
+		}
+	}
+
 	return &ObservabilityMgr{
 		config:                 config,
 		metricsRegistry:        metricsRegistry,
@@ -38,6 +55,8 @@ func NewObservabilityMgr(config static.Configuration, metricsRegistry metrics.Re
 		accessLoggerMiddleware: accessLoggerMiddleware,
 		tracer:                 tracer,
 		tracerCloser:           tracerCloser,
+		tapHub:                 tapHub,
+		logLevels:              logLevels,
 	}
 }
 
@@ -98,6 +117,24 @@ func (o *ObservabilityMgr) SemConvMetricsRegistry() *metrics.SemConvMetricsRegis
 	return o.semConvMetricRegistry
 }
 
+// TapHub is an accessor to the request tap hub.
+func (o *ObservabilityMgr) TapHub() *tap.Hub {
+	if o == nil {
+		return nil
+	}
+
+	return o.tapHub
+}
+
+// LogLevels is an accessor to the log level controller.
+func (o *ObservabilityMgr) LogLevels() *logs.LevelController {
+	if o == nil {
+		return nil
+	}
+
+	return o.logLevels
+}
+
 // Close closes the accessLogger and tracer.
 func (o *ObservabilityMgr) Close() {
 	if o == nil {
@@ -19,6 +19,7 @@ import (
 	metricsMiddle "github.com/hanzoai/ingress/pkg/middlewares/metrics"
 	"github.com/hanzoai/ingress/pkg/middlewares/observability"
 	"github.com/hanzoai/ingress/pkg/middlewares/recovery"
+	"github.com/hanzoai/ingress/pkg/middlewares/tap"
 	httpmuxer "github.com/hanzoai/ingress/pkg/muxer/http"
 	"github.com/hanzoai/ingress/pkg/observability/logs"
 	"github.com/hanzoai/ingress/pkg/server/middleware"
@@ -46,6 +47,12 @@ type Manager struct {
 	conf               *runtime.Configuration
 	tlsManager         *tls.Manager
 	parser             httpmuxer.SyntaxParser
+
+	// muxers are the muxers of the entry points, by entry point name, for the non-TLS and the TLS routers.
+	muxers    map[string]*httpmuxer.Muxer
+	muxersTLS map[string]*httpmuxer.Muxer
+	// childMuxers are the muxers of the child routers, by parent router name.
+	childMuxers map[string]*httpmuxer.Muxer
 }
 
 // NewManager creates a new Manager.
@@ -64,6 +71,9 @@ func NewManager(conf *runtime.Configuration,
 		conf:               conf,
 		tlsManager:         tlsManager,
 		parser:             parser,
+		muxers:             make(map[string]*httpmuxer.Muxer),
+		muxersTLS:          make(map[string]*httpmuxer.Muxer),
+		childMuxers:        make(map[string]*httpmuxer.Muxer),
 	}
 }
 
@@ -178,13 +188,19 @@ func (m *Manager) BuildHandlers(rootCtx context.Context, entryPoints []string, t
 			epObsConfig = model.Observability
 		}
 
-		handler, err := m.buildEntryPointHandler(ctx, entryPointName, routers, epObsConfig)
+		handler, muxer, err := m.buildEntryPointHandler(ctx, entryPointName, routers, epObsConfig)
 		if err != nil {
 			logger.Error().Err(err).Send()
 			continue
 		}
 
 		entryPointHandlers[entryPointName] = handler
+
+		if tls {
+			m.muxersTLS[entryPointName] = muxer
+		} else {
+			m.muxers[entryPointName] = muxer
+		}
 	}
 
 	// Create default handlers.
@@ -224,12 +240,39 @@ func (m *Manager) getHTTPRouters(ctx context.Context, entryPoints []string, tls
 	return make(map[string]map[string]*runtime.RouterInfo)
 }
 
-func (m *Manager) buildEntryPointHandler(ctx context.Context, entryPointName string, configs map[string]*runtime.RouterInfo, config dynamic.RouterObservabilityConfig) (http.Handler, error) {
+// Explain returns the routers handling the request received on the entry point, by level of the router tree:
+// the first level holds the matching root routers, and each next level the matching child routers of the first router of the previous level.
+// The routers of a level are in the order they are evaluated by the muxer, the first one being the router the request is forwarded to.
+func (m *Manager) Explain(entryPointName string, req *http.Request) ([][]httpmuxer.RouteMatch, error) {
+	muxer := m.muxers[entryPointName]
+	if req.TLS != nil {
+		muxer = m.muxersTLS[entryPointName]
+	}
+
+	var levels [][]httpmuxer.RouteMatch
+	for muxer != nil {
+		matches, err := muxer.Explain(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(matches) == 0 {
+			break
+		}
+
+		levels = append(levels, matches)
+		muxer = m.childMuxers[matches[0].Name]
+	}
+
+	return levels, nil
+}
+
+func (m *Manager) buildEntryPointHandler(ctx context.Context, entryPointName string, configs map[string]*runtime.RouterInfo, config dynamic.RouterObservabilityConfig) (http.Handler, *httpmuxer.Muxer, error) {
 	muxer := httpmuxer.NewMuxer(m.parser)
 
 	defaultHandler, err := m.observabilityMgr.BuildEPChain(ctx, entryPointName, false, config).Then(http.NotFoundHandler())
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	muxer.SetDefaultHandler(defaultHandler)
@@ -274,7 +317,7 @@ func (m *Manager) buildEntryPointHandler(ctx context.Context, entryPointName str
 			continue
 		}
 
-		if err = muxer.AddRoute(routerConfig.Rule, routerConfig.RuleSyntax, routerConfig.Priority, handler); err != nil {
+		if err = muxer.AddNamedRoute(routerName, routerConfig.Rule, routerConfig.RuleSyntax, routerConfig.Priority, handler); err != nil {
 			routerConfig.AddError(err, true)
 			logger.Error().Err(err).Send()
 			continue
@@ -286,7 +329,12 @@ func (m *Manager) buildEntryPointHandler(ctx context.Context, entryPointName str
 		return recovery.New(ctx, next)
 	})
 
-	return chain.Then(muxer)
+	handler, err := chain.Then(muxer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return handler, muxer, nil
 }
 
 func (m *Manager) buildRouterHandler(ctx context.Context, entryPointName, routerName string, routerConfig *runtime.RouterInfo) (http.Handler, error) {
@@ -337,10 +385,12 @@ func (m *Manager) buildHTTPHandler(ctx context.Context, router *runtime.RouterIn
 	switch {
 	case len(router.ChildRefs) > 0:
 		// This router routes to child routers - create a muxer for them
-		nextHandler, err = m.buildChildRoutersMuxer(ctx, entryPointName, router.ChildRefs)
+		childMuxer, err := m.buildChildRoutersMuxer(ctx, entryPointName, router.ChildRefs)
 		if err != nil {
 			return nil, fmt.Errorf("building child routers muxer: %w", err)
 		}
+		m.childMuxers[routerName] = childMuxer
+		nextHandler = childMuxer
 		serviceName = fmt.Sprintf("%s-muxer", routerName)
 	case router.Service != "":
 		// This router routes to a service
@@ -364,6 +414,8 @@ func (m *Manager) buildHTTPHandler(ctx context.Context, router *runtime.RouterIn
 		return accesslog.NewConcatFieldHandler(next, accesslog.RouterName, routerName), nil
 	})
 
+	chain = chain.Append(tap.WrapHandler(ctx, m.observabilityMgr.TapHub(), routerName))
+
 	// Here we are adding deny handlers for encoded path characters and fragment.
 	// Deny handler are only added for root routers, child routers are protected by their parent router deny handlers.
 	if len(router.ParentRefs) == 0 && router.DeniedEncodedPathCharacters != nil {
@@ -455,7 +507,7 @@ func (m *Manager) handleCycle(victimRouter string, path []string) {
 }
 
 // buildChildRoutersMuxer creates a muxer for child routers.
-func (m *Manager) buildChildRoutersMuxer(ctx context.Context, entryPointName string, childRefs []string) (http.Handler, error) {
+func (m *Manager) buildChildRoutersMuxer(ctx context.Context, entryPointName string, childRefs []string) (*httpmuxer.Muxer, error) {
 	childMuxer := httpmuxer.NewMuxer(m.parser)
 
 	// Set a default handler for the child muxer (404 Not Found).
@@ -490,7 +542,7 @@ func (m *Manager) buildChildRoutersMuxer(ctx context.Context, entryPointName str
 		}
 
 		// Add the child router to the muxer.
-		if err = childMuxer.AddRoute(childRouter.Rule, childRouter.RuleSyntax, childRouter.Priority, childHandler); err != nil {
+		if err = childMuxer.AddNamedRoute(childName, childRouter.Rule, childRouter.RuleSyntax, childRouter.Priority, childHandler); err != nil {
 			childRouter.AddError(err, true)
 			logger.Error().Err(err).Send()
 			continue
@@ -11,6 +11,7 @@ import (
 	"net"
 	"net/http"
 	"slices"
+	"sync"
 	"time"
 
 	"github.com/go-acme/lego/v4/challenge/tlsalpn01"
@@ -39,6 +40,8 @@ type Router struct {
 	muxerTCPTLS tcpmuxer.Muxer
 	// Contains HTTPS routes.
 	muxerHTTPS tcpmuxer.Muxer
+	// muxerHTTPSMu guards muxerHTTPS, whose routes are added when the router is switched, against the concurrent explanations.
+	muxerHTTPSMu sync.RWMutex
 
 	// Forwarder handlers.
 	// httpForwarder handles all HTTP requests.
@@ -227,6 +230,34 @@ func (r *Router) ServeTCP(conn tcp.WriteCloser) {
 	conn.Close()
 }
 
+// Explain returns the TCP routes matching the connection, in the order they are evaluated,
+// and reports whether the connection is forwarded to the HTTP handler rather than to the first TCP route,
+// following the same precedence rules as ServeTCP.
+func (r *Router) Explain(connData tcpmuxer.ConnData, isTLS bool) ([]tcpmuxer.RouteMatch, bool) {
+	if !isTLS {
+		matchesTCP := r.muxerTCP.Explain(connData)
+		return matchesTCP, len(matchesTCP) == 0
+	}
+
+	r.muxerHTTPSMu.RLock()
+	matchesHTTPS := r.muxerHTTPS.Explain(connData)
+	r.muxerHTTPSMu.RUnlock()
+
+	matchesTCPTLS := r.muxerTCPTLS.Explain(connData)
+
+	switch {
+	case len(matchesHTTPS) > 0 && !matchesHTTPS[0].CatchAll:
+		return matchesTCPTLS, true
+	case len(matchesTCPTLS) > 0 && !matchesTCPTLS[0].CatchAll:
+		return matchesTCPTLS, false
+	case len(matchesHTTPS) > 0:
+		return matchesTCPTLS, true
+	default:
+		// Without any matching TCP route, the connection falls back to the HTTPS forwarder.
+		return matchesTCPTLS, len(matchesTCPTLS) == 0
+	}
+}
+
 // AddTCPRoute defines a handler for the given rule.
 func (r *Router) AddTCPRoute(rule string, priority int, target tcp.Handler) error {
 	return r.muxerTCP.AddRoute(rule, "", priority, target)
@@ -271,6 +302,9 @@ func (r *Router) SetHTTPForwarder(handler tcp.Handler) {
 // It also sets up each TLS handler (with its TLS config) for each Host(SNI) rule we previously kept track of.
 // It sets up a special handler that closes the connection if a TLS config is nil.
 func (r *Router) SetHTTPSForwarder(handler tcp.Handler) {
+	r.muxerHTTPSMu.Lock()
+	defer r.muxerHTTPSMu.Unlock()
+
 	for sniHost, tlsConf := range r.hostHTTPTLSConfig {
 		var tcpHandler tcp.Handler
 		if tlsConf == nil {
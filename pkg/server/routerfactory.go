@@ -3,17 +3,27 @@ package server
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"sync"
 
 	"github.com/rs/zerolog/log"
+	"github.com/hanzoai/ingress/pkg/api"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
 	"github.com/hanzoai/ingress/pkg/config/runtime"
 	"github.com/hanzoai/ingress/pkg/config/static"
+	"github.com/hanzoai/ingress/pkg/middlewares/adaptiveconcurrency"
 	httpmuxer "github.com/hanzoai/ingress/pkg/muxer/http"
+	tcpmuxer "github.com/hanzoai/ingress/pkg/muxer/tcp"
+	"github.com/hanzoai/ingress/pkg/observability/logs"
 	"github.com/hanzoai/ingress/pkg/server/middleware"
 	tcpmiddleware "github.com/hanzoai/ingress/pkg/server/middleware/tcp"
 	"github.com/hanzoai/ingress/pkg/server/router"
 	tcprouter "github.com/hanzoai/ingress/pkg/server/router/tcp"
 	udprouter "github.com/hanzoai/ingress/pkg/server/router/udp"
 	"github.com/hanzoai/ingress/pkg/server/service"
+	"github.com/hanzoai/ingress/pkg/server/service/discovery"
 	tcpsvc "github.com/hanzoai/ingress/pkg/server/service/tcp"
 	udpsvc "github.com/hanzoai/ingress/pkg/server/service/udp"
 	"github.com/hanzoai/ingress/pkg/tcp"
@@ -37,9 +47,32 @@ type RouterFactory struct {
 
 	dialerManager *tcp.DialerManager
 
+	// dnsDiscovery is shared by the configurations, so that the resolved records survive the reloads.
+	dnsDiscovery *discovery.DNSResolver
+
+	// concurrencyLimiters is shared by the configurations, so that the learned concurrency limits survive the reloads.
+	concurrencyLimiters *adaptiveconcurrency.Limiters
+
+	// zone is the zone of the instance.
+	zone string
+
 	cancelPrevState func()
 
 	parser httpmuxer.SyntaxParser
+
+	// lastConf is the last configuration given to UpdateServers.
+	lastConf *dynamic.Configuration
+	// The state of the last routers created, whose servers are updated in place.
+	ctx            context.Context
+	rtConf         *runtime.Configuration
+	serviceManager *service.Manager
+	svcTCPManager  *tcpsvc.Manager
+	svcUDPManager  *udpsvc.Manager
+	// The routers are also used to explain the routing of the simulated requests,
+	// routersMu guards the state against these concurrent explanations.
+	routerManager *router.Manager
+	routersTCP    map[string]*tcprouter.Router
+	routersMu     sync.RWMutex
 }
 
 // NewRouterFactory creates a new RouterFactory.
@@ -77,16 +110,24 @@ func NewRouterFactory(staticConfiguration static.Configuration, managerFactory *
 		return nil, fmt.Errorf("creating parser: %w", err)
 	}
 
+	var zone string
+	if staticConfiguration.Global != nil {
+		zone = staticConfiguration.Global.Zone
+	}
+
 	return &RouterFactory{
-		entryPointsTCP:   entryPointsTCP,
-		entryPointsUDP:   entryPointsUDP,
-		managerFactory:   managerFactory,
-		observabilityMgr: observabilityMgr,
-		tlsManager:       tlsManager,
-		pluginBuilder:    pluginBuilder,
-		dialerManager:    dialerManager,
-		allowACMEByPass:  allowACMEByPass,
-		parser:           parser,
+		entryPointsTCP:      entryPointsTCP,
+		entryPointsUDP:      entryPointsUDP,
+		managerFactory:      managerFactory,
+		observabilityMgr:    observabilityMgr,
+		tlsManager:          tlsManager,
+		pluginBuilder:       pluginBuilder,
+		dialerManager:       dialerManager,
+		dnsDiscovery:        discovery.NewDNSResolver(staticConfiguration.HostResolver),
+		concurrencyLimiters: adaptiveconcurrency.NewLimiters(),
+		zone:                zone,
+		allowACMEByPass:     allowACMEByPass,
+		parser:              parser,
 	}, nil
 }
 
@@ -104,7 +145,12 @@ func (f *RouterFactory) CreateRouters(rtConf *runtime.Configuration) (map[string
 
 	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, f.pluginBuilder)
 
+	middlewaresBuilder.SetMetricsRegistry(f.observabilityMgr.MetricsRegistry())
+	middlewaresBuilder.SetConcurrencyLimiters(f.concurrencyLimiters)
+
 	serviceManager.SetMiddlewareChainBuilder(middlewaresBuilder)
+	serviceManager.SetDNSDiscovery(f.dnsDiscovery)
+	serviceManager.SetZone(f.zone)
 
 	routerManager := router.NewManager(rtConf, serviceManager, middlewaresBuilder, f.observabilityMgr, f.tlsManager, f.parser)
 
@@ -113,10 +159,14 @@ func (f *RouterFactory) CreateRouters(rtConf *runtime.Configuration) (map[string
 	handlersNonTLS := routerManager.BuildHandlers(ctx, f.entryPointsTCP, false)
 	handlersTLS := routerManager.BuildHandlers(ctx, f.entryPointsTCP, true)
 
+	// The limiters of the groups which are no longer used by the middlewares are dropped.
+	f.concurrencyLimiters.Prune()
+
 	serviceManager.LaunchHealthCheck(ctx)
 
 	// TCP
 	svcTCPManager := tcpsvc.NewManager(rtConf, f.dialerManager)
+	svcTCPManager.SetDNSDiscovery(f.dnsDiscovery)
 
 	middlewaresTCPBuilder := tcpmiddleware.NewBuilder(rtConf.TCPMiddlewares)
 
@@ -133,10 +183,228 @@ func (f *RouterFactory) CreateRouters(rtConf *runtime.Configuration) (map[string
 
 	// UDP
 	svcUDPManager := udpsvc.NewManager(rtConf)
+	svcUDPManager.SetDNSDiscovery(f.dnsDiscovery)
 	rtUDPManager := udprouter.NewManager(rtConf, svcUDPManager)
 	routersUDP := rtUDPManager.BuildHandlers(ctx, f.entryPointsUDP)
 
 	rtConf.PopulateUsedBy()
 
+	f.routersMu.Lock()
+	f.ctx = ctx
+	f.rtConf = rtConf
+	f.serviceManager = serviceManager
+	f.svcTCPManager = svcTCPManager
+	f.svcUDPManager = svcUDPManager
+	f.routerManager = routerManager
+	f.routersTCP = routersTCP
+	f.routersMu.Unlock()
+
 	return routersTCP, routersUDP
 }
+
+// ExplainRoute implements api.RouteExplainer,
+// by evaluating the request against the muxers of the last routers created.
+func (f *RouterFactory) ExplainRoute(entryPointName string, req *http.Request) ([]api.SimulatedRouter, []api.SimulatedRouter, error) {
+	f.routersMu.RLock()
+	routerManager, routersTCP, rtConf := f.routerManager, f.routersTCP, f.rtConf
+	f.routersMu.RUnlock()
+
+	if routerManager == nil {
+		return nil, nil, nil
+	}
+
+	levels, err := routerManager.Explain(entryPointName, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var matchesTCP []tcpmuxer.RouteMatch
+	forwardedToHTTP := true
+	if rt, ok := routersTCP[entryPointName]; ok {
+		var serverName string
+		if req.TLS != nil {
+			serverName = req.TLS.ServerName
+		}
+
+		clientIP, _, _ := net.SplitHostPort(req.RemoteAddr)
+
+		matchesTCP, forwardedToHTTP = rt.Explain(tcpmuxer.NewConnDataFromIP(serverName, clientIP, nil), req.TLS != nil)
+	}
+
+	var routers, runnerUps []api.SimulatedRouter
+	for i, match := range matchesTCP {
+		router := api.SimulatedRouter{Name: match.Name, Protocol: api.ProtocolTCP, Priority: match.Priority}
+		if info, ok := rtConf.TCPRouters[match.Name]; ok {
+			router.Rule = info.Rule
+		}
+
+		if !forwardedToHTTP && i == 0 {
+			routers = append(routers, router)
+			continue
+		}
+		runnerUps = append(runnerUps, router)
+	}
+
+	// When the connection is not forwarded to the HTTP handler, the child routers are never evaluated.
+	if !forwardedToHTTP {
+		levels = levels[:min(len(levels), 1)]
+	}
+
+	for _, level := range levels {
+		for i, match := range level {
+			router := api.SimulatedRouter{Name: match.Name, Protocol: api.ProtocolHTTP, Priority: match.Priority}
+			if info, ok := rtConf.Routers[match.Name]; ok {
+				router.Rule = info.Rule
+			}
+
+			if forwardedToHTTP && i == 0 {
+				routers = append(routers, router)
+				continue
+			}
+			runnerUps = append(runnerUps, router)
+		}
+	}
+
+	return routers, runnerUps, nil
+}
+
+// UpdateServers applies the given configuration in place, when it only differs from the last one by the servers of load-balancers,
+// by updating the servers of these load-balancers, without rebuilding the routers, the middlewares and the other services.
+// It reports whether the configuration has been applied, otherwise it has to be applied with CreateRouters.
+// It must be called with every configuration before CreateRouters, as the last one is the reference of the next update.
+func (f *RouterFactory) UpdateServers(conf dynamic.Configuration) bool {
+	previous := f.lastConf
+	f.lastConf = conf.DeepCopy()
+
+	if previous == nil || f.rtConf == nil {
+		return false
+	}
+
+	changes, ok := diffServers(previous, conf.DeepCopy())
+	if !ok || !f.canUpdateServers(changes) {
+		return false
+	}
+
+	for serviceName, servers := range changes.http {
+		if err := f.serviceManager.UpdateServers(f.ctx, serviceName, servers); err != nil {
+			log.Debug().Err(err).Str(logs.ServiceName, serviceName).Msg("Unable to update the servers in place")
+			return false
+		}
+
+		f.rtConf.UpdateServers(serviceName, servers)
+	}
+
+	for serviceName, servers := range changes.tcp {
+		if err := f.svcTCPManager.UpdateServers(f.ctx, serviceName, servers); err != nil {
+			log.Debug().Err(err).Str(logs.ServiceName, serviceName).Msg("Unable to update the servers in place")
+			return false
+		}
+
+		f.rtConf.UpdateTCPServers(serviceName, servers)
+	}
+
+	for serviceName, servers := range changes.udp {
+		f.svcUDPManager.UpdateServers(f.ctx, serviceName, servers)
+
+		f.rtConf.UpdateUDPServers(serviceName, servers)
+	}
+
+	log.Debug().Int("services", len(changes.http)+len(changes.tcp)+len(changes.udp)).
+		Msg("Servers updated without rebuilding the routers")
+
+	return true
+}
+
+// canUpdateServers reports whether the servers of the changed services can be updated in place.
+// The services in error are rebuilt, as their new servers might fix them, and the routers using them.
+func (f *RouterFactory) canUpdateServers(changes serverChanges) bool {
+	for serviceName := range changes.http {
+		if info, ok := f.rtConf.Services[serviceName]; !ok || info.Status != runtime.StatusEnabled {
+			return false
+		}
+	}
+
+	for serviceName := range changes.tcp {
+		if info, ok := f.rtConf.TCPServices[serviceName]; !ok || info.Status != runtime.StatusEnabled {
+			return false
+		}
+	}
+
+	for serviceName := range changes.udp {
+		if info, ok := f.rtConf.UDPServices[serviceName]; !ok || info.Status != runtime.StatusEnabled {
+			return false
+		}
+	}
+
+	return true
+}
+
+// serverChanges are the new servers of the load-balancers which changed, by service qualified name.
+type serverChanges struct {
+	http map[string][]dynamic.Server
+	tcp  map[string][]dynamic.TCPServer
+	udp  map[string][]dynamic.UDPServer
+}
+
+// diffServers returns the servers of the load-balancers which changed between the two configurations,
+// and reports whether the configurations only differ by these servers.
+// The servers of the load-balancers whose servers are discovered are not taken into account.
+// The given configurations are modified.
+func diffServers(previous, conf *dynamic.Configuration) (serverChanges, bool) {
+	var changes serverChanges
+
+	if previous.HTTP != nil && conf.HTTP != nil {
+		changes.http = detachServers(previous.HTTP.Services, conf.HTTP.Services, func(svc *dynamic.Service) *[]dynamic.Server {
+			if svc.LoadBalancer == nil || svc.LoadBalancer.DNS != nil {
+				return nil
+			}
+			return &svc.LoadBalancer.Servers
+		})
+	}
+
+	if previous.TCP != nil && conf.TCP != nil {
+		changes.tcp = detachServers(previous.TCP.Services, conf.TCP.Services, func(svc *dynamic.TCPService) *[]dynamic.TCPServer {
+			if svc.LoadBalancer == nil || svc.LoadBalancer.DNS != nil {
+				return nil
+			}
+			return &svc.LoadBalancer.Servers
+		})
+	}
+
+	if previous.UDP != nil && conf.UDP != nil {
+		changes.udp = detachServers(previous.UDP.Services, conf.UDP.Services, func(svc *dynamic.UDPService) *[]dynamic.UDPServer {
+			if svc.LoadBalancer == nil || svc.LoadBalancer.DNS != nil {
+				return nil
+			}
+			return &svc.LoadBalancer.Servers
+		})
+	}
+
+	return changes, reflect.DeepEqual(previous, conf)
+}
+
+// detachServers removes the servers of the load-balancers of the services present in both configurations,
+// and returns the new servers of those which changed.
+func detachServers[S, V any](previous, services map[string]*S, servers func(*S) *[]V) map[string][]V {
+	changes := make(map[string][]V)
+
+	for serviceName, svc := range services {
+		previousSvc, ok := previous[serviceName]
+		if !ok || svc == nil || previousSvc == nil {
+			continue
+		}
+
+		newServers, previousServers := servers(svc), servers(previousSvc)
+		if newServers == nil || previousServers == nil {
+			continue
+		}
+
+		if !reflect.DeepEqual(*previousServers, *newServers) {
+			changes[serviceName] = *newServers
+		}
+
+		*previousServers, *newServers = nil, nil
+	}
+
+	return changes
+}
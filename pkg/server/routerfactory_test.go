@@ -9,9 +9,11 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/hanzoai/ingress/pkg/api"
 	"github.com/hanzoai/ingress/pkg/config/dynamic"
 	"github.com/hanzoai/ingress/pkg/config/runtime"
 	"github.com/hanzoai/ingress/pkg/config/static"
+	"github.com/hanzoai/ingress/pkg/middlewares/requestdecorator"
 	"github.com/hanzoai/ingress/pkg/server/middleware"
 	"github.com/hanzoai/ingress/pkg/server/service"
 	"github.com/hanzoai/ingress/pkg/tcp"
@@ -185,7 +187,7 @@ func TestServerResponseEmptyBackend(t *testing.T) {
 
 			dialerManager := tcp.NewDialerManager(nil)
 			dialerManager.Update(map[string]*dynamic.TCPServersTransport{"default@internal": {}})
-			observabiltyMgr := middleware.NewObservabilityMgr(staticConfig, nil, nil, nil, nil, nil)
+			observabiltyMgr := middleware.NewObservabilityMgr(staticConfig, nil, nil, nil, nil, nil, nil)
 			factory, err := NewRouterFactory(staticConfig, managerFactory, tlsManager, observabiltyMgr, nil, dialerManager)
 			require.NoError(t, err)
 
@@ -299,6 +301,374 @@ func TestRecursionService(t *testing.T) {
 	assert.Contains(t, rtConf.Services["bar@provider1"].Err, "could not instantiate service bar@provider1: recursion detected in service:bar@provider1->service:foo@provider1->service:bar@provider1")
 }
 
+func TestRouterFactory_UpdateServers(t *testing.T) {
+	staticConfig := static.Configuration{
+		EntryPoints: map[string]*static.EntryPoint{
+			"web": {},
+		},
+	}
+
+	newConfiguration := func(rule string, servers ...string) dynamic.Configuration {
+		var serverOpts []func(*dynamic.Server)
+		for _, server := range servers {
+			serverOpts = append(serverOpts, th.WithServer(server))
+		}
+
+		return dynamic.Configuration{HTTP: th.BuildConfiguration(
+			th.WithRouters(th.WithRouter("foo@file",
+				th.WithEntryPoints("web"),
+				th.WithServiceName("bar"),
+				th.WithRule(rule)),
+			),
+			th.WithServices(
+				th.WithService("bar@file", th.WithServiceServersLoadBalancer(th.WithServers(serverOpts...))),
+			),
+		)}
+	}
+
+	transportManager := service.NewTransportManager(nil)
+	transportManager.Update(map[string]*dynamic.ServersTransport{"default@internal": {}})
+
+	managerFactory := service.NewManagerFactory(staticConfig, nil, nil, transportManager, targetProxyBuilder{}, nil)
+	tlsManager := tls.NewManager(nil)
+
+	dialerManager := tcp.NewDialerManager(nil)
+	dialerManager.Update(map[string]*dynamic.TCPServersTransport{"default@internal": {}})
+	factory, err := NewRouterFactory(staticConfig, managerFactory, tlsManager, nil, nil, dialerManager)
+	require.NoError(t, err)
+
+	conf := newConfiguration("Path(`/ok`)", "http://10.0.0.1")
+	require.False(t, factory.UpdateServers(conf))
+
+	rtConf := runtime.NewConfig(conf)
+	entryPointsHandlers, _ := factory.CreateRouters(rtConf)
+	handler := entryPointsHandlers["web"].GetHTTPHandler()
+
+	serve := func() string {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost/ok", nil))
+		return recorder.Body.String()
+	}
+
+	assert.Equal(t, "10.0.0.1", serve())
+
+	// The same configuration is applied in place.
+	assert.True(t, factory.UpdateServers(newConfiguration("Path(`/ok`)", "http://10.0.0.1")))
+
+	// The servers are replaced in the routers already built.
+	require.True(t, factory.UpdateServers(newConfiguration("Path(`/ok`)", "http://10.0.0.2")))
+
+	assert.Equal(t, "10.0.0.2", serve())
+	assert.Equal(t, []dynamic.Server{{URL: "http://10.0.0.2"}}, rtConf.Services["bar@file"].LoadBalancer.Servers)
+	assert.Equal(t, map[string]string{"http://10.0.0.2": runtime.StatusUp}, rtConf.Services["bar@file"].GetAllStatus())
+
+	// The configuration is not applied in place when the routers changed.
+	assert.False(t, factory.UpdateServers(newConfiguration("Path(`/other`)", "http://10.0.0.2")))
+}
+
+func TestRouterFactory_ExplainRoute(t *testing.T) {
+	staticConfig := static.Configuration{
+		EntryPoints: map[string]*static.EntryPoint{
+			"web":       {},
+			"websecure": {},
+		},
+	}
+
+	conf := dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers: map[string]*dynamic.Router{
+				"api@file": {
+					EntryPoints: []string{"web"},
+					Rule:        "Host(`example.com`) && PathPrefix(`/api`)",
+					Service:     "svc@file",
+				},
+				"site@file": {
+					EntryPoints: []string{"web"},
+					Rule:        "Host(`example.com`)",
+					Service:     "svc@file",
+				},
+				"catchall@file": {
+					EntryPoints: []string{"web"},
+					Rule:        "PathPrefix(`/`)",
+					Priority:    1,
+					Service:     "svc@file",
+				},
+				"parent@file": {
+					EntryPoints: []string{"web"},
+					Rule:        "Host(`multi.example.com`)",
+				},
+				"child-a@file": {
+					ParentRefs: []string{"parent@file"},
+					Rule:       "Header(`X-Version`, `2`)",
+					Service:    "svc@file",
+				},
+				"child-b@file": {
+					ParentRefs: []string{"parent@file"},
+					Rule:       "PathPrefix(`/`)",
+					Service:    "svc@file",
+				},
+				"secure@file": {
+					EntryPoints: []string{"websecure"},
+					Rule:        "Host(`example.com`)",
+					Service:     "svc@file",
+					TLS:         &dynamic.RouterTLSConfig{},
+				},
+			},
+			Services: map[string]*dynamic.Service{
+				"svc@file": {
+					LoadBalancer: &dynamic.ServersLoadBalancer{Servers: []dynamic.Server{{URL: "http://10.0.0.1"}}},
+				},
+			},
+		},
+		TCP: &dynamic.TCPConfiguration{
+			Routers: map[string]*dynamic.TCPRouter{
+				"db@file": {
+					EntryPoints: []string{"websecure"},
+					Rule:        "HostSNI(`db.example.com`)",
+					Service:     "tcp-svc@file",
+					TLS:         &dynamic.RouterTCPTLSConfig{Passthrough: true},
+				},
+				"tcp-catchall@file": {
+					EntryPoints: []string{"websecure"},
+					Rule:        "HostSNI(`*`)",
+					Service:     "tcp-svc@file",
+					TLS:         &dynamic.RouterTCPTLSConfig{},
+				},
+			},
+			Services: map[string]*dynamic.TCPService{
+				"tcp-svc@file": {
+					LoadBalancer: &dynamic.TCPServersLoadBalancer{Servers: []dynamic.TCPServer{{Address: "10.0.0.1:5432"}}},
+				},
+			},
+		},
+	}
+
+	transportManager := service.NewTransportManager(nil)
+	transportManager.Update(map[string]*dynamic.ServersTransport{"default@internal": {}})
+
+	managerFactory := service.NewManagerFactory(staticConfig, nil, nil, transportManager, proxyBuilderMock{}, nil)
+
+	tlsManager := tls.NewManager(nil)
+	tlsManager.UpdateConfigs(t.Context(), nil, map[string]tls.Options{"default": {}}, nil)
+
+	dialerManager := tcp.NewDialerManager(nil)
+	dialerManager.Update(map[string]*dynamic.TCPServersTransport{"default@internal": {}})
+	factory, err := NewRouterFactory(staticConfig, managerFactory, tlsManager, nil, nil, dialerManager)
+	require.NoError(t, err)
+
+	routers, _ := factory.CreateRouters(runtime.NewConfig(conf))
+
+	// The HTTPS routes are added when the routers are switched.
+	for _, router := range routers {
+		router.SetHTTPSForwarder(tcp.HandlerFunc(func(tcp.WriteCloser) {}))
+	}
+
+	testCases := []struct {
+		desc              string
+		entryPoint        string
+		target            string
+		serverName        string
+		headers           map[string]string
+		expectedRouters   []api.SimulatedRouter
+		expectedRunnerUps []api.SimulatedRouter
+	}{
+		{
+			desc:       "HTTP router",
+			entryPoint: "web",
+			target:     "http://example.com/api/users",
+			expectedRouters: []api.SimulatedRouter{
+				{Name: "api@file", Protocol: api.ProtocolHTTP, Rule: "Host(`example.com`) && PathPrefix(`/api`)", Priority: 41},
+			},
+			expectedRunnerUps: []api.SimulatedRouter{
+				{Name: "site@file", Protocol: api.ProtocolHTTP, Rule: "Host(`example.com`)", Priority: 19},
+				{Name: "catchall@file", Protocol: api.ProtocolHTTP, Rule: "PathPrefix(`/`)", Priority: 1},
+			},
+		},
+		{
+			desc:       "child routers",
+			entryPoint: "web",
+			target:     "http://multi.example.com/",
+			headers:    map[string]string{"X-Version": "2"},
+			expectedRouters: []api.SimulatedRouter{
+				{Name: "parent@file", Protocol: api.ProtocolHTTP, Rule: "Host(`multi.example.com`)", Priority: 25},
+				{Name: "child-a@file", Protocol: api.ProtocolHTTP, Rule: "Header(`X-Version`, `2`)", Priority: 24},
+			},
+			expectedRunnerUps: []api.SimulatedRouter{
+				{Name: "catchall@file", Protocol: api.ProtocolHTTP, Rule: "PathPrefix(`/`)", Priority: 1},
+				{Name: "child-b@file", Protocol: api.ProtocolHTTP, Rule: "PathPrefix(`/`)", Priority: 15},
+			},
+		},
+		{
+			desc:       "TCP router matching the SNI",
+			entryPoint: "websecure",
+			target:     "https://db.example.com/",
+			serverName: "db.example.com",
+			expectedRouters: []api.SimulatedRouter{
+				{Name: "db@file", Protocol: api.ProtocolTCP, Rule: "HostSNI(`db.example.com`)", Priority: 25},
+			},
+			expectedRunnerUps: []api.SimulatedRouter{
+				{Name: "tcp-catchall@file", Protocol: api.ProtocolTCP, Rule: "HostSNI(`*`)", Priority: -1},
+			},
+		},
+		{
+			desc:       "HTTPS router taking precedence over the TCP catch-all router",
+			entryPoint: "websecure",
+			target:     "https://example.com/",
+			serverName: "example.com",
+			expectedRouters: []api.SimulatedRouter{
+				{Name: "secure@file", Protocol: api.ProtocolHTTP, Rule: "Host(`example.com`)", Priority: 19},
+			},
+			expectedRunnerUps: []api.SimulatedRouter{
+				{Name: "tcp-catchall@file", Protocol: api.ProtocolTCP, Rule: "HostSNI(`*`)", Priority: -1},
+			},
+		},
+		{
+			desc:       "TCP catch-all router",
+			entryPoint: "websecure",
+			target:     "https://other.example.com/",
+			serverName: "other.example.com",
+			expectedRouters: []api.SimulatedRouter{
+				{Name: "tcp-catchall@file", Protocol: api.ProtocolTCP, Rule: "HostSNI(`*`)", Priority: -1},
+			},
+		},
+		{
+			desc:       "TLS connection without SNI",
+			entryPoint: "websecure",
+			target:     "https://example.com/",
+			expectedRouters: []api.SimulatedRouter{
+				{Name: "tcp-catchall@file", Protocol: api.ProtocolTCP, Rule: "HostSNI(`*`)", Priority: -1},
+			},
+			expectedRunnerUps: []api.SimulatedRouter{
+				{Name: "secure@file", Protocol: api.ProtocolHTTP, Rule: "Host(`example.com`)", Priority: 19},
+			},
+		},
+		{
+			desc:       "unknown entry point",
+			entryPoint: "unknown",
+			target:     "http://example.com/",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, test.target, http.NoBody)
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+
+			if req.TLS != nil {
+				req.TLS.ServerName = test.serverName
+			}
+
+			// The Host matchers rely on the canonical host stored in the request context.
+			requestdecorator.New(nil).ServeHTTP(nil, req, func(_ http.ResponseWriter, decorated *http.Request) {
+				req = decorated
+			})
+
+			explainedRouters, explainedRunnerUps, err := factory.ExplainRoute(test.entryPoint, req)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedRouters, explainedRouters)
+			assert.Equal(t, test.expectedRunnerUps, explainedRunnerUps)
+		})
+	}
+}
+
+func Test_diffServers(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		previous        dynamic.Configuration
+		conf            dynamic.Configuration
+		expected        serverChanges
+		expectedOnlyLBs bool
+	}{
+		{
+			desc: "HTTP servers changed",
+			previous: dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{Services: map[string]*dynamic.Service{
+				"foo@file": {LoadBalancer: &dynamic.ServersLoadBalancer{Servers: []dynamic.Server{{URL: "http://10.0.0.1"}}}},
+				"bar@file": {LoadBalancer: &dynamic.ServersLoadBalancer{Servers: []dynamic.Server{{URL: "http://10.0.0.3"}}}},
+			}}},
+			conf: dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{Services: map[string]*dynamic.Service{
+				"foo@file": {LoadBalancer: &dynamic.ServersLoadBalancer{Servers: []dynamic.Server{{URL: "http://10.0.0.1"}, {URL: "http://10.0.0.2"}}}},
+				"bar@file": {LoadBalancer: &dynamic.ServersLoadBalancer{Servers: []dynamic.Server{{URL: "http://10.0.0.3"}}}},
+			}}},
+			expected: serverChanges{
+				http: map[string][]dynamic.Server{"foo@file": {{URL: "http://10.0.0.1"}, {URL: "http://10.0.0.2"}}},
+			},
+			expectedOnlyLBs: true,
+		},
+		{
+			desc: "TCP and UDP servers changed",
+			previous: dynamic.Configuration{
+				TCP: &dynamic.TCPConfiguration{Services: map[string]*dynamic.TCPService{
+					"foo@file": {LoadBalancer: &dynamic.TCPServersLoadBalancer{Servers: []dynamic.TCPServer{{Address: "10.0.0.1:80"}}}},
+				}},
+				UDP: &dynamic.UDPConfiguration{Services: map[string]*dynamic.UDPService{
+					"foo@file": {LoadBalancer: &dynamic.UDPServersLoadBalancer{Servers: []dynamic.UDPServer{{Address: "10.0.0.1:53"}}}},
+				}},
+			},
+			conf: dynamic.Configuration{
+				TCP: &dynamic.TCPConfiguration{Services: map[string]*dynamic.TCPService{
+					"foo@file": {LoadBalancer: &dynamic.TCPServersLoadBalancer{Servers: []dynamic.TCPServer{{Address: "10.0.0.2:80"}}}},
+				}},
+				UDP: &dynamic.UDPConfiguration{Services: map[string]*dynamic.UDPService{
+					"foo@file": {LoadBalancer: &dynamic.UDPServersLoadBalancer{Servers: []dynamic.UDPServer{{Address: "10.0.0.2:53"}}}},
+				}},
+			},
+			expected: serverChanges{
+				tcp: map[string][]dynamic.TCPServer{"foo@file": {{Address: "10.0.0.2:80"}}},
+				udp: map[string][]dynamic.UDPServer{"foo@file": {{Address: "10.0.0.2:53"}}},
+			},
+			expectedOnlyLBs: true,
+		},
+		{
+			desc: "load-balancer option changed",
+			previous: dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{Services: map[string]*dynamic.Service{
+				"foo@file": {LoadBalancer: &dynamic.ServersLoadBalancer{Strategy: dynamic.BalancerStrategyWRR, Servers: []dynamic.Server{{URL: "http://10.0.0.1"}}}},
+			}}},
+			conf: dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{Services: map[string]*dynamic.Service{
+				"foo@file": {LoadBalancer: &dynamic.ServersLoadBalancer{Strategy: dynamic.BalancerStrategyP2C, Servers: []dynamic.Server{{URL: "http://10.0.0.2"}}}},
+			}}},
+			expected: serverChanges{
+				http: map[string][]dynamic.Server{"foo@file": {{URL: "http://10.0.0.2"}}},
+			},
+		},
+		{
+			desc: "new service",
+			previous: dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{Services: map[string]*dynamic.Service{
+				"foo@file": {LoadBalancer: &dynamic.ServersLoadBalancer{Servers: []dynamic.Server{{URL: "http://10.0.0.1"}}}},
+			}}},
+			conf: dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{Services: map[string]*dynamic.Service{
+				"foo@file": {LoadBalancer: &dynamic.ServersLoadBalancer{Servers: []dynamic.Server{{URL: "http://10.0.0.1"}}}},
+				"bar@file": {LoadBalancer: &dynamic.ServersLoadBalancer{Servers: []dynamic.Server{{URL: "http://10.0.0.2"}}}},
+			}}},
+			expected: serverChanges{http: map[string][]dynamic.Server{}},
+		},
+		{
+			desc: "servers of a load-balancer discovered through DNS changed",
+			previous: dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{Services: map[string]*dynamic.Service{
+				"foo@file": {LoadBalancer: &dynamic.ServersLoadBalancer{DNS: &dynamic.DNSDiscovery{Name: "foo.example.com"}}},
+			}}},
+			conf: dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{Services: map[string]*dynamic.Service{
+				"foo@file": {LoadBalancer: &dynamic.ServersLoadBalancer{DNS: &dynamic.DNSDiscovery{Name: "foo.example.com"}, Servers: []dynamic.Server{{URL: "http://10.0.0.1"}}}},
+			}}},
+			expected: serverChanges{http: map[string][]dynamic.Server{}},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			changes, onlyLBs := diffServers(&test.previous, &test.conf)
+			assert.Equal(t, test.expected, changes)
+			assert.Equal(t, test.expectedOnlyLBs, onlyLBs)
+		})
+	}
+}
+
 type proxyBuilderMock struct{}
 
 func (p proxyBuilderMock) Build(_ string, _ *url.URL, _, _ bool, _ time.Duration) (http.Handler, error) {
@@ -308,3 +678,14 @@ func (p proxyBuilderMock) Build(_ string, _ *url.URL, _, _ bool, _ time.Duration
 func (p proxyBuilderMock) Update(_ map[string]*dynamic.ServersTransport) {
 	panic("implement me")
 }
+
+// targetProxyBuilder builds proxies responding with the host of their target.
+type targetProxyBuilder struct{}
+
+func (p targetProxyBuilder) Build(_ string, targetURL *url.URL, _, _ bool, _ time.Duration) (http.Handler, error) {
+	return http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		_, _ = rw.Write([]byte(targetURL.Host))
+	}), nil
+}
+
+func (p targetProxyBuilder) Update(_ map[string]*dynamic.ServersTransport) {}
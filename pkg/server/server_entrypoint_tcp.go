@@ -27,6 +27,7 @@ import (
 	"github.com/hanzoai/ingress/pkg/middlewares/contenttype"
 	"github.com/hanzoai/ingress/pkg/middlewares/forwardedheaders"
 	"github.com/hanzoai/ingress/pkg/middlewares/requestdecorator"
+	"github.com/hanzoai/ingress/pkg/middlewares/requestid"
 	"github.com/hanzoai/ingress/pkg/observability/logs"
 	"github.com/hanzoai/ingress/pkg/observability/metrics"
 	"github.com/hanzoai/ingress/pkg/safe"
@@ -636,6 +637,18 @@ func newHTTPServer(ctx context.Context, ln net.Listener, configuration *static.E
 		return nil, err
 	}
 
+	if configuration.HTTP.RequestID != nil {
+		next, err = requestid.New(
+			configuration.HTTP.RequestID.HeaderName,
+			configuration.HTTP.RequestID.Generator,
+			configuration.ForwardedHeaders.Insecure,
+			configuration.ForwardedHeaders.TrustedIPs,
+			next)
+		if err != nil {
+			return nil, fmt.Errorf("creating request ID handler: %w", err)
+		}
+	}
+
 	var handler http.Handler
 	handler, err = forwardedheaders.NewXForwarded(
 		configuration.ForwardedHeaders.Insecure,
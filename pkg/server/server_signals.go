@@ -6,12 +6,17 @@ import (
 	"context"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/hanzoai/ingress/pkg/observability/logs"
 )
 
+// signalLogLevelDuration is the duration after which the log level raised by a SIGUSR2 signal is reverted.
+const signalLogLevelDuration = 10 * time.Minute
+
 func (s *Server) configureSignals() {
-	signal.Notify(s.signals, syscall.SIGUSR1)
+	signal.Notify(s.signals, syscall.SIGUSR1, syscall.SIGUSR2)
 }
 
 func (s *Server) listenSignals(ctx context.Context) {
@@ -27,6 +32,31 @@ func (s *Server) listenSignals(ctx context.Context) {
 					log.Error().Err(err).Msg("Error rotating access log")
 				}
 			}
+
+			if sig == syscall.SIGUSR2 {
+				toggleDebugLogLevel(s.observabilityMgr.LogLevels())
+			}
 		}
 	}
 }
+
+// toggleDebugLogLevel sets the global log level to DEBUG for signalLogLevelDuration,
+// or restores the log level defined at startup when the log levels have already been changed at runtime.
+func toggleDebugLogLevel(logLevels *logs.LevelController) {
+	if logLevels == nil {
+		return
+	}
+
+	if logLevels.Modified() {
+		logLevels.Reset()
+		log.Info().Msg("Log levels restored to the static configuration")
+		return
+	}
+
+	if err := logLevels.SetLevels(logs.Levels{Level: "debug"}, signalLogLevelDuration); err != nil {
+		log.Error().Err(err).Msg("Error setting the log level")
+		return
+	}
+
+	log.Info().Msgf("Log level set to DEBUG for %s", signalLogLevelDuration)
+}
@@ -0,0 +1,313 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/rs/zerolog/log"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/safe"
+	"github.com/hanzoai/ingress/pkg/types"
+)
+
+// Types of the DNS records from which the servers are discovered.
+const (
+	RecordTypeSRV  = "SRV"
+	RecordTypeA    = "A"
+	RecordTypeAAAA = "AAAA"
+)
+
+const (
+	defaultResolvConfig = "/etc/resolv.conf"
+	lookupTimeout       = 5 * time.Second
+)
+
+// Target is a server discovered through DNS.
+type Target struct {
+	Host string
+	Port int
+	// Weight is the weight of the SRV record, or 0 for A and AAAA records.
+	Weight int
+}
+
+// Address returns the host:port address of the target.
+func (t Target) Address() string {
+	return net.JoinHostPort(t.Host, strconv.Itoa(t.Port))
+}
+
+// Watcher watches the servers of a load-balancer discovered through DNS.
+type Watcher interface {
+	// Watch discovers the servers described by the given configuration, and calls update with them.
+	// update is called once before Watch returns, and then each time the discovered servers change, until the context is done.
+	Watch(ctx context.Context, config dynamic.DNSDiscovery, update func(targets []Target))
+}
+
+type answer struct {
+	targets []Target
+	expires time.Time
+}
+
+// DNSResolver is a Watcher resolving the DNS records with the resolver configuration of the host.
+// The answers are cached for their TTL, and shared by the load-balancers discovering the same records,
+// so that they survive the reloads of the configuration.
+type DNSResolver struct {
+	client       *dns.Client
+	tcpClient    *dns.Client
+	clientConfig func() (*dns.ClientConfig, error)
+
+	cacheMu sync.Mutex
+	cache   map[string]answer
+}
+
+// NewDNSResolver creates a new DNSResolver.
+func NewDNSResolver(config *types.HostResolverConfig) *DNSResolver {
+	resolvConfig := defaultResolvConfig
+	if config != nil && config.ResolvConfig != "" {
+		resolvConfig = config.ResolvConfig
+	}
+
+	return &DNSResolver{
+		client:    &dns.Client{Timeout: lookupTimeout},
+		tcpClient: &dns.Client{Net: "tcp", Timeout: lookupTimeout},
+		clientConfig: func() (*dns.ClientConfig, error) {
+			clientConfig, err := dns.ClientConfigFromFile(resolvConfig)
+			if err != nil {
+				return nil, fmt.Errorf("invalid resolver configuration file %s: %w", resolvConfig, err)
+			}
+			return clientConfig, nil
+		},
+		cache: make(map[string]answer),
+	}
+}
+
+// Watch discovers the servers described by the given configuration, and calls update with them.
+// update is called once before Watch returns, with no targets if the first resolution failed,
+// and then each time the discovered servers change, until the context is done.
+// The records are resolved again when their TTL expires, but not before the refresh interval.
+func (r *DNSResolver) Watch(ctx context.Context, config dynamic.DNSDiscovery, update func(targets []Target)) {
+	logger := log.Ctx(ctx).With().Str("dnsName", config.Name).Logger()
+
+	refreshInterval := time.Duration(config.RefreshInterval)
+	if refreshInterval <= 0 {
+		refreshInterval = time.Duration(dynamic.DefaultDNSRefreshInterval)
+	}
+
+	targets, ttl, err := r.Resolve(ctx, config)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to discover servers through DNS")
+	}
+
+	update(targets)
+
+	safe.Go(func() {
+		timer := time.NewTimer(max(ttl, refreshInterval))
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+			}
+
+			newTargets, ttl, err := r.Resolve(ctx, config)
+			if err != nil {
+				// The previously discovered servers are kept until the records can be resolved again.
+				logger.Error().Err(err).Msg("Unable to discover servers through DNS")
+				timer.Reset(refreshInterval)
+				continue
+			}
+
+			if !slices.Equal(targets, newTargets) {
+				logger.Debug().Int("servers", len(newTargets)).Msg("Discovered servers changed")
+
+				targets = newTargets
+				update(targets)
+			}
+
+			timer.Reset(max(ttl, refreshInterval))
+		}
+	})
+}
+
+// Resolve returns the targets described by the given configuration, sorted by address,
+// and the duration for which they are valid.
+func (r *DNSResolver) Resolve(ctx context.Context, config dynamic.DNSDiscovery) ([]Target, time.Duration, error) {
+	recordType := strings.ToUpper(config.RecordType)
+	if recordType == "" {
+		recordType = RecordTypeSRV
+	}
+
+	if config.Name == "" {
+		return nil, 0, errors.New("non-empty name is required")
+	}
+
+	var qtype uint16
+	switch recordType {
+	case RecordTypeSRV:
+		qtype = dns.TypeSRV
+	case RecordTypeA:
+		qtype = dns.TypeA
+	case RecordTypeAAAA:
+		qtype = dns.TypeAAAA
+	default:
+		return nil, 0, fmt.Errorf("unsupported record type %q", config.RecordType)
+	}
+
+	if qtype != dns.TypeSRV && (config.Port <= 0 || config.Port > 65535) {
+		return nil, 0, fmt.Errorf("a valid port is required for %s records", recordType)
+	}
+
+	key := recordType + "/" + config.Name + "/" + strconv.Itoa(config.Port)
+
+	r.cacheMu.Lock()
+	cached, ok := r.cache[key]
+	r.cacheMu.Unlock()
+
+	if ok {
+		if ttl := time.Until(cached.expires); ttl > 0 {
+			return cached.targets, ttl, nil
+		}
+	}
+
+	records, err := r.lookup(ctx, config.Name, qtype)
+	if err != nil {
+		return nil, 0, fmt.Errorf("resolving %s records of %s: %w", recordType, config.Name, err)
+	}
+
+	targets, ttl := toTargets(records, qtype, config.Port)
+
+	r.cacheMu.Lock()
+	// The expired answers are evicted, for the records which are no longer discovered to be forgotten.
+	now := time.Now()
+	maps.DeleteFunc(r.cache, func(_ string, a answer) bool { return !now.Before(a.expires) })
+	r.cache[key] = answer{targets: targets, expires: now.Add(ttl)}
+	r.cacheMu.Unlock()
+
+	return targets, ttl, nil
+}
+
+// lookup queries the names built from the given one with the search list of the resolver configuration,
+// as the system resolver does, until one of them has records.
+// A name which does not exist has no records.
+func (r *DNSResolver) lookup(ctx context.Context, name string, qtype uint16) ([]dns.RR, error) {
+	clientConfig, err := r.clientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fqdn := range clientConfig.NameList(name) {
+		records, err := r.exchange(ctx, clientConfig, fqdn, qtype)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(records) > 0 {
+			return records, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// exchange queries the name servers of the resolver configuration in order, until one of them answers.
+// The query is sent again over TCP when the UDP answer is truncated.
+func (r *DNSResolver) exchange(ctx context.Context, clientConfig *dns.ClientConfig, fqdn string, qtype uint16) ([]dns.RR, error) {
+	msg := &dns.Msg{}
+	msg.SetQuestion(fqdn, qtype)
+	msg.SetEdns0(dns.DefaultMsgSize, false)
+
+	var errs []error
+	for _, server := range clientConfig.Servers {
+		address := net.JoinHostPort(server, clientConfig.Port)
+
+		resp, _, err := r.client.ExchangeContext(ctx, msg, address)
+		if err == nil && resp.Truncated {
+			resp, _, err = r.tcpClient.ExchangeContext(ctx, msg, address)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("exchange error for server %s: %w", server, err))
+			continue
+		}
+
+		switch resp.Rcode {
+		case dns.RcodeSuccess:
+			return resp.Answer, nil
+		case dns.RcodeNameError:
+			return nil, nil
+		default:
+			errs = append(errs, fmt.Errorf("server %s answered %s", server, dns.RcodeToString[resp.Rcode]))
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil, errors.New("no name server configured")
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// toTargets converts the records of the given type to targets, and returns them with the lowest TTL of the records.
+// Only the SRV records with the lowest priority are used, the others being backups.
+func toTargets(records []dns.RR, qtype uint16, port int) ([]Target, time.Duration) {
+	var targets []Target
+	var ttl uint32
+	minPriority := -1
+
+	for _, record := range records {
+		if record.Header().Rrtype != qtype {
+			// The CNAME records leading to the requested ones.
+			continue
+		}
+
+		var target Target
+		switch rr := record.(type) {
+		case *dns.SRV:
+			// A target of "." means that the service is not available at this domain.
+			if rr.Target == "." {
+				continue
+			}
+
+			priority := int(rr.Priority)
+			if minPriority >= 0 && priority > minPriority {
+				continue
+			}
+			if priority < minPriority {
+				targets = nil
+				ttl = 0
+			}
+			minPriority = priority
+
+			// A weight of 0 means that the target has a very small chance of being selected,
+			// which is approximated with the lowest weight of the load-balancers.
+			target = Target{Host: strings.TrimSuffix(rr.Target, "."), Port: int(rr.Port), Weight: max(int(rr.Weight), 1)}
+		case *dns.A:
+			target = Target{Host: rr.A.String(), Port: port}
+		case *dns.AAAA:
+			target = Target{Host: rr.AAAA.String(), Port: port}
+		default:
+			continue
+		}
+
+		if ttl == 0 || record.Header().Ttl < ttl {
+			ttl = record.Header().Ttl
+		}
+
+		targets = append(targets, target)
+	}
+
+	slices.SortFunc(targets, func(a, b Target) int {
+		return strings.Compare(a.Address(), b.Address())
+	})
+
+	return slices.Compact(targets), time.Duration(ttl) * time.Second
+}
@@ -0,0 +1,376 @@
+package discovery
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/hanzoai/ingress-parser/types"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+)
+
+func TestDNSResolver_Resolve(t *testing.T) {
+	server := newDNSServer(t, map[string][]string{
+		"_http._tcp.legacy.example.com.": {
+			"_http._tcp.legacy.example.com. 30 IN SRV 10 5 8080 vm1.example.com.",
+			"_http._tcp.legacy.example.com. 60 IN SRV 10 0 8081 vm2.example.com.",
+			"_http._tcp.legacy.example.com. 10 IN SRV 20 5 8080 backup.example.com.",
+		},
+		"_http._tcp.backup.example.com.": {
+			"_http._tcp.backup.example.com. 30 IN SRV 20 5 8080 backup.example.com.",
+			"_http._tcp.backup.example.com. 10 IN SRV 10 5 8080 vm1.example.com.",
+		},
+		"_http._tcp.disabled.example.com.": {
+			"_http._tcp.disabled.example.com. 30 IN SRV 0 0 0 .",
+		},
+		"legacy.example.com.": {
+			"legacy.example.com. 120 IN CNAME vms.example.com.",
+			"vms.example.com. 40 IN A 10.0.0.2",
+			"vms.example.com. 20 IN A 10.0.0.1",
+			"legacy.example.com. 20 IN AAAA 2001:db8::1",
+		},
+	})
+
+	resolver := newTestDNSResolver(server.addr)
+
+	testCases := []struct {
+		desc        string
+		config      dynamic.DNSDiscovery
+		expected    []Target
+		expectedTTL time.Duration
+		expectedErr string
+	}{
+		{
+			desc:   "SRV records",
+			config: dynamic.DNSDiscovery{Name: "_http._tcp.legacy.example.com", RecordType: "SRV"},
+			expected: []Target{
+				{Host: "vm1.example.com", Port: 8080, Weight: 5},
+				{Host: "vm2.example.com", Port: 8081, Weight: 1},
+			},
+			expectedTTL: 30 * time.Second,
+		},
+		{
+			desc:        "SRV records with a lower priority after a higher one",
+			config:      dynamic.DNSDiscovery{Name: "_http._tcp.backup.example.com"},
+			expected:    []Target{{Host: "vm1.example.com", Port: 8080, Weight: 5}},
+			expectedTTL: 10 * time.Second,
+		},
+		{
+			desc:   "unavailable service",
+			config: dynamic.DNSDiscovery{Name: "_http._tcp.disabled.example.com", RecordType: "SRV"},
+		},
+		{
+			desc:   "A records",
+			config: dynamic.DNSDiscovery{Name: "legacy.example.com", RecordType: "A", Port: 80},
+			expected: []Target{
+				{Host: "10.0.0.1", Port: 80},
+				{Host: "10.0.0.2", Port: 80},
+			},
+			expectedTTL: 20 * time.Second,
+		},
+		{
+			desc:        "AAAA records",
+			config:      dynamic.DNSDiscovery{Name: "legacy.example.com", RecordType: "aaaa", Port: 443},
+			expected:    []Target{{Host: "2001:db8::1", Port: 443}},
+			expectedTTL: 20 * time.Second,
+		},
+		{
+			desc:   "unknown name",
+			config: dynamic.DNSDiscovery{Name: "unknown.example.com", RecordType: "A", Port: 80},
+		},
+		{
+			desc:        "missing port",
+			config:      dynamic.DNSDiscovery{Name: "legacy.example.com", RecordType: "A"},
+			expectedErr: "a valid port is required for A records",
+		},
+		{
+			desc:        "missing name",
+			config:      dynamic.DNSDiscovery{RecordType: "SRV"},
+			expectedErr: "non-empty name is required",
+		},
+		{
+			desc:        "unsupported record type",
+			config:      dynamic.DNSDiscovery{Name: "legacy.example.com", RecordType: "TXT"},
+			expectedErr: `unsupported record type "TXT"`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			server.queries.Store(0)
+
+			targets, ttl, err := resolver.Resolve(t.Context(), test.config)
+			if test.expectedErr != "" {
+				require.EqualError(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, targets)
+			assert.Equal(t, test.expectedTTL, ttl)
+
+			if test.expectedTTL == 0 {
+				return
+			}
+
+			// The answer is cached for its TTL.
+			_, _, err = resolver.Resolve(t.Context(), test.config)
+			require.NoError(t, err)
+			assert.Equal(t, int32(1), server.queries.Load())
+		})
+	}
+}
+
+func TestDNSResolver_Resolve_eviction(t *testing.T) {
+	server := newDNSServer(t, map[string][]string{
+		"legacy.example.com.": {"legacy.example.com. 30 IN A 10.0.0.1"},
+		"other.example.com.":  {"other.example.com. 30 IN A 10.0.0.2"},
+	})
+
+	resolver := newTestDNSResolver(server.addr)
+
+	_, _, err := resolver.Resolve(t.Context(), dynamic.DNSDiscovery{Name: "legacy.example.com", RecordType: "A", Port: 80})
+	require.NoError(t, err)
+
+	// The answer of legacy.example.com expires.
+	resolver.cacheMu.Lock()
+	for key, cached := range resolver.cache {
+		cached.expires = time.Now().Add(-time.Second)
+		resolver.cache[key] = cached
+	}
+	resolver.cacheMu.Unlock()
+
+	_, _, err = resolver.Resolve(t.Context(), dynamic.DNSDiscovery{Name: "other.example.com", RecordType: "A", Port: 80})
+	require.NoError(t, err)
+
+	resolver.cacheMu.Lock()
+	defer resolver.cacheMu.Unlock()
+	assert.Len(t, resolver.cache, 1)
+	assert.Contains(t, resolver.cache, "A/other.example.com/80")
+}
+
+func TestDNSResolver_Resolve_serverFailure(t *testing.T) {
+	server := newDNSServer(t, nil)
+	server.rcode.Store(dns.RcodeServerFailure)
+
+	resolver := newTestDNSResolver(server.addr)
+
+	_, _, err := resolver.Resolve(t.Context(), dynamic.DNSDiscovery{Name: "legacy.example.com", RecordType: "A", Port: 80})
+	require.ErrorContains(t, err, "resolving A records of legacy.example.com: server 127.0.0.1 answered SERVFAIL")
+}
+
+func TestDNSResolver_Resolve_truncated(t *testing.T) {
+	server := newDNSServer(t, map[string][]string{
+		"legacy.example.com.": {"legacy.example.com. 30 IN A 10.0.0.1"},
+	})
+	server.truncate.Store(true)
+
+	resolver := newTestDNSResolver(server.addr)
+
+	targets, _, err := resolver.Resolve(t.Context(), dynamic.DNSDiscovery{Name: "legacy.example.com", RecordType: "A", Port: 80})
+	require.NoError(t, err)
+
+	// The query is sent over UDP, and then over TCP.
+	assert.Equal(t, []Target{{Host: "10.0.0.1", Port: 80}}, targets)
+	assert.Equal(t, int32(2), server.queries.Load())
+}
+
+func TestDNSResolver_Resolve_searchList(t *testing.T) {
+	server := newDNSServer(t, map[string][]string{
+		"legacy.example.com.": {"legacy.example.com. 30 IN A 10.0.0.1"},
+		"legacy.":             {"legacy. 30 IN A 10.0.0.2"},
+		"vm1.example.com.":    {"vm1.example.com. 30 IN A 10.0.0.3"},
+	})
+
+	host, port, err := net.SplitHostPort(server.addr)
+	require.NoError(t, err)
+
+	resolver := NewDNSResolver(nil)
+	resolver.clientConfig = func() (*dns.ClientConfig, error) {
+		return &dns.ClientConfig{Servers: []string{host}, Port: port, Search: []string{"svc.example.com", "example.com"}, Ndots: 1}, nil
+	}
+
+	testCases := []struct {
+		desc     string
+		name     string
+		expected []Target
+	}{
+		{
+			desc:     "name with less dots than ndots",
+			name:     "legacy",
+			expected: []Target{{Host: "10.0.0.1", Port: 80}},
+		},
+		{
+			desc:     "name with more dots than ndots",
+			name:     "vm1.example.com",
+			expected: []Target{{Host: "10.0.0.3", Port: 80}},
+		},
+		{
+			desc:     "fully qualified name",
+			name:     "legacy.",
+			expected: []Target{{Host: "10.0.0.2", Port: 80}},
+		},
+		{
+			desc: "unknown name",
+			name: "unknown",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			targets, _, err := resolver.Resolve(t.Context(), dynamic.DNSDiscovery{Name: test.name, RecordType: "A", Port: 80})
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, targets)
+		})
+	}
+}
+
+func TestDNSResolver_Watch(t *testing.T) {
+	server := newDNSServer(t, map[string][]string{
+		"legacy.example.com.": {"legacy.example.com. 0 IN A 10.0.0.1"},
+	})
+
+	resolver := newTestDNSResolver(server.addr)
+
+	var mu sync.Mutex
+	var updates [][]Target
+
+	config := dynamic.DNSDiscovery{Name: "legacy.example.com", RecordType: "A", Port: 80, RefreshInterval: ptypes.Duration(10 * time.Millisecond)}
+	resolver.Watch(t.Context(), config, func(targets []Target) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		updates = append(updates, targets)
+	})
+
+	// The first update happens before Watch returns.
+	mu.Lock()
+	assert.Equal(t, [][]Target{{{Host: "10.0.0.1", Port: 80}}}, updates)
+	mu.Unlock()
+
+	// The records are resolved again, but only the changes are notified.
+	queries := server.queries.Load()
+	require.Eventually(t, func() bool { return server.queries.Load() > queries+2 }, time.Second, 5*time.Millisecond)
+
+	mu.Lock()
+	assert.Len(t, updates, 1)
+	mu.Unlock()
+
+	// The discovered servers are kept when the records cannot be resolved.
+	server.rcode.Store(dns.RcodeServerFailure)
+	queries = server.queries.Load()
+	require.Eventually(t, func() bool { return server.queries.Load() > queries+2 }, time.Second, 5*time.Millisecond)
+
+	mu.Lock()
+	assert.Len(t, updates, 1)
+	mu.Unlock()
+
+	server.rcode.Store(dns.RcodeSuccess)
+	server.setRecords("legacy.example.com.", "legacy.example.com. 0 IN A 10.0.0.1", "legacy.example.com. 0 IN A 10.0.0.2")
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return len(updates) == 2
+	}, time.Second, 5*time.Millisecond)
+
+	mu.Lock()
+	assert.Equal(t, []Target{{Host: "10.0.0.1", Port: 80}, {Host: "10.0.0.2", Port: 80}}, updates[1])
+	mu.Unlock()
+}
+
+type dnsServer struct {
+	addr    string
+	queries atomic.Int32
+	rcode   atomic.Int32
+	// truncate truncates the answers sent over UDP.
+	truncate atomic.Bool
+
+	mu      sync.RWMutex
+	records map[string][]dns.RR
+}
+
+func newDNSServer(t *testing.T, records map[string][]string) *dnsServer {
+	t.Helper()
+
+	s := &dnsServer{records: make(map[string][]dns.RR)}
+	for name, rrs := range records {
+		s.setRecords(name, rrs...)
+	}
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s.addr = conn.LocalAddr().String()
+
+	listener, err := net.Listen("tcp", s.addr)
+	require.NoError(t, err)
+
+	handler := dns.HandlerFunc(func(rw dns.ResponseWriter, req *dns.Msg) {
+		s.queries.Add(1)
+
+		resp := &dns.Msg{}
+		resp.SetReply(req)
+		resp.Rcode = int(s.rcode.Load())
+
+		s.mu.RLock()
+		rrs, ok := s.records[req.Question[0].Name]
+		s.mu.RUnlock()
+
+		if !ok && resp.Rcode == dns.RcodeSuccess {
+			resp.Rcode = dns.RcodeNameError
+		}
+
+		for _, rr := range rrs {
+			if rr.Header().Rrtype == req.Question[0].Qtype || rr.Header().Rrtype == dns.TypeCNAME {
+				resp.Answer = append(resp.Answer, rr)
+			}
+		}
+
+		if _, udp := rw.RemoteAddr().(*net.UDPAddr); udp && s.truncate.Load() {
+			resp.Answer = nil
+			resp.Truncated = true
+		}
+
+		_ = rw.WriteMsg(resp)
+	})
+
+	for _, server := range []*dns.Server{{PacketConn: conn, Handler: handler}, {Listener: listener, Handler: handler}} {
+		go func() { _ = server.ActivateAndServe() }()
+		t.Cleanup(func() { _ = server.Shutdown() })
+	}
+
+	return s
+}
+
+func (s *dnsServer) setRecords(name string, rrs ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[name] = nil
+	for _, rr := range rrs {
+		record, err := dns.NewRR(rr)
+		if err != nil {
+			panic(err)
+		}
+		s.records[name] = append(s.records[name], record)
+	}
+}
+
+func newTestDNSResolver(addr string) *DNSResolver {
+	host, port, _ := net.SplitHostPort(addr)
+
+	resolver := NewDNSResolver(nil)
+	resolver.clientConfig = func() (*dns.ClientConfig, error) {
+		return &dns.ClientConfig{Servers: []string{host}, Port: port}, nil
+	}
+
+	return resolver
+}
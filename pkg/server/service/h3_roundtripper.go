@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+	"github.com/rs/zerolog/log"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/proxy/egress"
+	"golang.org/x/net/http/httpguts"
+)
+
+const (
+	schemeH3 = "h3"
+
+	// h3BrokenDuration is the duration during which the requests to a server are sent over TCP,
+	// after a failure to establish a QUIC connection with it.
+	h3BrokenDuration = 5 * time.Minute
+)
+
+// h3DialError is the error returned when a QUIC connection cannot be established,
+// in which case the request has not been sent.
+type h3DialError struct {
+	err error
+}
+
+func (e h3DialError) Error() string {
+	return fmt.Sprintf("establishing QUIC connection: %v", e.err)
+}
+
+func (e h3DialError) Unwrap() error {
+	return e.err
+}
+
+// h3RoundTripper sends the requests of the h3 servers over QUIC,
+// and falls back to TCP when a QUIC connection cannot be established with a server,
+// or when the server is reached through a forward proxy.
+type h3RoundTripper struct {
+	transport   *http3.Transport
+	enable0RTT  bool
+	egressProxy *egress.Proxy
+
+	// fallback sends the requests over TCP, it is set once the TCP round tripper is created.
+	fallback http.RoundTripper
+
+	brokenMu sync.Mutex
+	broken   map[string]time.Time
+}
+
+func newH3RoundTripper(cfg *dynamic.ServersTransport, tlsConfig *tls.Config, egressProxy *egress.Proxy) *h3RoundTripper {
+	var enable0RTT bool
+	if cfg.HTTP3 != nil {
+		enable0RTT = cfg.HTTP3.Enable0RTT
+	}
+
+	dialTimeout := 30 * time.Second
+	quicConfig := &quic.Config{}
+	if cfg.ForwardingTimeouts != nil {
+		dialTimeout = time.Duration(cfg.ForwardingTimeouts.DialTimeout)
+		quicConfig.MaxIdleTimeout = time.Duration(cfg.ForwardingTimeouts.IdleConnTimeout)
+	}
+
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	} else {
+		tlsConfig = tlsConfig.Clone()
+	}
+
+	// 0-RTT is only possible when resuming a session.
+	if enable0RTT {
+		tlsConfig.ClientSessionCache = tls.NewLRUClientSessionCache(0)
+	}
+
+	rt := &h3RoundTripper{
+		enable0RTT:  enable0RTT,
+		egressProxy: egressProxy,
+		broken:      make(map[string]time.Time),
+	}
+
+	rt.transport = &http3.Transport{
+		TLSClientConfig: tlsConfig,
+		QUICConfig:      quicConfig,
+		Dial: func(ctx context.Context, addr string, tlsCfg *tls.Config, cfg *quic.Config) (*quic.Conn, error) {
+			if dialTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, dialTimeout)
+				defer cancel()
+			}
+
+			var conn *quic.Conn
+			var err error
+			if enable0RTT {
+				conn, err = quic.DialAddrEarly(ctx, addr, tlsCfg, cfg)
+			} else {
+				// The handshake is completed before sending the request, thus 0-RTT is never used.
+				conn, err = quic.DialAddr(ctx, addr, tlsCfg, cfg)
+			}
+			if err != nil {
+				return nil, h3DialError{err: err}
+			}
+
+			return conn, nil
+		},
+	}
+
+	return rt
+}
+
+func (r *h3RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// HTTP/3 doesn't support the protocols starting with a Connection Upgrade, such as Websocket.
+	if httpguts.HeaderValuesContainsToken(req.Header["Connection"], "Upgrade") || r.isBroken(req.URL.Host) {
+		return r.fallback.RoundTrip(withHTTPSScheme(req))
+	}
+
+	// QUIC cannot be tunneled through the forward proxy.
+	if r.egressProxy != nil {
+		proxyURL, err := r.egressProxy.URL(req.URL)
+		if err != nil {
+			return nil, err
+		}
+
+		if proxyURL != nil {
+			return r.fallback.RoundTrip(withHTTPSScheme(req))
+		}
+	}
+
+	outReq := withHTTPSScheme(req)
+	if r.enable0RTT {
+		switch req.Method {
+		case http.MethodGet:
+			outReq.Method = http3.MethodGet0RTT
+		case http.MethodHead:
+			outReq.Method = http3.MethodHead0RTT
+		}
+	}
+
+	resp, err := r.transport.RoundTrip(outReq)
+	if err == nil {
+		return resp, nil
+	}
+
+	var dialErr h3DialError
+	if !errors.As(err, &dialErr) || req.Context().Err() != nil {
+		return nil, err
+	}
+
+	r.setBroken(req.URL.Host)
+
+	log.Ctx(req.Context()).Debug().Err(err).Str("host", req.URL.Host).Msg("Falling back to TCP")
+
+	// The request body has been closed by the HTTP/3 transport, but nothing has been read from it.
+	fallbackReq := withHTTPSScheme(req)
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, err
+		}
+
+		if fallbackReq.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+
+	return r.fallback.RoundTrip(fallbackReq)
+}
+
+// CloseIdleConnections closes the idle QUIC connections, it is called by the http.Transport the round tripper is registered on.
+func (r *h3RoundTripper) CloseIdleConnections() {
+	r.transport.CloseIdleConnections()
+}
+
+func (r *h3RoundTripper) isBroken(host string) bool {
+	r.brokenMu.Lock()
+	defer r.brokenMu.Unlock()
+
+	until, ok := r.broken[host]
+	if !ok {
+		return false
+	}
+
+	if time.Now().After(until) {
+		delete(r.broken, host)
+		return false
+	}
+
+	return true
+}
+
+func (r *h3RoundTripper) setBroken(host string) {
+	r.brokenMu.Lock()
+	defer r.brokenMu.Unlock()
+
+	r.broken[host] = time.Now().Add(h3BrokenDuration)
+}
+
+// withHTTPSScheme returns a shallow copy of the request with the https scheme.
+func withHTTPSScheme(req *http.Request) *http.Request {
+	outReq := *req
+
+	u := *req.URL
+	u.Scheme = "https"
+	outReq.URL = &u
+
+	return &outReq
+}
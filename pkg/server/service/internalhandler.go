@@ -13,17 +13,19 @@ type InternalHandlers struct {
 	api        http.Handler
 	dashboard  http.Handler
 	rest       http.Handler
+	git        http.Handler
 	prometheus http.Handler
 	ping       http.Handler
 	acmeHTTP   http.Handler
 }
 
 // NewInternalHandlers creates a new InternalHandlers.
-func NewInternalHandlers(apiHandler, rest, metricsHandler, pingHandler, dashboard, acmeHTTP http.Handler) *InternalHandlers {
+func NewInternalHandlers(apiHandler, rest, git, metricsHandler, pingHandler, dashboard, acmeHTTP http.Handler) *InternalHandlers {
 	return &InternalHandlers{
 		api:        apiHandler,
 		dashboard:  dashboard,
 		rest:       rest,
+		git:        git,
 		prometheus: metricsHandler,
 		ping:       pingHandler,
 		acmeHTTP:   acmeHTTP,
@@ -75,6 +77,12 @@ func (m *InternalHandlers) get(serviceName string) (http.Handler, error) {
 		}
 		return m.rest, nil
 
+	case "git@internal":
+		if m.git == nil {
+			return nil, errors.New("git webhook is not enabled")
+		}
+		return m.git, nil
+
 	case "ping@internal":
 		if m.ping == nil {
 			return nil, errors.New("ping is not enabled")
@@ -0,0 +1,151 @@
+package loadbalancer
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// drainingServer holds the in-flight requests, or connections, of a server.
+type drainingServer struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	inFlight int
+	draining bool
+	timer    *time.Timer
+	// drained is called once the server is drained.
+	drained func()
+}
+
+// Drainer tracks the in-flight requests, or connections, of the servers of a load-balancer,
+// for the servers removed from it to be drained: they no longer receive new requests,
+// and their in-flight requests are allowed to finish until the drain timeout has elapsed.
+type Drainer struct {
+	timeout time.Duration
+
+	mu      sync.Mutex
+	servers map[string]*drainingServer
+}
+
+// NewDrainer creates a new Drainer canceling the in-flight requests of the drained servers after the given timeout.
+func NewDrainer(timeout time.Duration) *Drainer {
+	return &Drainer{
+		timeout: timeout,
+		servers: make(map[string]*drainingServer),
+	}
+}
+
+// Track records an in-flight request, or connection, to the given server.
+// It returns a context which is canceled when the server is drained,
+// and the function to call once the request is done.
+func (d *Drainer) Track(name string) (context.Context, func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s, ok := d.servers[name]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		s = &drainingServer{ctx: ctx, cancel: cancel}
+		d.servers[name] = s
+	}
+	s.inFlight++
+
+	return s.ctx, func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		s.inFlight--
+		if s.draining && s.inFlight == 0 {
+			d.finish(name, s)
+		}
+	}
+}
+
+// WrapHandler wraps the handler of the given server to track its in-flight requests,
+// and to cancel them when the server is drained.
+func (d *Drainer) WrapHandler(name string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		drainCtx, done := d.Track(name)
+		defer done()
+
+		ctx, cancel := context.WithCancel(req.Context())
+		defer cancel()
+
+		stop := context.AfterFunc(drainCtx, cancel)
+		defer stop()
+
+		next.ServeHTTP(rw, req.WithContext(ctx))
+	})
+}
+
+// Drain starts draining the given server, and calls drained once it has no more in-flight requests,
+// or once the drain timeout has elapsed, in which case its remaining in-flight requests are canceled.
+func (d *Drainer) Drain(name string, drained func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s, ok := d.servers[name]
+	if !ok {
+		drained()
+		return
+	}
+
+	if s.draining {
+		return
+	}
+
+	if s.inFlight == 0 {
+		d.finish(name, s)
+		drained()
+		return
+	}
+
+	s.draining = true
+	s.drained = drained
+	s.timer = time.AfterFunc(d.timeout, func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		if s.draining {
+			d.finish(name, s)
+		}
+	})
+}
+
+// Restore stops draining the given server, which has been added back to the load-balancer.
+func (d *Drainer) Restore(name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s, ok := d.servers[name]
+	if !ok || !s.draining {
+		return
+	}
+
+	s.draining = false
+	s.drained = nil
+	s.timer.Stop()
+}
+
+// finish cancels the in-flight requests of the given server, and forgets it.
+// It must be called with the lock held.
+func (d *Drainer) finish(name string, s *drainingServer) {
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+
+	s.cancel()
+	s.draining = false
+
+	// The server might have been tracked again since its in-flight requests have been canceled.
+	if d.servers[name] == s {
+		delete(d.servers, name)
+	}
+
+	if s.drained != nil {
+		s.drained()
+		s.drained = nil
+	}
+}
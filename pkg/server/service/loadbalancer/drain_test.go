@@ -0,0 +1,115 @@
+package loadbalancer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrainer_NoInFlightRequests(t *testing.T) {
+	drainer := NewDrainer(time.Minute)
+
+	_, done := drainer.Track("server")
+	done()
+
+	var drained bool
+	drainer.Drain("server", func() { drained = true })
+	assert.True(t, drained)
+
+	drained = false
+	drainer.Drain("unknown", func() { drained = true })
+	assert.True(t, drained)
+}
+
+func TestDrainer_InFlightRequests(t *testing.T) {
+	drainer := NewDrainer(time.Minute)
+
+	ctx, done := drainer.Track("server")
+	_, otherDone := drainer.Track("server")
+
+	drained := make(chan struct{})
+	drainer.Drain("server", func() { close(drained) })
+
+	otherDone()
+	select {
+	case <-drained:
+		t.Fatal("the server has been drained with an in-flight request")
+	default:
+	}
+
+	done()
+	<-drained
+
+	// The requests of a drained server are canceled, which has no effect once they are done.
+	require.Error(t, ctx.Err())
+}
+
+func TestDrainer_Timeout(t *testing.T) {
+	drainer := NewDrainer(10 * time.Millisecond)
+
+	ctx, done := drainer.Track("server")
+	defer done()
+
+	drained := make(chan struct{})
+	drainer.Drain("server", func() { close(drained) })
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("the in-flight request has not been canceled")
+	}
+	<-drained
+
+	// A new request to the server is tracked again.
+	ctx, otherDone := drainer.Track("server")
+	defer otherDone()
+	assert.NoError(t, ctx.Err())
+}
+
+func TestDrainer_Restore(t *testing.T) {
+	drainer := NewDrainer(10 * time.Millisecond)
+
+	ctx, done := drainer.Track("server")
+
+	var drained bool
+	drainer.Drain("server", func() { drained = true })
+	drainer.Restore("server")
+
+	time.Sleep(50 * time.Millisecond)
+	assert.NoError(t, ctx.Err())
+
+	done()
+	assert.False(t, drained)
+}
+
+func TestDrainer_WrapHandler(t *testing.T) {
+	drainer := NewDrainer(10 * time.Millisecond)
+
+	started := make(chan struct{})
+	handler := drainer.WrapHandler("server", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		close(started)
+		<-req.Context().Done()
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	recorder := httptest.NewRecorder()
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+
+	<-started
+	drainer.Drain("server", func() {})
+
+	select {
+	case <-served:
+	case <-time.After(time.Second):
+		t.Fatal("the in-flight request has not been canceled")
+	}
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+}
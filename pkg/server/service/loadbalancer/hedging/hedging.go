@@ -0,0 +1,468 @@
+package hedging
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/healthcheck"
+	"github.com/hanzoai/ingress/pkg/middlewares/accesslog"
+	"github.com/hanzoai/ingress/pkg/middlewares/capture"
+	"github.com/hanzoai/ingress/pkg/middlewares/retry"
+	"golang.org/x/net/http/httpguts"
+)
+
+const (
+	// percentileRefreshInterval is the interval at which the delay computed from the response times percentile is refreshed.
+	percentileRefreshInterval = time.Second
+
+	// maxBudget is the maximum number of hedged requests which can be sent in a burst.
+	maxBudget = 10
+)
+
+// Balancer is the load-balancer electing the server of the requests before they are hedged.
+type Balancer interface {
+	http.Handler
+	healthcheck.StatusSetter
+	healthcheck.StatusUpdater
+
+	AddServer(name string, handler http.Handler, server dynamic.Server)
+	RemoveServer(name string)
+}
+
+// latencyTracker is implemented by the load-balancers measuring the response times of their servers.
+type latencyTracker interface {
+	ResponseTimePercentile(percentile float64) (time.Duration, bool)
+}
+
+type metricsHedging interface {
+	ServiceHedgesCounter() gokitmetrics.Counter
+	ServiceHedgesWonCounter() gokitmetrics.Counter
+}
+
+type namedHandler struct {
+	http.Handler
+
+	name   string
+	fenced bool
+}
+
+// Hedger sends a duplicate of the idempotent requests to another server when the response of the server elected by
+// the load-balancer is delayed, and returns the first response.
+type Hedger struct {
+	balancer Balancer
+
+	delay      time.Duration
+	percentile float64
+	latency    latencyTracker
+
+	percentileDelay     atomic.Int64
+	percentileUpdatedAt atomic.Int64
+
+	budgetMu sync.Mutex
+	maxRatio float64
+	budget   float64
+
+	hedgesCounter    gokitmetrics.Counter
+	hedgesWonCounter gokitmetrics.Counter
+
+	// handlersMu protects the handlers slice and the status map.
+	handlersMu sync.RWMutex
+	handlers   []*namedHandler
+	// status is a record of which servers are healthy, keyed by server name.
+	status map[string]struct{}
+	next   atomic.Uint64
+}
+
+// New creates a new Hedger sending the requests through the given load-balancer.
+func New(balancer Balancer, config *dynamic.Hedging, serviceName string, metrics metricsHedging) (*Hedger, error) {
+	if config.Percentile < 0 || config.Percentile > 100 {
+		return nil, errors.New("hedging percentile must be between 0 and 100")
+	}
+
+	if config.MaxRatio < 0 || config.MaxRatio > 1 {
+		return nil, errors.New("hedging maxRatio must be between 0 and 1")
+	}
+
+	h := &Hedger{
+		balancer:   balancer,
+		delay:      time.Duration(config.Delay),
+		percentile: config.Percentile,
+		maxRatio:   config.MaxRatio,
+		status:     make(map[string]struct{}),
+	}
+
+	if config.Percentile > 0 {
+		latency, ok := balancer.(latencyTracker)
+		if !ok {
+			return nil, errors.New("hedging percentile requires the leasttime strategy")
+		}
+
+		h.latency = latency
+		h.percentileDelay.Store(int64(h.delay))
+	}
+
+	if metrics != nil {
+		h.hedgesCounter = metrics.ServiceHedgesCounter().With("service", serviceName)
+		h.hedgesWonCounter = metrics.ServiceHedgesWonCounter().With("service", serviceName)
+	}
+
+	return h, nil
+}
+
+// SetStatus sets on the balancer that its given child is now of the given status.
+func (h *Hedger) SetStatus(ctx context.Context, childName string, up bool) {
+	h.handlersMu.Lock()
+	if up {
+		h.status[childName] = struct{}{}
+	} else {
+		delete(h.status, childName)
+	}
+	h.handlersMu.Unlock()
+
+	h.balancer.SetStatus(ctx, childName, up)
+}
+
+// RegisterStatusUpdater adds fn to the list of hooks that are run when the status of the load-balancer changes.
+func (h *Hedger) RegisterStatusUpdater(fn func(up bool)) error {
+	return h.balancer.RegisterStatusUpdater(fn)
+}
+
+// AddServer adds a handler with a server.
+// A server with a non-positive weight never receives hedged requests.
+func (h *Hedger) AddServer(name string, handler http.Handler, server dynamic.Server) {
+	// The server elected by the load-balancer is recorded, for the hedged request to be sent to another one.
+	h.balancer.AddServer(name, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if a, ok := req.Context().Value(attemptKey{}).(*attempt); ok {
+			a.server.Store(&name)
+		}
+
+		handler.ServeHTTP(rw, req)
+	}), server)
+
+	if server.Weight != nil && *server.Weight <= 0 {
+		return
+	}
+
+	h.handlersMu.Lock()
+	h.handlers = append(h.handlers, &namedHandler{Handler: handler, name: name, fenced: server.Fenced})
+	h.status[name] = struct{}{}
+	h.handlersMu.Unlock()
+}
+
+// RemoveServer removes the handler of the given server.
+func (h *Hedger) RemoveServer(name string) {
+	h.handlersMu.Lock()
+	h.handlers = slices.DeleteFunc(h.handlers, func(handler *namedHandler) bool { return handler.name == name })
+	delete(h.status, name)
+	h.handlersMu.Unlock()
+
+	h.balancer.RemoveServer(name)
+}
+
+func (h *Hedger) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if !isHedgeable(req) {
+		h.balancer.ServeHTTP(rw, req)
+		return
+	}
+
+	h.deposit()
+
+	r := &race{rw: rw, claimed: make(chan struct{})}
+	done := make(chan *attempt, 2)
+
+	primary, primaryReq := newAttempt(r, req, false)
+	attempts := []*attempt{primary}
+	go primary.serve(h.balancer, primaryReq, done)
+
+	timer := time.NewTimer(h.hedgeDelay())
+	defer timer.Stop()
+
+	hedgeTimer := timer.C
+	claimed := r.claimed
+	for pending := 1; pending > 0; {
+		select {
+		case <-hedgeTimer:
+			hedgeTimer = nil
+
+			server := h.nextServer(primary.serverName())
+			if server == nil || !h.withdraw() {
+				continue
+			}
+
+			if h.hedgesCounter != nil {
+				h.hedgesCounter.Add(1)
+			}
+
+			hedge, hedgeReq := newAttempt(r, req, true)
+			attempts = append(attempts, hedge)
+			pending++
+			go hedge.serve(server, hedgeReq, done)
+
+		case <-claimed:
+			claimed = nil
+			hedgeTimer = nil
+
+			// The requests which have not returned the first response are canceled.
+			for _, a := range attempts {
+				if a != r.winner {
+					a.cancel()
+				}
+			}
+
+		case <-done:
+			pending--
+		}
+	}
+
+	for _, a := range attempts {
+		a.cancel()
+	}
+
+	if r.winner != nil {
+		if r.winner.hedged && h.hedgesWonCounter != nil {
+			h.hedgesWonCounter.Add(1)
+		}
+
+		if table := accesslog.GetLogData(req); table != nil && r.winner.table != nil {
+			for k, v := range r.winner.table.Core {
+				table.Core[k] = v
+			}
+			table.OriginResponse = r.winner.table.OriginResponse
+		}
+	}
+
+	for _, a := range attempts {
+		if a.panicValue == nil {
+			continue
+		}
+
+		// The canceled requests are expected to abort.
+		if err, ok := a.panicValue.(error); ok && errors.Is(err, http.ErrAbortHandler) && r.winner != nil && r.winner != a {
+			continue
+		}
+
+		panic(a.panicValue)
+	}
+}
+
+// hedgeDelay returns the delay after which a hedged request is sent.
+func (h *Hedger) hedgeDelay() time.Duration {
+	if h.latency == nil {
+		return h.delay
+	}
+
+	now := time.Now().UnixNano()
+	if now-h.percentileUpdatedAt.Load() >= int64(percentileRefreshInterval) {
+		h.percentileUpdatedAt.Store(now)
+
+		delay, ok := h.latency.ResponseTimePercentile(h.percentile)
+		if !ok {
+			delay = h.delay
+		}
+		h.percentileDelay.Store(int64(delay))
+	}
+
+	return time.Duration(h.percentileDelay.Load())
+}
+
+// nextServer returns, in a round-robin fashion, a healthy and non-fenced server other than the given one.
+func (h *Hedger) nextServer(exclude string) http.Handler {
+	h.handlersMu.RLock()
+	defer h.handlersMu.RUnlock()
+
+	n := uint64(len(h.handlers))
+	start := h.next.Add(1)
+	for i := range n {
+		server := h.handlers[(start+i)%n]
+		if server.name == exclude || server.fenced {
+			continue
+		}
+
+		if _, ok := h.status[server.name]; ok {
+			return server
+		}
+	}
+
+	return nil
+}
+
+// deposit increases the budget of hedged requests for a hedgeable request.
+func (h *Hedger) deposit() {
+	h.budgetMu.Lock()
+	defer h.budgetMu.Unlock()
+
+	h.budget = min(h.budget+h.maxRatio, maxBudget)
+}
+
+// withdraw reports whether the budget allows sending a hedged request, and decreases it accordingly.
+func (h *Hedger) withdraw() bool {
+	h.budgetMu.Lock()
+	defer h.budgetMu.Unlock()
+
+	if h.budget < 1 {
+		return false
+	}
+
+	h.budget--
+	return true
+}
+
+// isHedgeable reports whether the given request is idempotent and can be sent several times.
+// Only the methods defined as idempotent by RFC 9110 are hedged, the unknown ones being possibly unsafe.
+func isHedgeable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+
+	// The request body can only be read once.
+	if req.ContentLength != 0 || (req.Body != nil && req.Body != http.NoBody) {
+		return false
+	}
+
+	return !httpguts.HeaderValuesContainsToken(req.Header["Connection"], "Upgrade")
+}
+
+type attemptKey struct{}
+
+// attempt is one of the requests sent to a server, it writes the response only if it is the first one to respond.
+type attempt struct {
+	race   *race
+	hedged bool
+	cancel context.CancelFunc
+	table  *accesslog.LogData
+	server atomic.Pointer[string]
+
+	header      http.Header
+	wroteHeader bool
+	won         bool
+
+	panicValue any
+}
+
+func newAttempt(r *race, req *http.Request, hedged bool) (*attempt, *http.Request) {
+	ctx, cancel := context.WithCancel(req.Context())
+
+	a := &attempt{
+		race:   r,
+		hedged: hedged,
+		cancel: cancel,
+		header: make(http.Header),
+	}
+
+	// The requests do not share the access log data table, as they are served concurrently.
+	if accesslog.GetLogData(req) != nil {
+		a.table = &accesslog.LogData{Core: accesslog.CoreLogData{}}
+		ctx = context.WithValue(ctx, accesslog.DataTableKey, a.table)
+	}
+
+	if hedged {
+		ctx = retry.WithoutShouldRetry(ctx)
+	}
+
+	return a, req.Clone(context.WithValue(ctx, attemptKey{}, a))
+}
+
+func (a *attempt) serve(handler http.Handler, req *http.Request, done chan<- *attempt) {
+	defer func() {
+		a.panicValue = recover()
+		done <- a
+	}()
+
+	// The requests do not share the captured response, as they are served concurrently.
+	handler, _ = capture.Wrap(handler)
+	handler.ServeHTTP(a, req)
+
+	// Like the HTTP server, an empty response is sent when nothing has been written.
+	if !a.wroteHeader {
+		a.WriteHeader(http.StatusOK)
+	}
+}
+
+func (a *attempt) serverName() string {
+	if name := a.server.Load(); name != nil {
+		return *name
+	}
+	return ""
+}
+
+func (a *attempt) Header() http.Header {
+	if a.won {
+		return a.race.rw.Header()
+	}
+	return a.header
+}
+
+func (a *attempt) WriteHeader(statusCode int) {
+	if a.wroteHeader {
+		return
+	}
+
+	// The informational responses cannot be forwarded, as the first response is not elected yet.
+	if statusCode >= 100 && statusCode <= 199 && statusCode != http.StatusSwitchingProtocols {
+		return
+	}
+
+	a.wroteHeader = true
+
+	if !a.race.claim(a) {
+		return
+	}
+	a.won = true
+
+	header := a.race.rw.Header()
+	for k, v := range a.header {
+		header[k] = append(header[k], v...)
+	}
+
+	a.race.rw.WriteHeader(statusCode)
+}
+
+func (a *attempt) Write(b []byte) (int, error) {
+	if !a.wroteHeader {
+		a.WriteHeader(http.StatusOK)
+	}
+
+	if !a.won {
+		return len(b), nil
+	}
+	return a.race.rw.Write(b)
+}
+
+func (a *attempt) Flush() {
+	if !a.wroteHeader {
+		a.WriteHeader(http.StatusOK)
+	}
+
+	if a.won {
+		_ = http.NewResponseController(a.race.rw).Flush()
+	}
+}
+
+// race elects the first request to respond.
+type race struct {
+	rw      http.ResponseWriter
+	claimed chan struct{}
+
+	mu     sync.Mutex
+	winner *attempt
+}
+
+func (r *race) claim(a *attempt) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.winner == nil {
+		r.winner = a
+		close(r.claimed)
+	}
+	return r.winner == a
+}
@@ -0,0 +1,254 @@
+package hedging
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/hanzoai/ingress-parser/types"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/server/service/loadbalancer/leasttime"
+	"github.com/hanzoai/ingress/pkg/server/service/loadbalancer/wrr"
+)
+
+type counterMock struct {
+	value *float64
+}
+
+func (c counterMock) With(_ ...string) gokitmetrics.Counter {
+	return c
+}
+
+func (c counterMock) Add(delta float64) {
+	*c.value += delta
+}
+
+type metricsMock struct {
+	hedges    float64
+	hedgesWon float64
+}
+
+func (m *metricsMock) ServiceHedgesCounter() gokitmetrics.Counter {
+	return counterMock{value: &m.hedges}
+}
+
+func (m *metricsMock) ServiceHedgesWonCounter() gokitmetrics.Counter {
+	return counterMock{value: &m.hedgesWon}
+}
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		balancer Balancer
+		config   dynamic.Hedging
+		wantErr  bool
+	}{
+		{
+			desc:     "delay",
+			balancer: wrr.New(nil, false),
+			config:   dynamic.Hedging{Delay: 1, MaxRatio: 0.1},
+		},
+		{
+			desc:     "percentile with leasttime",
+			balancer: leasttime.New(nil, false),
+			config:   dynamic.Hedging{Percentile: 95, MaxRatio: 0.1},
+		},
+		{
+			desc:     "percentile without leasttime",
+			balancer: wrr.New(nil, false),
+			config:   dynamic.Hedging{Percentile: 95, MaxRatio: 0.1},
+			wantErr:  true,
+		},
+		{
+			desc:     "invalid percentile",
+			balancer: leasttime.New(nil, false),
+			config:   dynamic.Hedging{Percentile: 101, MaxRatio: 0.1},
+			wantErr:  true,
+		},
+		{
+			desc:     "invalid max ratio",
+			balancer: wrr.New(nil, false),
+			config:   dynamic.Hedging{MaxRatio: 2},
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(test.balancer, &test.config, "test", nil)
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestHedger_SlowServer(t *testing.T) {
+	metrics := &metricsMock{}
+
+	hedger, err := New(wrr.New(nil, false), &dynamic.Hedging{Delay: ptypes.Duration(10 * time.Millisecond), MaxRatio: 1}, "test", metrics)
+	require.NoError(t, err)
+
+	canceled := make(chan struct{}, 1)
+	hedger.AddServer("slow", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		select {
+		case <-req.Context().Done():
+			canceled <- struct{}{}
+		case <-time.After(5 * time.Second):
+		}
+
+		rw.Header().Set("server", "slow")
+		rw.WriteHeader(http.StatusBadGateway)
+	}), dynamic.Server{})
+	hedger.AddServer("fast", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "fast")
+		_, _ = rw.Write([]byte("fast"))
+	}), dynamic.Server{})
+
+	for range 2 {
+		recorder := httptest.NewRecorder()
+		hedger.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, []string{"fast"}, recorder.Header().Values("server"))
+		assert.Equal(t, "fast", recorder.Body.String())
+	}
+
+	// Only the request sent first to the slow server is hedged, and its hedged request wins.
+	assert.InDelta(t, 1, metrics.hedges, 0)
+	assert.InDelta(t, 1, metrics.hedgesWon, 0)
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("the request sent to the slow server has not been canceled")
+	}
+}
+
+func TestHedger_NotHedged(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		method   string
+		body     string
+		maxRatio float64
+		servers  []string
+	}{
+		{
+			desc:     "non-idempotent method",
+			method:   http.MethodPost,
+			maxRatio: 1,
+			servers:  []string{"first", "second"},
+		},
+		{
+			desc:     "extension method",
+			method:   "LOCK",
+			maxRatio: 1,
+			servers:  []string{"first", "second"},
+		},
+		{
+			desc:     "request with a body",
+			method:   http.MethodPut,
+			body:     "body",
+			maxRatio: 1,
+			servers:  []string{"first", "second"},
+		},
+		{
+			desc:    "no budget",
+			method:  http.MethodGet,
+			servers: []string{"first", "second"},
+		},
+		{
+			desc:     "no other server",
+			method:   http.MethodGet,
+			maxRatio: 1,
+			servers:  []string{"first"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			metrics := &metricsMock{}
+
+			hedger, err := New(wrr.New(nil, false), &dynamic.Hedging{MaxRatio: test.maxRatio}, "test", metrics)
+			require.NoError(t, err)
+
+			for _, name := range test.servers {
+				hedger.AddServer(name, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+					time.Sleep(20 * time.Millisecond)
+					rw.Header().Set("server", name)
+					rw.WriteHeader(http.StatusOK)
+				}), dynamic.Server{})
+			}
+
+			for range 2 {
+				recorder := httptest.NewRecorder()
+				hedger.ServeHTTP(recorder, httptest.NewRequest(test.method, "/", strings.NewReader(test.body)))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Len(t, recorder.Header().Values("server"), 1)
+			}
+
+			assert.Zero(t, metrics.hedges)
+		})
+	}
+}
+
+func TestHedger_FastServer(t *testing.T) {
+	metrics := &metricsMock{}
+
+	hedger, err := New(wrr.New(nil, false), &dynamic.Hedging{Delay: ptypes.Duration(time.Second), MaxRatio: 1}, "test", metrics)
+	require.NoError(t, err)
+
+	for _, name := range []string{"first", "second"} {
+		hedger.AddServer(name, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.Header().Set("server", name)
+			rw.WriteHeader(http.StatusNoContent)
+		}), dynamic.Server{})
+	}
+
+	recorder := httptest.NewRecorder()
+	hedger.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Zero(t, metrics.hedges)
+}
+
+func TestHedger_DownServer(t *testing.T) {
+	metrics := &metricsMock{}
+
+	hedger, err := New(wrr.New(nil, false), &dynamic.Hedging{MaxRatio: 1}, "test", metrics)
+	require.NoError(t, err)
+
+	for _, name := range []string{"first", "second", "third"} {
+		hedger.AddServer(name, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if name != "third" {
+				time.Sleep(50 * time.Millisecond)
+			}
+			rw.Header().Set("server", name)
+			rw.WriteHeader(http.StatusOK)
+		}), dynamic.Server{})
+	}
+
+	hedger.SetStatus(t.Context(), "third", false)
+
+	for range 4 {
+		recorder := httptest.NewRecorder()
+		hedger.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.NotEqual(t, "third", recorder.Header().Get("server"))
+	}
+
+	assert.InDelta(t, 4, metrics.hedges, 0)
+}
@@ -6,11 +6,13 @@ import (
 	"hash/fnv"
 	"math"
 	"net/http"
+	"slices"
 	"sync"
 
 	"github.com/rs/zerolog/log"
 	"github.com/hanzoai/ingress/pkg/config/dynamic"
 	"github.com/hanzoai/ingress/pkg/ip"
+	"github.com/hanzoai/ingress/pkg/server/service/loadbalancer"
 )
 
 var errNoAvailableServer = errors.New("no available server")
@@ -46,6 +48,8 @@ type Balancer struct {
 	updaters []func(bool)
 	// fenced is the list of terminating yet still serving child services.
 	fenced map[string]struct{}
+
+	slowStart *loadbalancer.SlowStart
 }
 
 // New creates a new load balancer.
@@ -87,6 +91,10 @@ func (b *Balancer) SetStatus(ctx context.Context, childName string, up bool) {
 	log.Ctx(ctx).Debug().Msgf("Setting status of %s to %v", childName, status)
 
 	if up {
+		if _, ok := b.status[childName]; !ok {
+			// The recovered server warms up before receiving its full share of the requests.
+			b.slowStart.Start(childName)
+		}
 		b.status[childName] = struct{}{}
 	} else {
 		delete(b.status, childName)
@@ -124,6 +132,13 @@ func (b *Balancer) RegisterStatusUpdater(fn func(up bool)) error {
 	return nil
 }
 
+// SetSlowStart sets the SlowStart ramping up the weight of the recovered servers,
+// and of the new servers whose warm-up is started by the owner of the balancer.
+// Not thread safe.
+func (b *Balancer) SetSlowStart(slowStart *loadbalancer.SlowStart) {
+	b.slowStart = slowStart
+}
+
 func (b *Balancer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	// give ip fetched to b.nextServer
 	clientIP := b.strategy.GetIP(req)
@@ -162,14 +177,43 @@ func (b *Balancer) Add(name string, handler http.Handler, weight *int, fenced bo
 	h := &namedHandler{Handler: handler, name: name, weight: float64(w)}
 
 	b.handlersMu.Lock()
+	upBefore := len(b.status) > 0
 	b.handlers = append(b.handlers, h)
 	b.status[name] = struct{}{}
 	if fenced {
 		b.fenced[name] = struct{}{}
 	}
+	b.propagateStatus(upBefore)
 	b.handlersMu.Unlock()
 }
 
+// RemoveServer removes the handler of the given server.
+func (b *Balancer) RemoveServer(name string) {
+	b.handlersMu.Lock()
+	defer b.handlersMu.Unlock()
+
+	upBefore := len(b.status) > 0
+
+	b.handlers = slices.DeleteFunc(b.handlers, func(h *namedHandler) bool { return h.name == name })
+	delete(b.status, name)
+	delete(b.fenced, name)
+
+	b.propagateStatus(upBefore)
+}
+
+// propagateStatus runs the updaters when the status of the balancer is no longer the given one.
+// It must be called with the handlers lock held.
+func (b *Balancer) propagateStatus(upBefore bool) {
+	upAfter := len(b.status) > 0
+	if upBefore == upAfter {
+		return
+	}
+
+	for _, fn := range b.updaters {
+		fn(upAfter)
+	}
+}
+
 func (b *Balancer) nextServer(ip string) (*namedHandler, error) {
 	b.handlersMu.RLock()
 	var healthy []*namedHandler
@@ -189,7 +233,7 @@ func (b *Balancer) nextServer(ip string) (*namedHandler, error) {
 	var handler *namedHandler
 	score := 0.0
 	for _, h := range healthy {
-		s := getNodeScore(h, ip)
+		s := getNodeScore(h, ip) * b.slowStart.Factor(h.name)
 		if s > score {
 			handler = h
 			score = s
@@ -6,6 +6,7 @@ import (
 	"math"
 	"net/http"
 	"net/http/httptrace"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -73,6 +74,14 @@ func (s *namedHandler) getAvgResponseTime() float64 {
 	return s.responseTimeSum / float64(s.sampleCount)
 }
 
+// appendResponseTimes appends the collected response times, in milliseconds, to the given slice.
+func (s *namedHandler) appendResponseTimes(times []float64) []float64 {
+	s.responseTimeMu.RLock()
+	defer s.responseTimeMu.RUnlock()
+
+	return append(times, s.responseTimes[:s.sampleCount]...)
+}
+
 func (s *namedHandler) getDeadline() float64 {
 	s.deadlineMu.RLock()
 	defer s.deadlineMu.RUnlock()
@@ -106,7 +115,8 @@ type Balancer struct {
 	// No mutex is needed, as it is modified only during the configuration build.
 	updaters []func(bool)
 
-	sticky *loadbalancer.Sticky
+	sticky    *loadbalancer.Sticky
+	slowStart *loadbalancer.SlowStart
 
 	// deadlineMu protects EDF scheduling state (curDeadline and all handler deadline fields).
 	// Separate from handlersMu to reduce lock contention during tie-breaking.
@@ -145,6 +155,10 @@ func (b *Balancer) SetStatus(ctx context.Context, childName string, up bool) {
 	log.Ctx(ctx).Debug().Msgf("Setting status of %s to %v", childName, status)
 
 	if up {
+		if _, ok := b.status[childName]; !ok {
+			// The recovered server warms up before receiving its full share of the requests.
+			b.slowStart.Start(childName)
+		}
 		b.status[childName] = struct{}{}
 	} else {
 		delete(b.status, childName)
@@ -181,6 +195,13 @@ func (b *Balancer) RegisterStatusUpdater(fn func(up bool)) error {
 	return nil
 }
 
+// SetSlowStart sets the SlowStart ramping up the weight of the recovered servers,
+// and of the new servers whose warm-up is started by the owner of the balancer.
+// Not thread safe.
+func (b *Balancer) SetSlowStart(slowStart *loadbalancer.SlowStart) {
+	b.slowStart = slowStart
+}
+
 func (b *Balancer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	// Handle sticky sessions first.
 	if b.sticky != nil {
@@ -235,6 +256,29 @@ func (b *Balancer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	server.ServeHTTP(rw, req.WithContext(traceCtx))
 }
 
+// ResponseTimePercentile returns the given percentile of the response times (TTFB) observed on all the servers,
+// and false if no response time has been observed yet.
+func (b *Balancer) ResponseTimePercentile(percentile float64) (time.Duration, bool) {
+	b.handlersMu.RLock()
+	var times []float64
+	for _, h := range b.handlers {
+		times = h.appendResponseTimes(times)
+	}
+	b.handlersMu.RUnlock()
+
+	if len(times) == 0 {
+		return 0, false
+	}
+
+	slices.Sort(times)
+
+	// Nearest-rank method.
+	rank := int(math.Ceil(percentile / 100 * float64(len(times))))
+	rank = min(max(rank, 1), len(times))
+
+	return time.Duration(times[rank-1] * float64(time.Millisecond)), true
+}
+
 // AddServer adds a handler with a server.
 func (b *Balancer) AddServer(name string, handler http.Handler, server dynamic.Server) {
 	b.Add(name, handler, server.Weight, server.Fenced)
@@ -269,11 +313,13 @@ func (b *Balancer) Add(name string, handler http.Handler, weight *int, fenced bo
 	b.curDeadlineMu.Unlock()
 
 	b.handlersMu.Lock()
+	upBefore := len(b.status) > 0
 	b.handlers = append(b.handlers, h)
 	b.status[name] = struct{}{}
 	if fenced {
 		b.fenced[name] = struct{}{}
 	}
+	b.propagateStatus(upBefore)
 	b.handlersMu.Unlock()
 
 	if b.sticky != nil {
@@ -281,6 +327,37 @@ func (b *Balancer) Add(name string, handler http.Handler, weight *int, fenced bo
 	}
 }
 
+// RemoveServer removes the handler of the given server.
+func (b *Balancer) RemoveServer(name string) {
+	if b.sticky != nil {
+		b.sticky.RemoveHandler(name)
+	}
+
+	b.handlersMu.Lock()
+	defer b.handlersMu.Unlock()
+
+	upBefore := len(b.status) > 0
+
+	b.handlers = slices.DeleteFunc(b.handlers, func(h *namedHandler) bool { return h.name == name })
+	delete(b.status, name)
+	delete(b.fenced, name)
+
+	b.propagateStatus(upBefore)
+}
+
+// propagateStatus runs the updaters when the status of the balancer is no longer the given one.
+// It must be called with the handlers lock held.
+func (b *Balancer) propagateStatus(upBefore bool) {
+	upAfter := len(b.status) > 0
+	if upBefore == upAfter {
+		return
+	}
+
+	for _, fn := range b.updaters {
+		fn(upAfter)
+	}
+}
+
 // getHealthyServers returns the list of healthy, non-fenced servers.
 func (b *Balancer) getHealthyServers() []*namedHandler {
 	b.handlersMu.RLock()
@@ -318,7 +395,7 @@ func (b *Balancer) selectWRR(candidates []*namedHandler) *namedHandler {
 
 	// Update deadline based on when this server was selected (minDeadline),
 	// not the global curDeadline. This ensures proper weighted distribution.
-	newDeadline := minDeadline + 1/selected.weight
+	newDeadline := minDeadline + 1/(selected.weight*b.slowStart.Factor(selected.name))
 	selected.setDeadline(newDeadline)
 
 	// Track the maximum deadline assigned for initializing new servers.
@@ -350,7 +427,15 @@ func (b *Balancer) nextServer() (*namedHandler, error) {
 	for _, h := range healthy {
 		avgRT := h.getAvgResponseTime()
 		inflight := float64(h.inflightCount.Load())
-		score := (avgRT * (1 + inflight)) / h.weight
+
+		factor := b.slowStart.Factor(h.name)
+		if factor < 1 && avgRT == 0 {
+			// A warming up server without measured response time is scored on its inflight requests,
+			// not to receive all the requests until its first response.
+			avgRT = 1
+		}
+
+		score := (avgRT * (1 + inflight)) / (h.weight * factor)
 
 		if score < minScore {
 			minScore = score
@@ -11,7 +11,10 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/hanzoai/ingress-parser/types"
 	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/server/service/loadbalancer"
 )
 
 type key string
@@ -755,6 +758,34 @@ func TestZeroSamplesReturnsZero(t *testing.T) {
 	assert.InDelta(t, 0.0, avg, 0)
 }
 
+// TestResponseTimePercentile tests that the percentile is computed over the response times of all the servers.
+func TestResponseTimePercentile(t *testing.T) {
+	balancer := New(nil, false)
+
+	_, ok := balancer.ResponseTimePercentile(90)
+	assert.False(t, ok)
+
+	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), nil, false)
+	balancer.Add("second", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), nil, false)
+
+	for i := 1; i <= 5; i++ {
+		balancer.handlers[0].updateResponseTime(time.Duration(i) * time.Millisecond)
+		balancer.handlers[1].updateResponseTime(time.Duration(i+5) * time.Millisecond)
+	}
+
+	percentile, ok := balancer.ResponseTimePercentile(90)
+	assert.True(t, ok)
+	assert.Equal(t, 9*time.Millisecond, percentile)
+
+	percentile, ok = balancer.ResponseTimePercentile(100)
+	assert.True(t, ok)
+	assert.Equal(t, 10*time.Millisecond, percentile)
+
+	percentile, ok = balancer.ResponseTimePercentile(50)
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Millisecond, percentile)
+}
+
 // TestScoreCalculationWithWeights tests that weights are properly considered in score calculation.
 func TestScoreCalculationWithWeights(t *testing.T) {
 	balancer := New(nil, false)
@@ -883,6 +914,44 @@ func TestScoreCalculationColdStart(t *testing.T) {
 	assert.Greater(t, recorder.save["cold"], recorder.save["warm"])
 }
 
+func TestScoreCalculationSlowStart(t *testing.T) {
+	balancer := New(nil, false)
+
+	balancer.Add("warm", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		rw.Header().Set("server", "warm")
+		rw.WriteHeader(http.StatusOK)
+		httptrace.ContextClientTrace(req.Context()).GotFirstResponseByte()
+	}), pointer(1), false)
+
+	for range 5 {
+		recorder := httptest.NewRecorder()
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	slowStart, err := loadbalancer.NewSlowStart(&dynamic.SlowStart{Window: ptypes.Duration(time.Hour), MinWeightPercent: 10})
+	require.NoError(t, err)
+	balancer.SetSlowStart(slowStart)
+
+	// The new server is faster, but warms up with a tenth of its weight.
+	slowStart.Start("cold")
+	balancer.Add("cold", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		rw.Header().Set("server", "cold")
+		rw.WriteHeader(http.StatusOK)
+		httptrace.ContextClientTrace(req.Context()).GotFirstResponseByte()
+	}), pointer(1), false)
+
+	// Score for warm: (20 × (1 + 0)) / 1 = 20
+	// Score for cold: (10 × (1 + 0)) / (1 × 0.1) = 100
+	recorder := &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	for range 20 {
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	assert.Greater(t, recorder.save["warm"], recorder.save["cold"])
+}
+
 // TestFastServerGetsMoreTraffic verifies that servers with lower response times
 // receive proportionally more traffic in steady state (after cold start).
 // This tests the core selection bias of the least-time algorithm.
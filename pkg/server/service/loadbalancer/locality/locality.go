@@ -0,0 +1,269 @@
+package locality
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/healthcheck"
+)
+
+// Balancer is the load-balancer of the servers of a zone.
+type Balancer interface {
+	http.Handler
+	healthcheck.StatusSetter
+	healthcheck.StatusUpdater
+
+	AddServer(name string, handler http.Handler, server dynamic.Server)
+	RemoveServer(name string)
+}
+
+// latencyTracker is implemented by the load-balancers measuring the response times of their servers.
+type latencyTracker interface {
+	ResponseTimePercentile(percentile float64) (time.Duration, bool)
+}
+
+type server struct {
+	local bool
+	// available is false for the servers which never receive new requests: the fenced servers,
+	// and the servers with a non-positive weight.
+	available bool
+	up        bool
+}
+
+// ZoneAware forwards the requests to the load-balancer of the servers of the zone of the instance,
+// and spills them over to the load-balancer of the servers of the other zones
+// when not enough local servers are healthy, or when they are overloaded.
+type ZoneAware struct {
+	wantsHealthCheck     bool
+	zone                 string
+	minHealthyRatio      float64
+	maxInFlightPerServer int64
+
+	local  Balancer
+	remote Balancer
+
+	localInFlight atomic.Int64
+
+	// mu protects the servers, their counts, and the status.
+	mu         sync.RWMutex
+	servers    map[string]*server
+	localTotal int
+	localUp    int
+	remoteUp   int
+	// updaters is the list of hooks that are run (to update the parent(s)), whenever the status changes.
+	updaters []func(bool)
+}
+
+// New creates a new ZoneAware load-balancer, forwarding the requests to the given local load-balancer
+// for the servers of the given zone, and to the given remote load-balancer for the other servers.
+func New(zone string, config *dynamic.Locality, local, remote Balancer, wantsHealthCheck bool) (*ZoneAware, error) {
+	if zone == "" {
+		return nil, errors.New("locality requires the zone of the instance")
+	}
+
+	if config.MinHealthyPercent <= 0 || config.MinHealthyPercent > 100 {
+		return nil, errors.New("locality minHealthyPercent must be between 1 and 100")
+	}
+
+	if config.MaxInFlightPerServer < 0 {
+		return nil, errors.New("locality maxInFlightPerServer must be positive")
+	}
+
+	return &ZoneAware{
+		wantsHealthCheck:     wantsHealthCheck,
+		zone:                 zone,
+		minHealthyRatio:      float64(config.MinHealthyPercent) / 100,
+		maxInFlightPerServer: int64(config.MaxInFlightPerServer),
+		local:                local,
+		remote:               remote,
+		servers:              make(map[string]*server),
+	}, nil
+}
+
+// SetStatus sets on the balancer that its given child is now of the given status.
+func (z *ZoneAware) SetStatus(ctx context.Context, childName string, up bool) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	s, ok := z.servers[childName]
+	if !ok {
+		log.Ctx(ctx).Debug().Msgf("Unknown server %s", childName)
+		return
+	}
+
+	if s.local {
+		z.local.SetStatus(ctx, childName, up)
+	} else {
+		z.remote.SetStatus(ctx, childName, up)
+	}
+
+	if !s.available || s.up == up {
+		return
+	}
+
+	upBefore := z.localUp+z.remoteUp > 0
+
+	s.up = up
+	delta := 1
+	if !up {
+		delta = -1
+	}
+	if s.local {
+		z.localUp += delta
+	} else {
+		z.remoteUp += delta
+	}
+
+	z.propagateStatus(upBefore)
+}
+
+// RegisterStatusUpdater adds fn to the list of hooks that are run when the status of the load-balancer changes.
+// Not thread safe.
+func (z *ZoneAware) RegisterStatusUpdater(fn func(up bool)) error {
+	if !z.wantsHealthCheck {
+		return errors.New("healthCheck not enabled in config for this zone-aware service")
+	}
+
+	z.updaters = append(z.updaters, fn)
+	return nil
+}
+
+// ResponseTimePercentile returns the given percentile of the response times observed on the servers of the zone,
+// when their load-balancer measures them.
+func (z *ZoneAware) ResponseTimePercentile(percentile float64) (time.Duration, bool) {
+	if tracker, ok := z.local.(latencyTracker); ok {
+		if d, ok := tracker.ResponseTimePercentile(percentile); ok {
+			return d, true
+		}
+	}
+
+	if tracker, ok := z.remote.(latencyTracker); ok {
+		return tracker.ResponseTimePercentile(percentile)
+	}
+
+	return 0, false
+}
+
+// AddServer adds a handler with a server.
+// The servers without zone are considered to be in another zone than the instance.
+func (z *ZoneAware) AddServer(name string, handler http.Handler, srv dynamic.Server) {
+	s := &server{
+		local:     srv.Zone == z.zone,
+		available: !srv.Fenced && (srv.Weight == nil || *srv.Weight > 0),
+	}
+	// Servers are considered UP by default.
+	s.up = s.available
+
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	upBefore := z.localUp+z.remoteUp > 0
+
+	z.servers[name] = s
+	if s.available {
+		if s.local {
+			z.localTotal++
+			z.localUp++
+		} else {
+			z.remoteUp++
+		}
+	}
+
+	if s.local {
+		z.local.AddServer(name, handler, srv)
+	} else {
+		z.remote.AddServer(name, handler, srv)
+	}
+
+	z.propagateStatus(upBefore)
+}
+
+// RemoveServer removes the handler of the given server.
+func (z *ZoneAware) RemoveServer(name string) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	s, ok := z.servers[name]
+	if !ok {
+		return
+	}
+
+	upBefore := z.localUp+z.remoteUp > 0
+
+	delete(z.servers, name)
+	if s.available {
+		if s.local {
+			z.localTotal--
+		}
+
+		switch {
+		case s.up && s.local:
+			z.localUp--
+		case s.up:
+			z.remoteUp--
+		}
+	}
+
+	if s.local {
+		z.local.RemoveServer(name)
+	} else {
+		z.remote.RemoveServer(name)
+	}
+
+	z.propagateStatus(upBefore)
+}
+
+// propagateStatus runs the updaters when the status of the load-balancer is no longer the given one.
+// It must be called with the lock held.
+func (z *ZoneAware) propagateStatus(upBefore bool) {
+	upAfter := z.localUp+z.remoteUp > 0
+	if upBefore == upAfter {
+		return
+	}
+
+	for _, fn := range z.updaters {
+		fn(upAfter)
+	}
+}
+
+func (z *ZoneAware) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if !z.serveLocally() {
+		z.remote.ServeHTTP(rw, req)
+		return
+	}
+
+	z.localInFlight.Add(1)
+	defer z.localInFlight.Add(-1)
+
+	z.local.ServeHTTP(rw, req)
+}
+
+// serveLocally reports whether the request is to be forwarded to the servers of the zone.
+func (z *ZoneAware) serveLocally() bool {
+	z.mu.RLock()
+	localTotal, localUp, remoteUp := z.localTotal, z.localUp, z.remoteUp
+	z.mu.RUnlock()
+
+	if localUp == 0 {
+		return false
+	}
+
+	if remoteUp == 0 {
+		return true
+	}
+
+	// Below the minimum ratio of healthy servers, the share of the requests of the zone decreases in proportion.
+	share := float64(localUp) / float64(localTotal) / z.minHealthyRatio
+	if share < 1 && rand.Float64() >= share {
+		return false
+	}
+
+	return z.maxInFlightPerServer == 0 || z.localInFlight.Load() < z.maxInFlightPerServer*int64(localUp)
+}
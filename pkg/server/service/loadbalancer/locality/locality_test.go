@@ -0,0 +1,197 @@
+package locality
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/server/service/loadbalancer/wrr"
+)
+
+func newConfig() *dynamic.Locality {
+	config := &dynamic.Locality{}
+	config.SetDefaults()
+	return config
+}
+
+func newZoneAware(t *testing.T, config *dynamic.Locality, zones map[string]string) *ZoneAware {
+	t.Helper()
+
+	zoneAware, err := New("zone-a", config, wrr.New(nil, true), wrr.New(nil, true), true)
+	require.NoError(t, err)
+
+	for name, zone := range zones {
+		zoneAware.AddServer(name, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.Header().Set("server", name)
+			rw.WriteHeader(http.StatusOK)
+		}), dynamic.Server{Zone: zone})
+	}
+
+	return zoneAware
+}
+
+func serve(zoneAware *ZoneAware, requests int) map[string]int {
+	servers := make(map[string]int)
+	for range requests {
+		recorder := httptest.NewRecorder()
+		zoneAware.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		servers[recorder.Header().Get("server")]++
+	}
+
+	return servers
+}
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		zone    string
+		config  func(config *dynamic.Locality)
+		wantErr bool
+	}{
+		{
+			desc:   "defaults",
+			zone:   "zone-a",
+			config: func(config *dynamic.Locality) {},
+		},
+		{
+			desc:    "no zone",
+			config:  func(config *dynamic.Locality) {},
+			wantErr: true,
+		},
+		{
+			desc: "invalid min healthy percent",
+			zone: "zone-a",
+			config: func(config *dynamic.Locality) {
+				config.MinHealthyPercent = 0
+			},
+			wantErr: true,
+		},
+		{
+			desc: "negative max in-flight requests",
+			zone: "zone-a",
+			config: func(config *dynamic.Locality) {
+				config.MaxInFlightPerServer = -1
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := newConfig()
+			test.config(config)
+
+			_, err := New(test.zone, config, wrr.New(nil, false), wrr.New(nil, false), false)
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestZoneAware_PrefersLocalServers(t *testing.T) {
+	zoneAware := newZoneAware(t, newConfig(), map[string]string{
+		"first":  "zone-a",
+		"second": "zone-a",
+		"third":  "zone-b",
+		"fourth": "",
+	})
+
+	assert.Equal(t, map[string]int{"first": 5, "second": 5}, serve(zoneAware, 10))
+}
+
+func TestZoneAware_SpillsOverUnhealthyLocalServers(t *testing.T) {
+	zoneAware := newZoneAware(t, newConfig(), map[string]string{
+		"first":  "zone-a",
+		"second": "zone-a",
+		"third":  "zone-a",
+		"fourth": "zone-a",
+		"remote": "zone-b",
+	})
+
+	// With 3 of the 4 local servers healthy, the local servers are above the 70% minimum.
+	zoneAware.SetStatus(t.Context(), "first", false)
+	assert.Zero(t, serve(zoneAware, 100)["remote"])
+
+	// With 2 of the 4 local servers healthy, about 50/70 of the requests stay local.
+	zoneAware.SetStatus(t.Context(), "second", false)
+	servers := serve(zoneAware, 1000)
+	assert.InDelta(t, 1000*(1-0.5/0.7), servers["remote"], 60)
+	assert.InDelta(t, 1000*0.5/0.7, servers["third"]+servers["fourth"], 60)
+
+	// Without healthy local servers, all the requests spill over.
+	zoneAware.SetStatus(t.Context(), "third", false)
+	zoneAware.SetStatus(t.Context(), "fourth", false)
+	assert.Equal(t, map[string]int{"remote": 10}, serve(zoneAware, 10))
+}
+
+func TestZoneAware_NoHealthyRemoteServers(t *testing.T) {
+	zoneAware := newZoneAware(t, newConfig(), map[string]string{
+		"first":  "zone-a",
+		"second": "zone-a",
+		"remote": "zone-b",
+	})
+
+	zoneAware.SetStatus(t.Context(), "first", false)
+	zoneAware.SetStatus(t.Context(), "remote", false)
+
+	assert.Equal(t, map[string]int{"second": 10}, serve(zoneAware, 10))
+}
+
+func TestZoneAware_SpillsOverLoadedLocalServers(t *testing.T) {
+	config := newConfig()
+	config.MaxInFlightPerServer = 1
+
+	zoneAware, err := New("zone-a", config, wrr.New(nil, false), wrr.New(nil, false), false)
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	zoneAware.AddServer("local", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		started <- struct{}{}
+		<-release
+		rw.Header().Set("server", "local")
+	}), dynamic.Server{Zone: "zone-a"})
+	zoneAware.AddServer("remote", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "remote")
+	}), dynamic.Server{Zone: "zone-b"})
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		zoneAware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+	<-started
+
+	assert.Equal(t, map[string]int{"remote": 1}, serve(zoneAware, 1))
+
+	close(release)
+	wg.Wait()
+}
+
+func TestZoneAware_Status(t *testing.T) {
+	zoneAware := newZoneAware(t, newConfig(), map[string]string{
+		"local":  "zone-a",
+		"remote": "zone-b",
+	})
+
+	var statuses []bool
+	require.NoError(t, zoneAware.RegisterStatusUpdater(func(up bool) {
+		statuses = append(statuses, up)
+	}))
+
+	zoneAware.SetStatus(t.Context(), "local", false)
+	zoneAware.SetStatus(t.Context(), "remote", false)
+	zoneAware.SetStatus(t.Context(), "remote", false)
+	zoneAware.SetStatus(t.Context(), "local", true)
+
+	assert.Equal(t, []bool{false, true}, statuses)
+}
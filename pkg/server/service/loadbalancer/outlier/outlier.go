@@ -0,0 +1,401 @@
+package outlier
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/healthcheck"
+	"github.com/rs/zerolog/log"
+)
+
+// Balancer is the load-balancer from which the outlier servers are ejected.
+type Balancer interface {
+	http.Handler
+	healthcheck.StatusSetter
+	healthcheck.StatusUpdater
+
+	AddServer(name string, handler http.Handler, server dynamic.Server)
+	RemoveServer(name string)
+}
+
+type metricsOutlier interface {
+	ServiceServerUpGauge() gokitmetrics.Gauge
+}
+
+// server holds the statistics of a server over the current interval, and its ejection state.
+type server struct {
+	name string
+
+	// healthy is the status of the server set by the health checks.
+	healthy bool
+
+	ejected      bool
+	ejectedUntil time.Time
+	ejections    int
+
+	requests int
+	failures int
+	latency  time.Duration
+}
+
+// Detector ejects from the load-balancer the servers whose success rate or latency deviates from the other servers,
+// and puts them back once their ejection time has elapsed.
+type Detector struct {
+	balancer    Balancer
+	serviceName string
+	metrics     metricsOutlier
+
+	interval               time.Duration
+	baseEjectionTime       time.Duration
+	maxEjectionTime        time.Duration
+	maxEjectionPercent     int
+	minimumHosts           int
+	requestVolume          int
+	successRateStdevFactor float64
+	latencyStdevFactor     float64
+
+	nextAnalysis atomic.Int64
+
+	// mu protects the servers and their statistics.
+	mu      sync.Mutex
+	servers []*server
+	byName  map[string]*server
+}
+
+// New creates a new Detector ejecting the outlier servers from the given load-balancer.
+func New(balancer Balancer, config *dynamic.OutlierDetection, serviceName string, metrics metricsOutlier) (*Detector, error) {
+	if config.Interval <= 0 {
+		return nil, errors.New("outlier detection interval must be greater than zero")
+	}
+
+	if config.BaseEjectionTime <= 0 {
+		return nil, errors.New("outlier detection baseEjectionTime must be greater than zero")
+	}
+
+	if config.MaxEjectionTime < config.BaseEjectionTime {
+		return nil, errors.New("outlier detection maxEjectionTime must be greater than or equal to baseEjectionTime")
+	}
+
+	if config.MaxEjectionPercent < 0 || config.MaxEjectionPercent > 100 {
+		return nil, errors.New("outlier detection maxEjectionPercent must be between 0 and 100")
+	}
+
+	if config.SuccessRateStdevFactor < 0 || config.LatencyStdevFactor < 0 {
+		return nil, errors.New("outlier detection standard deviation factors must be positive")
+	}
+
+	d := &Detector{
+		balancer:               balancer,
+		serviceName:            serviceName,
+		metrics:                metrics,
+		interval:               time.Duration(config.Interval),
+		baseEjectionTime:       time.Duration(config.BaseEjectionTime),
+		maxEjectionTime:        time.Duration(config.MaxEjectionTime),
+		maxEjectionPercent:     config.MaxEjectionPercent,
+		minimumHosts:           config.MinimumHosts,
+		requestVolume:          config.RequestVolume,
+		successRateStdevFactor: config.SuccessRateStdevFactor,
+		latencyStdevFactor:     config.LatencyStdevFactor,
+		byName:                 make(map[string]*server),
+	}
+	d.nextAnalysis.Store(time.Now().Add(d.interval).UnixNano())
+
+	return d, nil
+}
+
+// SetStatus sets on the balancer that its given child is now of the given status.
+// An ejected server is kept down until the end of its ejection.
+func (d *Detector) SetStatus(ctx context.Context, childName string, up bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s := d.server(childName)
+	s.healthy = up
+
+	d.balancer.SetStatus(ctx, childName, up && !s.ejected)
+}
+
+// RegisterStatusUpdater adds fn to the list of hooks that are run when the status of the load-balancer changes.
+func (d *Detector) RegisterStatusUpdater(fn func(up bool)) error {
+	return d.balancer.RegisterStatusUpdater(fn)
+}
+
+// AddServer adds a handler with a server.
+func (d *Detector) AddServer(name string, handler http.Handler, srv dynamic.Server) {
+	d.mu.Lock()
+	d.server(name)
+	d.mu.Unlock()
+
+	d.balancer.AddServer(name, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// The trace hooks are called by the transport, possibly from another goroutine.
+		var backendCalled atomic.Bool
+		trace := &httptrace.ClientTrace{
+			WroteHeaders: func() {
+				backendCalled.Store(true)
+			},
+			WroteRequest: func(httptrace.WroteRequestInfo) {
+				backendCalled.Store(true)
+			},
+		}
+
+		recorder := &responseRecorder{ResponseWriter: rw, start: time.Now()}
+		handler.ServeHTTP(recorder, req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+
+		latency := recorder.latency
+		if latency == 0 {
+			latency = time.Since(recorder.start)
+		}
+
+		d.record(name, !backendCalled.Load() || recorder.statusCode >= http.StatusInternalServerError, latency)
+	}), srv)
+}
+
+// RemoveServer removes the handler of the given server, and forgets its statistics and ejections.
+func (d *Detector) RemoveServer(name string) {
+	d.mu.Lock()
+	if s, ok := d.byName[name]; ok {
+		d.servers = slices.DeleteFunc(d.servers, func(other *server) bool { return other == s })
+		delete(d.byName, name)
+	}
+	d.mu.Unlock()
+
+	d.balancer.RemoveServer(name)
+}
+
+func (d *Detector) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if now := time.Now(); now.UnixNano() >= d.nextAnalysis.Load() {
+		d.analyze(req.Context(), now)
+	}
+
+	d.balancer.ServeHTTP(rw, req)
+}
+
+// server returns the server of the given name, and creates it if needed.
+// It must be called with the lock held.
+func (d *Detector) server(name string) *server {
+	if s, ok := d.byName[name]; ok {
+		return s
+	}
+
+	s := &server{name: name, healthy: true}
+	d.servers = append(d.servers, s)
+	d.byName[name] = s
+
+	return s
+}
+
+func (d *Detector) record(name string, failed bool, latency time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// The server might have been removed while the request was in flight.
+	s, ok := d.byName[name]
+	if !ok {
+		return
+	}
+
+	s.requests++
+	s.latency += latency
+	if failed {
+		s.failures++
+	}
+}
+
+// analyze puts back the servers whose ejection time has elapsed, ejects the outlier servers of the last interval,
+// and resets the statistics for the next interval.
+func (d *Detector) analyze(ctx context.Context, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Another request has already run the analysis.
+	if now.UnixNano() < d.nextAnalysis.Load() {
+		return
+	}
+	d.nextAnalysis.Store(now.Add(d.interval).UnixNano())
+
+	logger := log.Ctx(ctx)
+
+	ejected := 0
+	putBack := make(map[*server]struct{})
+	for _, s := range d.servers {
+		if !s.ejected {
+			continue
+		}
+
+		if now.Before(s.ejectedUntil) {
+			ejected++
+			continue
+		}
+
+		s.ejected = false
+		putBack[s] = struct{}{}
+		logger.Debug().Msgf("Putting back outlier server %s", s.name)
+
+		d.balancer.SetStatus(ctx, s.name, s.healthy)
+		if s.healthy {
+			d.setServerUp(s.name, 1)
+		}
+	}
+
+	var candidates []*server
+	for _, s := range d.servers {
+		if !s.ejected && s.healthy && s.requests > 0 && s.requests >= d.requestVolume {
+			candidates = append(candidates, s)
+		}
+	}
+
+	maxEjected := max(1, len(d.servers)*d.maxEjectionPercent/100)
+
+	if len(candidates) > 0 && len(candidates) >= d.minimumHosts {
+		var outliers []*server
+
+		if d.successRateStdevFactor > 0 {
+			outliers = append(outliers, deviating(candidates, successRate, -d.successRateStdevFactor)...)
+		}
+
+		if d.latencyStdevFactor > 0 {
+			outliers = append(outliers, deviating(candidates, averageLatency, d.latencyStdevFactor)...)
+		}
+
+		for _, s := range outliers {
+			if ejected >= maxEjected {
+				break
+			}
+
+			if s.ejected {
+				continue
+			}
+
+			d.eject(ctx, s, now)
+			ejected++
+		}
+	}
+
+	for _, s := range d.servers {
+		// The ejection time of a server decreases back for each interval it has not been ejected during.
+		_, wasEjected := putBack[s]
+		if !s.ejected && !wasEjected && s.ejections > 0 {
+			s.ejections--
+		}
+
+		s.requests = 0
+		s.failures = 0
+		s.latency = 0
+	}
+}
+
+func (d *Detector) eject(ctx context.Context, s *server, now time.Time) {
+	s.ejections++
+
+	// The ejection time doubles with each consecutive ejection of the server.
+	ejectionTime := d.maxEjectionTime
+	if s.ejections <= 32 {
+		ejectionTime = min(d.baseEjectionTime<<(s.ejections-1), d.maxEjectionTime)
+	}
+
+	s.ejected = true
+	s.ejectedUntil = now.Add(ejectionTime)
+
+	log.Ctx(ctx).Debug().Msgf("Ejecting outlier server %s for %s", s.name, ejectionTime)
+
+	d.balancer.SetStatus(ctx, s.name, false)
+	d.setServerUp(s.name, 0)
+}
+
+func (d *Detector) setServerUp(name string, value float64) {
+	if d.metrics != nil {
+		d.metrics.ServiceServerUpGauge().With("service", d.serviceName, "url", name).Set(value)
+	}
+}
+
+func successRate(s *server) float64 {
+	return float64(s.requests-s.failures) / float64(s.requests)
+}
+
+func averageLatency(s *server) float64 {
+	return float64(s.latency) / float64(s.requests)
+}
+
+// deviating returns the servers whose value deviates from the mean of all the servers values by more than
+// factor times their standard deviation, below the mean for a negative factor and above otherwise.
+func deviating(servers []*server, value func(*server) float64, factor float64) []*server {
+	var sum float64
+	for _, s := range servers {
+		sum += value(s)
+	}
+	mean := sum / float64(len(servers))
+
+	var variance float64
+	for _, s := range servers {
+		variance += math.Pow(value(s)-mean, 2)
+	}
+	threshold := mean + factor*math.Sqrt(variance/float64(len(servers)))
+
+	var outliers []*server
+	for _, s := range servers {
+		if (factor < 0 && value(s) < threshold) || (factor > 0 && value(s) > threshold) {
+			outliers = append(outliers, s)
+		}
+	}
+
+	return outliers
+}
+
+// responseRecorder records the status code of the response and the time it took to be written.
+type responseRecorder struct {
+	http.ResponseWriter
+
+	start      time.Time
+	statusCode int
+	latency    time.Duration
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	// The informational responses are not the final response of the server.
+	if statusCode >= http.StatusContinue && statusCode < http.StatusOK {
+		r.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+
+	if r.statusCode == 0 {
+		r.statusCode = statusCode
+		r.latency = time.Since(r.start)
+	}
+
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.statusCode == 0 {
+		r.statusCode = http.StatusOK
+		r.latency = time.Since(r.start)
+	}
+
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := r.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+
+	return nil, nil, fmt.Errorf("not a hijacker: %T", r.ResponseWriter)
+}
@@ -0,0 +1,242 @@
+package outlier
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/hanzoai/ingress-parser/types"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/server/service/loadbalancer/wrr"
+)
+
+type balancerMock struct {
+	Balancer
+
+	status map[string]bool
+}
+
+func (b *balancerMock) SetStatus(_ context.Context, childName string, up bool) {
+	b.status[childName] = up
+}
+
+func (b *balancerMock) AddServer(name string, _ http.Handler, _ dynamic.Server) {
+	b.status[name] = true
+}
+
+func newConfig() *dynamic.OutlierDetection {
+	config := &dynamic.OutlierDetection{}
+	config.SetDefaults()
+	return config
+}
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		config  func(config *dynamic.OutlierDetection)
+		wantErr bool
+	}{
+		{
+			desc:   "defaults",
+			config: func(config *dynamic.OutlierDetection) {},
+		},
+		{
+			desc: "invalid interval",
+			config: func(config *dynamic.OutlierDetection) {
+				config.Interval = 0
+			},
+			wantErr: true,
+		},
+		{
+			desc: "invalid base ejection time",
+			config: func(config *dynamic.OutlierDetection) {
+				config.BaseEjectionTime = 0
+			},
+			wantErr: true,
+		},
+		{
+			desc: "max ejection time lower than base ejection time",
+			config: func(config *dynamic.OutlierDetection) {
+				config.MaxEjectionTime = ptypes.Duration(time.Second)
+			},
+			wantErr: true,
+		},
+		{
+			desc: "invalid max ejection percent",
+			config: func(config *dynamic.OutlierDetection) {
+				config.MaxEjectionPercent = 101
+			},
+			wantErr: true,
+		},
+		{
+			desc: "negative standard deviation factor",
+			config: func(config *dynamic.OutlierDetection) {
+				config.LatencyStdevFactor = -1
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := newConfig()
+			test.config(config)
+
+			_, err := New(wrr.New(nil, false), config, "test", nil)
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestDetector_SuccessRate(t *testing.T) {
+	config := newConfig()
+	config.RequestVolume = 10
+
+	detector, err := New(wrr.New(nil, false), config, "test", nil)
+	require.NoError(t, err)
+
+	for _, name := range []string{"first", "second", "third", "fourth", "failing"} {
+		detector.AddServer(name, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			// Like the proxy, the request is reported as sent to the backend.
+			httptrace.ContextClientTrace(req.Context()).WroteHeaders()
+
+			rw.Header().Set("server", name)
+			if name == "failing" {
+				rw.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			rw.WriteHeader(http.StatusOK)
+		}), dynamic.Server{})
+	}
+
+	for range 50 {
+		detector.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	now := time.Now().Add(time.Duration(config.Interval))
+	detector.analyze(t.Context(), now)
+
+	for range 16 {
+		recorder := httptest.NewRecorder()
+		detector.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.NotEqual(t, "failing", recorder.Header().Get("server"))
+	}
+
+	// The failing server is put back once its ejection time has elapsed.
+	detector.analyze(t.Context(), now.Add(time.Duration(config.BaseEjectionTime)))
+
+	servers := make(map[string]int)
+	for range 10 {
+		recorder := httptest.NewRecorder()
+		detector.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		servers[recorder.Header().Get("server")]++
+	}
+
+	assert.Equal(t, 2, servers["failing"])
+}
+
+func TestDetector_Latency(t *testing.T) {
+	config := newConfig()
+	config.SuccessRateStdevFactor = 0
+	config.LatencyStdevFactor = 1
+	config.MinimumHosts = 3
+	config.RequestVolume = 1
+
+	balancer := &balancerMock{status: make(map[string]bool)}
+	detector, err := New(balancer, config, "test", nil)
+	require.NoError(t, err)
+
+	for _, name := range []string{"first", "second", "third", "slow"} {
+		detector.AddServer(name, nil, dynamic.Server{})
+	}
+
+	detector.record("first", false, 10*time.Millisecond)
+	detector.record("second", false, 12*time.Millisecond)
+	detector.record("third", false, 11*time.Millisecond)
+	detector.record("slow", false, time.Second)
+
+	detector.analyze(t.Context(), time.Now().Add(time.Duration(config.Interval)))
+
+	assert.Equal(t, map[string]bool{"first": true, "second": true, "third": true, "slow": false}, balancer.status)
+}
+
+func TestDetector_Ejection(t *testing.T) {
+	config := newConfig()
+	config.MinimumHosts = 3
+	config.RequestVolume = 1
+	config.MaxEjectionPercent = 50
+
+	balancer := &balancerMock{status: make(map[string]bool)}
+	detector, err := New(balancer, config, "test", nil)
+	require.NoError(t, err)
+
+	for _, name := range []string{"first", "second", "third", "fourth", "fifth", "sixth"} {
+		detector.AddServer(name, nil, dynamic.Server{})
+	}
+
+	now := time.Now()
+	analyze := func(failing ...string) {
+		for _, s := range detector.servers {
+			if !s.ejected {
+				detector.record(s.name, false, time.Millisecond)
+			}
+		}
+		for _, name := range failing {
+			detector.record(name, true, time.Millisecond)
+			detector.record(name, true, time.Millisecond)
+		}
+
+		now = now.Add(time.Duration(config.Interval))
+		detector.analyze(t.Context(), now)
+	}
+
+	// Not enough servers have received requests.
+	detector.record("first", true, time.Millisecond)
+	detector.record("second", false, time.Millisecond)
+	now = now.Add(time.Duration(config.Interval))
+	detector.analyze(t.Context(), now)
+	assert.True(t, balancer.status["first"])
+
+	analyze("first")
+	assert.False(t, balancer.status["first"])
+	assert.Equal(t, now.Add(30*time.Second), detector.byName["first"].ejectedUntil)
+
+	// A server ejected while healthy is put back after its ejection time.
+	now = now.Add(20 * time.Second)
+	analyze()
+	assert.True(t, balancer.status["first"])
+
+	// The ejection time doubles for consecutive ejections.
+	analyze("first")
+	assert.False(t, balancer.status["first"])
+	assert.Equal(t, now.Add(time.Minute), detector.byName["first"].ejectedUntil)
+
+	// The health checks do not put back an ejected server.
+	detector.SetStatus(t.Context(), "first", true)
+	assert.False(t, balancer.status["first"])
+
+	// No more than the maximum percentage of the servers can be ejected.
+	detector.successRateStdevFactor = 0.5
+	analyze("second", "third", "fourth")
+	ejected := 0
+	for _, up := range balancer.status {
+		if !up {
+			ejected++
+		}
+	}
+	assert.Equal(t, 3, ejected)
+}
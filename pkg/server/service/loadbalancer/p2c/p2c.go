@@ -5,6 +5,7 @@ import (
 	"errors"
 	"math/rand"
 	"net/http"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -61,7 +62,8 @@ type Balancer struct {
 	// No mutex is needed, as it is modified only during the configuration build.
 	updaters []func(bool)
 
-	sticky *loadbalancer.Sticky
+	sticky    *loadbalancer.Sticky
+	slowStart *loadbalancer.SlowStart
 
 	randMu sync.Mutex
 	rand   rnd
@@ -98,6 +100,10 @@ func (b *Balancer) SetStatus(ctx context.Context, childName string, up bool) {
 	log.Ctx(ctx).Debug().Msgf("Setting status of %s to %v", childName, status)
 
 	if up {
+		if _, ok := b.status[childName]; !ok {
+			// The recovered server warms up before receiving its full share of the requests.
+			b.slowStart.Start(childName)
+		}
 		b.status[childName] = struct{}{}
 	} else {
 		delete(b.status, childName)
@@ -134,6 +140,13 @@ func (b *Balancer) RegisterStatusUpdater(fn func(up bool)) error {
 	return nil
 }
 
+// SetSlowStart sets the SlowStart ramping up the weight of the recovered servers,
+// and of the new servers whose warm-up is started by the owner of the balancer.
+// Not thread safe.
+func (b *Balancer) SetSlowStart(slowStart *loadbalancer.SlowStart) {
+	b.slowStart = slowStart
+}
+
 func (b *Balancer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	if b.sticky != nil {
 		h, rewrite, err := b.sticky.StickyHandler(req)
@@ -180,11 +193,13 @@ func (b *Balancer) AddServer(name string, handler http.Handler, server dynamic.S
 	h := &namedHandler{Handler: handler, name: name}
 
 	b.handlersMu.Lock()
+	upBefore := len(b.status) > 0
 	b.handlers = append(b.handlers, h)
 	b.status[name] = struct{}{}
 	if server.Fenced {
 		b.fenced[name] = struct{}{}
 	}
+	b.propagateStatus(upBefore)
 	b.handlersMu.Unlock()
 
 	if b.sticky != nil {
@@ -192,6 +207,37 @@ func (b *Balancer) AddServer(name string, handler http.Handler, server dynamic.S
 	}
 }
 
+// RemoveServer removes the handler of the given server.
+func (b *Balancer) RemoveServer(name string) {
+	if b.sticky != nil {
+		b.sticky.RemoveHandler(name)
+	}
+
+	b.handlersMu.Lock()
+	defer b.handlersMu.Unlock()
+
+	upBefore := len(b.status) > 0
+
+	b.handlers = slices.DeleteFunc(b.handlers, func(h *namedHandler) bool { return h.name == name })
+	delete(b.status, name)
+	delete(b.fenced, name)
+
+	b.propagateStatus(upBefore)
+}
+
+// propagateStatus runs the updaters when the status of the balancer is no longer the given one.
+// It must be called with the handlers lock held.
+func (b *Balancer) propagateStatus(upBefore bool) {
+	upAfter := len(b.status) > 0
+	if upBefore == upAfter {
+		return
+	}
+
+	for _, fn := range b.updaters {
+		fn(upAfter)
+	}
+}
+
 func (b *Balancer) nextServer() (*namedHandler, error) {
 	// We kept the same representation (map) as in the WRR strategy to improve maintainability.
 	// However, with the P2C strategy, we only need a slice of healthy servers.
@@ -227,7 +273,7 @@ func (b *Balancer) nextServer() (*namedHandler, error) {
 
 	h1, h2 := healthy[n1], healthy[n2]
 	// Ensure h1 has fewer inflight requests than h2.
-	if h2.inflight.Load() < h1.inflight.Load() {
+	if b.load(h2) < b.load(h1) {
 		log.Debug().Msgf("Service selected by P2C: %s", h2.name)
 		return h2, nil
 	}
@@ -235,3 +281,8 @@ func (b *Balancer) nextServer() (*namedHandler, error) {
 	log.Debug().Msgf("Service selected by P2C: %s", h1.name)
 	return h1, nil
 }
+
+// load returns the inflight requests of the given server, relative to its weight ramped up by the slow start.
+func (b *Balancer) load(h *namedHandler) float64 {
+	return float64(h.inflight.Load()+1) / b.slowStart.Factor(h.name)
+}
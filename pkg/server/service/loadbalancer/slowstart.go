@@ -0,0 +1,107 @@
+package loadbalancer
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+)
+
+// SlowStart ramps up the weight of the servers which have just been added to a load-balancer or have recovered,
+// for them to warm up before receiving their full share of the requests.
+// A nil SlowStart gives their full weight to all the servers.
+type SlowStart struct {
+	window      time.Duration
+	exponential bool
+	minWeight   float64
+
+	// warmUntil is the end, in Unix nanoseconds, of the latest warm-up,
+	// to skip looking for the warm-up of a server when none is ongoing.
+	warmUntil atomic.Int64
+
+	startsMu sync.RWMutex
+	starts   map[string]time.Time
+}
+
+// NewSlowStart creates a new SlowStart.
+func NewSlowStart(config *dynamic.SlowStart) (*SlowStart, error) {
+	if config.Window <= 0 {
+		return nil, fmt.Errorf("invalid slow start window: %s", config.Window)
+	}
+
+	if config.MinWeightPercent <= 0 || config.MinWeightPercent > 100 {
+		return nil, fmt.Errorf("slow start minWeightPercent must be between 1 and 100, got %d", config.MinWeightPercent)
+	}
+
+	s := &SlowStart{
+		window:    time.Duration(config.Window),
+		minWeight: float64(config.MinWeightPercent) / 100,
+		starts:    make(map[string]time.Time),
+	}
+
+	switch config.Ramp {
+	case dynamic.SlowStartRampLinear, "":
+	case dynamic.SlowStartRampExponential:
+		s.exponential = true
+	default:
+		return nil, fmt.Errorf("unsupported slow start ramp %q", config.Ramp)
+	}
+
+	return s, nil
+}
+
+// Start starts the warm-up of the given server.
+func (s *SlowStart) Start(name string) {
+	if s == nil {
+		return
+	}
+
+	now := time.Now()
+
+	s.startsMu.Lock()
+	// The servers which are warm are forgotten, as the SlowStart outlives the servers of a service.
+	for n, start := range s.starts {
+		if now.Sub(start) >= s.window {
+			delete(s.starts, n)
+		}
+	}
+	s.starts[name] = now
+	s.startsMu.Unlock()
+
+	s.warmUntil.Store(now.Add(s.window).UnixNano())
+}
+
+// Factor returns the factor, between the minimum weight and 1, to apply to the weight of the given server.
+func (s *SlowStart) Factor(name string) float64 {
+	if s == nil {
+		return 1
+	}
+
+	now := time.Now()
+	if now.UnixNano() >= s.warmUntil.Load() {
+		return 1
+	}
+
+	s.startsMu.RLock()
+	start, ok := s.starts[name]
+	s.startsMu.RUnlock()
+
+	if !ok {
+		return 1
+	}
+
+	progress := float64(now.Sub(start)) / float64(s.window)
+	if progress >= 1 {
+		return 1
+	}
+
+	if s.exponential {
+		// The weight doubles at a constant pace, from the minimum weight to the full weight.
+		return math.Pow(s.minWeight, 1-progress)
+	}
+
+	return s.minWeight + (1-s.minWeight)*progress
+}
@@ -0,0 +1,107 @@
+package loadbalancer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/hanzoai/ingress-parser/types"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+)
+
+func TestNewSlowStart(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		config  dynamic.SlowStart
+		wantErr bool
+	}{
+		{
+			desc:   "linear",
+			config: dynamic.SlowStart{Window: ptypes.Duration(time.Second), Ramp: dynamic.SlowStartRampLinear, MinWeightPercent: 10},
+		},
+		{
+			desc:   "exponential",
+			config: dynamic.SlowStart{Window: ptypes.Duration(time.Second), Ramp: dynamic.SlowStartRampExponential, MinWeightPercent: 10},
+		},
+		{
+			desc:    "invalid window",
+			config:  dynamic.SlowStart{MinWeightPercent: 10},
+			wantErr: true,
+		},
+		{
+			desc:    "invalid min weight percent",
+			config:  dynamic.SlowStart{Window: ptypes.Duration(time.Second)},
+			wantErr: true,
+		},
+		{
+			desc:    "unsupported ramp",
+			config:  dynamic.SlowStart{Window: ptypes.Duration(time.Second), Ramp: "quadratic", MinWeightPercent: 10},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewSlowStart(&test.config)
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSlowStart_Factor(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		ramp       string
+		elapsed    time.Duration
+		wantFactor float64
+	}{
+		{
+			desc:       "linear start",
+			ramp:       dynamic.SlowStartRampLinear,
+			wantFactor: 0.1,
+		},
+		{
+			desc:       "linear half window",
+			ramp:       dynamic.SlowStartRampLinear,
+			elapsed:    30 * time.Minute,
+			wantFactor: 0.55,
+		},
+		{
+			desc:       "exponential half window",
+			ramp:       dynamic.SlowStartRampExponential,
+			elapsed:    30 * time.Minute,
+			wantFactor: 0.316,
+		},
+		{
+			desc:       "window elapsed",
+			ramp:       dynamic.SlowStartRampExponential,
+			elapsed:    time.Hour,
+			wantFactor: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			slowStart, err := NewSlowStart(&dynamic.SlowStart{Window: ptypes.Duration(time.Hour), Ramp: test.ramp, MinWeightPercent: 10})
+			require.NoError(t, err)
+
+			slowStart.Start("server")
+			slowStart.starts["server"] = slowStart.starts["server"].Add(-test.elapsed)
+
+			assert.InDelta(t, test.wantFactor, slowStart.Factor("server"), 0.01)
+			assert.InDelta(t, 1, slowStart.Factor("other"), 0)
+		})
+	}
+
+	var slowStart *SlowStart
+	assert.InDelta(t, 1, slowStart.Factor("server"), 0)
+}
@@ -98,6 +98,23 @@ func (s *Sticky) AddHandler(name string, h http.Handler) {
 	s.compatibilityStickyMap[hashedName] = handler
 }
 
+// RemoveHandler removes the http.Handler of the given name from the sticky pool.
+func (s *Sticky) RemoveHandler(name string) {
+	s.handlersMu.Lock()
+	defer s.handlersMu.Unlock()
+
+	if sha256HashedName, ok := s.hashMap[name]; ok {
+		delete(s.stickyMap, sha256HashedName)
+		delete(s.hashMap, name)
+	}
+
+	delete(s.compatibilityStickyMap, name)
+
+	hashedName := fnvHash(name)
+	delete(s.compatibilityStickyMap, hashedName)
+	delete(s.compatibilityStickyMap, fnvHash(hashedName))
+}
+
 // StickyHandler returns the NamedHandler corresponding to the sticky cookie if one.
 // It also returns a boolean which indicates if the sticky cookie has to be overwritten because it uses a deprecated hash algorithm.
 func (s *Sticky) StickyHandler(req *http.Request) (*NamedHandler, bool, error) {
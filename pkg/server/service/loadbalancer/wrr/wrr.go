@@ -46,7 +46,8 @@ type Balancer struct {
 	// No mutex is needed, as it is modified only during the configuration build.
 	updaters []func(bool)
 
-	sticky *loadbalancer.Sticky
+	sticky    *loadbalancer.Sticky
+	slowStart *loadbalancer.SlowStart
 
 	curDeadline float64
 }
@@ -112,6 +113,10 @@ func (b *Balancer) SetStatus(ctx context.Context, childName string, up bool) {
 	log.Ctx(ctx).Debug().Msgf("Setting status of %s to %v", childName, status)
 
 	if up {
+		if _, ok := b.status[childName]; !ok {
+			// The recovered server warms up before receiving its full share of the requests.
+			b.slowStart.Start(childName)
+		}
 		b.status[childName] = struct{}{}
 	} else {
 		delete(b.status, childName)
@@ -148,6 +153,13 @@ func (b *Balancer) RegisterStatusUpdater(fn func(up bool)) error {
 	return nil
 }
 
+// SetSlowStart sets the SlowStart ramping up the weight of the recovered servers,
+// and of the new servers whose warm-up is started by the owner of the balancer.
+// Not thread safe.
+func (b *Balancer) SetSlowStart(slowStart *loadbalancer.SlowStart) {
+	b.slowStart = slowStart
+}
+
 func (b *Balancer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	if b.sticky != nil {
 		h, rewrite, err := b.sticky.StickyHandler(req)
@@ -209,12 +221,14 @@ func (b *Balancer) Add(name string, handler http.Handler, weight *int, fenced bo
 	h := &namedHandler{Handler: handler, name: name, weight: float64(w)}
 
 	b.handlersMu.Lock()
+	upBefore := len(b.status) > 0
 	h.deadline = b.curDeadline + 1/h.weight
 	heap.Push(b, h)
 	b.status[name] = struct{}{}
 	if fenced {
 		b.fenced[name] = struct{}{}
 	}
+	b.propagateStatus(upBefore)
 	b.handlersMu.Unlock()
 
 	if b.sticky != nil {
@@ -222,6 +236,42 @@ func (b *Balancer) Add(name string, handler http.Handler, weight *int, fenced bo
 	}
 }
 
+// RemoveServer removes the handler of the given server.
+func (b *Balancer) RemoveServer(name string) {
+	if b.sticky != nil {
+		b.sticky.RemoveHandler(name)
+	}
+
+	b.handlersMu.Lock()
+	defer b.handlersMu.Unlock()
+
+	upBefore := len(b.status) > 0
+
+	for i, h := range b.handlers {
+		if h.name == name {
+			heap.Remove(b, i)
+			break
+		}
+	}
+	delete(b.status, name)
+	delete(b.fenced, name)
+
+	b.propagateStatus(upBefore)
+}
+
+// propagateStatus runs the updaters when the status of the balancer is no longer the given one.
+// It must be called with the handlers lock held.
+func (b *Balancer) propagateStatus(upBefore bool) {
+	upAfter := len(b.status) > 0
+	if upBefore == upAfter {
+		return
+	}
+
+	for _, fn := range b.updaters {
+		fn(upAfter)
+	}
+}
+
 func (b *Balancer) nextServer() (*namedHandler, error) {
 	b.handlersMu.Lock()
 	defer b.handlersMu.Unlock()
@@ -237,7 +287,7 @@ func (b *Balancer) nextServer() (*namedHandler, error) {
 
 		// curDeadline should be handler's deadline so that new added entry would have a fair competition environment with the old ones.
 		b.curDeadline = handler.deadline
-		handler.deadline += 1 / handler.weight
+		handler.deadline += 1 / (handler.weight * b.slowStart.Factor(handler.name))
 
 		heap.Push(b, handler)
 		if _, ok := b.status[handler.name]; ok {
@@ -7,9 +7,12 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/hanzoai/ingress/pkg/api"
 	"github.com/hanzoai/ingress/pkg/api/dashboard"
+	"github.com/hanzoai/ingress/pkg/config/history"
 	"github.com/hanzoai/ingress/pkg/config/runtime"
+	"github.com/hanzoai/ingress/pkg/config/snapshot"
 	"github.com/hanzoai/ingress/pkg/config/static"
 	"github.com/hanzoai/ingress/pkg/observability/metrics"
+	"github.com/hanzoai/ingress/pkg/provider/status"
 	"github.com/hanzoai/ingress/pkg/safe"
 	"github.com/hanzoai/ingress/pkg/server/middleware"
 )
@@ -23,11 +26,15 @@ type ManagerFactory struct {
 
 	api              func(configuration *runtime.Configuration) http.Handler
 	restHandler      http.Handler
+	gitHandler       http.Handler
 	dashboardHandler http.Handler
 	metricsHandler   http.Handler
 	pingHandler      http.Handler
 	acmeHTTPHandler  http.Handler
 
+	// apiOptions holds the optional components used by the API endpoints.
+	apiOptions api.Options
+
 	routinesPool *safe.Pool
 }
 
@@ -39,10 +46,17 @@ func NewManagerFactory(staticConfiguration static.Configuration, routinesPool *s
 		transportManager: transportManager,
 		proxyBuilder:     proxyBuilder,
 		acmeHTTPHandler:  acmeHTTPHandler,
+		apiOptions: api.Options{
+			TapHub:    observabilityMgr.TapHub(),
+			LogLevels: observabilityMgr.LogLevels(),
+		},
 	}
 
 	if staticConfiguration.API != nil {
-		apiRouterBuilder := api.NewBuilder(staticConfiguration)
+		// The API router is built with the options set when the services are built.
+		apiRouterBuilder := func(configuration *runtime.Configuration) http.Handler {
+			return api.NewBuilder(staticConfiguration, factory.apiOptions)(configuration)
+		}
 
 		if staticConfiguration.API.Dashboard {
 			factory.dashboardHandler = dashboard.Handler{BasePath: staticConfiguration.API.BasePath}
@@ -63,8 +77,12 @@ func NewManagerFactory(staticConfiguration static.Configuration, routinesPool *s
 		factory.restHandler = staticConfiguration.Providers.Rest.CreateRouter()
 	}
 
+	if staticConfiguration.Providers != nil && staticConfiguration.Providers.Git != nil && staticConfiguration.Providers.Git.Webhook != nil {
+		factory.gitHandler = staticConfiguration.Providers.Git.CreateRouter()
+	}
+
 	if staticConfiguration.Metrics != nil && staticConfiguration.Metrics.Prometheus != nil {
-		factory.metricsHandler = metrics.PrometheusHandler()
+		factory.metricsHandler = metrics.PrometheusHandler(staticConfiguration.Metrics.Prometheus)
 	}
 
 	// This check is necessary because even when staticConfiguration.Ping == nil ,
@@ -77,6 +95,26 @@ func NewManagerFactory(staticConfiguration static.Configuration, routinesPool *s
 	return factory
 }
 
+// SetConfigSnapshot sets the configuration snapshot exposed by the API.
+func (f *ManagerFactory) SetConfigSnapshot(configSnapshot *snapshot.Snapshot) {
+	f.apiOptions.ConfigSnapshot = configSnapshot
+}
+
+// SetConfigHistory sets the configuration history exposed by the API.
+func (f *ManagerFactory) SetConfigHistory(configHistory *history.History) {
+	f.apiOptions.ConfigHistory = configHistory
+}
+
+// SetProviderStatus sets the provider status tracker exposed by the API.
+func (f *ManagerFactory) SetProviderStatus(providerStatus *status.Tracker) {
+	f.apiOptions.ProviderStatus = providerStatus
+}
+
+// SetRouteExplainer sets the route explainer used by the route simulation of the API.
+func (f *ManagerFactory) SetRouteExplainer(routeExplainer api.RouteExplainer) {
+	f.apiOptions.RouteExplainer = routeExplainer
+}
+
 // Build creates a service manager.
 func (f *ManagerFactory) Build(configuration *runtime.Configuration) *Manager {
 	var apiHandler http.Handler
@@ -84,6 +122,6 @@ func (f *ManagerFactory) Build(configuration *runtime.Configuration) *Manager {
 		apiHandler = f.api(configuration)
 	}
 
-	internalHandlers := NewInternalHandlers(apiHandler, f.restHandler, f.metricsHandler, f.pingHandler, f.dashboardHandler, f.acmeHTTPHandler)
+	internalHandlers := NewInternalHandlers(apiHandler, f.restHandler, f.gitHandler, f.metricsHandler, f.pingHandler, f.dashboardHandler, f.acmeHTTPHandler)
 	return NewManager(configuration.Services, f.observabilityMgr, f.routinesPool, f.transportManager, f.proxyBuilder, internalHandlers)
 }
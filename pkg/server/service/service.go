@@ -9,7 +9,9 @@ import (
 	"net/http"
 	"net/url"
 	"reflect"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/containous/alice"
@@ -28,13 +30,19 @@ import (
 	"github.com/hanzoai/ingress/pkg/server/middleware"
 	"github.com/hanzoai/ingress/pkg/server/provider"
 	"github.com/hanzoai/ingress/pkg/server/recursion"
+	"github.com/hanzoai/ingress/pkg/server/service/discovery"
+	"github.com/hanzoai/ingress/pkg/server/service/loadbalancer"
 	"github.com/hanzoai/ingress/pkg/server/service/loadbalancer/failover"
+	"github.com/hanzoai/ingress/pkg/server/service/loadbalancer/hedging"
 	"github.com/hanzoai/ingress/pkg/server/service/loadbalancer/hrw"
 	"github.com/hanzoai/ingress/pkg/server/service/loadbalancer/leasttime"
+	"github.com/hanzoai/ingress/pkg/server/service/loadbalancer/locality"
 	"github.com/hanzoai/ingress/pkg/server/service/loadbalancer/mirror"
+	"github.com/hanzoai/ingress/pkg/server/service/loadbalancer/outlier"
 	"github.com/hanzoai/ingress/pkg/server/service/loadbalancer/p2c"
 	"github.com/hanzoai/ingress/pkg/server/service/loadbalancer/wrr"
 	"google.golang.org/grpc/status"
+	"k8s.io/utils/ptr"
 )
 
 // ProxyBuilder builds reverse proxy handlers.
@@ -63,22 +71,28 @@ type Manager struct {
 	services               map[string]http.Handler
 	configs                map[string]*runtime.ServiceInfo
 	healthCheckers         map[string]*healthcheck.ServiceHealthChecker
+	cancelHealthChecks     map[string]context.CancelFunc
+	balancers              map[string]*serversBalancer
 	rand                   *rand.Rand // For the initial shuffling of load-balancers.
 	middlewareChainBuilder middlewareChainBuilder
+	dnsDiscovery           discovery.Watcher
+	zone                   string
 }
 
 // NewManager creates a new Manager.
 func NewManager(configs map[string]*runtime.ServiceInfo, observabilityMgr *middleware.ObservabilityMgr, routinePool *safe.Pool, transportManager httputil.TransportManager, proxyBuilder ProxyBuilder, serviceBuilders ...ServiceBuilder) *Manager {
 	return &Manager{
-		routinePool:      routinePool,
-		observabilityMgr: observabilityMgr,
-		transportManager: transportManager,
-		proxyBuilder:     proxyBuilder,
-		serviceBuilders:  serviceBuilders,
-		services:         make(map[string]http.Handler),
-		configs:          configs,
-		healthCheckers:   make(map[string]*healthcheck.ServiceHealthChecker),
-		rand:             rand.New(rand.NewSource(time.Now().UnixNano())),
+		routinePool:        routinePool,
+		observabilityMgr:   observabilityMgr,
+		transportManager:   transportManager,
+		proxyBuilder:       proxyBuilder,
+		serviceBuilders:    serviceBuilders,
+		services:           make(map[string]http.Handler),
+		configs:            configs,
+		healthCheckers:     make(map[string]*healthcheck.ServiceHealthChecker),
+		cancelHealthChecks: make(map[string]context.CancelFunc),
+		balancers:          make(map[string]*serversBalancer),
+		rand:               rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
@@ -87,6 +101,16 @@ func (m *Manager) SetMiddlewareChainBuilder(middlewareChainBuilder middlewareCha
 	m.middlewareChainBuilder = middlewareChainBuilder
 }
 
+// SetDNSDiscovery sets the Watcher discovering the servers of the load-balancers through DNS.
+func (m *Manager) SetDNSDiscovery(dnsDiscovery discovery.Watcher) {
+	m.dnsDiscovery = dnsDiscovery
+}
+
+// SetZone sets the zone of the instance, whose servers are preferred by the load-balancers with locality enabled.
+func (m *Manager) SetZone(zone string) {
+	m.zone = zone
+}
+
 // BuildHTTP Creates a http.Handler for a service configuration.
 func (m *Manager) BuildHTTP(rootCtx context.Context, serviceName string) (http.Handler, error) {
 	serviceName = provider.GetQualifiedName(rootCtx, serviceName)
@@ -149,6 +173,10 @@ func (m *Manager) BuildHTTP(rootCtx context.Context, serviceName string) (http.H
 			conf.AddError(err, true)
 			return nil, err
 		}
+
+		if balancer, ok := lb.(*serversBalancer); ok {
+			m.balancers[serviceName] = balancer
+		}
 	case conf.Weighted != nil:
 		var err error
 		lb, err = m.getWRRServiceHandler(ctx, serviceName, conf.Weighted)
@@ -204,9 +232,22 @@ func (m *Manager) BuildHTTP(rootCtx context.Context, serviceName string) (http.H
 // LaunchHealthCheck launches the health checks.
 func (m *Manager) LaunchHealthCheck(ctx context.Context) {
 	for serviceName, hc := range m.healthCheckers {
-		logger := log.Ctx(ctx).With().Str(logs.ServiceName, serviceName).Logger()
-		go hc.Launch(logger.WithContext(ctx))
+		m.launchHealthCheck(ctx, serviceName, hc)
+	}
+}
+
+// launchHealthCheck launches the given health check of a service, and stops its previous one.
+func (m *Manager) launchHealthCheck(ctx context.Context, serviceName string, hc *healthcheck.ServiceHealthChecker) {
+	if cancel, ok := m.cancelHealthChecks[serviceName]; ok {
+		cancel()
 	}
+
+	logger := log.Ctx(ctx).With().Str(logs.ServiceName, serviceName).Logger()
+
+	hcCtx, cancel := context.WithCancel(logger.WithContext(ctx))
+	m.cancelHealthChecks[serviceName] = cancel
+
+	go hc.Launch(hcCtx)
 }
 
 func (m *Manager) getFailoverServiceHandler(ctx context.Context, serviceName string, config *dynamic.Failover) (http.Handler, error) {
@@ -429,25 +470,225 @@ func (m *Manager) getLoadBalancerServiceHandler(ctx context.Context, serviceName
 		passHostHeader = *service.PassHostHeader
 	}
 
-	var lb serverBalancer
-	switch service.Strategy {
-	// Here we are handling the empty value to comply with providers that are not applying defaults (e.g. REST provider)
-	// TODO: remove this empty check when all providers apply default values.
-	case dynamic.BalancerStrategyWRR, "":
-		lb = wrr.New(service.Sticky, service.HealthCheck != nil)
-	case dynamic.BalancerStrategyP2C:
-		lb = p2c.New(service.Sticky, service.HealthCheck != nil)
-	case dynamic.BalancerStrategyHRW:
-		lb = hrw.New(service.HealthCheck != nil)
-	case dynamic.BalancerStrategyLeastTime:
-		lb = leasttime.New(service.Sticky, service.HealthCheck != nil)
-	default:
-		return nil, fmt.Errorf("unsupported load-balancer strategy %q", service.Strategy)
+	balancer, err := m.newServersBalancer(ctx, serviceName, info, passHostHeader, flushInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	if service.DNS != nil {
+		return m.getDNSLoadBalancerServiceHandler(ctx, balancer)
+	}
+
+	healthChecker, err := balancer.update(ctx, shuffle(service.Servers, m.rand))
+	if err != nil {
+		return nil, err
+	}
+
+	if healthChecker != nil {
+		m.healthCheckers[serviceName] = healthChecker
+	}
+
+	return balancer, nil
+}
+
+// UpdateServers replaces the servers of the given load-balancer service,
+// without rebuilding the routers and the services using it, and restarts its health check.
+// It has no effect on a service which has not been built.
+func (m *Manager) UpdateServers(ctx context.Context, serviceName string, servers []dynamic.Server) error {
+	serviceName = provider.GetQualifiedName(ctx, serviceName)
+
+	balancer, ok := m.balancers[serviceName]
+	if !ok {
+		return nil
+	}
+
+	if balancer.discovered {
+		return fmt.Errorf("the servers of the service %q are discovered", serviceName)
+	}
+
+	healthChecker, err := balancer.update(ctx, shuffle(servers, m.rand))
+	if err != nil {
+		return err
+	}
+
+	if healthChecker != nil {
+		m.healthCheckers[serviceName] = healthChecker
+		m.launchHealthCheck(ctx, serviceName, healthChecker)
+	}
+
+	return nil
+}
+
+// getDNSLoadBalancerServiceHandler updates the servers of the given load-balancer with the servers discovered through DNS,
+// each time they change.
+func (m *Manager) getDNSLoadBalancerServiceHandler(ctx context.Context, balancer *serversBalancer) (http.Handler, error) {
+	if m.dnsDiscovery == nil {
+		return nil, errors.New("DNS discovery is not available")
+	}
+
+	service := balancer.info.LoadBalancer
+	balancer.discovered = true
+
+	scheme := service.DNS.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+
+	cancelHealthCheck := func() {}
+
+	// The first update happens before Watch returns, the next ones in the discovery goroutine.
+	initial := true
+	var buildErr error
+
+	// The discovery is stopped if the load-balancer cannot be built with the first discovered servers.
+	watchCtx, cancelWatch := context.WithCancel(ctx)
+	defer func() {
+		if buildErr != nil {
+			cancelWatch()
+		}
+	}()
+
+	m.dnsDiscovery.Watch(watchCtx, *service.DNS, func(targets []discovery.Target) {
+		defer func() { initial = false }()
+
+		servers := slices.Clone(service.Servers)
+		for _, target := range targets {
+			server := dynamic.Server{URL: scheme + "://" + target.Address()}
+			if target.Weight > 0 {
+				server.Weight = ptr.To(target.Weight)
+			}
+			servers = append(servers, server)
+		}
+
+		// The package level shuffling is used, as the discovered servers are updated concurrently.
+		rand.Shuffle(len(servers), func(i, j int) { servers[i], servers[j] = servers[j], servers[i] })
+
+		healthChecker, err := balancer.update(ctx, servers)
+		if err != nil {
+			if initial {
+				buildErr = err
+				return
+			}
+
+			log.Ctx(ctx).Error().Err(err).Msg("Unable to update the discovered servers")
+			return
+		}
+
+		cancelHealthCheck()
+		if healthChecker != nil {
+			var hcCtx context.Context
+			hcCtx, cancelHealthCheck = context.WithCancel(ctx)
+			go healthChecker.Launch(hcCtx)
+		}
+	})
+
+	if buildErr != nil {
+		return nil, buildErr
+	}
+
+	return balancer, nil
+}
+
+// serversBalancer is the load-balancer of a service, whose servers are added and removed in place when they are updated,
+// for the state of the load-balancer (e.g. sticky sessions, ejected servers, retry budget) to outlive the updates.
+type serversBalancer struct {
+	serverBalancer
+
+	manager        *Manager
+	serviceName    string
+	info           *runtime.ServiceInfo
+	passHostHeader bool
+	flushInterval  time.Duration
+
+	drainer              *loadbalancer.Drainer
+	slowStart            *loadbalancer.SlowStart
+	retryBudget          *retry.Budget
+	passiveHealthChecker *healthcheck.PassiveServiceHealthChecker
+
+	// discovered is true when the servers are discovered through DNS, and cannot be updated otherwise.
+	discovered bool
+
+	mu      sync.Mutex
+	servers map[string]dynamic.Server // By URL, nil until the first update.
+}
+
+// newServersBalancer creates the load-balancer of the given service, without servers.
+func (m *Manager) newServersBalancer(ctx context.Context, serviceName string, info *runtime.ServiceInfo, passHostHeader bool, flushInterval time.Duration) (*serversBalancer, error) {
+	logger := log.Ctx(ctx)
+
+	service := info.LoadBalancer
+
+	// The SlowStart outlives the servers of the service, for the servers to keep warming up across the updates.
+	var slowStart *loadbalancer.SlowStart
+	if service.SlowStart != nil {
+		var err error
+		slowStart, err = loadbalancer.NewSlowStart(service.SlowStart)
+		if err != nil {
+			return nil, fmt.Errorf("creating slow start: %w", err)
+		}
+	}
+
+	lb, err := newServerBalancer(service, slowStart)
+	if err != nil {
+		return nil, err
+	}
+
+	if service.Locality != nil {
+		if m.zone == "" {
+			logger.Warn().Msg("Locality is ignored because the zone of the instance is not set")
+		} else {
+			// The servers of the other zones are load-balanced by another load-balancer of the same strategy.
+			remote, err := newServerBalancer(service, slowStart)
+			if err != nil {
+				return nil, err
+			}
+
+			zoneAware, err := locality.New(m.zone, service.Locality, lb, remote, service.HealthCheck != nil)
+			if err != nil {
+				return nil, fmt.Errorf("creating locality: %w", err)
+			}
+			lb = zoneAware
+		}
+	}
+
+	if service.Hedging != nil {
+		hedger, err := hedging.New(lb, service.Hedging, provider.GetQualifiedName(ctx, serviceName), m.observabilityMgr.MetricsRegistry())
+		if err != nil {
+			return nil, fmt.Errorf("creating hedging: %w", err)
+		}
+		lb = hedger
+	}
+
+	if service.OutlierDetection != nil {
+		detector, err := outlier.New(lb, service.OutlierDetection, provider.GetQualifiedName(ctx, serviceName), m.observabilityMgr.MetricsRegistry())
+		if err != nil {
+			return nil, fmt.Errorf("creating outlier detection: %w", err)
+		}
+		lb = detector
+	}
+
+	balancer := &serversBalancer{
+		serverBalancer: lb,
+		manager:        m,
+		serviceName:    serviceName,
+		info:           info,
+		passHostHeader: passHostHeader,
+		flushInterval:  flushInterval,
+		slowStart:      slowStart,
+	}
+
+	// The Drainer only outlives the updates of the servers, the routers being rebuilt with a new one.
+	if service.DrainTimeout != nil && *service.DrainTimeout > 0 {
+		balancer.drainer = loadbalancer.NewDrainer(time.Duration(*service.DrainTimeout))
+	}
+
+	// The retry budget is shared by all the servers of the service.
+	if service.RetryBudget != nil {
+		balancer.retryBudget = retry.NewBudget(service.RetryBudget.Percent, service.RetryBudget.MinRetryConcurrency)
 	}
 
-	var passiveHealthChecker *healthcheck.PassiveServiceHealthChecker
 	if service.PassiveHealthCheck != nil {
-		passiveHealthChecker = healthcheck.NewPassiveHealthChecker(
+		balancer.passiveHealthChecker = healthcheck.NewPassiveHealthChecker(
 			serviceName,
 			lb,
 			service.PassiveHealthCheck.MaxFailedAttempts,
@@ -456,85 +697,202 @@ func (m *Manager) getLoadBalancerServiceHandler(ctx context.Context, serviceName
 			m.observabilityMgr.MetricsRegistry())
 	}
 
-	healthCheckTargets := make(map[string]*url.URL)
+	return balancer, nil
+}
+
+// SetStatus sets the status of the given server, unless it has been removed in the meantime.
+func (b *serversBalancer) SetStatus(ctx context.Context, name string, up bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.servers[name]; !ok {
+		return
+	}
+
+	b.serverBalancer.SetStatus(ctx, name, up)
+}
+
+// update replaces the servers of the load-balancer with the given ones, and returns the health checker of the new servers
+// when the active health check is enabled.
+// Only the new and modified servers are (re)created, and the load-balancer is left untouched on error.
+// The duplicated servers are ignored.
+func (b *serversBalancer) update(ctx context.Context, servers []dynamic.Server) (*healthcheck.ServiceHealthChecker, error) {
+	logger := log.Ctx(ctx)
+
+	service := b.info.LoadBalancer
+
+	var roundTripper http.RoundTripper
+	if service.HealthCheck != nil {
+		var err error
+		roundTripper, err = b.manager.transportManager.GetRoundTripper(service.ServersTransport)
+		if err != nil {
+			return nil, fmt.Errorf("getting RoundTripper: %w", err)
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	updated := make(map[string]dynamic.Server, len(servers))
+	targets := make(map[string]*url.URL, len(servers))
+	handlers := make(map[string]http.Handler)
+	var added []dynamic.Server
+
+	for i, server := range servers {
+		if _, ok := updated[server.URL]; ok {
+			continue
+		}
 
-	for i, server := range shuffle(service.Servers, m.rand) {
 		target, err := url.Parse(server.URL)
 		if err != nil {
 			return nil, fmt.Errorf("error parsing server URL %s: %w", server.URL, err)
 		}
 
+		updated[server.URL] = server
+		targets[server.URL] = target
+
+		if current, ok := b.servers[server.URL]; ok && reflect.DeepEqual(current, server) {
+			continue
+		}
+
 		logger.Debug().Int(logs.ServerIndex, i).Str("URL", server.URL).
 			Msg("Creating server")
 
-		qualifiedSvcName := provider.GetQualifiedName(ctx, serviceName)
-
-		proxy, err := m.proxyBuilder.Build(service.ServersTransport, target, passHostHeader, server.PreservePath, flushInterval)
+		handler, err := b.buildServer(ctx, server, target)
 		if err != nil {
-			return nil, fmt.Errorf("error building proxy for server URL %s: %w", server.URL, err)
+			return nil, err
 		}
 
-		if passiveHealthChecker != nil {
-			// If passive health check is enabled, we wrap the proxy with the passive health checker.
-			proxy = passiveHealthChecker.WrapHandler(ctx, proxy, target.String())
-		}
+		handlers[server.URL] = handler
+		added = append(added, server)
+	}
 
-		// The retry wrapping must be done just before the proxy handler,
-		// to make sure that the retry will not be triggered/disabled by
-		// middlewares in the chain.
-		proxy = retry.WrapHandler(proxy)
+	// The servers are added before the others are removed, for the load-balancer to stay up.
+	for _, server := range added {
+		if _, ok := b.servers[server.URL]; ok {
+			b.serverBalancer.RemoveServer(server.URL)
+		} else if b.servers != nil {
+			// The servers of the initial update are all considered warm.
+			b.slowStart.Start(server.URL)
+		}
 
-		// Access logs, metrics, and tracing middlewares are idempotent if the associated signal is disabled.
-		proxy = accesslog.NewFieldHandler(proxy, accesslog.ServiceURL, target.String(), nil)
-		proxy = accesslog.NewFieldHandler(proxy, accesslog.ServiceAddr, target.Host, nil)
-		proxy = accesslog.NewFieldHandler(proxy, accesslog.ServiceName, qualifiedSvcName, accesslog.AddServiceFields)
+		b.serverBalancer.AddServer(server.URL, handlers[server.URL], server)
 
-		metricsHandler := metricsMiddle.ServiceMetricsHandler(ctx, m.observabilityMgr.MetricsRegistry(), qualifiedSvcName)
-		metricsHandler = observability.WrapMiddleware(ctx, metricsHandler)
+		// Servers are considered UP by default.
+		b.info.UpdateServerStatus(targets[server.URL].String(), runtime.StatusUp)
+	}
 
-		proxy, err = alice.New().
-			Append(metricsHandler).
-			Then(proxy)
-		if err != nil {
-			return nil, fmt.Errorf("error wrapping metrics handler: %w", err)
+	for name := range b.servers {
+		if _, ok := updated[name]; !ok {
+			b.serverBalancer.RemoveServer(name)
 		}
+	}
+
+	removeServerStatuses(b.info, b.drainer, b.servers, updated)
+	b.servers = updated
 
-		proxy = observability.NewService(ctx, qualifiedSvcName, proxy)
+	if service.HealthCheck == nil {
+		return nil, nil
+	}
 
-		lb.AddServer(server.URL, proxy, server)
+	return healthcheck.NewServiceHealthChecker(
+		ctx,
+		b.manager.observabilityMgr.MetricsRegistry(),
+		service.HealthCheck,
+		b,
+		b.info,
+		roundTripper,
+		targets,
+		b.serviceName,
+	), nil
+}
 
-		// Servers are considered UP by default.
-		info.UpdateServerStatus(target.String(), runtime.StatusUp)
+// buildServer creates the handler forwarding the requests to the given server.
+func (b *serversBalancer) buildServer(ctx context.Context, server dynamic.Server, target *url.URL) (http.Handler, error) {
+	m := b.manager
+	service := b.info.LoadBalancer
 
-		healthCheckTargets[server.URL] = target
+	qualifiedSvcName := provider.GetQualifiedName(ctx, b.serviceName)
+
+	proxy, err := m.proxyBuilder.Build(service.ServersTransport, target, b.passHostHeader, server.PreservePath, b.flushInterval)
+	if err != nil {
+		return nil, fmt.Errorf("error building proxy for server URL %s: %w", server.URL, err)
 	}
 
-	if service.HealthCheck != nil {
-		roundTripper, err := m.transportManager.GetRoundTripper(service.ServersTransport)
-		if err != nil {
-			return nil, fmt.Errorf("getting RoundTripper: %w", err)
-		}
+	if b.drainer != nil {
+		proxy = b.drainer.WrapHandler(server.URL, proxy)
+	}
 
-		m.healthCheckers[serviceName] = healthcheck.NewServiceHealthChecker(
-			ctx,
-			m.observabilityMgr.MetricsRegistry(),
-			service.HealthCheck,
-			lb,
-			info,
-			roundTripper,
-			healthCheckTargets,
-			serviceName,
-		)
+	if b.passiveHealthChecker != nil {
+		// If passive health check is enabled, we wrap the proxy with the passive health checker.
+		proxy = b.passiveHealthChecker.WrapHandler(ctx, proxy, target.String())
 	}
 
-	return lb, nil
+	// The retry wrapping must be done just before the proxy handler,
+	// to make sure that the retry will not be triggered/disabled by
+	// middlewares in the chain.
+	proxy = retry.WrapHandler(proxy)
+
+	if b.retryBudget != nil {
+		proxy = b.retryBudget.WrapHandler(proxy)
+	}
+
+	// Access logs, metrics, and tracing middlewares are idempotent if the associated signal is disabled.
+	proxy = accesslog.NewFieldHandler(proxy, accesslog.ServiceURL, target.String(), nil)
+	proxy = accesslog.NewFieldHandler(proxy, accesslog.ServiceAddr, target.Host, nil)
+	proxy = accesslog.NewFieldHandler(proxy, accesslog.ServiceName, qualifiedSvcName, accesslog.AddServiceFields)
+
+	metricsHandler := metricsMiddle.ServiceMetricsHandler(ctx, m.observabilityMgr.MetricsRegistry(), qualifiedSvcName)
+	metricsHandler = observability.WrapMiddleware(ctx, metricsHandler)
+
+	proxy, err = alice.New().
+		Append(metricsHandler).
+		Then(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("error wrapping metrics handler: %w", err)
+	}
+
+	return observability.NewService(ctx, qualifiedSvcName, proxy), nil
 }
 
 type serverBalancer interface {
 	http.Handler
 	healthcheck.StatusSetter
+	healthcheck.StatusUpdater
 
 	AddServer(name string, handler http.Handler, server dynamic.Server)
+	RemoveServer(name string)
+}
+
+// slowStarter is implemented by the load-balancers ramping up the weight of their started and recovered servers.
+type slowStarter interface {
+	SetSlowStart(slowStart *loadbalancer.SlowStart)
+}
+
+// newServerBalancer creates a load-balancer of the strategy of the given service, ramping up the weight of
+// its started and recovered servers with the given SlowStart, if any.
+func newServerBalancer(service *dynamic.ServersLoadBalancer, slowStart *loadbalancer.SlowStart) (serverBalancer, error) {
+	var lb serverBalancer
+	switch service.Strategy {
+	// Here we are handling the empty value to comply with providers that are not applying defaults (e.g. REST provider)
+	// TODO: remove this empty check when all providers apply default values.
+	case dynamic.BalancerStrategyWRR, "":
+		lb = wrr.New(service.Sticky, service.HealthCheck != nil)
+	case dynamic.BalancerStrategyP2C:
+		lb = p2c.New(service.Sticky, service.HealthCheck != nil)
+	case dynamic.BalancerStrategyHRW:
+		lb = hrw.New(service.HealthCheck != nil)
+	case dynamic.BalancerStrategyLeastTime:
+		lb = leasttime.New(service.Sticky, service.HealthCheck != nil)
+	default:
+		return nil, fmt.Errorf("unsupported load-balancer strategy %q", service.Strategy)
+	}
+
+	if balancer, ok := lb.(slowStarter); ok && slowStart != nil {
+		balancer.SetSlowStart(slowStart)
+	}
+
+	return lb, nil
 }
 
 func shuffle[T any](values []T, r *rand.Rand) []T {
@@ -544,3 +902,30 @@ func shuffle[T any](values []T, r *rand.Rand) []T {
 
 	return shuffled
 }
+
+// removeServerStatuses removes the statuses of the previous servers of a service which are not in the given ones.
+// With a Drainer, the removed servers are drained first, and the servers added back are no longer drained.
+func removeServerStatuses(info *runtime.ServiceInfo, drainer *loadbalancer.Drainer, previous, servers map[string]dynamic.Server) {
+	if drainer != nil {
+		for name := range servers {
+			drainer.Restore(name)
+		}
+	}
+
+	for name := range previous {
+		if _, ok := servers[name]; ok {
+			continue
+		}
+
+		if drainer == nil {
+			info.RemoveServerStatus(name)
+			continue
+		}
+
+		info.UpdateServerStatus(name, runtime.StatusDraining)
+		drainer.Drain(name, func() {
+			// The server might have been added back in the meantime.
+			info.RemoveServerStatusIf(name, runtime.StatusDraining)
+		})
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"net/http/httptest"
 	"net/http/httptrace"
 	"net/textproto"
+	"net/url"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -19,6 +21,7 @@ import (
 	"github.com/hanzoai/ingress/pkg/config/runtime"
 	"github.com/hanzoai/ingress/pkg/proxy/httputil"
 	"github.com/hanzoai/ingress/pkg/server/provider"
+	"github.com/hanzoai/ingress/pkg/server/service/discovery"
 	"github.com/hanzoai/ingress/pkg/testhelpers"
 )
 
@@ -400,6 +403,226 @@ func TestGetLoadBalancerServiceHandler(t *testing.T) {
 	}
 }
 
+func TestGetLoadBalancerServiceHandler_DNS(t *testing.T) {
+	server1 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-From", "first")
+	}))
+	t.Cleanup(server1.Close)
+
+	server2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-From", "second")
+	}))
+	t.Cleanup(server2.Close)
+
+	static := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-From", "static")
+	}))
+	t.Cleanup(static.Close)
+
+	watcher := &dnsWatcherMock{targets: []discovery.Target{toTarget(t, server1.URL)}}
+
+	pb := httputil.NewProxyBuilder(&transportManagerMock{}, nil)
+	sm := NewManager(nil, nil, nil, transportManagerMock{}, pb)
+	sm.SetDNSDiscovery(watcher)
+
+	serviceInfo := &runtime.ServiceInfo{Service: &dynamic.Service{LoadBalancer: &dynamic.ServersLoadBalancer{
+		Strategy: dynamic.BalancerStrategyWRR,
+		Servers:  []dynamic.Server{{URL: static.URL}},
+		DNS:      &dynamic.DNSDiscovery{Name: "legacy.example.com", RecordType: "A", Port: 80},
+	}}}
+
+	handler, err := sm.getLoadBalancerServiceHandler(t.Context(), "test", serviceInfo)
+	require.NoError(t, err)
+
+	assert.Equal(t, dynamic.DNSDiscovery{Name: "legacy.example.com", RecordType: "A", Port: 80}, watcher.config)
+
+	served := func() []string {
+		var xFrom []string
+		for range 4 {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://callme", nil))
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			xFrom = append(xFrom, recorder.Header().Get("X-From"))
+		}
+		return xFrom
+	}
+
+	assert.ElementsMatch(t, []string{"first", "first", "static", "static"}, served())
+	assert.Equal(t, map[string]string{server1.URL: runtime.StatusUp, static.URL: runtime.StatusUp}, serviceInfo.GetAllStatus())
+
+	// The discovered servers are replaced without rebuilding the service.
+	target := toTarget(t, server2.URL)
+	target.Weight = 3
+	watcher.update([]discovery.Target{target})
+
+	assert.ElementsMatch(t, []string{"second", "second", "second", "static"}, served())
+	assert.Equal(t, map[string]string{server2.URL: runtime.StatusUp, static.URL: runtime.StatusUp}, serviceInfo.GetAllStatus())
+}
+
+func TestGetLoadBalancerServiceHandler_DNS_buildError(t *testing.T) {
+	watcher := &dnsWatcherMock{}
+
+	sm := NewManager(nil, nil, nil, transportManagerMock{}, nil)
+	sm.SetDNSDiscovery(watcher)
+
+	serviceInfo := &runtime.ServiceInfo{Service: &dynamic.Service{LoadBalancer: &dynamic.ServersLoadBalancer{
+		Strategy: dynamic.BalancerStrategyWRR,
+		Servers:  []dynamic.Server{{URL: ":invalid"}},
+		DNS:      &dynamic.DNSDiscovery{Name: "legacy.example.com", RecordType: "A", Port: 80},
+	}}}
+
+	_, err := sm.getLoadBalancerServiceHandler(t.Context(), "test", serviceInfo)
+	require.Error(t, err)
+
+	// The discovery is stopped along with the failed service.
+	assert.ErrorIs(t, watcher.ctx.Err(), context.Canceled)
+}
+
+func TestGetLoadBalancerServiceHandler_Draining(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	removed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.Header().Set("X-From", "removed")
+	}))
+	t.Cleanup(removed.Close)
+
+	added := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-From", "added")
+	}))
+	t.Cleanup(added.Close)
+
+	pb := httputil.NewProxyBuilder(&transportManagerMock{}, nil)
+	sm := NewManager(nil, nil, nil, transportManagerMock{}, pb)
+
+	serviceInfo := &runtime.ServiceInfo{Service: &dynamic.Service{LoadBalancer: &dynamic.ServersLoadBalancer{
+		Strategy:     dynamic.BalancerStrategyWRR,
+		Servers:      []dynamic.Server{{URL: removed.URL}},
+		DrainTimeout: pointer(ptypes.Duration(time.Minute)),
+	}}}
+
+	handler, err := sm.getLoadBalancerServiceHandler(t.Context(), "test", serviceInfo)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://callme", nil))
+	}()
+	<-started
+
+	balancer, ok := handler.(*serversBalancer)
+	require.True(t, ok)
+	_, err = balancer.update(t.Context(), []dynamic.Server{{URL: added.URL}})
+	require.NoError(t, err)
+
+	// The removed server no longer receives new requests, but its in-flight request is allowed to finish.
+	assert.Equal(t, map[string]string{removed.URL: runtime.StatusDraining, added.URL: runtime.StatusUp}, serviceInfo.GetAllStatus())
+
+	newRecorder := httptest.NewRecorder()
+	handler.ServeHTTP(newRecorder, httptest.NewRequest(http.MethodGet, "http://callme", nil))
+	assert.Equal(t, "added", newRecorder.Header().Get("X-From"))
+
+	close(release)
+	<-served
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "removed", recorder.Header().Get("X-From"))
+	assert.Equal(t, map[string]string{added.URL: runtime.StatusUp}, serviceInfo.GetAllStatus())
+}
+
+func TestGetLoadBalancerServiceHandler_SlowStart(t *testing.T) {
+	newServer := func(name string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-From", name)
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	warm := newServer("warm")
+	joining := newServer("joining")
+
+	pb := httputil.NewProxyBuilder(&transportManagerMock{}, nil)
+	sm := NewManager(map[string]*runtime.ServiceInfo{
+		"test": {Service: &dynamic.Service{LoadBalancer: &dynamic.ServersLoadBalancer{
+			Strategy:  dynamic.BalancerStrategyWRR,
+			Servers:   []dynamic.Server{{URL: warm.URL}},
+			SlowStart: &dynamic.SlowStart{Window: ptypes.Duration(time.Hour), MinWeightPercent: 10},
+		}}},
+	}, nil, nil, transportManagerMock{}, pb)
+
+	handler, err := sm.BuildHTTP(t.Context(), "test")
+	require.NoError(t, err)
+
+	require.NoError(t, sm.UpdateServers(t.Context(), "test", []dynamic.Server{{URL: warm.URL}, {URL: joining.URL}}))
+
+	served := make(map[string]int)
+	for range 110 {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://callme", nil))
+		served[recorder.Header().Get("X-From")]++
+	}
+
+	// The server which was already there keeps its full weight, while the joining server warms up with a tenth of it.
+	assert.InDelta(t, 100, served["warm"], 2)
+	assert.InDelta(t, 10, served["joining"], 2)
+}
+
+func TestGetLoadBalancerServiceHandler_UpdateServersInPlace(t *testing.T) {
+	newServer := func(name string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-From", name)
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	down := newServer("down")
+	up := newServer("up")
+	added := newServer("added")
+
+	pb := httputil.NewProxyBuilder(&transportManagerMock{}, nil)
+	sm := NewManager(map[string]*runtime.ServiceInfo{
+		"test": {Service: &dynamic.Service{LoadBalancer: &dynamic.ServersLoadBalancer{
+			Strategy: dynamic.BalancerStrategyWRR,
+			Servers:  []dynamic.Server{{URL: down.URL}, {URL: up.URL}},
+		}}},
+	}, nil, nil, transportManagerMock{}, pb)
+
+	handler, err := sm.BuildHTTP(t.Context(), "test")
+	require.NoError(t, err)
+
+	balancer, ok := sm.balancers["test"]
+	require.True(t, ok)
+	balancer.SetStatus(t.Context(), down.URL, false)
+
+	require.NoError(t, sm.UpdateServers(t.Context(), "test", []dynamic.Server{{URL: down.URL}, {URL: up.URL}, {URL: added.URL}}))
+
+	served := make(map[string]int)
+	for range 10 {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://callme", nil))
+		served[recorder.Header().Get("X-From")]++
+	}
+
+	// The load-balancer is updated in place, so the server which is down stays down.
+	assert.Equal(t, map[string]int{"up": 5, "added": 5}, served)
+}
+
+func TestGetLoadBalancerServiceHandler_DNS_notAvailable(t *testing.T) {
+	sm := NewManager(nil, nil, nil, transportManagerMock{}, nil)
+
+	serviceInfo := &runtime.ServiceInfo{Service: &dynamic.Service{LoadBalancer: &dynamic.ServersLoadBalancer{
+		DNS: &dynamic.DNSDiscovery{Name: "_http._tcp.legacy.example.com"},
+	}}}
+
+	_, err := sm.getLoadBalancerServiceHandler(t.Context(), "test", serviceInfo)
+	require.EqualError(t, err, "DNS discovery is not available")
+}
+
 // This test is an adapted version of net/http/httputil.Test1xxResponses test.
 func Test1xxResponses(t *testing.T) {
 	pb := httputil.NewProxyBuilder(&transportManagerMock{}, nil)
@@ -752,3 +975,30 @@ func (t transportManagerMock) GetTLSConfig(_ string) (*tls.Config, error) {
 func (t transportManagerMock) Get(_ string) (*dynamic.ServersTransport, error) {
 	return &dynamic.ServersTransport{}, nil
 }
+
+type dnsWatcherMock struct {
+	ctx     context.Context
+	config  dynamic.DNSDiscovery
+	targets []discovery.Target
+	update  func(targets []discovery.Target)
+}
+
+func (w *dnsWatcherMock) Watch(ctx context.Context, config dynamic.DNSDiscovery, update func(targets []discovery.Target)) {
+	w.ctx = ctx
+	w.config = config
+	w.update = update
+
+	update(w.targets)
+}
+
+func toTarget(t *testing.T, rawURL string) discovery.Target {
+	t.Helper()
+
+	u, err := url.Parse(rawURL)
+	require.NoError(t, err)
+
+	port, err := strconv.Atoi(u.Port())
+	require.NoError(t, err)
+
+	return discovery.Target{Host: u.Hostname(), Port: port}
+}
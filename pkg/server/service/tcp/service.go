@@ -8,14 +8,19 @@ import (
 	"math/rand"
 	"net"
 	"slices"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
 	"github.com/hanzoai/ingress/pkg/config/runtime"
 	"github.com/hanzoai/ingress/pkg/healthcheck"
 	"github.com/hanzoai/ingress/pkg/observability/logs"
 	"github.com/hanzoai/ingress/pkg/server/provider"
+	"github.com/hanzoai/ingress/pkg/server/service/discovery"
+	"github.com/hanzoai/ingress/pkg/server/service/loadbalancer"
 	"github.com/hanzoai/ingress/pkg/tcp"
+	"k8s.io/utils/ptr"
 )
 
 // Manager is the TCPHandlers factory.
@@ -24,18 +29,30 @@ type Manager struct {
 	configs        map[string]*runtime.TCPServiceInfo
 	rand           *rand.Rand // For the initial shuffling of load-balancers.
 	healthCheckers map[string]*healthcheck.ServiceTCPHealthChecker
+	dnsDiscovery   discovery.Watcher
+
+	cancelHealthChecks map[string]context.CancelFunc
+	// balancers are the load-balancers built for each service, by qualified name.
+	balancers map[string][]*switchableBalancer
 }
 
 // NewManager creates a new manager.
 func NewManager(conf *runtime.Configuration, dialerManager *tcp.DialerManager) *Manager {
 	return &Manager{
-		dialerManager:  dialerManager,
-		healthCheckers: make(map[string]*healthcheck.ServiceTCPHealthChecker),
-		configs:        conf.TCPServices,
-		rand:           rand.New(rand.NewSource(time.Now().UnixNano())),
+		dialerManager:      dialerManager,
+		healthCheckers:     make(map[string]*healthcheck.ServiceTCPHealthChecker),
+		cancelHealthChecks: make(map[string]context.CancelFunc),
+		balancers:          make(map[string][]*switchableBalancer),
+		configs:            conf.TCPServices,
+		rand:               rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
+// SetDNSDiscovery sets the Watcher discovering the servers of the load-balancers through DNS.
+func (m *Manager) SetDNSDiscovery(dnsDiscovery discovery.Watcher) {
+	m.dnsDiscovery = dnsDiscovery
+}
+
 // BuildTCP Creates a tcp.Handler for a service configuration.
 func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Handler, error) {
 	serviceQualifiedName := provider.GetQualifiedName(rootCtx, serviceName)
@@ -56,8 +73,6 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 
 	switch {
 	case conf.LoadBalancer != nil:
-		loadBalancer := tcp.NewWRRLoadBalancer(conf.LoadBalancer.HealthCheck != nil)
-
 		if conf.LoadBalancer.TerminationDelay != nil {
 			log.Ctx(ctx).Warn().Msgf("Service %q load balancer uses `TerminationDelay`, but this option is deprecated, please use ServersTransport configuration instead.", serviceName)
 		}
@@ -70,54 +85,11 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 			conf.LoadBalancer.ServersTransport = provider.GetQualifiedName(ctx, conf.LoadBalancer.ServersTransport)
 		}
 
-		uniqHealthCheckTargets := make(map[string]healthcheck.TCPHealthCheckTarget, len(conf.LoadBalancer.Servers))
-
-		for index, server := range shuffle(conf.LoadBalancer.Servers, m.rand) {
-			srvLogger := logger.With().
-				Int(logs.ServerIndex, index).
-				Str("serverAddress", server.Address).Logger()
-
-			if _, _, err := net.SplitHostPort(server.Address); err != nil {
-				srvLogger.Error().Err(err).Msg("Failed to split host port")
-				continue
-			}
-
-			dialer, err := m.dialerManager.Build(conf.LoadBalancer, server.TLS)
-			if err != nil {
-				return nil, err
-			}
-
-			handler, err := tcp.NewProxy(server.Address, dialer)
-			if err != nil {
-				srvLogger.Error().Err(err).Msg("Failed to create server")
-				continue
-			}
-
-			loadBalancer.Add(server.Address, handler, nil)
-
-			// Servers are considered UP by default.
-			conf.UpdateServerStatus(server.Address, runtime.StatusUp)
-
-			uniqHealthCheckTargets[server.Address] = healthcheck.TCPHealthCheckTarget{
-				Address: server.Address,
-				TLS:     server.TLS,
-				Dialer:  dialer,
-			}
-
-			logger.Debug().Msg("Creating TCP server")
-		}
-
-		if conf.LoadBalancer.HealthCheck != nil {
-			m.healthCheckers[serviceName] = healthcheck.NewServiceTCPHealthChecker(
-				ctx,
-				conf.LoadBalancer.HealthCheck,
-				loadBalancer,
-				conf,
-				slices.Collect(maps.Values(uniqHealthCheckTargets)),
-				serviceQualifiedName)
+		if conf.LoadBalancer.DNS != nil {
+			return m.getDNSLoadBalancer(ctx, serviceName, conf)
 		}
 
-		return loadBalancer, nil
+		return m.getLoadBalancer(ctx, serviceName, conf)
 
 	case conf.Weighted != nil:
 		loadBalancer := tcp.NewWRRLoadBalancer(conf.Weighted.HealthCheck != nil)
@@ -162,9 +134,332 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 // LaunchHealthCheck launches the health checks.
 func (m *Manager) LaunchHealthCheck(ctx context.Context) {
 	for serviceName, hc := range m.healthCheckers {
-		logger := log.Ctx(ctx).With().Str(logs.ServiceName, serviceName).Logger()
-		go hc.Launch(logger.WithContext(ctx))
+		m.launchHealthCheck(ctx, serviceName, hc)
+	}
+}
+
+// launchHealthCheck launches the given health check of a service, and stops its previous one.
+func (m *Manager) launchHealthCheck(ctx context.Context, serviceName string, hc *healthcheck.ServiceTCPHealthChecker) {
+	if cancel, ok := m.cancelHealthChecks[serviceName]; ok {
+		cancel()
+	}
+
+	logger := log.Ctx(ctx).With().Str(logs.ServiceName, serviceName).Logger()
+
+	hcCtx, cancel := context.WithCancel(logger.WithContext(ctx))
+	m.cancelHealthChecks[serviceName] = cancel
+
+	go hc.Launch(hcCtx)
+}
+
+// UpdateServers replaces the servers of the given load-balancer service,
+// without rebuilding the routers and the services using it, and restarts its health check.
+// It has no effect on a service which has not been built.
+func (m *Manager) UpdateServers(ctx context.Context, serviceName string, servers []dynamic.TCPServer) error {
+	serviceQualifiedName := provider.GetQualifiedName(ctx, serviceName)
+
+	for _, balancer := range m.balancers[serviceQualifiedName] {
+		if balancer.updateServers == nil {
+			return fmt.Errorf("the servers of the service %q are discovered", serviceQualifiedName)
+		}
+
+		if err := balancer.updateServers(shuffle(servers, m.rand)); err != nil {
+			return err
+		}
+	}
+
+	if healthChecker, ok := m.healthCheckers[serviceQualifiedName]; ok {
+		m.launchHealthCheck(ctx, serviceQualifiedName, healthChecker)
+	}
+
+	return nil
+}
+
+// getLoadBalancer creates the load-balancer of a service, whose servers can be updated with UpdateServers.
+func (m *Manager) getLoadBalancer(ctx context.Context, serviceName string, conf *runtime.TCPServiceInfo) (tcp.Handler, error) {
+	serviceQualifiedName := provider.GetQualifiedName(ctx, serviceName)
+	lbConf := conf.LoadBalancer
+
+	balancer := &switchableBalancer{}
+	var previous []weightedServer
+	drainer := newDrainer(lbConf)
+
+	updateServers := func(tcpServers []dynamic.TCPServer) error {
+		servers := make([]weightedServer, 0, len(tcpServers))
+		for _, server := range tcpServers {
+			servers = append(servers, weightedServer{TCPServer: server})
+		}
+
+		loadBalancer, healthChecker, err := m.buildLoadBalancer(ctx, serviceName, conf, lbConf, servers, drainer)
+		if err != nil {
+			return err
+		}
+
+		balancer.switchTo(ctx, loadBalancer, len(servers) > 0)
+
+		removeServerStatuses(conf, drainer, previous, servers)
+		previous = servers
+
+		if healthChecker != nil {
+			m.healthCheckers[serviceQualifiedName] = healthChecker
+		}
+
+		return nil
+	}
+
+	if err := updateServers(shuffle(lbConf.Servers, m.rand)); err != nil {
+		return nil, err
+	}
+
+	balancer.updateServers = updateServers
+	m.balancers[serviceQualifiedName] = append(m.balancers[serviceQualifiedName], balancer)
+
+	return balancer, nil
+}
+
+// getDNSLoadBalancer creates the load-balancer of a service whose servers are discovered through DNS,
+// which is replaced each time the discovered servers change.
+func (m *Manager) getDNSLoadBalancer(ctx context.Context, serviceName string, conf *runtime.TCPServiceInfo) (tcp.Handler, error) {
+	if m.dnsDiscovery == nil {
+		return nil, errors.New("DNS discovery is not available")
+	}
+
+	lbConf := conf.LoadBalancer
+
+	balancer := &switchableBalancer{}
+	cancelHealthCheck := func() {}
+	drainer := newDrainer(lbConf)
+
+	// The first update happens before Watch returns, the next ones in the discovery goroutine.
+	initial := true
+	var previous []weightedServer
+	var buildErr error
+
+	// The discovery is stopped if the load-balancer cannot be built with the first discovered servers.
+	watchCtx, cancelWatch := context.WithCancel(ctx)
+	defer func() {
+		if buildErr != nil {
+			cancelWatch()
+		}
+	}()
+
+	m.dnsDiscovery.Watch(watchCtx, *lbConf.DNS, func(targets []discovery.Target) {
+		defer func() { initial = false }()
+
+		servers := make([]weightedServer, 0, len(lbConf.Servers)+len(targets))
+		for _, server := range lbConf.Servers {
+			servers = append(servers, weightedServer{TCPServer: server})
+		}
+		for _, target := range targets {
+			server := weightedServer{TCPServer: dynamic.TCPServer{Address: target.Address(), TLS: lbConf.DNS.TLS}}
+			if target.Weight > 0 {
+				server.weight = ptr.To(target.Weight)
+			}
+			servers = append(servers, server)
+		}
+
+		// The package level shuffling is used, as the discovered servers are updated concurrently.
+		rand.Shuffle(len(servers), func(i, j int) { servers[i], servers[j] = servers[j], servers[i] })
+
+		loadBalancer, healthChecker, err := m.buildLoadBalancer(ctx, serviceName, conf, lbConf, servers, drainer)
+		if err != nil {
+			if initial {
+				buildErr = err
+				return
+			}
+
+			log.Ctx(ctx).Error().Err(err).Msg("Unable to update the discovered servers")
+			return
+		}
+
+		balancer.switchTo(ctx, loadBalancer, len(servers) > 0)
+
+		removeServerStatuses(conf, drainer, previous, servers)
+		previous = servers
+
+		cancelHealthCheck()
+		if healthChecker != nil {
+			var hcCtx context.Context
+			hcCtx, cancelHealthCheck = context.WithCancel(ctx)
+			go healthChecker.Launch(hcCtx)
+		}
+	})
+
+	if buildErr != nil {
+		return nil, buildErr
+	}
+
+	return balancer, nil
+}
+
+// buildLoadBalancer creates a load-balancer of the given servers, configured by lbConf,
+// and its health checker when the health check is enabled.
+// The connections to the servers are tracked by the given Drainer, if any.
+func (m *Manager) buildLoadBalancer(ctx context.Context, serviceName string, conf *runtime.TCPServiceInfo, lbConf *dynamic.TCPServersLoadBalancer, servers []weightedServer, drainer *loadbalancer.Drainer) (*tcp.WRRLoadBalancer, *healthcheck.ServiceTCPHealthChecker, error) {
+	serviceQualifiedName := provider.GetQualifiedName(ctx, serviceName)
+	logger := log.Ctx(ctx).With().Str(logs.ServiceName, serviceQualifiedName).Logger()
+
+	loadBalancer := tcp.NewWRRLoadBalancer(lbConf.HealthCheck != nil)
+
+	uniqHealthCheckTargets := make(map[string]healthcheck.TCPHealthCheckTarget, len(servers))
+
+	for index, server := range servers {
+		srvLogger := logger.With().
+			Int(logs.ServerIndex, index).
+			Str("serverAddress", server.Address).Logger()
+
+		if _, _, err := net.SplitHostPort(server.Address); err != nil {
+			srvLogger.Error().Err(err).Msg("Failed to split host port")
+			continue
+		}
+
+		dialer, err := m.dialerManager.Build(lbConf, server.TLS)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		handler, err := tcp.NewProxy(server.Address, dialer)
+		if err != nil {
+			srvLogger.Error().Err(err).Msg("Failed to create server")
+			continue
+		}
+
+		if drainer == nil {
+			loadBalancer.Add(server.Address, handler, server.weight)
+		} else {
+			loadBalancer.Add(server.Address, drainHandler(drainer, server.Address, handler), server.weight)
+		}
+
+		// Servers are considered UP by default.
+		conf.UpdateServerStatus(server.Address, runtime.StatusUp)
+
+		uniqHealthCheckTargets[server.Address] = healthcheck.TCPHealthCheckTarget{
+			Address: server.Address,
+			TLS:     server.TLS,
+			Dialer:  dialer,
+		}
+
+		logger.Debug().Msg("Creating TCP server")
+	}
+
+	if lbConf.HealthCheck == nil {
+		return loadBalancer, nil, nil
 	}
+
+	healthChecker := healthcheck.NewServiceTCPHealthChecker(
+		ctx,
+		lbConf.HealthCheck,
+		loadBalancer,
+		conf,
+		slices.Collect(maps.Values(uniqHealthCheckTargets)),
+		serviceQualifiedName)
+
+	return loadBalancer, healthChecker, nil
+}
+
+// weightedServer is a server of a load-balancer, with the weight of its SRV record when it is discovered through DNS.
+type weightedServer struct {
+	dynamic.TCPServer
+
+	weight *int
+}
+
+// removeServerStatuses removes the statuses of the previous servers of a service which are not in the given ones.
+// With a Drainer, the removed servers are drained first, and the servers added back are no longer drained.
+func removeServerStatuses(conf *runtime.TCPServiceInfo, drainer *loadbalancer.Drainer, previous, servers []weightedServer) {
+	if drainer != nil {
+		for _, server := range servers {
+			drainer.Restore(server.Address)
+		}
+	}
+
+	for _, server := range previous {
+		if slices.ContainsFunc(servers, func(s weightedServer) bool { return s.Address == server.Address }) {
+			continue
+		}
+
+		if drainer == nil {
+			conf.RemoveServerStatus(server.Address)
+			continue
+		}
+
+		conf.UpdateServerStatus(server.Address, runtime.StatusDraining)
+		drainer.Drain(server.Address, func() {
+			// The server might have been added back in the meantime.
+			conf.RemoveServerStatusIf(server.Address, runtime.StatusDraining)
+		})
+	}
+}
+
+// newDrainer creates the Drainer of the servers of the given load-balancer, if their draining is enabled.
+// It is replaced when the routers are rebuilt, so the servers removed by a rebuild are not drained.
+func newDrainer(lbConf *dynamic.TCPServersLoadBalancer) *loadbalancer.Drainer {
+	if lbConf.DrainTimeout == nil || *lbConf.DrainTimeout <= 0 {
+		return nil
+	}
+
+	return loadbalancer.NewDrainer(time.Duration(*lbConf.DrainTimeout))
+}
+
+// drainHandler wraps the handler of the given server to track its connections,
+// and to close them when the server is drained.
+func drainHandler(drainer *loadbalancer.Drainer, name string, next tcp.Handler) tcp.Handler {
+	return tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+		drainCtx, done := drainer.Track(name)
+		defer done()
+
+		stop := context.AfterFunc(drainCtx, func() {
+			_ = conn.Close()
+		})
+		defer stop()
+
+		next.ServeTCP(conn)
+	})
+}
+
+// switchableBalancer is the load-balancer of a service whose servers are updated,
+// forwarding the connections to the load-balancer of the current servers.
+type switchableBalancer struct {
+	tcp.HandlerSwitcher
+
+	mu       sync.Mutex
+	balancer *tcp.WRRLoadBalancer
+	updaters []func(up bool)
+
+	// updateServers replaces the servers of the load-balancer, it is nil when they are discovered.
+	updateServers func(servers []dynamic.TCPServer) error
+}
+
+// RegisterStatusUpdater adds fn to the list of hooks that are run when the status of the load-balancer changes,
+// including when the load-balancer is replaced.
+func (b *switchableBalancer) RegisterStatusUpdater(fn func(up bool)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.balancer.RegisterStatusUpdater(fn); err != nil {
+		return err
+	}
+
+	b.updaters = append(b.updaters, fn)
+	return nil
+}
+
+// switchTo replaces the load-balancer with the given one, which is up if it has servers.
+func (b *switchableBalancer) switchTo(ctx context.Context, balancer *tcp.WRRLoadBalancer, up bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, fn := range b.updaters {
+		if err := balancer.RegisterStatusUpdater(fn); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Unable to register status updater")
+			continue
+		}
+
+		fn(up)
+	}
+
+	b.balancer = balancer
+	b.Switch(balancer)
 }
 
 func shuffle[T any](values []T, r *rand.Rand) []T {
@@ -1,14 +1,21 @@
 package tcp
 
 import (
+	"context"
+	"io"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	ptypes "github.com/hanzoai/ingress-parser/types"
 	"github.com/hanzoai/ingress/pkg/config/dynamic"
 	"github.com/hanzoai/ingress/pkg/config/runtime"
 	"github.com/hanzoai/ingress/pkg/server/provider"
+	"github.com/hanzoai/ingress/pkg/server/service/discovery"
 	"github.com/hanzoai/ingress/pkg/tcp"
+	"k8s.io/utils/ptr"
 )
 
 func TestManager_BuildTCP(t *testing.T) {
@@ -308,3 +315,190 @@ func TestManager_BuildTCP(t *testing.T) {
 		})
 	}
 }
+
+func TestManager_BuildTCP_DNS(t *testing.T) {
+	configs := map[string]*runtime.TCPServiceInfo{
+		"test@provider-1": {
+			TCPService: &dynamic.TCPService{
+				LoadBalancer: &dynamic.TCPServersLoadBalancer{
+					Servers: []dynamic.TCPServer{{Address: "192.168.0.1:3306"}},
+					DNS:     &dynamic.DNSDiscovery{Name: "_mysql._tcp.legacy.example.com", RecordType: "SRV"},
+				},
+			},
+		},
+	}
+
+	watcher := &dnsWatcherMock{targets: []discovery.Target{
+		{Host: "vm1.example.com", Port: 3306, Weight: 10},
+		{Host: "vm2.example.com", Port: 3306, Weight: 5},
+	}}
+
+	dialerManager := tcp.NewDialerManager(nil)
+	dialerManager.Update(map[string]*dynamic.TCPServersTransport{"default@internal": {}})
+
+	manager := NewManager(&runtime.Configuration{TCPServices: configs}, dialerManager)
+	manager.SetDNSDiscovery(watcher)
+
+	handler, err := manager.BuildTCP(provider.AddInContext(t.Context(), "test@provider-1"), "test")
+	require.NoError(t, err)
+	require.NotNil(t, handler)
+
+	assert.Equal(t, "_mysql._tcp.legacy.example.com", watcher.config.Name)
+	assert.Equal(t, map[string]string{
+		"192.168.0.1:3306":     runtime.StatusUp,
+		"vm1.example.com:3306": runtime.StatusUp,
+		"vm2.example.com:3306": runtime.StatusUp,
+	}, configs["test@provider-1"].GetAllStatus())
+
+	watcher.update([]discovery.Target{{Host: "vm3.example.com", Port: 3306, Weight: 1}})
+
+	assert.Equal(t, map[string]string{
+		"192.168.0.1:3306":     runtime.StatusUp,
+		"vm3.example.com:3306": runtime.StatusUp,
+	}, configs["test@provider-1"].GetAllStatus())
+}
+
+func TestManager_BuildTCP_DNS_notAvailable(t *testing.T) {
+	manager := NewManager(&runtime.Configuration{TCPServices: map[string]*runtime.TCPServiceInfo{
+		"test@provider-1": {
+			TCPService: &dynamic.TCPService{
+				LoadBalancer: &dynamic.TCPServersLoadBalancer{
+					DNS: &dynamic.DNSDiscovery{Name: "_mysql._tcp.legacy.example.com"},
+				},
+			},
+		},
+	}}, tcp.NewDialerManager(nil))
+
+	_, err := manager.BuildTCP(provider.AddInContext(t.Context(), "test@provider-1"), "test")
+	require.EqualError(t, err, "DNS discovery is not available")
+}
+
+func TestManager_UpdateServers(t *testing.T) {
+	configs := map[string]*runtime.TCPServiceInfo{
+		"test@provider-1": {
+			TCPService: &dynamic.TCPService{
+				LoadBalancer: &dynamic.TCPServersLoadBalancer{
+					Servers: []dynamic.TCPServer{{Address: "192.168.0.1:3306"}, {Address: "192.168.0.2:3306"}},
+				},
+			},
+		},
+	}
+
+	dialerManager := tcp.NewDialerManager(nil)
+	dialerManager.Update(map[string]*dynamic.TCPServersTransport{"default@internal": {}})
+
+	manager := NewManager(&runtime.Configuration{TCPServices: configs}, dialerManager)
+
+	// The servers of a service which has not been built are not updated.
+	require.NoError(t, manager.UpdateServers(t.Context(), "test@provider-1", []dynamic.TCPServer{{Address: "192.168.0.3:3306"}}))
+	assert.Empty(t, configs["test@provider-1"].GetAllStatus())
+
+	_, err := manager.BuildTCP(provider.AddInContext(t.Context(), "test@provider-1"), "test")
+	require.NoError(t, err)
+
+	err = manager.UpdateServers(t.Context(), "test@provider-1", []dynamic.TCPServer{{Address: "192.168.0.2:3306"}, {Address: "192.168.0.3:3306"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"192.168.0.2:3306": runtime.StatusUp,
+		"192.168.0.3:3306": runtime.StatusUp,
+	}, configs["test@provider-1"].GetAllStatus())
+
+	err = manager.UpdateServers(t.Context(), "test@provider-1", nil)
+	require.NoError(t, err)
+
+	assert.Empty(t, configs["test@provider-1"].GetAllStatus())
+}
+
+func TestManager_UpdateServers_Draining(t *testing.T) {
+	backend, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = backend.Close() })
+
+	go func() {
+		for {
+			conn, err := backend.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	configs := map[string]*runtime.TCPServiceInfo{
+		"test@provider-1": {
+			TCPService: &dynamic.TCPService{
+				LoadBalancer: &dynamic.TCPServersLoadBalancer{
+					Servers:      []dynamic.TCPServer{{Address: backend.Addr().String()}},
+					DrainTimeout: ptr.To(ptypes.Duration(50 * time.Millisecond)),
+				},
+			},
+		},
+	}
+
+	dialerManager := tcp.NewDialerManager(nil)
+	dialerManager.Update(map[string]*dynamic.TCPServersTransport{"default@internal": {}})
+
+	manager := NewManager(&runtime.Configuration{TCPServices: configs}, dialerManager)
+
+	handler, err := manager.BuildTCP(provider.AddInContext(t.Context(), "test@provider-1"), "test")
+	require.NoError(t, err)
+
+	// The connections are accepted from a listener, as the proxied connections must be able to close their writes.
+	frontend, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = frontend.Close() })
+
+	go func() {
+		conn, err := frontend.Accept()
+		if err != nil {
+			return
+		}
+		handler.ServeTCP(conn.(tcp.WriteCloser))
+	}()
+
+	client, err := net.Dial("tcp", frontend.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	_, err = client.Write([]byte("ping"))
+	require.NoError(t, err)
+
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(client, buf)
+	require.NoError(t, err)
+
+	err = manager.UpdateServers(t.Context(), "test@provider-1", []dynamic.TCPServer{{Address: "192.168.0.2:3306"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		backend.Addr().String(): runtime.StatusDraining,
+		"192.168.0.2:3306":      runtime.StatusUp,
+	}, configs["test@provider-1"].GetAllStatus())
+
+	// The connection is closed once the drain timeout has elapsed.
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(time.Second)))
+	_, err = client.Read(buf)
+	require.ErrorIs(t, err, io.EOF)
+
+	assert.Eventually(t, func() bool {
+		return len(configs["test@provider-1"].GetAllStatus()) == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+type dnsWatcherMock struct {
+	config  dynamic.DNSDiscovery
+	targets []discovery.Target
+	update  func(targets []discovery.Target)
+}
+
+func (w *dnsWatcherMock) Watch(_ context.Context, config dynamic.DNSDiscovery, update func(targets []discovery.Target)) {
+	w.config = config
+	w.update = update
+
+	update(w.targets)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
 	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
 	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/proxy/egress"
 	ingresstls "github.com/hanzoai/ingress/pkg/tls"
 	"github.com/hanzoai/ingress/pkg/types"
 )
@@ -270,9 +271,9 @@ func (c connWithTimeouts) Write(b []byte) (n int, err error) {
 	return n, nil
 }
 
-func customDialContext(d *net.Dialer, cfg *dynamic.ForwardingTimeouts) func(ctx context.Context, network string, address string) (net.Conn, error) {
+func customDialContext(dial func(ctx context.Context, network, address string) (net.Conn, error), cfg *dynamic.ForwardingTimeouts) func(ctx context.Context, network string, address string) (net.Conn, error) {
 	return func(ctx context.Context, network, address string) (net.Conn, error) {
-		conn, err := d.DialContext(ctx, network, address)
+		conn, err := dial(ctx, network, address)
 
 		if cfg.ReadTimeout <= 0 && cfg.WriteTimeout <= 0 {
 			return conn, err
@@ -317,14 +318,40 @@ func (t *TransportManager) createRoundTripper(cfg *dynamic.ServersTransport, tls
 		TLSClientConfig:       tlsConfig,
 	}
 
+	var egressProxy *egress.Proxy
+	if cfg.Proxy != nil {
+		var err error
+		egressProxy, err = egress.New(cfg.Proxy)
+		if err != nil {
+			return nil, err
+		}
+
+		if egressProxy.Secure() {
+			// The http.Transport would establish the TLS connection with the forward proxy with the TLS configuration of the servers,
+			// thus the connections are tunneled by the forward proxy dialer instead, which uses the TLS options of the forward proxy.
+			transport.Proxy = nil
+			transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+				return egressProxy.DialContext(ctx, dialer, network, address)
+			}
+		} else {
+			transport.Proxy = egressProxy.HTTPProxy
+		}
+	}
+
 	if cfg.ForwardingTimeouts != nil {
 		transport.ResponseHeaderTimeout = time.Duration(cfg.ForwardingTimeouts.ResponseHeaderTimeout)
 		transport.IdleConnTimeout = time.Duration(cfg.ForwardingTimeouts.IdleConnTimeout)
-		transport.DialContext = customDialContext(dialer, cfg.ForwardingTimeouts)
+		transport.DialContext = customDialContext(transport.DialContext, cfg.ForwardingTimeouts)
 	}
 
+	// The h3 servers are contacted over QUIC, and over TCP with the https scheme as a fallback.
+	h3RoundTripper := newH3RoundTripper(cfg, tlsConfig, egressProxy)
+	transport.RegisterProtocol(schemeH3, h3RoundTripper)
+
 	// Return directly HTTP/1.1 transport when HTTP/2 is disabled
 	if cfg.DisableHTTP2 {
+		h3RoundTripper.fallback = transport
+
 		return &kerberosRoundTripper{
 			OriginalRoundTripper: transport,
 			new: func() http.RoundTripper {
@@ -333,10 +360,11 @@ func (t *TransportManager) createRoundTripper(cfg *dynamic.ServersTransport, tls
 		}, nil
 	}
 
-	rt, err := newSmartRoundTripper(transport, cfg.ForwardingTimeouts)
+	rt, err := newSmartRoundTripper(transport, cfg.ForwardingTimeouts, egressProxy, dialer)
 	if err != nil {
 		return nil, err
 	}
+	h3RoundTripper.fallback = rt
 	return &kerberosRoundTripper{
 		OriginalRoundTripper: rt,
 		new: func() http.RoundTripper {
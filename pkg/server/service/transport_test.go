@@ -7,6 +7,8 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
 	"math/big"
 	"net"
 	"net/http"
@@ -17,6 +19,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/quic-go/quic-go/http3"
 	"github.com/rs/zerolog/log"
 	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
@@ -27,6 +30,7 @@ import (
 	"github.com/hanzoai/ingress/pkg/config/dynamic"
 	ingresstls "github.com/hanzoai/ingress/pkg/tls"
 	"github.com/hanzoai/ingress/pkg/types"
+	ptypes "github.com/hanzoai/ingress-parser/types"
 )
 
 // LocalhostCert is a PEM-encoded TLS cert
@@ -778,6 +782,251 @@ func TestDisableHTTP2(t *testing.T) {
 	}
 }
 
+func TestHTTP3(t *testing.T) {
+	cert, err := tls.X509KeyPair(LocalhostCert, LocalhostKey)
+	require.NoError(t, err)
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Proto", req.Proto)
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	udpConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	h3Server := &http3.Server{
+		Handler:   handler,
+		TLSConfig: http3.ConfigureTLSConfig(&tls.Config{Certificates: []tls.Certificate{cert}}),
+	}
+	go func() { _ = h3Server.Serve(udpConn) }()
+	t.Cleanup(func() { _ = h3Server.Close() })
+
+	// The TCP server has no QUIC listener on its port.
+	tcpServer := httptest.NewUnstartedServer(handler)
+	tcpServer.EnableHTTP2 = true
+	tcpServer.StartTLS()
+	t.Cleanup(tcpServer.Close)
+
+	testCases := []struct {
+		desc          string
+		url           string
+		expectedProto string
+	}{
+		{
+			desc:          "HTTP3 server",
+			url:           "h3://" + udpConn.LocalAddr().String(),
+			expectedProto: "HTTP/3.0",
+		},
+		{
+			desc:          "fallback to TCP",
+			url:           "h3://" + tcpServer.Listener.Addr().String(),
+			expectedProto: "HTTP/2.0",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			transportManager := NewTransportManager(nil)
+			transportManager.Update(map[string]*dynamic.ServersTransport{
+				"test": {
+					InsecureSkipVerify: true,
+					ForwardingTimeouts: &dynamic.ForwardingTimeouts{DialTimeout: ptypes.Duration(time.Second)},
+				},
+			})
+
+			tr, err := transportManager.GetRoundTripper("test")
+			require.NoError(t, err)
+
+			client := http.Client{Transport: tr}
+
+			// The second request reuses the QUIC connection, or is directly sent over TCP.
+			for range 2 {
+				resp, err := client.Get(test.url)
+				require.NoError(t, err)
+				_ = resp.Body.Close()
+
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, test.expectedProto, resp.Header.Get("X-Proto"))
+			}
+		})
+	}
+}
+
+func TestForwardProxy(t *testing.T) {
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Proto", req.Proto)
+		rw.WriteHeader(http.StatusOK)
+	}))
+	backend.EnableHTTP2 = true
+	backend.StartTLS()
+	t.Cleanup(backend.Close)
+
+	_, port, err := net.SplitHostPort(backend.Listener.Addr().String())
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc string
+		url  string
+	}{
+		{
+			desc: "https server",
+			url:  "https://backend.example.com:" + port,
+		},
+		{
+			desc: "h3 server",
+			url:  "h3://backend.example.com:" + port,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var connectHost atomic.Value
+			proxyServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				connectHost.Store(req.Host)
+
+				// The backend host name is resolved by the forward proxy.
+				conn, err := net.Dial("tcp", backend.Listener.Addr().String())
+				require.NoError(t, err)
+				defer conn.Close()
+
+				hj, ok := rw.(http.Hijacker)
+				require.True(t, ok)
+
+				clientConn, _, err := hj.Hijack()
+				require.NoError(t, err)
+				defer clientConn.Close()
+
+				_, err = clientConn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+				require.NoError(t, err)
+
+				go func() {
+					_, _ = io.Copy(conn, clientConn)
+					_ = conn.Close()
+				}()
+				_, _ = io.Copy(clientConn, conn)
+			}))
+			t.Cleanup(proxyServer.Close)
+
+			transportManager := NewTransportManager(nil)
+			transportManager.Update(map[string]*dynamic.ServersTransport{
+				"test": {
+					InsecureSkipVerify: true,
+					Proxy:              &dynamic.ForwardProxy{URL: proxyServer.URL},
+				},
+			})
+
+			tr, err := transportManager.GetRoundTripper("test")
+			require.NoError(t, err)
+
+			client := http.Client{Transport: tr}
+
+			resp, err := client.Get(test.url)
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, "HTTP/2.0", resp.Header.Get("X-Proto"))
+			assert.Equal(t, "backend.example.com:"+port, connectHost.Load())
+		})
+	}
+}
+
+func TestForwardProxy_https(t *testing.T) {
+	backend := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(backend.Close)
+
+	_, port, err := net.SplitHostPort(backend.Listener.Addr().String())
+	require.NoError(t, err)
+
+	var connectHost atomic.Value
+	proxyServer := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		connectHost.Store(req.Host)
+
+		conn, err := net.Dial("tcp", backend.Listener.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+
+		hj, ok := rw.(http.Hijacker)
+		require.True(t, ok)
+
+		clientConn, _, err := hj.Hijack()
+		require.NoError(t, err)
+		defer clientConn.Close()
+
+		_, err = clientConn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		require.NoError(t, err)
+
+		go func() {
+			_, _ = io.Copy(conn, clientConn)
+			_ = conn.Close()
+		}()
+		_, _ = io.Copy(clientConn, conn)
+	}))
+	t.Cleanup(proxyServer.Close)
+
+	proxyCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: proxyServer.Certificate().Raw})
+
+	testCases := []struct {
+		desc         string
+		disableHTTP2 bool
+		proxy        dynamic.ForwardProxy
+		wantErr      bool
+	}{
+		{
+			// The forward proxy is not verified with the TLS options of the servers.
+			desc:    "system root CAs",
+			wantErr: true,
+		},
+		{
+			desc:  "proxy root CAs",
+			proxy: dynamic.ForwardProxy{RootCAs: []types.FileOrContent{types.FileOrContent(proxyCA)}},
+		},
+		{
+			desc:         "proxy root CAs without HTTP/2",
+			disableHTTP2: true,
+			proxy:        dynamic.ForwardProxy{RootCAs: []types.FileOrContent{types.FileOrContent(proxyCA)}},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			proxyConfig := test.proxy
+			proxyConfig.URL = proxyServer.URL
+
+			transportManager := NewTransportManager(nil)
+			transportManager.Update(map[string]*dynamic.ServersTransport{
+				"test": {
+					InsecureSkipVerify: true,
+					DisableHTTP2:       test.disableHTTP2,
+					Proxy:              &proxyConfig,
+				},
+			})
+
+			tr, err := transportManager.GetRoundTripper("test")
+			require.NoError(t, err)
+
+			client := http.Client{Transport: tr}
+
+			resp, err := client.Get("https://backend.example.com:" + port)
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, "backend.example.com:"+port, connectHost.Load())
+		})
+	}
+}
+
 // fakeSpiffePKI simulates a SPIFFE aware PKI and allows generating multiple valid SVIDs.
 type fakeSpiffePKI struct {
 	caPrivateKey *rsa.PrivateKey
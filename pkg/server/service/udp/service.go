@@ -9,26 +9,39 @@ import (
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
 	"github.com/hanzoai/ingress/pkg/config/runtime"
 	"github.com/hanzoai/ingress/pkg/observability/logs"
 	"github.com/hanzoai/ingress/pkg/server/provider"
+	"github.com/hanzoai/ingress/pkg/server/service/discovery"
 	"github.com/hanzoai/ingress/pkg/udp"
+	"k8s.io/utils/ptr"
 )
 
 // Manager handles UDP services creation.
 type Manager struct {
-	configs map[string]*runtime.UDPServiceInfo
-	rand    *rand.Rand // For the initial shuffling of load-balancers.
+	configs      map[string]*runtime.UDPServiceInfo
+	rand         *rand.Rand // For the initial shuffling of load-balancers.
+	dnsDiscovery discovery.Watcher
+
+	// serverUpdaters replace the servers of the load-balancers built for each service, by qualified name.
+	serverUpdaters map[string][]func(servers []dynamic.UDPServer)
 }
 
 // NewManager creates a new manager.
 func NewManager(conf *runtime.Configuration) *Manager {
 	return &Manager{
-		configs: conf.UDPServices,
-		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		configs:        conf.UDPServices,
+		rand:           rand.New(rand.NewSource(time.Now().UnixNano())),
+		serverUpdaters: make(map[string][]func(servers []dynamic.UDPServer)),
 	}
 }
 
+// SetDNSDiscovery sets the Watcher discovering the servers of the load-balancers through DNS.
+func (m *Manager) SetDNSDiscovery(dnsDiscovery discovery.Watcher) {
+	m.dnsDiscovery = dnsDiscovery
+}
+
 // BuildUDP creates the UDP handler for the given service name.
 func (m *Manager) BuildUDP(rootCtx context.Context, serviceName string) (udp.Handler, error) {
 	serviceQualifiedName := provider.GetQualifiedName(rootCtx, serviceName)
@@ -49,29 +62,26 @@ func (m *Manager) BuildUDP(rootCtx context.Context, serviceName string) (udp.Han
 
 	switch {
 	case conf.LoadBalancer != nil:
-		loadBalancer := udp.NewWRRLoadBalancer()
-
-		for index, server := range shuffle(conf.LoadBalancer.Servers, m.rand) {
-			srvLogger := logger.With().
-				Int(logs.ServerIndex, index).
-				Str("serverAddress", server.Address).Logger()
+		if conf.LoadBalancer.DNS != nil {
+			return m.getDNSLoadBalancer(ctx, serviceQualifiedName, conf)
+		}
 
-			if _, _, err := net.SplitHostPort(server.Address); err != nil {
-				srvLogger.Error().Err(err).Msg("Failed to split host port")
-				continue
-			}
+		switcher := &udp.HandlerSwitcher{}
 
-			handler, err := udp.NewProxy(server.Address)
-			if err != nil {
-				srvLogger.Error().Err(err).Msg("Failed to create server")
-				continue
+		updateServers := func(udpServers []dynamic.UDPServer) {
+			servers := make([]weightedServer, 0, len(udpServers))
+			for _, server := range udpServers {
+				servers = append(servers, weightedServer{UDPServer: server})
 			}
 
-			loadBalancer.AddServer(handler)
-			srvLogger.Debug().Msg("Creating UDP server")
+			switcher.Switch(buildLoadBalancer(ctx, serviceQualifiedName, servers))
 		}
 
-		return loadBalancer, nil
+		updateServers(shuffle(conf.LoadBalancer.Servers, m.rand))
+
+		m.serverUpdaters[serviceQualifiedName] = append(m.serverUpdaters[serviceQualifiedName], updateServers)
+
+		return switcher, nil
 
 	case conf.Weighted != nil:
 		loadBalancer := udp.NewWRRLoadBalancer()
@@ -95,6 +105,80 @@ func (m *Manager) BuildUDP(rootCtx context.Context, serviceName string) (udp.Han
 	}
 }
 
+// UpdateServers replaces the servers of the given load-balancer service, without rebuilding the routers and the services using it.
+// It has no effect on a service which has not been built.
+func (m *Manager) UpdateServers(ctx context.Context, serviceName string, servers []dynamic.UDPServer) {
+	for _, updateServers := range m.serverUpdaters[provider.GetQualifiedName(ctx, serviceName)] {
+		updateServers(shuffle(servers, m.rand))
+	}
+}
+
+// getDNSLoadBalancer creates the load-balancer of a service whose servers are discovered through DNS,
+// which is replaced each time the discovered servers change.
+func (m *Manager) getDNSLoadBalancer(ctx context.Context, serviceQualifiedName string, conf *runtime.UDPServiceInfo) (udp.Handler, error) {
+	if m.dnsDiscovery == nil {
+		return nil, errors.New("DNS discovery is not available")
+	}
+
+	switcher := &udp.HandlerSwitcher{}
+	m.dnsDiscovery.Watch(ctx, *conf.LoadBalancer.DNS, func(targets []discovery.Target) {
+		servers := make([]weightedServer, 0, len(conf.LoadBalancer.Servers)+len(targets))
+		for _, server := range conf.LoadBalancer.Servers {
+			servers = append(servers, weightedServer{UDPServer: server})
+		}
+		for _, target := range targets {
+			server := weightedServer{UDPServer: dynamic.UDPServer{Address: target.Address()}}
+			if target.Weight > 0 {
+				server.weight = ptr.To(target.Weight)
+			}
+			servers = append(servers, server)
+		}
+
+		// The package level shuffling is used, as the discovered servers are updated concurrently.
+		rand.Shuffle(len(servers), func(i, j int) { servers[i], servers[j] = servers[j], servers[i] })
+
+		switcher.Switch(buildLoadBalancer(ctx, serviceQualifiedName, servers))
+	})
+
+	return switcher, nil
+}
+
+// buildLoadBalancer creates a load-balancer of the given servers.
+func buildLoadBalancer(ctx context.Context, serviceQualifiedName string, servers []weightedServer) *udp.WRRLoadBalancer {
+	logger := log.Ctx(ctx).With().Str(logs.ServiceName, serviceQualifiedName).Logger()
+
+	loadBalancer := udp.NewWRRLoadBalancer()
+
+	for index, server := range servers {
+		srvLogger := logger.With().
+			Int(logs.ServerIndex, index).
+			Str("serverAddress", server.Address).Logger()
+
+		if _, _, err := net.SplitHostPort(server.Address); err != nil {
+			srvLogger.Error().Err(err).Msg("Failed to split host port")
+			continue
+		}
+
+		handler, err := udp.NewProxy(server.Address)
+		if err != nil {
+			srvLogger.Error().Err(err).Msg("Failed to create server")
+			continue
+		}
+
+		loadBalancer.AddWeightedServer(handler, server.weight)
+		srvLogger.Debug().Msg("Creating UDP server")
+	}
+
+	return loadBalancer
+}
+
+// weightedServer is a server of a load-balancer, with the weight of its SRV record when it is discovered through DNS.
+type weightedServer struct {
+	dynamic.UDPServer
+
+	weight *int
+}
+
 func shuffle[T any](values []T, r *rand.Rand) []T {
 	shuffled := make([]T, len(values))
 	copy(shuffled, values)
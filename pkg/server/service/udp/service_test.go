@@ -1,6 +1,7 @@
 package udp
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -8,6 +9,7 @@ import (
 	"github.com/hanzoai/ingress/pkg/config/dynamic"
 	"github.com/hanzoai/ingress/pkg/config/runtime"
 	"github.com/hanzoai/ingress/pkg/server/provider"
+	"github.com/hanzoai/ingress/pkg/server/service/discovery"
 )
 
 func TestManager_BuildUDP(t *testing.T) {
@@ -197,3 +199,39 @@ func TestManager_BuildUDP(t *testing.T) {
 		})
 	}
 }
+
+func TestManager_BuildUDP_DNS(t *testing.T) {
+	watcher := &dnsWatcherMock{targets: []discovery.Target{{Host: "10.0.0.1", Port: 53}}}
+
+	manager := NewManager(&runtime.Configuration{UDPServices: map[string]*runtime.UDPServiceInfo{
+		"test@provider-1": {
+			UDPService: &dynamic.UDPService{
+				LoadBalancer: &dynamic.UDPServersLoadBalancer{
+					DNS: &dynamic.DNSDiscovery{Name: "legacy.example.com", RecordType: "A", Port: 53},
+				},
+			},
+		},
+	}})
+
+	_, err := manager.BuildUDP(provider.AddInContext(t.Context(), "test@provider-1"), "test")
+	require.EqualError(t, err, "DNS discovery is not available")
+
+	manager.SetDNSDiscovery(watcher)
+
+	handler, err := manager.BuildUDP(provider.AddInContext(t.Context(), "test@provider-1"), "test")
+	require.NoError(t, err)
+	require.NotNil(t, handler)
+
+	assert.Equal(t, "legacy.example.com", watcher.config.Name)
+}
+
+type dnsWatcherMock struct {
+	config  dynamic.DNSDiscovery
+	targets []discovery.Target
+}
+
+func (w *dnsWatcherMock) Watch(_ context.Context, config dynamic.DNSDiscovery, update func(targets []discovery.Target)) {
+	w.config = config
+
+	update(w.targets)
+}
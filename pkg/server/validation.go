@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/config/runtime"
+	"github.com/hanzoai/ingress/pkg/config/static"
+	"github.com/hanzoai/ingress/pkg/proxy/httputil"
+	"github.com/hanzoai/ingress/pkg/server/middleware"
+	"github.com/hanzoai/ingress/pkg/server/service"
+	"github.com/hanzoai/ingress/pkg/tcp"
+	"github.com/hanzoai/ingress/pkg/tls"
+)
+
+// Validation is the result of the validation of dynamic configurations.
+type Validation struct {
+	// Runtime is the runtime configuration, in which each router, service and middleware
+	// holds the errors which occurred while building it.
+	Runtime *runtime.Configuration
+	// TLSOptions holds the errors of the invalid TLS options, by name.
+	TLSOptions map[string]error
+}
+
+// ValidateConfigurations builds the given provider configurations as a configuration reload does,
+// without starting the entry points nor the health checks, and reports the errors which occurred.
+func ValidateConfigurations(ctx context.Context, staticConfiguration static.Configuration, configurations dynamic.Configurations, defaultEntryPoints []string, pluginBuilder middleware.PluginsBuilder) (*Validation, error) {
+	conf := mergeConfiguration(configurations.DeepCopy(), defaultEntryPoints)
+	conf = applyModel(conf)
+
+	tlsManager := tls.NewManager(nil)
+	tlsManager.UpdateConfigs(ctx, conf.TLS.Stores, conf.TLS.Options, conf.TLS.Certificates)
+
+	validation := &Validation{TLSOptions: make(map[string]error)}
+	for name := range conf.TLS.Options {
+		if _, err := tlsManager.Get(tls.DefaultTLSStoreName, name); err != nil {
+			validation.TLSOptions[name] = err
+		}
+	}
+
+	transportManager := service.NewTransportManager(nil)
+	transportManager.Update(conf.HTTP.ServersTransports)
+
+	proxyBuilder := httputil.NewProxyBuilder(transportManager, nil)
+	proxyBuilder.Update(conf.HTTP.ServersTransports)
+
+	dialerManager := tcp.NewDialerManager(nil)
+	dialerManager.Update(conf.TCP.ServersTransports)
+
+	managerFactory := service.NewManagerFactory(staticConfiguration, nil, nil, transportManager, proxyBuilder, nil)
+
+	routerFactory, err := NewRouterFactory(staticConfiguration, managerFactory, tlsManager, nil, pluginBuilder, dialerManager)
+	if err != nil {
+		return nil, fmt.Errorf("creating router factory: %w", err)
+	}
+
+	validation.Runtime = runtime.NewConfig(conf)
+	routerFactory.CreateRouters(validation.Runtime)
+
+	// Stops the health checks launched while creating the routers.
+	routerFactory.cancelPrevState()
+
+	return validation, nil
+}
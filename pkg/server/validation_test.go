@@ -0,0 +1,71 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/config/runtime"
+	"github.com/hanzoai/ingress/pkg/config/static"
+	"github.com/hanzoai/ingress/pkg/plugins"
+	th "github.com/hanzoai/ingress/pkg/testhelpers"
+	"github.com/hanzoai/ingress/pkg/tls"
+)
+
+func TestValidateConfigurations(t *testing.T) {
+	staticConfiguration := static.Configuration{
+		EntryPoints: map[string]*static.EntryPoint{
+			"web": {},
+		},
+	}
+
+	configurations := dynamic.Configurations{
+		"internal": &dynamic.Configuration{
+			HTTP: &dynamic.HTTPConfiguration{
+				ServersTransports: map[string]*dynamic.ServersTransport{"default": {}},
+			},
+		},
+		"file": &dynamic.Configuration{
+			HTTP: th.BuildConfiguration(
+				th.WithRouters(
+					th.WithRouter("valid",
+						th.WithRule("Host(`foo.com`)"),
+						th.WithServiceName("whoami")),
+					th.WithRouter("invalid-rule",
+						th.WithRule("Host(`foo.com`"),
+						th.WithServiceName("whoami")),
+					th.WithRouter("missing-service",
+						th.WithRule("Path(`/foo`)"),
+						th.WithServiceName("missing")),
+				),
+				th.WithServices(
+					th.WithService("whoami", th.WithServiceServersLoadBalancer(th.WithServers(th.WithServer("http://127.0.0.1:8080")))),
+				),
+			),
+			TLS: &dynamic.TLSConfiguration{
+				Options: map[string]tls.Options{
+					"invalid": {CipherSuites: []string{"foo"}},
+				},
+			},
+		},
+	}
+
+	validation, err := ValidateConfigurations(t.Context(), staticConfiguration, configurations, []string{"web"}, (*plugins.Builder)(nil))
+	require.NoError(t, err)
+
+	require.Contains(t, validation.Runtime.Routers, "valid@file")
+	assert.Equal(t, runtime.StatusEnabled, validation.Runtime.Routers["valid@file"].Status)
+	assert.Empty(t, validation.Runtime.Routers["valid@file"].Err)
+
+	require.Contains(t, validation.Runtime.Routers, "invalid-rule@file")
+	assert.Equal(t, runtime.StatusDisabled, validation.Runtime.Routers["invalid-rule@file"].Status)
+	assert.NotEmpty(t, validation.Runtime.Routers["invalid-rule@file"].Err)
+
+	require.Contains(t, validation.Runtime.Routers, "missing-service@file")
+	assert.Equal(t, runtime.StatusDisabled, validation.Runtime.Routers["missing-service@file"].Status)
+	assert.Equal(t, []string{`the service "missing@file" does not exist`}, validation.Runtime.Routers["missing-service@file"].Err)
+
+	assert.Contains(t, validation.TLSOptions, "invalid@file")
+	assert.NotContains(t, validation.TLSOptions, "default")
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
 	ptypes "github.com/hanzoai/ingress-parser/types"
 	"github.com/hanzoai/ingress/pkg/config/dynamic"
+	"github.com/hanzoai/ingress/pkg/proxy/egress"
 	ingresstls "github.com/hanzoai/ingress/pkg/tls"
 	"github.com/hanzoai/ingress/pkg/types"
 )
@@ -42,6 +43,7 @@ type tcpDialer struct {
 	dialer           *net.Dialer
 	terminationDelay time.Duration
 	proxyProtocol    *dynamic.ProxyProtocol
+	egressProxy      *egress.Proxy
 }
 
 // TerminationDelay returns the termination delay duration.
@@ -56,7 +58,13 @@ func (d tcpDialer) Dial(network, addr string, clientConn ClientConn) (net.Conn,
 
 // DialContext dials a network connection and optionally sends a PROXY protocol header, with context.
 func (d tcpDialer) DialContext(ctx context.Context, network, addr string, clientConn ClientConn) (net.Conn, error) {
-	conn, err := d.dialer.DialContext(ctx, network, addr)
+	var conn net.Conn
+	var err error
+	if d.egressProxy != nil {
+		conn, err = d.egressProxy.DialContext(ctx, d.dialer, network, addr)
+	} else {
+		conn, err = d.dialer.DialContext(ctx, network, addr)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -195,6 +203,15 @@ func (d *DialerManager) Build(config *dynamic.TCPServersLoadBalancer, isTLS bool
 		}
 	}
 
+	var egressProxy *egress.Proxy
+	if st.Proxy != nil {
+		var err error
+		egressProxy, err = egress.New(st.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("creating forward proxy: %w", err)
+		}
+	}
+
 	dialer := tcpDialer{
 		dialer: &net.Dialer{
 			Timeout:   time.Duration(st.DialTimeout),
@@ -202,6 +219,7 @@ func (d *DialerManager) Build(config *dynamic.TCPServersLoadBalancer, isTLS bool
 		},
 		terminationDelay: time.Duration(terminationDelay),
 		proxyProtocol:    proxyProtocol,
+		egressProxy:      egressProxy,
 	}
 
 	if !isTLS {
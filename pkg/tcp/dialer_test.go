@@ -11,6 +11,8 @@ import (
 	"io"
 	"math/big"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 	"time"
@@ -177,6 +179,72 @@ func TestNoTLS(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestForwardProxy(t *testing.T) {
+	backendListener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	defer backendListener.Close()
+
+	go fakeServer(t, backendListener)
+
+	_, port, err := net.SplitHostPort(backendListener.Addr().String())
+	require.NoError(t, err)
+
+	var connectHost string
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		connectHost = req.Host
+
+		// The backend host name is resolved by the forward proxy.
+		conn, err := net.Dial("tcp", backendListener.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+
+		hj, ok := rw.(http.Hijacker)
+		require.True(t, ok)
+
+		clientConn, _, err := hj.Hijack()
+		require.NoError(t, err)
+		defer clientConn.Close()
+
+		_, err = clientConn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		require.NoError(t, err)
+
+		go func() { _, _ = io.Copy(conn, clientConn) }()
+		_, _ = io.Copy(clientConn, conn)
+	}))
+	defer proxyServer.Close()
+
+	dialerManager := NewDialerManager(nil)
+
+	dynamicConf := map[string]*dynamic.TCPServersTransport{
+		"test": {
+			Proxy: &dynamic.ForwardProxy{URL: proxyServer.URL},
+		},
+	}
+
+	dialerManager.Update(dynamicConf)
+
+	dialer, err := dialerManager.Build(&dynamic.TCPServersLoadBalancer{ServersTransport: "test"}, false)
+	require.NoError(t, err)
+
+	conn, err := dialer.Dial("tcp", "backend.example.com:"+port, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "backend.example.com:"+port, connectHost)
+
+	_, err = conn.Write([]byte("ping\n"))
+	require.NoError(t, err)
+
+	buf := make([]byte, 64)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, n)
+	assert.Equal(t, "PONG", string(buf[:4]))
+
+	err = conn.Close()
+	require.NoError(t, err)
+}
+
 func TestTLS(t *testing.T) {
 	cert, err := tls.X509KeyPair(LocalhostCert, LocalhostKey)
 	require.NoError(t, err)
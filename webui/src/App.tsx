@@ -10,7 +10,7 @@ import fetch from './libs/fetch'
 import { VersionProvider } from 'contexts/version'
 import { useIsDarkMode } from 'hooks/use-theme'
 import ErrorSuspenseWrapper from 'layout/ErrorSuspenseWrapper'
-import { Dashboard, HTTPPages, NotFound, TCPPages, UDPPages } from 'pages'
+import { ConfigPages, Dashboard, HTTPPages, NotFound, TCPPages, UDPPages } from 'pages'
 import { DashboardSkeleton } from 'pages/dashboard/Dashboard'
 
 export const LIGHT_THEME = lightTheme('blue')
@@ -63,6 +63,7 @@ export const Routes = () => {
           <Route path="/http" element={<Navigate to="/http/routers" replace />} />
           <Route path="/tcp" element={<Navigate to="/tcp/routers" replace />} />
           <Route path="/udp" element={<Navigate to="/udp/routers" replace />} />
+          <Route path="/config/history" element={<ConfigPages.ConfigHistory />} />
 
           <Route path="*" element={<NotFound />} />
         </RouterRoutes>
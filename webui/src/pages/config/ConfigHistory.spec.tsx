@@ -0,0 +1,76 @@
+import { ConfigHistory as ConfigHistoryPage, ConfigHistoryRender, makeRowRender } from './ConfigHistory'
+
+import * as useFetchWithPagination from 'hooks/use-fetch-with-pagination'
+import { useFetchWithPaginationMock } from 'utils/mocks'
+import { renderWithProviders } from 'utils/test'
+
+describe('<ConfigHistoryPage />', () => {
+  it('should render the configuration history', () => {
+    const pages = [
+      {
+        id: 2,
+        appliedAt: '2026-10-19T10:00:00Z',
+        providers: [
+          {
+            provider: 'docker',
+            changes: [
+              { kind: 'http.routers', name: 'whoami', action: 'modified', fields: ['rule', 'priority'] },
+              { kind: 'http.services', name: 'whoami', action: 'added' },
+            ],
+          },
+          {
+            provider: 'file',
+            changes: [{ kind: 'tls.certificates', action: 'removed' }],
+          },
+        ],
+      },
+      {
+        id: 1,
+        appliedAt: '2026-10-19T09:00:00Z',
+      },
+    ].map(makeRowRender())
+    const mock = vi
+      .spyOn(useFetchWithPagination, 'default')
+      .mockImplementation(() => useFetchWithPaginationMock({ pages }))
+
+    const { container, getByTestId } = renderWithProviders(<ConfigHistoryPage />, {
+      route: '/config/history',
+      withPage: true,
+    })
+
+    expect(mock).toHaveBeenCalled()
+    expect(getByTestId('/config/history page')).toBeInTheDocument()
+    const tbody = container.querySelectorAll('div[role="table"] > div[role="rowgroup"]')[1]
+    const rows = tbody.querySelectorAll('div[role="row"]')
+    expect(rows).toHaveLength(2)
+
+    expect(rows[0].querySelector('[data-testid="provider-docker"]')?.innerHTML).toContain('rule, priority')
+    expect(rows[0].querySelector('[data-testid="provider-docker"]')?.innerHTML).toContain('http.services')
+    expect(rows[0].querySelector('svg[data-testid="docker"]')).toBeTruthy()
+    expect(rows[0].querySelector('[data-testid="provider-file"]')?.innerHTML).toContain('tls.certificates')
+    expect(rows[0].querySelector('[data-testid="provider-file"]')?.innerHTML).toContain('removed')
+
+    expect(rows[1].innerHTML).toContain('No changes')
+  })
+
+  it('should render "No data available" when the API returns empty array', async () => {
+    const { container, getByTestId } = renderWithProviders(
+      <ConfigHistoryRender
+        error={undefined}
+        isEmpty={true}
+        isLoadingMore={false}
+        isReachingEnd={true}
+        loadMore={() => {}}
+        pageCount={1}
+        pages={[]}
+      />,
+      { route: '/config/history', withPage: true },
+    )
+    expect(() => getByTestId('loading')).toThrow('Unable to find an element by: [data-testid="loading"]')
+    const tfoot = container.querySelectorAll('div[role="table"] > div[role="rowgroup"]')[2]
+    expect(tfoot.querySelectorAll('div[role="row"]')).toHaveLength(1)
+    expect(tfoot.querySelectorAll('div[role="row"]')[0].querySelector('span')?.innerHTML).toContain(
+      'No data available',
+    )
+  })
+})
@@ -0,0 +1,135 @@
+import { AriaTable, AriaTbody, AriaTd, AriaTfoot, AriaThead, AriaTr, Badge, Flex, Text } from '@traefiklabs/faency'
+import useInfiniteScroll from 'react-infinite-scroll-hook'
+
+import { ScrollTopButton } from 'components/buttons/ScrollTopButton'
+import { ProviderIconWithTooltip } from 'components/icons/providers'
+import { SpinnerLoader } from 'components/SpinnerLoader'
+import SortableTh from 'components/tables/SortableTh'
+import useFetchWithPagination, { pagesResponseInterface, RenderRowType } from 'hooks/use-fetch-with-pagination'
+import { EmptyPlaceholderTd } from 'layout/EmptyPlaceholder'
+import PageTitle from 'layout/PageTitle'
+
+type Change = {
+  kind: string
+  name?: string
+  action: 'added' | 'removed' | 'modified'
+  fields?: string[]
+}
+
+type ProviderDiff = {
+  provider: string
+  changes: Change[]
+}
+
+const ACTION_VARIANTS: Partial<Record<Change['action'], 'green' | 'blue'>> = {
+  added: 'green',
+  modified: 'blue',
+}
+
+const ChangeItem = ({ change }: { change: Change }) => (
+  <Flex gap={2} css={{ alignItems: 'center', flexWrap: 'wrap' }}>
+    <Badge variant={ACTION_VARIANTS[change.action]}>{change.action}</Badge>
+    <Text>{change.kind}</Text>
+    {change.name && <Text css={{ fontWeight: 600 }}>{change.name}</Text>}
+    {!!change.fields?.length && <Text variant="subtle">({change.fields.join(', ')})</Text>}
+  </Flex>
+)
+
+export const makeRowRender = (): RenderRowType => {
+  const ConfigHistoryRenderRow = (row) => {
+    const providers = (row.providers as ProviderDiff[]) || []
+
+    return (
+      <AriaTr key={row.id as number}>
+        <AriaTd css={{ verticalAlign: 'top' }}>
+          <Text>{new Date(row.appliedAt as string).toLocaleString()}</Text>
+        </AriaTd>
+        <AriaTd>
+          <Flex direction="column" gap={3}>
+            {providers.length ? (
+              providers.map((diff) => (
+                <Flex key={diff.provider} gap={3} data-testid={`provider-${diff.provider}`}>
+                  <ProviderIconWithTooltip provider={diff.provider} />
+                  <Flex direction="column" gap={1}>
+                    {diff.changes.map((change) => (
+                      <ChangeItem key={`${change.kind}-${change.name}`} change={change} />
+                    ))}
+                  </Flex>
+                </Flex>
+              ))
+            ) : (
+              <Text variant="subtle">No changes</Text>
+            )}
+          </Flex>
+        </AriaTd>
+      </AriaTr>
+    )
+  }
+  return ConfigHistoryRenderRow
+}
+
+export const ConfigHistoryRender = ({
+  error,
+  isEmpty,
+  isLoadingMore,
+  isReachingEnd,
+  loadMore,
+  pageCount,
+  pages,
+}: pagesResponseInterface) => {
+  const [infiniteRef] = useInfiniteScroll({
+    loading: isLoadingMore,
+    hasNextPage: !isReachingEnd && !error,
+    onLoadMore: loadMore,
+  })
+
+  return (
+    <>
+      <AriaTable>
+        <AriaThead>
+          <AriaTr>
+            <SortableTh label="Applied at" css={{ width: '200px' }} />
+            <SortableTh label="Changes" />
+          </AriaTr>
+        </AriaThead>
+        <AriaTbody>{pages}</AriaTbody>
+        {(isEmpty || !!error) && (
+          <AriaTfoot>
+            <AriaTr>
+              <EmptyPlaceholderTd message={error ? 'Failed to fetch data' : 'No data available'} />
+            </AriaTr>
+          </AriaTfoot>
+        )}
+      </AriaTable>
+      <Flex css={{ height: 60, alignItems: 'center', justifyContent: 'center' }} ref={infiniteRef}>
+        {isLoadingMore ? <SpinnerLoader /> : isReachingEnd && pageCount > 1 && <ScrollTopButton />}
+      </Flex>
+    </>
+  )
+}
+
+export const ConfigHistory = () => {
+  const renderRow = makeRowRender()
+  const { pages, pageCount, isLoadingMore, isReachingEnd, loadMore, error, isEmpty } = useFetchWithPagination(
+    '/config/history',
+    {
+      renderRow,
+      renderLoader: () => null,
+    },
+  )
+
+  return (
+    <>
+      <PageTitle title="Configuration History" />
+      <ConfigHistoryRender
+        error={error}
+        isEmpty={isEmpty}
+        isLoadingMore={isLoadingMore}
+        isReachingEnd={isReachingEnd}
+        loadMore={loadMore}
+        pageCount={pageCount}
+        pages={pages}
+      />
+    </>
+  )
+}
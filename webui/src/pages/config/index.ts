@@ -0,0 +1 @@
+export { ConfigHistory } from './ConfigHistory'
@@ -1,7 +1,8 @@
+import * as ConfigPages from './config'
 import * as HTTPPages from './http'
 import * as TCPPages from './tcp'
 import * as UDPPages from './udp'
 
 export { Dashboard } from './dashboard/Dashboard'
 export { NotFound } from './NotFound'
-export { HTTPPages, TCPPages, UDPPages }
+export { ConfigPages, HTTPPages, TCPPages, UDPPages }
@@ -1,5 +1,5 @@
 import { ReactNode } from 'react'
-import { LiaProjectDiagramSolid, LiaServerSolid, LiaCogsSolid, LiaHomeSolid } from 'react-icons/lia'
+import { LiaProjectDiagramSolid, LiaServerSolid, LiaCogsSolid, LiaHomeSolid, LiaHistorySolid } from 'react-icons/lia'
 
 export type Route = {
   path: string
@@ -91,4 +91,15 @@ export const ROUTES: RouteSections[] = [
       },
     ],
   },
+  {
+    section: 'config',
+    sectionLabel: 'Configuration',
+    items: [
+      {
+        path: '/config/history',
+        label: 'History',
+        icon: <LiaHistorySolid color="currentColor" size={20} />,
+      },
+    ],
+  },
 ]